@@ -483,6 +483,7 @@ export interface DataQueryRequest<TQuery extends DataQuery = DataQuery> {
   timeInfo?: string; // The query time description (blue text in the upper right)
   panelId?: number;
   dashboardId?: number;
+  dashboardUID?: string;
   publicDashboardAccessToken?: string;
 
   // Request Timing
@@ -87,6 +87,7 @@ describe('DataSourceWithBackend', () => {
             },
           ],
         },
+        "headers": Object {},
         "method": "POST",
         "requestId": undefined,
         "url": "/api/ds/query",
@@ -94,6 +95,29 @@ describe('DataSourceWithBackend', () => {
     `);
   });
 
+  test('it sends the dashboard and panel of the query', () => {
+    const settings = {
+      name: 'test',
+      id: 1234,
+      uid: 'abc',
+      type: 'dummy',
+      jsonData: {},
+    } as DataSourceInstanceSettings<DataSourceJsonData>;
+
+    mockDatasourceRequest.mockReset();
+    mockDatasourceRequest.mockReturnValue(Promise.resolve({}));
+    const ds = new MyDataSource(settings);
+
+    ds.query({
+      dashboardUID: 'dash',
+      panelId: 2,
+      targets: [{ refId: 'A' }],
+    } as DataQueryRequest);
+
+    const args = mockDatasourceRequest.mock.calls[0][0];
+    expect(args.headers).toEqual({ 'X-Dashboard-Uid': 'dash', 'X-Panel-Id': '2' });
+  });
+
   test('it converts results with channels to streaming queries', () => {
     const request: DataQueryRequest = {
       intervalMs: 100,
@@ -110,7 +110,7 @@ class DataSourceWithBackend<
    * Ideally final -- any other implementation may not work as expected
    */
   query(request: DataQueryRequest<TQuery>): Observable<DataQueryResponse> {
-    const { intervalMs, maxDataPoints, range, requestId } = request;
+    const { intervalMs, maxDataPoints, range, requestId, dashboardUID, panelId } = request;
     let targets = request.targets;
 
     if (this.filterQuery) {
@@ -168,12 +168,20 @@ class DataSourceWithBackend<
       });
     }
 
+    // Lets the server track the queries of dashboard panels
+    const headers: Record<string, string> = {};
+    if (dashboardUID && panelId !== undefined) {
+      headers['X-Dashboard-Uid'] = dashboardUID;
+      headers['X-Panel-Id'] = String(panelId);
+    }
+
     return getBackendSrv()
       .fetch<BackendDataSourceResponse>({
         url: '/api/ds/query',
         method: 'POST',
         data: body,
         requestId,
+        headers,
       })
       .pipe(
         switchMap((raw) => {
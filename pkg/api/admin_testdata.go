@@ -0,0 +1,109 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strconv"
+
+	"github.com/grafana/grafana/pkg/api/dtos"
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/store"
+	"github.com/grafana/grafana/pkg/tsdb/legacydata"
+	"github.com/grafana/grafana/pkg/tsdb/testdatasource"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+// swagger:route POST /admin/testdata/recordings admin_testdata adminRecordQuery
+//
+// Record the response of a data source query.
+//
+// Runs the queries and stores the returned frames in the resources storage, under `testdata/recordings/<name>.json`.
+// The recording can be replayed with the `Recorded` scenario of the TestData data source, to build reproducible fixtures for dashboards and alert rules.
+// The request is rejected if any of the queries returns an error.
+//
+// Security:
+// - basic:
+//
+// Responses:
+// 200: adminRecordQueryResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 500: internalServerError
+func (hs *HTTPServer) AdminRecordQuery(c *models.ReqContext) response.Response {
+	cmd := dtos.RecordQueryCommand{}
+	if err := web.Bind(c.Req, &cmd); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+
+	recordingPath, err := testdatasource.RecordingPath(cmd.Name)
+	if err != nil {
+		return response.Error(http.StatusBadRequest, err.Error(), err)
+	}
+	path := store.RootResources + "/" + recordingPath
+
+	// resolve relative times once, so the recorded time range is the one of the executed queries
+	timeRange := legacydata.NewDataTimeRange(cmd.Query.From, cmd.Query.To)
+	from, err := timeRange.ParseFrom()
+	if err != nil {
+		return response.Error(http.StatusBadRequest, "invalid from time", err)
+	}
+	to, err := timeRange.ParseTo()
+	if err != nil {
+		return response.Error(http.StatusBadRequest, "invalid to time", err)
+	}
+	cmd.Query.From = strconv.FormatInt(from.UnixMilli(), 10)
+	cmd.Query.To = strconv.FormatInt(to.UnixMilli(), 10)
+	cmd.Query.HTTPRequest = c.Req
+
+	resp, err := hs.queryDataService.QueryData(c.Req.Context(), c.SignedInUser, true, cmd.Query, true)
+	if err != nil {
+		return hs.handleQueryMetricsError(err)
+	}
+
+	recording, err := testdatasource.NewRecording(from, to, resp)
+	if err != nil {
+		return response.Error(http.StatusBadRequest, err.Error(), err)
+	}
+
+	contents, err := json.Marshal(recording)
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "failed to encode the recording", err)
+	}
+
+	err = hs.StorageService.Upload(c.Req.Context(), c.SignedInUser, &store.UploadRequest{
+		Contents:              contents,
+		Path:                  path,
+		EntityType:            store.EntityTypeJSON,
+		OverwriteExistingFile: cmd.Overwrite,
+	})
+	if err != nil {
+		return response.Error(store.UploadErrorToStatusCode(err), err.Error(), err)
+	}
+
+	refIDs := make([]string, 0, len(recording.Frames))
+	for refID := range recording.Frames {
+		refIDs = append(refIDs, refID)
+	}
+	sort.Strings(refIDs)
+
+	return response.JSON(http.StatusOK, dtos.RecordQueryResult{
+		Path:   path,
+		RefIDs: refIDs,
+	})
+}
+
+// swagger:parameters adminRecordQuery
+type AdminRecordQueryParams struct {
+	// in:body
+	// required:true
+	Body dtos.RecordQueryCommand `json:"body"`
+}
+
+// swagger:response adminRecordQueryResponse
+type AdminRecordQueryResponse struct {
+	// in:body
+	Body dtos.RecordQueryResult `json:"body"`
+}
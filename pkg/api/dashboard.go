@@ -224,6 +224,12 @@ func (hs *HTTPServer) GetDashboard(c *models.ReqContext) response.Response {
 		Meta:      meta,
 	}
 
+	if hs.dashboardUsageService != nil {
+		if err := hs.dashboardUsageService.RecordView(c.Req.Context(), c.OrgId, dash.Uid); err != nil {
+			hs.log.Warn("Failed to record dashboard view", "uid", dash.Uid, "err", err)
+		}
+	}
+
 	c.TimeRequest(metrics.MApiDashboardGet)
 	return response.JSON(http.StatusOK, dto)
 }
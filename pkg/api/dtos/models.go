@@ -75,6 +75,28 @@ type MetricRequest struct {
 	HTTPRequest *http.Request `json:"-"`
 }
 
+// swagger:model
+type RecordQueryCommand struct {
+	// Name of the recording, letters, digits, dashes and underscores only.
+	// required: true
+	// example: node-cpu
+	Name string `json:"name"`
+	// Queries to record, as sent to /api/ds/query.
+	// required: true
+	Query MetricRequest `json:"query"`
+	// Replace an existing recording with the same name.
+	// required: false
+	Overwrite bool `json:"overwrite"`
+}
+
+// swagger:model
+type RecordQueryResult struct {
+	// Path of the recording in the storage service.
+	Path string `json:"path"`
+	// RefIDs of the recorded queries.
+	RefIDs []string `json:"refIds"`
+}
+
 func (mr *MetricRequest) CloneWithQueries(queries []*simplejson.Json) MetricRequest {
 	return MetricRequest{
 		From:        mr.From,
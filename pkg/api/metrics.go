@@ -4,6 +4,8 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"strconv"
+	"time"
 
 	"github.com/grafana/grafana-plugin-sdk-go/backend"
 
@@ -11,8 +13,10 @@ import (
 	"github.com/grafana/grafana/pkg/api/response"
 	"github.com/grafana/grafana/pkg/models"
 	"github.com/grafana/grafana/pkg/plugins/backendplugin"
+	"github.com/grafana/grafana/pkg/services/dashboardusage"
 	"github.com/grafana/grafana/pkg/services/datasources"
 	"github.com/grafana/grafana/pkg/services/featuremgmt"
+	"github.com/grafana/grafana/pkg/services/guardian"
 	"github.com/grafana/grafana/pkg/services/query"
 	"github.com/grafana/grafana/pkg/util"
 	"github.com/grafana/grafana/pkg/web"
@@ -67,12 +71,77 @@ func (hs *HTTPServer) QueryMetricsV2(c *models.ReqContext) response.Response {
 	reqDTO.HTTPRequest = c.Req
 
 	resp, err := hs.queryDataService.QueryData(c.Req.Context(), c.SignedInUser, c.SkipCache, reqDTO, true)
+	hs.recordPanelQueries(c, len(reqDTO.Queries), resp, err)
 	if err != nil {
 		return hs.handleQueryMetricsError(err)
 	}
 	return hs.toJsonStreamingResponse(resp)
 }
 
+// panelUsageViewCacheTTL is how long the result of the dashboard lookup and view check
+// for recording panel queries is cached, so a dashboard refresh doesn't read the
+// dashboard and its permissions once per panel query.
+const panelUsageViewCacheTTL = time.Minute
+
+// recordPanelQueries counts the executed and failed queries of the dashboard panel
+// identified by the X-Dashboard-Uid and X-Panel-Id headers of the request. The headers
+// are set by the client, so queries are only recorded for dashboards of the organization
+// the user can view.
+func (hs *HTTPServer) recordPanelQueries(c *models.ReqContext, queries int, resp *backend.QueryDataResponse, queryErr error) {
+	if hs.dashboardUsageService == nil || hs.dashboardUsageService.IsDisabled() || queries == 0 {
+		return
+	}
+
+	dashboardUID := c.Req.Header.Get("X-Dashboard-Uid")
+	panelID, err := strconv.ParseInt(c.Req.Header.Get("X-Panel-Id"), 10, 64)
+	if dashboardUID == "" || err != nil {
+		return
+	}
+
+	if !hs.canViewUsageDashboard(c, dashboardUID) {
+		return
+	}
+
+	cmd := dashboardusage.RecordPanelUsageCommand{Queries: int64(queries)}
+	switch {
+	case queryErr != nil:
+		cmd.Errors = int64(queries)
+	case resp != nil:
+		for _, res := range resp.Responses {
+			if res.Error != nil {
+				cmd.Errors++
+			}
+		}
+	}
+
+	if err := hs.dashboardUsageService.RecordPanelUsage(c.Req.Context(), c.OrgId, dashboardUID, panelID, cmd); err != nil {
+		hs.log.Debug("Failed to record panel usage", "uid", dashboardUID, "panelId", panelID, "err", err)
+	}
+}
+
+// canViewUsageDashboard reports whether the dashboard exists in the organization of the
+// signed in user and the user can view it. The result is cached per organization, user
+// and dashboard for panelUsageViewCacheTTL.
+func (hs *HTTPServer) canViewUsageDashboard(c *models.ReqContext, dashboardUID string) bool {
+	cacheKey := fmt.Sprintf("panel-usage-view-%d-%d-%s", c.OrgId, c.UserId, dashboardUID)
+	if hs.CacheService != nil {
+		if cached, found := hs.CacheService.Get(cacheKey); found {
+			return cached.(bool)
+		}
+	}
+
+	canView := false
+	if dash, rsp := hs.getDashboardHelper(c.Req.Context(), c.OrgId, 0, dashboardUID); rsp == nil {
+		ok, err := guardian.New(c.Req.Context(), dash.Id, c.OrgId, c.SignedInUser).CanView()
+		canView = err == nil && ok
+	}
+
+	if hs.CacheService != nil {
+		hs.CacheService.Set(cacheKey, canView, panelUsageViewCacheTTL)
+	}
+	return canView
+}
+
 func (hs *HTTPServer) toJsonStreamingResponse(qdr *backend.QueryDataResponse) response.Response {
 	statusWhenError := http.StatusBadRequest
 	if hs.Features.IsEnabled(featuremgmt.FlagDatasourceQueryMultiStatus) {
@@ -10,10 +10,15 @@ import (
 	"testing"
 
 	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 
+	"github.com/grafana/grafana/pkg/infra/localcache"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/dashboardusage"
 	"github.com/grafana/grafana/pkg/services/datasources"
 	"github.com/grafana/grafana/pkg/services/featuremgmt"
+	"github.com/grafana/grafana/pkg/services/guardian"
 	"github.com/grafana/grafana/pkg/services/quota/quotatest"
 	"github.com/grafana/grafana/pkg/web/webtest"
 
@@ -113,6 +118,94 @@ func TestAPIEndpoint_Metrics_QueryMetricsV2(t *testing.T) {
 		require.NoError(t, resp.Body.Close())
 		require.Equal(t, http.StatusMultiStatus, resp.StatusCode)
 	})
+
+	recordPanelQueries := func(t *testing.T, dashboardUID string, canView bool, disabled bool, requests int) ([]recordedPanelUsage, *dashboards.FakeDashboardService) {
+		t.Helper()
+		origNewGuardian := guardian.New
+		t.Cleanup(func() { guardian.New = origNewGuardian })
+		guardian.MockDashboardGuardian(&guardian.FakeDashboardGuardian{CanViewValue: canView})
+
+		dashSvc := dashboards.NewFakeDashboardService(t)
+		dashSvc.On("GetDashboard", mock.Anything, mock.AnythingOfType("*models.GetDashboardQuery")).Return(func(_ context.Context, q *models.GetDashboardQuery) error {
+			if q.Uid != "dash-a" || q.OrgId != 1 {
+				return dashboards.ErrDashboardNotFound
+			}
+			q.Result = &models.Dashboard{Id: 10, Uid: q.Uid, OrgId: q.OrgId}
+			return nil
+		}).Maybe()
+
+		usage := &fakeDashboardUsageService{disabled: disabled}
+		server := SetupAPITestServer(t, func(hs *HTTPServer) {
+			hs.CacheService = localcache.ProvideService()
+			hs.queryDataService = qds
+			hs.Features = featuremgmt.WithFeatures()
+			hs.QuotaService = quotatest.NewQuotaServiceFake()
+			hs.DashboardService = dashSvc
+			hs.dashboardUsageService = usage
+		})
+
+		for i := 0; i < requests; i++ {
+			req := server.NewPostRequest("/api/ds/query", strings.NewReader(queryDatasourceInput))
+			req.Header.Set("X-Dashboard-Uid", dashboardUID)
+			req.Header.Set("X-Panel-Id", "2")
+			webtest.RequestWithSignedInUser(req, &models.SignedInUser{UserId: 1, OrgId: 1, OrgRole: models.ROLE_VIEWER})
+			resp, err := server.SendJSON(req)
+			require.NoError(t, err)
+			require.NoError(t, resp.Body.Close())
+		}
+		return usage.panels, dashSvc
+	}
+
+	t.Run("Queries and errors of the panel are recorded", func(t *testing.T) {
+		panels, _ := recordPanelQueries(t, "dash-a", true, false, 1)
+		require.Equal(t, []recordedPanelUsage{
+			{orgID: 1, dashboardUID: "dash-a", panelID: 2, cmd: dashboardusage.RecordPanelUsageCommand{Queries: 1, Errors: 1}},
+		}, panels)
+	})
+
+	t.Run("Queries of dashboards missing in the organization are not recorded", func(t *testing.T) {
+		panels, _ := recordPanelQueries(t, "made-up", true, false, 1)
+		require.Empty(t, panels)
+	})
+
+	t.Run("Queries of dashboards the user can't view are not recorded", func(t *testing.T) {
+		panels, _ := recordPanelQueries(t, "dash-a", false, false, 1)
+		require.Empty(t, panels)
+	})
+
+	t.Run("Dashboard is not read when usage tracking is disabled", func(t *testing.T) {
+		panels, dashSvc := recordPanelQueries(t, "dash-a", true, true, 1)
+		require.Empty(t, panels)
+		dashSvc.AssertNotCalled(t, "GetDashboard", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Dashboard is read once for repeated queries of the same user", func(t *testing.T) {
+		panels, dashSvc := recordPanelQueries(t, "dash-a", true, false, 3)
+		require.Len(t, panels, 3)
+		dashSvc.AssertNumberOfCalls(t, "GetDashboard", 1)
+	})
+}
+
+type recordedPanelUsage struct {
+	orgID        int64
+	dashboardUID string
+	panelID      int64
+	cmd          dashboardusage.RecordPanelUsageCommand
+}
+
+type fakeDashboardUsageService struct {
+	dashboardusage.Service
+	disabled bool
+	panels   []recordedPanelUsage
+}
+
+func (s *fakeDashboardUsageService) IsDisabled() bool {
+	return s.disabled
+}
+
+func (s *fakeDashboardUsageService) RecordPanelUsage(_ context.Context, orgID int64, dashboardUID string, panelID int64, cmd dashboardusage.RecordPanelUsageCommand) error {
+	s.panels = append(s.panels, recordedPanelUsage{orgID: orgID, dashboardUID: dashboardUID, panelID: panelID, cmd: cmd})
+	return nil
 }
 
 func TestAPIEndpoint_Metrics_PluginDecryptionFailure(t *testing.T) {
@@ -54,6 +54,7 @@ import (
 	"github.com/grafana/grafana/pkg/services/dashboardsnapshots"
 	dashsnapstore "github.com/grafana/grafana/pkg/services/dashboardsnapshots/database"
 	dashsnapsvc "github.com/grafana/grafana/pkg/services/dashboardsnapshots/service"
+	"github.com/grafana/grafana/pkg/services/dashboardusage"
 	"github.com/grafana/grafana/pkg/services/dashboardversion/dashverimpl"
 	"github.com/grafana/grafana/pkg/services/datasourceproxy"
 	"github.com/grafana/grafana/pkg/services/datasources"
@@ -131,6 +132,7 @@ import (
 	"github.com/grafana/grafana/pkg/tsdb/mysql"
 	"github.com/grafana/grafana/pkg/tsdb/postgres"
 	"github.com/grafana/grafana/pkg/tsdb/prometheus"
+	"github.com/grafana/grafana/pkg/tsdb/sqlite"
 	"github.com/grafana/grafana/pkg/tsdb/tempo"
 	"github.com/grafana/grafana/pkg/tsdb/testdatasource"
 	"github.com/grafana/grafana/pkg/web"
@@ -187,6 +189,7 @@ var wireSet = wire.NewSet(
 	azuremonitor.ProvideService,
 	postgres.ProvideService,
 	mysql.ProvideService,
+	sqlite.ProvideService,
 	mssql.ProvideService,
 	store.ProvideEntityEventsService,
 	httpclientprovider.New,
@@ -209,6 +212,8 @@ var wireSet = wire.NewSet(
 	datasourceproxy.ProvideService,
 	search.ProvideService,
 	searchV2.ProvideService,
+	dashboardusage.ProvideService,
+	wire.Bind(new(dashboardusage.Service), new(*dashboardusage.DashboardUsageService)),
 	store.ProvideService,
 	export.ProvideService,
 	live.ProvideService,
@@ -226,6 +231,8 @@ var wireSet = wire.NewSet(
 	notifications.ProvideSmtpService,
 	metrics.ProvideService,
 	testdatasource.ProvideService,
+	store.ProvideDatasourceResources,
+	wire.Bind(new(testdatasource.ResourceReader), new(*store.DatasourceResources)),
 	social.ProvideService,
 	influxdb.ProvideService,
 	wire.Bind(new(social.Service), new(*social.SocialService)),
@@ -26,6 +26,12 @@ var (
 	maxPathPartLength        = 256
 )
 
+var (
+	ErrVersioningNotSupported = errors.New("storage does not support file versions")
+	ErrVersionNotFound        = errors.New("file version not found")
+	ErrVersionAccessDenied    = errors.New("no access to the file versions")
+)
+
 func ValidatePath(path string) error {
 	if !strings.HasPrefix(path, Delimiter) {
 		return ErrRelativePath
@@ -92,6 +98,15 @@ type FileMetadata struct {
 	Properties map[string]string
 }
 
+// FileVersion describes a single historical revision of a file
+type FileVersion struct {
+	Version  int64     `json:"version"`
+	ETag     string    `json:"etag"`
+	MimeType string    `json:"mimeType"`
+	Size     int64     `json:"size"`
+	Created  time.Time `json:"created"`
+}
+
 type Paging struct {
 	After string
 	First int
@@ -180,3 +195,16 @@ type FileStorage interface {
 
 	close() error
 }
+
+// FileVersionStorage is implemented by storages which keep the history of file contents
+type FileVersionStorage interface {
+	// ListVersions lists all the recorded versions of a file, newest first
+	ListVersions(ctx context.Context, path string) ([]*FileVersion, error)
+
+	// GetVersion returns the file as it was stored in the given version
+	GetVersion(ctx context.Context, path string, version int64) (*File, error)
+
+	// RestoreVersion writes the contents of the given version back to the file.
+	// Restoring creates a new version, so the history is never rewritten.
+	RestoreVersion(ctx context.Context, path string, version int64) error
+}
@@ -47,6 +47,9 @@ type fileMeta struct {
 type dbFileStorage struct {
 	db  *sqlstore.SQLStore
 	log log.Logger
+
+	// versioned storages keep the history of file contents in the `file_version` table
+	versioned bool
 }
 
 func createPathHash(path string) (string, error) {
@@ -178,6 +181,12 @@ func (s dbFileStorage) Upsert(ctx context.Context, cmd *UpsertFileCommand) error
 		}
 
 		if exists {
+			if s.versioned && cmd.Contents != nil {
+				if err = recordInitialVersion(sess, existing); err != nil {
+					return err
+				}
+			}
+
 			existing.Updated = now
 			if cmd.Contents != nil {
 				contents := cmd.Contents
@@ -193,6 +202,12 @@ func (s dbFileStorage) Upsert(ctx context.Context, cmd *UpsertFileCommand) error
 			if err != nil {
 				return err
 			}
+
+			if s.versioned && cmd.Contents != nil {
+				if err = recordVersion(sess, existing, now); err != nil {
+					return err
+				}
+			}
 		} else {
 			contentsToInsert := make([]byte, 0)
 			if cmd.Contents != nil {
@@ -221,6 +236,12 @@ func (s dbFileStorage) Upsert(ctx context.Context, cmd *UpsertFileCommand) error
 			if _, err = sess.Insert(file); err != nil {
 				return err
 			}
+
+			if s.versioned {
+				if err = recordVersion(sess, file, now); err != nil {
+					return err
+				}
+			}
 		}
 
 		if len(cmd.Properties) != 0 {
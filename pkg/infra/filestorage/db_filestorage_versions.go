@@ -0,0 +1,226 @@
+package filestorage
+
+import (
+	"context"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+)
+
+type fileVersion struct {
+	Id       int64     `xorm:"pk autoincr 'id'"`
+	Path     string    `xorm:"path"`
+	PathHash string    `xorm:"path_hash"`
+	Version  int64     `xorm:"'version'"`
+	Contents []byte    `xorm:"contents"`
+	ETag     string    `xorm:"etag"`
+	MimeType string    `xorm:"mime_type"`
+	Size     int64     `xorm:"size"`
+	Created  time.Time `xorm:"created"`
+}
+
+var (
+	fileVersionColsNoContents = []string{"path", "path_hash", "version", "etag", "mime_type", "size", "created"}
+)
+
+var (
+	_ FileVersionStorage = (*dbFileStorage)(nil) // dbFileStorage implements FileVersionStorage
+)
+
+// NewVersionedDbStorage creates a SQL backed storage which records every write of file contents in the `file_version` table
+func NewVersionedDbStorage(log log.Logger, db *sqlstore.SQLStore, filter PathFilter, rootFolder string) FileStorage {
+	return newWrapper(log, &dbFileStorage{
+		log:       log,
+		db:        db,
+		versioned: true,
+	}, filter, rootFolder)
+}
+
+// recordInitialVersion snapshots files written before versioning was enabled so their original contents can be restored
+func recordInitialVersion(sess *sqlstore.DBSession, existing *file) error {
+	count, err := sess.Table("file_version").Where("path_hash = ?", existing.PathHash).Count(&fileVersion{})
+	if err != nil || count > 0 {
+		return err
+	}
+
+	return recordVersion(sess, existing, existing.Updated)
+}
+
+// recordVersion stores the current contents of the file as a new version, unless they match the latest recorded version
+func recordVersion(sess *sqlstore.DBSession, f *file, now time.Time) error {
+	latest := make([]*fileVersion, 0)
+	if err := sess.Table("file_version").
+		Where("path_hash = ?", f.PathHash).
+		Cols("version", "etag").
+		Desc("version").
+		Limit(1).
+		Find(&latest); err != nil {
+		return err
+	}
+
+	var version int64 = 1
+	if len(latest) > 0 {
+		if latest[0].ETag == f.ETag {
+			return nil
+		}
+		version = latest[0].Version + 1
+	}
+
+	contents := f.Contents
+	if contents == nil {
+		contents = make([]byte, 0)
+	}
+
+	_, err := sess.Insert(&fileVersion{
+		Path:     f.Path,
+		PathHash: f.PathHash,
+		Version:  version,
+		Contents: contents,
+		ETag:     f.ETag,
+		MimeType: f.MimeType,
+		Size:     f.Size,
+		Created:  now,
+	})
+	return err
+}
+
+func (s dbFileStorage) ListVersions(ctx context.Context, filePath string) ([]*FileVersion, error) {
+	if !s.versioned {
+		return nil, ErrVersioningNotSupported
+	}
+
+	pathHash, err := createPathHash(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	versions := make([]*FileVersion, 0)
+	err = s.db.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		entities := make([]*fileVersion, 0)
+		if err := sess.Table("file_version").
+			Where("path_hash = ?", pathHash).
+			Cols(fileVersionColsNoContents...).
+			Desc("version").
+			Find(&entities); err != nil {
+			return err
+		}
+
+		for _, entity := range entities {
+			versions = append(versions, &FileVersion{
+				Version:  entity.Version,
+				ETag:     entity.ETag,
+				MimeType: entity.MimeType,
+				Size:     entity.Size,
+				Created:  entity.Created,
+			})
+		}
+		return nil
+	})
+
+	return versions, err
+}
+
+func (s dbFileStorage) GetVersion(ctx context.Context, filePath string, version int64) (*File, error) {
+	if !s.versioned {
+		return nil, ErrVersioningNotSupported
+	}
+
+	pathHash, err := createPathHash(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	var result *File
+	err = s.db.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		entity := &fileVersion{}
+		exists, err := sess.Table("file_version").Where("path_hash = ? AND version = ?", pathHash, version).Get(entity)
+		if err != nil || !exists {
+			return err
+		}
+
+		contents := entity.Contents
+		if contents == nil {
+			contents = make([]byte, 0)
+		}
+
+		result = &File{
+			Contents: contents,
+			FileMetadata: FileMetadata{
+				Name:       getName(entity.Path),
+				FullPath:   entity.Path,
+				Created:    entity.Created,
+				Modified:   entity.Created,
+				Size:       entity.Size,
+				MimeType:   entity.MimeType,
+				Properties: map[string]string{},
+			},
+		}
+		return nil
+	})
+
+	return result, err
+}
+
+func (s dbFileStorage) RestoreVersion(ctx context.Context, filePath string, version int64) error {
+	file, err := s.GetVersion(ctx, filePath, version)
+	if err != nil {
+		return err
+	}
+
+	if file == nil {
+		return ErrVersionNotFound
+	}
+
+	return s.Upsert(ctx, &UpsertFileCommand{
+		Path:     filePath,
+		MimeType: file.MimeType,
+		Contents: file.Contents,
+	})
+}
+
+// DeleteStaleFileVersions removes versions created before `olderThan`. The `keepLatest` most recent versions of every file
+// are always kept, regardless of their age.
+func DeleteStaleFileVersions(ctx context.Context, db *sqlstore.SQLStore, olderThan time.Time, keepLatest int) (int64, error) {
+	var deleted int64
+	err := db.WithTransactionalDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		if keepLatest <= 0 {
+			count, err := sess.Table("file_version").Where("created < ?", olderThan).Delete(&fileVersion{})
+			deleted = count
+			return err
+		}
+
+		pathHashes := make([]string, 0)
+		if err := sess.Table("file_version").Where("created < ?", olderThan).Distinct("path_hash").Find(&pathHashes); err != nil {
+			return err
+		}
+
+		for _, pathHash := range pathHashes {
+			// the oldest of the versions which have to be kept
+			oldestKept := make([]*fileVersion, 0)
+			if err := sess.Table("file_version").
+				Where("path_hash = ?", pathHash).
+				Cols("version").
+				Desc("version").
+				Limit(1, keepLatest-1).
+				Find(&oldestKept); err != nil {
+				return err
+			}
+
+			if len(oldestKept) == 0 {
+				continue
+			}
+
+			count, err := sess.Table("file_version").
+				Where("path_hash = ? AND version < ? AND created < ?", pathHash, oldestKept[0].Version, olderThan).
+				Delete(&fileVersion{})
+			if err != nil {
+				return err
+			}
+			deleted += count
+		}
+		return nil
+	})
+
+	return deleted, err
+}
@@ -0,0 +1,150 @@
+package filestorage
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIntegrationDbFileVersions(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	testLogger := log.New("testStorageLogger")
+
+	t.Run("every write of contents creates a version", func(t *testing.T) {
+		ctx := context.Background()
+		sqlStore := sqlstore.InitTestDB(t)
+		fs := NewVersionedDbStorage(testLogger, sqlStore, nil, "/1/resources/")
+		versioned := fs.(FileVersionStorage)
+
+		require.NoError(t, fs.Upsert(ctx, &UpsertFileCommand{Path: "/folder/a.txt", Contents: []byte("v1")}))
+		require.NoError(t, fs.Upsert(ctx, &UpsertFileCommand{Path: "/folder/a.txt", Contents: []byte("v2")}))
+		// unchanged contents and property updates do not create versions
+		require.NoError(t, fs.Upsert(ctx, &UpsertFileCommand{Path: "/folder/a.txt", Contents: []byte("v2")}))
+		require.NoError(t, fs.Upsert(ctx, &UpsertFileCommand{Path: "/folder/a.txt", Properties: map[string]string{"a": "b"}}))
+
+		versions, err := versioned.ListVersions(ctx, "/folder/a.txt")
+		require.NoError(t, err)
+		require.Len(t, versions, 2)
+		require.Equal(t, int64(2), versions[0].Version)
+		require.Equal(t, int64(1), versions[1].Version)
+		require.Equal(t, int64(2), versions[1].Size)
+
+		file, err := versioned.GetVersion(ctx, "/folder/a.txt", 1)
+		require.NoError(t, err)
+		require.Equal(t, []byte("v1"), file.Contents)
+		require.Equal(t, "/folder/a.txt", file.FullPath)
+
+		file, err = versioned.GetVersion(ctx, "/folder/a.txt", 3)
+		require.NoError(t, err)
+		require.Nil(t, file)
+
+		require.NoError(t, versioned.RestoreVersion(ctx, "/folder/a.txt", 1))
+		file, err = fs.Get(ctx, "/folder/a.txt")
+		require.NoError(t, err)
+		require.Equal(t, []byte("v1"), file.Contents)
+
+		versions, err = versioned.ListVersions(ctx, "/folder/a.txt")
+		require.NoError(t, err)
+		require.Len(t, versions, 3)
+
+		require.ErrorIs(t, versioned.RestoreVersion(ctx, "/folder/a.txt", 10), ErrVersionNotFound)
+	})
+
+	t.Run("deleted files can be restored", func(t *testing.T) {
+		ctx := context.Background()
+		sqlStore := sqlstore.InitTestDB(t)
+		fs := NewVersionedDbStorage(testLogger, sqlStore, nil, "/")
+		versioned := fs.(FileVersionStorage)
+
+		require.NoError(t, fs.Upsert(ctx, &UpsertFileCommand{Path: "/a/b/c.txt", Contents: []byte("contents")}))
+		require.NoError(t, fs.DeleteFolder(ctx, "/a", &DeleteFolderOptions{Force: true}))
+
+		file, err := fs.Get(ctx, "/a/b/c.txt")
+		require.NoError(t, err)
+		require.Nil(t, file)
+
+		require.NoError(t, versioned.RestoreVersion(ctx, "/a/b/c.txt", 1))
+		file, err = fs.Get(ctx, "/a/b/c.txt")
+		require.NoError(t, err)
+		require.Equal(t, []byte("contents"), file.Contents)
+
+		resp, err := fs.List(ctx, "/a", nil, &ListOptions{WithFolders: true})
+		require.NoError(t, err)
+		require.Len(t, resp.Files, 1)
+		require.Equal(t, "/a/b", resp.Files[0].FullPath)
+	})
+
+	t.Run("files written before versioning was enabled keep their original contents", func(t *testing.T) {
+		ctx := context.Background()
+		sqlStore := sqlstore.InitTestDB(t)
+
+		require.NoError(t, NewDbStorage(testLogger, sqlStore, nil, "/").Upsert(ctx, &UpsertFileCommand{Path: "/a.txt", Contents: []byte("original")}))
+
+		fs := NewVersionedDbStorage(testLogger, sqlStore, nil, "/")
+		versioned := fs.(FileVersionStorage)
+		require.NoError(t, fs.Upsert(ctx, &UpsertFileCommand{Path: "/a.txt", Contents: []byte("updated")}))
+
+		file, err := versioned.GetVersion(ctx, "/a.txt", 1)
+		require.NoError(t, err)
+		require.Equal(t, []byte("original"), file.Contents)
+	})
+
+	t.Run("stale versions are deleted except for the latest ones", func(t *testing.T) {
+		ctx := context.Background()
+		sqlStore := sqlstore.InitTestDB(t)
+		fs := NewVersionedDbStorage(testLogger, sqlStore, nil, "/")
+		versioned := fs.(FileVersionStorage)
+
+		for _, contents := range []string{"1", "2", "3", "4"} {
+			require.NoError(t, fs.Upsert(ctx, &UpsertFileCommand{Path: "/a.txt", Contents: []byte(contents)}))
+		}
+		require.NoError(t, fs.Upsert(ctx, &UpsertFileCommand{Path: "/b.txt", Contents: []byte("1")}))
+
+		deleted, err := DeleteStaleFileVersions(ctx, sqlStore, time.Now().Add(-time.Hour), 2)
+		require.NoError(t, err)
+		require.Equal(t, int64(0), deleted)
+
+		deleted, err = DeleteStaleFileVersions(ctx, sqlStore, time.Now().Add(time.Hour), 2)
+		require.NoError(t, err)
+		require.Equal(t, int64(2), deleted)
+
+		versions, err := versioned.ListVersions(ctx, "/a.txt")
+		require.NoError(t, err)
+		require.Len(t, versions, 2)
+		require.Equal(t, int64(4), versions[0].Version)
+		require.Equal(t, int64(3), versions[1].Version)
+
+		deleted, err = DeleteStaleFileVersions(ctx, sqlStore, time.Now().Add(time.Hour), 0)
+		require.NoError(t, err)
+		require.Equal(t, int64(3), deleted)
+	})
+
+	t.Run("unversioned storages do not support versions", func(t *testing.T) {
+		fs := NewDbStorage(testLogger, sqlstore.InitTestDB(t), nil, "/")
+		_, err := fs.(FileVersionStorage).ListVersions(context.Background(), "/a.txt")
+		require.ErrorIs(t, err, ErrVersioningNotSupported)
+	})
+
+	t.Run("restoring a version requires access to the file", func(t *testing.T) {
+		ctx := context.Background()
+		sqlStore := sqlstore.InitTestDB(t)
+		fs := NewVersionedDbStorage(testLogger, sqlStore, nil, "/")
+		require.NoError(t, fs.Upsert(ctx, &UpsertFileCommand{Path: "/secret/a.txt", Contents: []byte("v1")}))
+		require.NoError(t, fs.Upsert(ctx, &UpsertFileCommand{Path: "/secret/a.txt", Contents: []byte("v2")}))
+
+		filtered := NewVersionedDbStorage(testLogger, sqlStore, NewPathFilter(nil, nil, []string{"/secret/"}, nil), "/")
+		err := filtered.(FileVersionStorage).RestoreVersion(ctx, "/secret/a.txt", 1)
+		require.ErrorIs(t, err, ErrVersionAccessDenied)
+
+		file, err := fs.Get(ctx, "/secret/a.txt")
+		require.NoError(t, err)
+		require.Equal(t, []byte("v2"), file.Contents)
+	})
+}
@@ -68,7 +68,8 @@ func newWrapper(log log.Logger, wrapped FileStorage, pathFilter PathFilter, root
 }
 
 var (
-	_ FileStorage = (*wrapper)(nil) // wrapper implements FileStorage
+	_ FileStorage        = (*wrapper)(nil) // wrapper implements FileStorage
+	_ FileVersionStorage = (*wrapper)(nil) // wrapper implements FileVersionStorage
 )
 
 func getParentFolderPath(path string) string {
@@ -379,6 +380,76 @@ func (b wrapper) isFolderEmpty(ctx context.Context, path string) (bool, error) {
 	return true, nil
 }
 
+func (b wrapper) versionStorage() (FileVersionStorage, error) {
+	if versioned, ok := b.wrapped.(FileVersionStorage); ok {
+		return versioned, nil
+	}
+	return nil, ErrVersioningNotSupported
+}
+
+func (b wrapper) ListVersions(ctx context.Context, path string) ([]*FileVersion, error) {
+	if err := b.validatePath(path); err != nil {
+		return nil, err
+	}
+
+	versioned, err := b.versionStorage()
+	if err != nil {
+		return nil, err
+	}
+
+	rootedPath := b.addRoot(path)
+	if !b.filter.IsAllowed(rootedPath) {
+		return make([]*FileVersion, 0), nil
+	}
+
+	return versioned.ListVersions(ctx, rootedPath)
+}
+
+func (b wrapper) GetVersion(ctx context.Context, path string, version int64) (*File, error) {
+	if err := b.validatePath(path); err != nil {
+		return nil, err
+	}
+
+	versioned, err := b.versionStorage()
+	if err != nil {
+		return nil, err
+	}
+
+	rootedPath := b.addRoot(path)
+	if !b.filter.IsAllowed(rootedPath) {
+		return nil, nil
+	}
+
+	file, err := versioned.GetVersion(ctx, rootedPath, version)
+	if file != nil {
+		file.FullPath = b.removeRoot(file.FullPath)
+	}
+	return file, err
+}
+
+func (b wrapper) RestoreVersion(ctx context.Context, path string, version int64) error {
+	if err := b.validatePath(path); err != nil {
+		return err
+	}
+
+	versioned, err := b.versionStorage()
+	if err != nil {
+		return err
+	}
+
+	rootedPath := b.addRoot(path)
+	if !b.filter.IsAllowed(rootedPath) {
+		return fmt.Errorf("restore version unauthorized - no access to %s: %w", path, ErrVersionAccessDenied)
+	}
+
+	// the file or any of its parent folders could have been deleted since the version was recorded
+	if err := b.CreateFolder(ctx, getParentFolderPath(path)); err != nil {
+		return err
+	}
+
+	return versioned.RestoreVersion(ctx, rootedPath, version)
+}
+
 func (b wrapper) close() error {
 	return b.wrapped.close()
 }
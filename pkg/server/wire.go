@@ -56,6 +56,7 @@ import (
 	"github.com/grafana/grafana/pkg/services/dashboardsnapshots"
 	dashsnapstore "github.com/grafana/grafana/pkg/services/dashboardsnapshots/database"
 	dashsnapsvc "github.com/grafana/grafana/pkg/services/dashboardsnapshots/service"
+	"github.com/grafana/grafana/pkg/services/dashboardusage"
 	"github.com/grafana/grafana/pkg/services/dashboardversion/dashverimpl"
 	"github.com/grafana/grafana/pkg/services/datasourceproxy"
 	"github.com/grafana/grafana/pkg/services/datasources"
@@ -132,6 +133,7 @@ import (
 	"github.com/grafana/grafana/pkg/tsdb/opentsdb"
 	"github.com/grafana/grafana/pkg/tsdb/postgres"
 	"github.com/grafana/grafana/pkg/tsdb/prometheus"
+	"github.com/grafana/grafana/pkg/tsdb/sqlite"
 	"github.com/grafana/grafana/pkg/tsdb/tempo"
 	"github.com/grafana/grafana/pkg/tsdb/testdatasource"
 )
@@ -179,6 +181,7 @@ var wireBasicSet = wire.NewSet(
 	azuremonitor.ProvideService,
 	postgres.ProvideService,
 	mysql.ProvideService,
+	sqlite.ProvideService,
 	mssql.ProvideService,
 	store.ProvideEntityEventsService,
 	httpclientprovider.New,
@@ -203,6 +206,8 @@ var wireBasicSet = wire.NewSet(
 	datasourceproxy.ProvideService,
 	search.ProvideService,
 	searchV2.ProvideService,
+	dashboardusage.ProvideService,
+	wire.Bind(new(dashboardusage.Service), new(*dashboardusage.DashboardUsageService)),
 	store.ProvideService,
 	export.ProvideService,
 	live.ProvideService,
@@ -221,6 +226,8 @@ var wireBasicSet = wire.NewSet(
 	tracing.ProvideService,
 	metrics.ProvideService,
 	testdatasource.ProvideService,
+	store.ProvideDatasourceResources,
+	wire.Bind(new(testdatasource.ResourceReader), new(*store.DatasourceResources)),
 	opentsdb.ProvideService,
 	social.ProvideService,
 	influxdb.ProvideService,
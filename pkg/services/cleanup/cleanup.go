@@ -9,10 +9,12 @@ import (
 	"time"
 
 	"github.com/grafana/grafana/pkg/services/dashboardsnapshots"
+	"github.com/grafana/grafana/pkg/services/dashboardusage"
 	dashver "github.com/grafana/grafana/pkg/services/dashboardversion"
 	"github.com/grafana/grafana/pkg/services/queryhistory"
 	"github.com/grafana/grafana/pkg/services/shorturls"
 	"github.com/grafana/grafana/pkg/services/sqlstore"
+	"github.com/grafana/grafana/pkg/services/store"
 
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/infra/serverlock"
@@ -23,7 +25,8 @@ import (
 
 func ProvideService(cfg *setting.Cfg, serverLockService *serverlock.ServerLockService,
 	shortURLService shorturls.Service, store sqlstore.Store, queryHistoryService queryhistory.Service,
-	dashboardVersionService dashver.Service, dashSnapSvc dashboardsnapshots.Service) *CleanUpService {
+	dashboardVersionService dashver.Service, dashSnapSvc dashboardsnapshots.Service, dashboardUsageService dashboardusage.Service,
+	storageService store.StorageService) *CleanUpService {
 	s := &CleanUpService{
 		Cfg:                      cfg,
 		ServerLockService:        serverLockService,
@@ -33,6 +36,8 @@ func ProvideService(cfg *setting.Cfg, serverLockService *serverlock.ServerLockSe
 		log:                      log.New("cleanup"),
 		dashboardVersionService:  dashboardVersionService,
 		dashboardSnapshotService: dashSnapSvc,
+		dashboardUsageService:    dashboardUsageService,
+		storageService:           storageService,
 	}
 	return s
 }
@@ -46,6 +51,8 @@ type CleanUpService struct {
 	QueryHistoryService      queryhistory.Service
 	dashboardVersionService  dashver.Service
 	dashboardSnapshotService dashboardsnapshots.Service
+	dashboardUsageService    dashboardusage.Service
+	storageService           store.StorageService
 }
 
 func (srv *CleanUpService) Run(ctx context.Context) error {
@@ -65,6 +72,8 @@ func (srv *CleanUpService) Run(ctx context.Context) error {
 			srv.expireOldUserInvites(ctx)
 			srv.deleteStaleShortURLs(ctx)
 			srv.deleteStaleQueryHistory(ctx)
+			srv.deleteStaleDashboardUsage(ctx)
+			srv.deleteStaleFileVersions(ctx)
 			err := srv.ServerLockService.LockAndExecute(ctx, "delete old login attempts",
 				time.Minute*10, func(context.Context) {
 					srv.deleteOldLoginAttempts(ctx)
@@ -224,3 +233,31 @@ func (srv *CleanUpService) deleteStaleQueryHistory(ctx context.Context) {
 		srv.log.Debug("Enforced row limit for query_history_star", "rows affected", rowsCount)
 	}
 }
+
+func (srv *CleanUpService) deleteStaleDashboardUsage(ctx context.Context) {
+	if !srv.Cfg.DashboardUsage.Enabled || srv.Cfg.DashboardUsage.RetentionDays <= 0 {
+		return
+	}
+
+	olderThan := time.Now().AddDate(0, 0, -srv.Cfg.DashboardUsage.RetentionDays)
+	rowsCount, err := srv.dashboardUsageService.DeleteStaleUsage(ctx, olderThan)
+	if err != nil {
+		srv.log.Error("Problem deleting stale dashboard usage", "error", err.Error())
+	} else {
+		srv.log.Debug("Deleted stale dashboard usage", "rows affected", rowsCount)
+	}
+}
+
+func (srv *CleanUpService) deleteStaleFileVersions(ctx context.Context) {
+	if !srv.Cfg.Storage.VersionsEnabled || srv.Cfg.Storage.VersionsRetentionDays <= 0 {
+		return
+	}
+
+	olderThan := time.Now().AddDate(0, 0, -srv.Cfg.Storage.VersionsRetentionDays)
+	rowsCount, err := srv.storageService.DeleteStaleVersions(ctx, olderThan, srv.Cfg.Storage.VersionsKeepLatest)
+	if err != nil {
+		srv.log.Error("Problem deleting stale file versions", "error", err.Error())
+	} else {
+		srv.log.Debug("Deleted stale file versions", "rows affected", rowsCount)
+	}
+}
@@ -0,0 +1,136 @@
+package dashboardusage
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/middleware"
+	"github.com/grafana/grafana/pkg/models"
+	ac "github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+const (
+	defaultUsageDays  = 30
+	defaultUnusedDays = 90
+)
+
+func (s *DashboardUsageService) registerAPIEndpoints() {
+	uidScope := dashboards.ScopeDashboardsProvider.GetResourceScopeUID(ac.Parameter(":uid"))
+	authorize := ac.Middleware(s.AccessControl)
+
+	s.RouteRegister.Group("/api/dashboard-usage", func(entities routing.RouteRegister) {
+		entities.Get("/unused", middleware.ReqSignedIn, authorize(ac.ReqOrgAdmin, ac.EvalPermission(dashboards.ActionDashboardsRead)), routing.Wrap(s.getUnusedHandler))
+		entities.Get("/uid/:uid", middleware.ReqSignedIn, authorize(ac.ReqViewer, ac.EvalPermission(dashboards.ActionDashboardsRead, uidScope)), routing.Wrap(s.getUsageHandler))
+	})
+}
+
+// swagger:route GET /dashboard-usage/uid/{uid} dashboard_usage getDashboardUsage
+//
+// Get usage of a dashboard and its panels over the last `days` days (default 30).
+//
+// Responses:
+// 200: getDashboardUsageResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 500: internalServerError
+func (s *DashboardUsageService) getUsageHandler(c *models.ReqContext) response.Response {
+	since, err := s.sinceFromDays(c, defaultUsageDays)
+	if err != nil {
+		return response.Error(http.StatusBadRequest, "days is invalid", err)
+	}
+
+	uid := web.Params(c.Req)[":uid"]
+	usage, err := s.GetDashboardUsage(c.Req.Context(), GetDashboardUsageQuery{
+		OrgID:         c.OrgId,
+		DashboardUIDs: []string{uid},
+		Since:         since,
+	})
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to get dashboard usage", err)
+	}
+
+	panels, err := s.GetPanelUsage(c.Req.Context(), GetPanelUsageQuery{
+		OrgID:        c.OrgId,
+		DashboardUID: uid,
+		Since:        since,
+	})
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to get panel usage", err)
+	}
+
+	result := GetDashboardUsageResponse{
+		Since:  since.Unix(),
+		Usage:  DashboardUsage{DashboardUID: uid},
+		Panels: panels,
+	}
+	if len(usage) > 0 {
+		result.Usage = usage[0]
+	}
+	return response.JSON(http.StatusOK, result)
+}
+
+// swagger:route GET /dashboard-usage/unused dashboard_usage getUnusedDashboards
+//
+// Get dashboards that were not viewed in the last `days` days (default 90).
+//
+// Responses:
+// 200: getUnusedDashboardsResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 500: internalServerError
+func (s *DashboardUsageService) getUnusedHandler(c *models.ReqContext) response.Response {
+	since, err := s.sinceFromDays(c, defaultUnusedDays)
+	if err != nil {
+		return response.Error(http.StatusBadRequest, "days is invalid", err)
+	}
+
+	dashboards, err := s.GetUnusedDashboards(c.Req.Context(), GetUnusedDashboardsQuery{
+		OrgID: c.OrgId,
+		Since: since,
+		Limit: c.QueryInt("limit"),
+	})
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to get unused dashboards", err)
+	}
+
+	return response.JSON(http.StatusOK, GetUnusedDashboardsResponse{Since: since.Unix(), Dashboards: dashboards})
+}
+
+func (s *DashboardUsageService) sinceFromDays(c *models.ReqContext, defaultDays int) (time.Time, error) {
+	days := defaultDays
+	if v := c.Query("days"); v != "" {
+		parsed, err := strconv.Atoi(v)
+		if err != nil || parsed <= 0 {
+			return time.Time{}, errors.New("days must be a positive number")
+		}
+		days = parsed
+	}
+	return s.now().AddDate(0, 0, -days), nil
+}
+
+// swagger:parameters getDashboardUsage
+type DashboardUsageParams struct {
+	// in:path
+	// required:true
+	UID string `json:"uid"`
+}
+
+//swagger:response getDashboardUsageResponse
+type GetDashboardUsageResponseBody struct {
+	// in: body
+	Body GetDashboardUsageResponse `json:"body"`
+}
+
+//swagger:response getUnusedDashboardsResponse
+type GetUnusedDashboardsResponseBody struct {
+	// in: body
+	Body GetUnusedDashboardsResponse `json:"body"`
+}
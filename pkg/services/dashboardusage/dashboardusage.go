@@ -0,0 +1,195 @@
+package dashboardusage
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func ProvideService(cfg *setting.Cfg, sqlStore *sqlstore.SQLStore, routeRegister routing.RouteRegister, ac accesscontrol.AccessControl) *DashboardUsageService {
+	s := &DashboardUsageService{
+		SQLStore:      sqlStore,
+		Cfg:           cfg,
+		RouteRegister: routeRegister,
+		AccessControl: ac,
+		log:           log.New("dashboard-usage"),
+		buffer:        make(map[usageKey]*usageCounters),
+		now:           time.Now,
+	}
+
+	// Register routes only when usage tracking is enabled
+	if !s.IsDisabled() {
+		s.registerAPIEndpoints()
+	}
+
+	return s
+}
+
+type Service interface {
+	// IsDisabled reports whether usage tracking is turned off, callers can skip collecting usage when it is.
+	IsDisabled() bool
+	// RecordView counts a view of the dashboard, it is called when the dashboard is loaded.
+	RecordView(ctx context.Context, orgID int64, dashboardUID string) error
+	// RecordPanelUsage counts executed and failed queries of a dashboard panel, it is called by the query API.
+	RecordPanelUsage(ctx context.Context, orgID int64, dashboardUID string, panelID int64, cmd RecordPanelUsageCommand) error
+	GetDashboardUsage(ctx context.Context, query GetDashboardUsageQuery) ([]DashboardUsage, error)
+	GetPanelUsage(ctx context.Context, query GetPanelUsageQuery) ([]PanelUsage, error)
+	GetUnusedDashboards(ctx context.Context, query GetUnusedDashboardsQuery) ([]UnusedDashboard, error)
+	DeleteStaleUsage(ctx context.Context, olderThan time.Time) (int64, error)
+}
+
+// DashboardUsageService buffers usage counters in memory and periodically
+// flushes them into daily rollups stored in the dashboard_usage_daily table.
+type DashboardUsageService struct {
+	SQLStore      *sqlstore.SQLStore
+	Cfg           *setting.Cfg
+	RouteRegister routing.RouteRegister
+	AccessControl accesscontrol.AccessControl
+	log           log.Logger
+
+	mu     sync.Mutex
+	buffer map[usageKey]*usageCounters
+	now    func() time.Time
+}
+
+func (s *DashboardUsageService) IsDisabled() bool {
+	if s.Cfg == nil {
+		return true
+	}
+	return !s.Cfg.DashboardUsage.Enabled
+}
+
+func (s *DashboardUsageService) Run(ctx context.Context) error {
+	if s.IsDisabled() {
+		return nil
+	}
+
+	interval := s.Cfg.DashboardUsage.FlushInterval
+	if interval <= 0 {
+		interval = 30 * time.Second
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if err := s.flush(ctx); err != nil {
+				s.log.Error("Failed to flush dashboard usage", "error", err)
+			}
+		case <-ctx.Done():
+			// Use a fresh context so the last counters are not lost on shutdown.
+			flushCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			if err := s.flush(flushCtx); err != nil {
+				s.log.Error("Failed to flush dashboard usage on shutdown", "error", err)
+			}
+			cancel()
+			return ctx.Err()
+		}
+	}
+}
+
+func (s *DashboardUsageService) RecordView(_ context.Context, orgID int64, dashboardUID string) error {
+	if s.IsDisabled() {
+		return nil
+	}
+	if dashboardUID == "" {
+		return ErrInvalidDashboardUID
+	}
+	s.add(orgID, dashboardUID, 0, usageCounters{views: 1})
+	return nil
+}
+
+func (s *DashboardUsageService) RecordPanelUsage(_ context.Context, orgID int64, dashboardUID string, panelID int64, cmd RecordPanelUsageCommand) error {
+	if s.IsDisabled() {
+		return nil
+	}
+	if dashboardUID == "" {
+		return ErrInvalidDashboardUID
+	}
+	if panelID <= 0 {
+		return ErrInvalidPanelID
+	}
+	if cmd.Queries < 0 || cmd.Errors < 0 {
+		return ErrInvalidUsageCount
+	}
+	s.add(orgID, dashboardUID, panelID, usageCounters{queries: cmd.Queries, errors: cmd.Errors})
+	return nil
+}
+
+func (s *DashboardUsageService) GetDashboardUsage(ctx context.Context, query GetDashboardUsageQuery) ([]DashboardUsage, error) {
+	return s.getDashboardUsage(ctx, query)
+}
+
+func (s *DashboardUsageService) GetPanelUsage(ctx context.Context, query GetPanelUsageQuery) ([]PanelUsage, error) {
+	return s.getPanelUsage(ctx, query)
+}
+
+func (s *DashboardUsageService) GetUnusedDashboards(ctx context.Context, query GetUnusedDashboardsQuery) ([]UnusedDashboard, error) {
+	return s.getUnusedDashboards(ctx, query)
+}
+
+func (s *DashboardUsageService) DeleteStaleUsage(ctx context.Context, olderThan time.Time) (int64, error) {
+	return s.deleteStaleUsage(ctx, olderThan)
+}
+
+func (s *DashboardUsageService) add(orgID int64, dashboardUID string, panelID int64, c usageCounters) {
+	key := usageKey{
+		orgID:        orgID,
+		dashboardUID: dashboardUID,
+		panelID:      panelID,
+		day:          startOfDay(s.now()),
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	counters, ok := s.buffer[key]
+	if !ok {
+		counters = &usageCounters{}
+		s.buffer[key] = counters
+	}
+	counters.views += c.views
+	counters.queries += c.queries
+	counters.errors += c.errors
+}
+
+// flush writes buffered counters into the database. Counters that fail to be
+// written are merged back into the buffer to be retried on the next flush.
+func (s *DashboardUsageService) flush(ctx context.Context) error {
+	s.mu.Lock()
+	pending := s.buffer
+	s.buffer = make(map[usageKey]*usageCounters, len(pending))
+	s.mu.Unlock()
+
+	if len(pending) == 0 {
+		return nil
+	}
+
+	err := s.writeCounters(ctx, pending)
+	if err != nil {
+		s.mu.Lock()
+		for key, c := range pending {
+			counters, ok := s.buffer[key]
+			if !ok {
+				s.buffer[key] = c
+				continue
+			}
+			counters.views += c.views
+			counters.queries += c.queries
+			counters.errors += c.errors
+		}
+		s.mu.Unlock()
+	}
+	return err
+}
+
+func startOfDay(t time.Time) int64 {
+	return t.UTC().Truncate(24 * time.Hour).Unix()
+}
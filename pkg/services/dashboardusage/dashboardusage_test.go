@@ -0,0 +1,160 @@
+package dashboardusage
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/stretchr/testify/require"
+)
+
+var testOrgID = int64(1)
+
+func setupTestService(t *testing.T) *DashboardUsageService {
+	t.Helper()
+
+	cfg := setting.NewCfg()
+	cfg.DashboardUsage.Enabled = true
+
+	return &DashboardUsageService{
+		Cfg:      cfg,
+		SQLStore: sqlstore.InitTestDB(t),
+		buffer:   make(map[usageKey]*usageCounters),
+		now:      time.Now,
+	}
+}
+
+func insertTestDashboard(t *testing.T, sqlStore *sqlstore.SQLStore, uid string, title string, created time.Time) {
+	t.Helper()
+
+	err := sqlStore.WithDbSession(context.Background(), func(sess *sqlstore.DBSession) error {
+		_, err := sess.Insert(&models.Dashboard{
+			Uid:     uid,
+			Slug:    uid,
+			OrgId:   testOrgID,
+			Title:   title,
+			Data:    simplejson.NewFromAny(map[string]interface{}{"title": title}),
+			Created: created,
+			Updated: created,
+		})
+		return err
+	})
+	require.NoError(t, err)
+}
+
+func TestIntegrationDashboardUsage(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	t.Run("Counters are rolled up per day", func(t *testing.T) {
+		s := setupTestService(t)
+		ctx := context.Background()
+
+		today := time.Now().UTC()
+		yesterday := today.AddDate(0, 0, -1)
+
+		s.now = func() time.Time { return yesterday }
+		require.NoError(t, s.RecordView(ctx, testOrgID, "dash-a"))
+		require.NoError(t, s.flush(ctx))
+
+		s.now = func() time.Time { return today }
+		require.NoError(t, s.RecordView(ctx, testOrgID, "dash-a"))
+		require.NoError(t, s.RecordView(ctx, testOrgID, "dash-a"))
+		require.NoError(t, s.RecordPanelUsage(ctx, testOrgID, "dash-a", 2, RecordPanelUsageCommand{Queries: 3, Errors: 1}))
+		require.NoError(t, s.flush(ctx))
+		require.NoError(t, s.RecordPanelUsage(ctx, testOrgID, "dash-a", 2, RecordPanelUsageCommand{Queries: 2}))
+		require.NoError(t, s.RecordView(ctx, testOrgID, "dash-b"))
+		require.NoError(t, s.flush(ctx))
+
+		var rows int64
+		err := s.SQLStore.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+			var err error
+			rows, err = sess.Count(&DailyUsage{})
+			return err
+		})
+		require.NoError(t, err)
+		require.Equal(t, int64(4), rows)
+
+		usage, err := s.GetDashboardUsage(ctx, GetDashboardUsageQuery{
+			OrgID:         testOrgID,
+			DashboardUIDs: []string{"dash-a"},
+			Since:         today.AddDate(0, 0, -7),
+		})
+		require.NoError(t, err)
+		require.Len(t, usage, 1)
+		require.Equal(t, int64(3), usage[0].Views)
+		require.Equal(t, int64(5), usage[0].Queries)
+		require.Equal(t, int64(1), usage[0].Errors)
+		require.Equal(t, startOfDay(today), usage[0].LastViewed)
+
+		usage, err = s.GetDashboardUsage(ctx, GetDashboardUsageQuery{OrgID: testOrgID, Since: today})
+		require.NoError(t, err)
+		require.Len(t, usage, 2)
+
+		panels, err := s.GetPanelUsage(ctx, GetPanelUsageQuery{OrgID: testOrgID, DashboardUID: "dash-a", Since: today})
+		require.NoError(t, err)
+		require.Equal(t, []PanelUsage{{PanelID: 2, Queries: 5, Errors: 1}}, panels)
+
+		deleted, err := s.DeleteStaleUsage(ctx, today)
+		require.NoError(t, err)
+		require.Equal(t, int64(1), deleted)
+	})
+
+	t.Run("Invalid usage is rejected", func(t *testing.T) {
+		s := setupTestService(t)
+		ctx := context.Background()
+
+		require.ErrorIs(t, s.RecordView(ctx, testOrgID, ""), ErrInvalidDashboardUID)
+		require.ErrorIs(t, s.RecordPanelUsage(ctx, testOrgID, "dash-a", 0, RecordPanelUsageCommand{Queries: 1}), ErrInvalidPanelID)
+		require.ErrorIs(t, s.RecordPanelUsage(ctx, testOrgID, "dash-a", 2, RecordPanelUsageCommand{Queries: 1, Errors: -1}), ErrInvalidUsageCount)
+		require.Empty(t, s.buffer)
+	})
+
+	t.Run("Usage is not recorded when disabled", func(t *testing.T) {
+		s := setupTestService(t)
+		s.Cfg.DashboardUsage.Enabled = false
+		ctx := context.Background()
+
+		require.NoError(t, s.RecordView(ctx, testOrgID, "dash-a"))
+		require.NoError(t, s.RecordPanelUsage(ctx, testOrgID, "dash-a", 2, RecordPanelUsageCommand{Queries: 1}))
+		require.Empty(t, s.buffer)
+	})
+
+	t.Run("Dashboards without recent views are unused", func(t *testing.T) {
+		s := setupTestService(t)
+		ctx := context.Background()
+
+		now := time.Now()
+		since := now.AddDate(0, 0, -90)
+		insertTestDashboard(t, s.SQLStore, "viewed", "Viewed", now.AddDate(-1, 0, 0))
+		insertTestDashboard(t, s.SQLStore, "stale", "Stale", now.AddDate(-1, 0, 0))
+		insertTestDashboard(t, s.SQLStore, "never", "Never viewed", now.AddDate(-1, 0, 0))
+		insertTestDashboard(t, s.SQLStore, "new", "Recently created", now.AddDate(0, 0, -1))
+
+		s.now = func() time.Time { return now.AddDate(0, 0, -120) }
+		require.NoError(t, s.RecordView(ctx, testOrgID, "stale"))
+		s.now = func() time.Time { return now }
+		require.NoError(t, s.RecordView(ctx, testOrgID, "viewed"))
+		require.NoError(t, s.flush(ctx))
+
+		unused, err := s.GetUnusedDashboards(ctx, GetUnusedDashboardsQuery{OrgID: testOrgID, Since: since})
+		require.NoError(t, err)
+		require.Len(t, unused, 2)
+		require.Equal(t, "never", unused[0].UID)
+		require.Equal(t, int64(0), unused[0].LastViewed)
+		require.Equal(t, "stale", unused[1].UID)
+		require.Equal(t, startOfDay(now.AddDate(0, 0, -120)), unused[1].LastViewed)
+	})
+}
+
+func TestRunWhenDisabled(t *testing.T) {
+	s := &DashboardUsageService{Cfg: setting.NewCfg()}
+
+	// Run returns right away instead of waiting for the context to be done.
+	require.NoError(t, s.Run(context.Background()))
+}
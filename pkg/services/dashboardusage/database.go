@@ -0,0 +1,143 @@
+package dashboardusage
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+)
+
+// writeCounters adds the counters to the matching daily rollup rows, creating them when missing
+func (s *DashboardUsageService) writeCounters(ctx context.Context, counters map[usageKey]*usageCounters) error {
+	return s.SQLStore.WithTransactionalDbSession(ctx, func(session *sqlstore.DBSession) error {
+		for key, c := range counters {
+			res, err := session.Exec(`UPDATE dashboard_usage_daily
+				SET views = views + ?, queries = queries + ?, errors = errors + ?
+				WHERE org_id = ? AND dashboard_uid = ? AND panel_id = ? AND day = ?`,
+				c.views, c.queries, c.errors, key.orgID, key.dashboardUID, key.panelID, key.day)
+			if err != nil {
+				return err
+			}
+
+			affected, err := res.RowsAffected()
+			if err != nil {
+				return err
+			}
+			if affected > 0 {
+				continue
+			}
+
+			if _, err := session.Insert(&DailyUsage{
+				OrgID:        key.orgID,
+				DashboardUID: key.dashboardUID,
+				PanelID:      key.panelID,
+				Day:          key.day,
+				Views:        c.views,
+				Queries:      c.queries,
+				Errors:       c.errors,
+			}); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// getDashboardUsage sums up daily rollups per dashboard
+func (s *DashboardUsageService) getDashboardUsage(ctx context.Context, query GetDashboardUsageQuery) ([]DashboardUsage, error) {
+	result := make([]DashboardUsage, 0)
+
+	err := s.SQLStore.WithDbSession(ctx, func(session *sqlstore.DBSession) error {
+		builder := sqlstore.SQLBuilder{}
+		builder.Write(`SELECT
+			dashboard_uid,
+			SUM(views) AS views,
+			SUM(queries) AS queries,
+			SUM(errors) AS errors,
+			MAX(CASE WHEN views > 0 THEN day ELSE 0 END) AS last_viewed
+			FROM dashboard_usage_daily
+			WHERE org_id = ? AND day >= ?`, query.OrgID, startOfDay(query.Since))
+
+		if len(query.DashboardUIDs) > 0 {
+			builder.Write(" AND dashboard_uid IN (?" + strings.Repeat(",?", len(query.DashboardUIDs)-1) + ")")
+			for _, uid := range query.DashboardUIDs {
+				builder.AddParams(uid)
+			}
+		}
+		builder.Write(" GROUP BY dashboard_uid")
+
+		return session.SQL(builder.GetSQLString(), builder.GetParams()...).Find(&result)
+	})
+
+	return result, err
+}
+
+// getPanelUsage sums up daily rollups per panel of a single dashboard
+func (s *DashboardUsageService) getPanelUsage(ctx context.Context, query GetPanelUsageQuery) ([]PanelUsage, error) {
+	result := make([]PanelUsage, 0)
+
+	err := s.SQLStore.WithDbSession(ctx, func(session *sqlstore.DBSession) error {
+		return session.SQL(`SELECT
+			panel_id,
+			SUM(queries) AS queries,
+			SUM(errors) AS errors
+			FROM dashboard_usage_daily
+			WHERE org_id = ? AND dashboard_uid = ? AND panel_id > 0 AND day >= ?
+			GROUP BY panel_id
+			ORDER BY panel_id`, query.OrgID, query.DashboardUID, startOfDay(query.Since)).Find(&result)
+	})
+
+	return result, err
+}
+
+// getUnusedDashboards returns dashboards created before query.Since that were not viewed since then
+func (s *DashboardUsageService) getUnusedDashboards(ctx context.Context, query GetUnusedDashboardsQuery) ([]UnusedDashboard, error) {
+	result := make([]UnusedDashboard, 0)
+
+	if query.Limit <= 0 {
+		query.Limit = 1000
+	}
+
+	err := s.SQLStore.WithDbSession(ctx, func(session *sqlstore.DBSession) error {
+		sql := `SELECT
+			dashboard.uid,
+			dashboard.title,
+			dashboard.folder_id,
+			dashboard.created,
+			dashboard.updated,
+			COALESCE(MAX(dashboard_usage_daily.day), 0) AS last_viewed
+			FROM dashboard
+			LEFT JOIN dashboard_usage_daily
+			ON dashboard_usage_daily.org_id = dashboard.org_id
+			AND dashboard_usage_daily.dashboard_uid = dashboard.uid
+			AND dashboard_usage_daily.panel_id = 0
+			AND dashboard_usage_daily.views > 0
+			WHERE dashboard.org_id = ? AND dashboard.is_folder = ` + s.SQLStore.Dialect.BooleanStr(false) + `
+			AND dashboard.created < ?
+			GROUP BY dashboard.uid, dashboard.title, dashboard.folder_id, dashboard.created, dashboard.updated
+			HAVING COALESCE(MAX(dashboard_usage_daily.day), 0) < ?
+			ORDER BY last_viewed ASC, dashboard.title ASC ` + s.SQLStore.Dialect.Limit(int64(query.Limit))
+
+		return session.SQL(sql, query.OrgID, query.Since, startOfDay(query.Since)).Find(&result)
+	})
+
+	return result, err
+}
+
+// deleteStaleUsage removes daily rollups older than the given time
+func (s *DashboardUsageService) deleteStaleUsage(ctx context.Context, olderThan time.Time) (int64, error) {
+	var rowsCount int64
+
+	err := s.SQLStore.WithDbSession(ctx, func(session *sqlstore.DBSession) error {
+		res, err := session.Exec("DELETE FROM dashboard_usage_daily WHERE day < ?", startOfDay(olderThan))
+		if err != nil {
+			return err
+		}
+
+		rowsCount, err = res.RowsAffected()
+		return err
+	})
+
+	return rowsCount, err
+}
@@ -0,0 +1,103 @@
+package dashboardusage
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	ErrInvalidDashboardUID = errors.New("dashboard UID is required")
+	ErrInvalidPanelID      = errors.New("panel ID must be a positive number")
+	ErrInvalidUsageCount   = errors.New("query and error counts must not be negative")
+)
+
+// DailyUsage is a daily rollup of usage counters for a dashboard or one of its panels.
+// Dashboard level counters are stored with PanelID set to zero.
+type DailyUsage struct {
+	ID           int64  `xorm:"pk autoincr 'id'"`
+	OrgID        int64  `xorm:"org_id"`
+	DashboardUID string `xorm:"dashboard_uid"`
+	PanelID      int64  `xorm:"panel_id"`
+	Day          int64  // unix timestamp of the start of the day (UTC)
+	Views        int64
+	Queries      int64
+	Errors       int64
+}
+
+func (DailyUsage) TableName() string {
+	return "dashboard_usage_daily"
+}
+
+// DashboardUsage is the aggregated usage of a dashboard over a period of time.
+type DashboardUsage struct {
+	DashboardUID string `json:"dashboardUid" xorm:"dashboard_uid"`
+	Views        int64  `json:"views"`
+	Queries      int64  `json:"queries"`
+	Errors       int64  `json:"errors"`
+	LastViewed   int64  `json:"lastViewed" xorm:"last_viewed"` // day of the last recorded view, zero when never viewed
+}
+
+// PanelUsage is the aggregated usage of a single panel over a period of time.
+type PanelUsage struct {
+	PanelID int64 `json:"panelId" xorm:"panel_id"`
+	Queries int64 `json:"queries"`
+	Errors  int64 `json:"errors"`
+}
+
+type GetDashboardUsageQuery struct {
+	OrgID         int64
+	DashboardUIDs []string // all dashboards when empty
+	Since         time.Time
+}
+
+type GetPanelUsageQuery struct {
+	OrgID        int64
+	DashboardUID string
+	Since        time.Time
+}
+
+type GetUnusedDashboardsQuery struct {
+	OrgID int64
+	Since time.Time
+	Limit int
+}
+
+// UnusedDashboard is a dashboard without any recorded view since the requested point in time.
+type UnusedDashboard struct {
+	UID        string    `json:"uid" xorm:"uid"`
+	Title      string    `json:"title"`
+	FolderID   int64     `json:"folderId" xorm:"folder_id"`
+	Created    time.Time `json:"created"`
+	Updated    time.Time `json:"updated"`
+	LastViewed int64     `json:"lastViewed" xorm:"last_viewed"` // day of the last recorded view, zero when never viewed
+}
+
+type RecordPanelUsageCommand struct {
+	Queries int64 `json:"queries"`
+	Errors  int64 `json:"errors"`
+}
+
+type GetUnusedDashboardsResponse struct {
+	Since      int64             `json:"since"`
+	Dashboards []UnusedDashboard `json:"dashboards"`
+}
+
+type GetDashboardUsageResponse struct {
+	Since  int64          `json:"since"`
+	Usage  DashboardUsage `json:"usage"`
+	Panels []PanelUsage   `json:"panels"`
+}
+
+// usageKey identifies a row in the daily rollup table.
+type usageKey struct {
+	orgID        int64
+	dashboardUID string
+	panelID      int64
+	day          int64
+}
+
+type usageCounters struct {
+	views   int64
+	queries int64
+	errors  int64
+}
@@ -0,0 +1,227 @@
+// Package habroker provides Centrifuge Broker which connects Grafana instances
+// without Redis. Messages are exchanged over a Transport: SQL database shared
+// by instances or direct HTTP requests between them.
+package habroker
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/centrifugal/centrifuge"
+	"github.com/grafana/grafana/pkg/infra/log"
+)
+
+var logger = log.New("live.habroker")
+
+// MessageType describes a kind of Message.
+type MessageType string
+
+const (
+	MessageTypePublication MessageType = "publication"
+	MessageTypeJoin        MessageType = "join"
+	MessageTypeLeave       MessageType = "leave"
+	MessageTypeControl     MessageType = "control"
+)
+
+// Message is a unit of data exchanged between Grafana instances.
+type Message struct {
+	Type MessageType `json:"type"`
+	// NodeID is an ID of Centrifuge node which sent the message.
+	NodeID string `json:"nodeId"`
+	// TargetNodeID is set for control messages addressed to a single node.
+	TargetNodeID string                 `json:"targetNodeId,omitempty"`
+	Channel      string                 `json:"channel,omitempty"`
+	Data         []byte                 `json:"data,omitempty"`
+	Info         *centrifuge.ClientInfo `json:"info,omitempty"`
+}
+
+// MessageHandler handles messages received from other instances.
+type MessageHandler func(messages []Message)
+
+// Transport delivers messages between Grafana instances.
+type Transport interface {
+	// Run receives messages sent by other instances and passes them to
+	// handler until context is done. Transport may also return messages
+	// sent by this instance – those are filtered out by Broker.
+	Run(ctx context.Context, handler MessageHandler) error
+	// Send delivers messages to other instances.
+	Send(ctx context.Context, messages []Message) error
+}
+
+const (
+	sendQueueSize    = 4096
+	maxSendBatchSize = 256
+	sendTimeout      = 5 * time.Second
+)
+
+// Broker is a Centrifuge Broker which delivers all publications to every
+// Grafana instance over a Transport. Nodes ignore publications to channels
+// without local subscribers so Subscribe and Unsubscribe are noop.
+//
+// Delivery is at most once: messages may be dropped when transport is slow
+// or unavailable. Broker does not support channel history.
+type Broker struct {
+	nodeID    string
+	transport Transport
+	queue     chan Message
+	handler   centrifuge.BrokerEventHandler
+}
+
+var _ centrifuge.Broker = (*Broker)(nil)
+
+// New creates Broker for a node.
+func New(node *centrifuge.Node, transport Transport) *Broker {
+	return &Broker{
+		nodeID:    node.ID(),
+		transport: transport,
+		queue:     make(chan Message, sendQueueSize),
+	}
+}
+
+// Run is called once by Centrifuge node on start. Messages are exchanged
+// with other instances after RunTransport is called.
+func (b *Broker) Run(h centrifuge.BrokerEventHandler) error {
+	b.handler = h
+	return nil
+}
+
+// RunTransport receives and sends messages over the transport until context
+// is done.
+func (b *Broker) RunTransport(ctx context.Context) error {
+	if b.handler == nil {
+		return errors.New("broker is not running")
+	}
+	go b.runSender(ctx)
+	backoff := time.Second
+	for {
+		err := b.transport.Run(ctx, b.handleMessages)
+		if err == nil || ctx.Err() != nil {
+			return nil
+		}
+		logger.Error("Error running HA transport", "error", err, "retryIn", backoff)
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(backoff):
+		}
+		if backoff < 30*time.Second {
+			backoff *= 2
+		}
+	}
+}
+
+func (b *Broker) runSender(ctx context.Context) {
+	batch := make([]Message, 0, maxSendBatchSize)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case msg := <-b.queue:
+			batch = append(batch[:0], msg)
+		drain:
+			for len(batch) < maxSendBatchSize {
+				select {
+				case msg := <-b.queue:
+					batch = append(batch, msg)
+				default:
+					break drain
+				}
+			}
+			sendCtx, cancel := context.WithTimeout(ctx, sendTimeout)
+			err := b.transport.Send(sendCtx, batch)
+			cancel()
+			if err != nil {
+				logger.Error("Error sending messages to HA transport", "error", err, "numMessages", len(batch))
+			}
+		}
+	}
+}
+
+func (b *Broker) enqueue(msg Message) {
+	msg.NodeID = b.nodeID
+	select {
+	case b.queue <- msg:
+	default:
+		logger.Warn("HA send queue is full, dropping message", "type", msg.Type, "channel", msg.Channel)
+	}
+}
+
+func (b *Broker) handleMessages(messages []Message) {
+	for _, msg := range messages {
+		if msg.NodeID == b.nodeID {
+			continue
+		}
+		if msg.TargetNodeID != "" && msg.TargetNodeID != b.nodeID {
+			continue
+		}
+		var err error
+		switch msg.Type {
+		case MessageTypePublication:
+			err = b.handler.HandlePublication(msg.Channel, &centrifuge.Publication{Data: msg.Data, Info: msg.Info}, centrifuge.StreamPosition{})
+		case MessageTypeJoin:
+			err = b.handler.HandleJoin(msg.Channel, msg.Info)
+		case MessageTypeLeave:
+			err = b.handler.HandleLeave(msg.Channel, msg.Info)
+		case MessageTypeControl:
+			err = b.handler.HandleControl(msg.Data)
+		default:
+			logger.Warn("Unknown HA message type", "type", msg.Type)
+		}
+		if err != nil {
+			logger.Error("Error handling HA message", "error", err, "type", msg.Type, "channel", msg.Channel)
+		}
+	}
+}
+
+// Subscribe is noop since all publications are delivered to all nodes.
+func (b *Broker) Subscribe(_ string) error {
+	return nil
+}
+
+// Unsubscribe is noop since all publications are delivered to all nodes.
+func (b *Broker) Unsubscribe(_ string) error {
+	return nil
+}
+
+// Publish delivers publication to local subscribers right away and to
+// other instances asynchronously.
+func (b *Broker) Publish(ch string, data []byte, opts centrifuge.PublishOptions) (centrifuge.StreamPosition, error) {
+	if opts.HistorySize > 0 && opts.HistoryTTL > 0 {
+		return centrifuge.StreamPosition{}, centrifuge.ErrorNotAvailable
+	}
+	b.enqueue(Message{Type: MessageTypePublication, Channel: ch, Data: data, Info: opts.ClientInfo})
+	return centrifuge.StreamPosition{}, b.handler.HandlePublication(ch, &centrifuge.Publication{Data: data, Info: opts.ClientInfo}, centrifuge.StreamPosition{})
+}
+
+// PublishJoin publishes Join Push message into channel.
+func (b *Broker) PublishJoin(ch string, info *centrifuge.ClientInfo) error {
+	b.enqueue(Message{Type: MessageTypeJoin, Channel: ch, Info: info})
+	return b.handler.HandleJoin(ch, info)
+}
+
+// PublishLeave publishes Leave Push message into channel.
+func (b *Broker) PublishLeave(ch string, info *centrifuge.ClientInfo) error {
+	b.enqueue(Message{Type: MessageTypeLeave, Channel: ch, Info: info})
+	return b.handler.HandleLeave(ch, info)
+}
+
+// PublishControl sends control command to other nodes. Node ignores its own
+// control commands so they are not handled locally.
+func (b *Broker) PublishControl(data []byte, nodeID, _ string) error {
+	if nodeID == b.nodeID {
+		return b.handler.HandleControl(data)
+	}
+	b.enqueue(Message{Type: MessageTypeControl, TargetNodeID: nodeID, Data: data})
+	return nil
+}
+
+// History is not supported.
+func (b *Broker) History(_ string, _ centrifuge.HistoryFilter) ([]*centrifuge.Publication, centrifuge.StreamPosition, error) {
+	return nil, centrifuge.StreamPosition{}, centrifuge.ErrorNotAvailable
+}
+
+// RemoveHistory is not supported.
+func (b *Broker) RemoveHistory(_ string) error {
+	return centrifuge.ErrorNotAvailable
+}
@@ -0,0 +1,135 @@
+package habroker
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/centrifugal/centrifuge"
+	"github.com/stretchr/testify/require"
+)
+
+// testBus delivers messages sent by any transport to all transports.
+type testBus struct {
+	mu       sync.Mutex
+	handlers []MessageHandler
+}
+
+type testTransport struct {
+	bus *testBus
+}
+
+func (t *testTransport) Run(ctx context.Context, handler MessageHandler) error {
+	t.bus.mu.Lock()
+	t.bus.handlers = append(t.bus.handlers, handler)
+	t.bus.mu.Unlock()
+	<-ctx.Done()
+	return nil
+}
+
+func (t *testTransport) Send(_ context.Context, messages []Message) error {
+	t.bus.mu.Lock()
+	defer t.bus.mu.Unlock()
+	for _, h := range t.bus.handlers {
+		h(messages)
+	}
+	return nil
+}
+
+type testEventHandler struct {
+	mu           sync.Mutex
+	publications []string
+	joins        []string
+	controls     [][]byte
+}
+
+func (h *testEventHandler) HandlePublication(ch string, pub *centrifuge.Publication, _ centrifuge.StreamPosition) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.publications = append(h.publications, ch+":"+string(pub.Data))
+	return nil
+}
+
+func (h *testEventHandler) HandleJoin(ch string, _ *centrifuge.ClientInfo) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.joins = append(h.joins, ch)
+	return nil
+}
+
+func (h *testEventHandler) HandleLeave(_ string, _ *centrifuge.ClientInfo) error {
+	return nil
+}
+
+func (h *testEventHandler) HandleControl(data []byte) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.controls = append(h.controls, data)
+	return nil
+}
+
+func (h *testEventHandler) numPublications() int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return len(h.publications)
+}
+
+func (h *testEventHandler) numControls() int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return len(h.controls)
+}
+
+func newTestBroker(t *testing.T, transport Transport) (*Broker, *testEventHandler) {
+	t.Helper()
+	node, err := centrifuge.New(centrifuge.DefaultConfig)
+	require.NoError(t, err)
+	b := New(node, transport)
+	h := &testEventHandler{}
+	require.NoError(t, b.Run(h))
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- b.RunTransport(ctx) }()
+	t.Cleanup(func() {
+		cancel()
+		require.NoError(t, <-done)
+	})
+	return b, h
+}
+
+func TestBroker(t *testing.T) {
+	bus := &testBus{}
+	b1, h1 := newTestBroker(t, &testTransport{bus: bus})
+	b2, h2 := newTestBroker(t, &testTransport{bus: bus})
+	require.Eventually(t, func() bool {
+		bus.mu.Lock()
+		defer bus.mu.Unlock()
+		return len(bus.handlers) == 2
+	}, time.Second, 10*time.Millisecond)
+
+	_, err := b1.Publish("1/stream/test", []byte("test"), centrifuge.PublishOptions{})
+	require.NoError(t, err)
+	require.Eventually(t, func() bool { return h2.numPublications() == 1 }, time.Second, 10*time.Millisecond)
+	// Publication delivered to local node once, not echoed back from transport.
+	require.Equal(t, []string{"1/stream/test:test"}, h1.publications)
+	require.Equal(t, []string{"1/stream/test:test"}, h2.publications)
+
+	require.NoError(t, b2.PublishJoin("1/stream/test", &centrifuge.ClientInfo{ClientID: "client"}))
+	require.Eventually(t, func() bool {
+		h1.mu.Lock()
+		defer h1.mu.Unlock()
+		return len(h1.joins) == 1
+	}, time.Second, 10*time.Millisecond)
+
+	// Control addressed to another node is ignored.
+	require.NoError(t, b1.PublishControl([]byte("to-b1"), b1.nodeID, ""))
+	require.NoError(t, b2.PublishControl([]byte("to-nobody"), "unknown", ""))
+	require.NoError(t, b2.PublishControl([]byte("to-all"), "", ""))
+	require.Eventually(t, func() bool { return h1.numControls() == 2 }, time.Second, 10*time.Millisecond)
+	require.Equal(t, [][]byte{[]byte("to-b1"), []byte("to-all")}, h1.controls)
+	require.Equal(t, 0, h2.numControls())
+
+	_, err = b1.Publish("1/stream/test", []byte("test"), centrifuge.PublishOptions{HistorySize: 10, HistoryTTL: time.Minute})
+	require.ErrorIs(t, err, centrifuge.ErrorNotAvailable)
+}
@@ -0,0 +1,198 @@
+package habroker
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/grafana/grafana/pkg/util"
+)
+
+const (
+	// HTTPMessagesPath is a path peers accept messages on.
+	HTTPMessagesPath = "/api/live/ha/messages"
+
+	signatureHeader = "X-Grafana-Live-Signature"
+	timestampHeader = "X-Grafana-Live-Timestamp"
+	nonceHeader     = "X-Grafana-Live-Nonce"
+	maxBodySize     = 16 * 1024 * 1024
+	// maxRequestAge limits clock skew between instances and how long nonces
+	// of accepted requests are kept to reject replayed requests.
+	maxRequestAge = 30 * time.Second
+)
+
+// HTTPTransport sends messages directly to peer Grafana instances over HTTP.
+// Requests are signed with a secret shared by all instances, signature
+// covers request time and a random nonce so requests can't be replayed.
+type HTTPTransport struct {
+	peers  []string
+	secret []byte
+	client *http.Client
+	now    func() time.Time
+
+	mu      sync.RWMutex
+	handler MessageHandler
+
+	noncesMu sync.Mutex
+	// nonces keep nonces of accepted requests until the time requests expire.
+	nonces map[string]time.Time
+}
+
+// NewHTTPTransport creates HTTPTransport. Peers are root URLs of other Grafana
+// instances, messages a node receives from itself are ignored by Broker so
+// the list may be the same on all instances.
+func NewHTTPTransport(peers []string, secret string) *HTTPTransport {
+	normalized := make([]string, 0, len(peers))
+	for _, p := range peers {
+		normalized = append(normalized, strings.TrimRight(p, "/"))
+	}
+	return &HTTPTransport{
+		peers:  normalized,
+		secret: []byte(secret),
+		client: &http.Client{Timeout: 5 * time.Second},
+		now:    time.Now,
+		nonces: map[string]time.Time{},
+	}
+}
+
+func (t *HTTPTransport) sign(timestamp, nonce string, body []byte) string {
+	mac := hmac.New(sha256.New, t.secret)
+	_, _ = mac.Write([]byte(timestamp + "\n" + nonce + "\n"))
+	_, _ = mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// useNonce returns false if nonce was already used by a request which has
+// not expired yet.
+func (t *HTTPTransport) useNonce(nonce string, expires time.Time) bool {
+	t.noncesMu.Lock()
+	defer t.noncesMu.Unlock()
+	now := t.now()
+	for n, exp := range t.nonces {
+		if now.After(exp) {
+			delete(t.nonces, n)
+		}
+	}
+	if _, ok := t.nonces[nonce]; ok {
+		return false
+	}
+	t.nonces[nonce] = expires
+	return true
+}
+
+// Send posts messages to all peers concurrently.
+func (t *HTTPTransport) Send(ctx context.Context, messages []Message) error {
+	body, err := json.Marshal(messages)
+	if err != nil {
+		return err
+	}
+	timestamp := strconv.FormatInt(t.now().Unix(), 10)
+
+	var wg sync.WaitGroup
+	errs := make([]error, len(t.peers))
+	for i, peer := range t.peers {
+		wg.Add(1)
+		go func(i int, peer string) {
+			defer wg.Done()
+			errs[i] = t.send(ctx, peer, timestamp, body)
+		}(i, peer)
+	}
+	wg.Wait()
+
+	var errStrings []string
+	for i, err := range errs {
+		if err != nil {
+			errStrings = append(errStrings, fmt.Sprintf("%s: %v", t.peers[i], err))
+		}
+	}
+	if len(errStrings) > 0 {
+		return fmt.Errorf("error sending to peers: %s", strings.Join(errStrings, "; "))
+	}
+	return nil
+}
+
+func (t *HTTPTransport) send(ctx context.Context, peer string, timestamp string, body []byte) error {
+	nonce, err := util.GetRandomString(32)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, peer+HTTPMessagesPath, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(timestampHeader, timestamp)
+	req.Header.Set(nonceHeader, nonce)
+	req.Header.Set(signatureHeader, t.sign(timestamp, nonce, body))
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// Run sets handler for messages received by ServeHTTP and blocks until
+// context is done.
+func (t *HTTPTransport) Run(ctx context.Context, handler MessageHandler) error {
+	t.mu.Lock()
+	t.handler = handler
+	t.mu.Unlock()
+	<-ctx.Done()
+	return nil
+}
+
+// ServeHTTP accepts messages sent by peers.
+func (t *HTTPTransport) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, err := ioutil.ReadAll(http.MaxBytesReader(w, r.Body, maxBodySize))
+	if err != nil {
+		http.Error(w, "error reading body", http.StatusBadRequest)
+		return
+	}
+	timestamp, nonce := r.Header.Get(timestampHeader), r.Header.Get(nonceHeader)
+	if nonce == "" || !hmac.Equal([]byte(t.sign(timestamp, nonce, body)), []byte(r.Header.Get(signatureHeader))) {
+		http.Error(w, "invalid signature", http.StatusUnauthorized)
+		return
+	}
+	sentAt, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		http.Error(w, "invalid timestamp", http.StatusUnauthorized)
+		return
+	}
+	sent := time.Unix(sentAt, 0)
+	if age := t.now().Sub(sent); age > maxRequestAge || age < -maxRequestAge {
+		http.Error(w, "expired request", http.StatusUnauthorized)
+		return
+	}
+	if !t.useNonce(nonce, sent.Add(maxRequestAge)) {
+		http.Error(w, "replayed request", http.StatusUnauthorized)
+		return
+	}
+	var messages []Message
+	if err := json.Unmarshal(body, &messages); err != nil {
+		http.Error(w, "error decoding messages", http.StatusBadRequest)
+		return
+	}
+	t.mu.RLock()
+	handler := t.handler
+	t.mu.RUnlock()
+	if handler == nil {
+		http.Error(w, "not ready", http.StatusServiceUnavailable)
+		return
+	}
+	handler(messages)
+	w.WriteHeader(http.StatusOK)
+}
@@ -0,0 +1,82 @@
+package habroker
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestHTTPTransport(t *testing.T) {
+	receiver := NewHTTPTransport(nil, "secret")
+	received := make(chan []Message, 10)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() { _ = receiver.Run(ctx, func(messages []Message) { received <- messages }) }()
+
+	mux := http.NewServeMux()
+	mux.Handle(HTTPMessagesPath, receiver)
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	require.Eventually(t, func() bool {
+		receiver.mu.RLock()
+		defer receiver.mu.RUnlock()
+		return receiver.handler != nil
+	}, time.Second, 10*time.Millisecond)
+
+	sender := NewHTTPTransport([]string{server.URL + "/"}, "secret")
+	err := sender.Send(context.Background(), []Message{{Type: MessageTypePublication, NodeID: "node", Channel: "1/stream/test", Data: []byte("test")}})
+	require.NoError(t, err)
+	messages := <-received
+	require.Len(t, messages, 1)
+	require.Equal(t, "1/stream/test", messages[0].Channel)
+	require.Equal(t, []byte("test"), messages[0].Data)
+
+	t.Run("rejects wrong secret", func(t *testing.T) {
+		sender := NewHTTPTransport([]string{server.URL}, "wrong")
+		err := sender.Send(context.Background(), []Message{{Type: MessageTypePublication}})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "401")
+	})
+
+	t.Run("rejects unsigned request", func(t *testing.T) {
+		resp, err := http.Post(server.URL+HTTPMessagesPath, "application/json", bytes.NewReader([]byte(`[]`)))
+		require.NoError(t, err)
+		require.NoError(t, resp.Body.Close())
+		require.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+	})
+
+	signedRequest := func(sender *HTTPTransport, timestamp, nonce string) *http.Request {
+		body := []byte(`[]`)
+		req, err := http.NewRequest(http.MethodPost, server.URL+HTTPMessagesPath, bytes.NewReader(body))
+		require.NoError(t, err)
+		req.Header.Set(timestampHeader, timestamp)
+		req.Header.Set(nonceHeader, nonce)
+		req.Header.Set(signatureHeader, sender.sign(timestamp, nonce, body))
+		return req
+	}
+	doRequest := func(req *http.Request) int {
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		require.NoError(t, resp.Body.Close())
+		return resp.StatusCode
+	}
+
+	t.Run("rejects replayed request", func(t *testing.T) {
+		timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+		require.Equal(t, http.StatusOK, doRequest(signedRequest(sender, timestamp, "nonce")))
+		require.Equal(t, http.StatusUnauthorized, doRequest(signedRequest(sender, timestamp, "nonce")))
+		require.Equal(t, http.StatusOK, doRequest(signedRequest(sender, timestamp, "other-nonce")))
+	})
+
+	t.Run("rejects expired request", func(t *testing.T) {
+		timestamp := strconv.FormatInt(time.Now().Add(-2*maxRequestAge).Unix(), 10)
+		require.Equal(t, http.StatusUnauthorized, doRequest(signedRequest(sender, timestamp, "expired")))
+	})
+}
@@ -0,0 +1,185 @@
+package habroker
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+	"github.com/grafana/grafana/pkg/services/sqlstore/migrator"
+)
+
+const (
+	// sqlPollInterval is used for databases without notification support.
+	sqlPollInterval = 250 * time.Millisecond
+	// sqlNotifyPollInterval is a fallback poll interval when Postgres
+	// LISTEN/NOTIFY is used to wake up readers.
+	sqlNotifyPollInterval = 5 * time.Second
+	sqlNotifyChannel      = "grafana_live_ha"
+	sqlMessageTTL         = time.Minute
+	sqlCleanupInterval    = 30 * time.Second
+	sqlReadBatchSize      = 100
+	// sqlReadOverlap is how long recent rows are re-read. Row IDs are not
+	// committed in order, so a row with a lower ID may become visible after
+	// rows with higher IDs were read. Rows already handled are skipped.
+	sqlReadOverlap = 10 * time.Second
+)
+
+type liveHAMessage struct {
+	Id      int64
+	Data    string
+	Created int64
+}
+
+func (m liveHAMessage) TableName() string {
+	return "live_ha_message"
+}
+
+// SQLTransport exchanges messages over a table in the Grafana database. On
+// Postgres LISTEN/NOTIFY wakes up readers right after a write, other databases
+// are polled.
+type SQLTransport struct {
+	store *sqlstore.SQLStore
+	now   func() time.Time
+}
+
+// NewSQLTransport creates SQLTransport.
+func NewSQLTransport(store *sqlstore.SQLStore) *SQLTransport {
+	return &SQLTransport{store: store, now: time.Now}
+}
+
+func (t *SQLTransport) isPostgres() bool {
+	return t.store.GetDialect().DriverName() == migrator.Postgres
+}
+
+// Send stores messages as a single row and notifies readers.
+func (t *SQLTransport) Send(ctx context.Context, messages []Message) error {
+	data, err := json.Marshal(messages)
+	if err != nil {
+		return err
+	}
+	return t.store.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		if _, err := sess.Insert(&liveHAMessage{Data: string(data), Created: t.now().UnixMilli()}); err != nil {
+			return err
+		}
+		if t.isPostgres() {
+			_, err := sess.Exec("NOTIFY " + sqlNotifyChannel)
+			return err
+		}
+		return nil
+	})
+}
+
+// Run reads messages written after the start.
+func (t *SQLTransport) Run(ctx context.Context, handler MessageHandler) error {
+	// seen keeps creation time of recent rows which were already handled.
+	seen := map[int64]int64{}
+	if err := t.read(ctx, seen, nil); err != nil {
+		return fmt.Errorf("error reading recent HA messages: %w", err)
+	}
+
+	pollInterval := sqlPollInterval
+	var notify <-chan *pq.Notification
+	if t.isPostgres() {
+		listener := pq.NewListener(t.store.ConnectionString(), time.Second, 30*time.Second, func(ev pq.ListenerEventType, err error) {
+			if err != nil {
+				logger.Error("Postgres HA listener error", "error", err)
+			}
+		})
+		defer func() { _ = listener.Close() }()
+		if err := listener.Listen(sqlNotifyChannel); err != nil {
+			return fmt.Errorf("error listening to %s: %w", sqlNotifyChannel, err)
+		}
+		notify = listener.Notify
+		pollInterval = sqlNotifyPollInterval
+	}
+
+	pollTicker := time.NewTicker(pollInterval)
+	defer pollTicker.Stop()
+	cleanupTicker := time.NewTicker(sqlCleanupInterval)
+	defer cleanupTicker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-cleanupTicker.C:
+			if err := t.cleanup(ctx); err != nil {
+				logger.Error("Error removing expired HA messages", "error", err)
+			}
+			continue
+		case <-notify:
+		case <-pollTicker.C:
+		}
+		if err := t.read(ctx, seen, handler); err != nil {
+			logger.Error("Error reading HA messages", "error", err)
+		}
+	}
+}
+
+// read passes messages of rows created within sqlReadOverlap which are not in
+// seen to handler and adds them to seen. Rows are only marked as seen when
+// handler is nil.
+func (t *SQLTransport) read(ctx context.Context, seen map[int64]int64, handler MessageHandler) error {
+	since := t.now().Add(-sqlReadOverlap).UnixMilli()
+	for id, created := range seen {
+		if created < since {
+			delete(seen, id)
+		}
+	}
+
+	var recent []*liveHAMessage
+	err := t.store.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		return sess.Cols("id", "created").Where("created >= ?", since).Asc("id").Find(&recent)
+	})
+	if err != nil {
+		return err
+	}
+	var ids []int64
+	for _, row := range recent {
+		if _, ok := seen[row.Id]; ok {
+			continue
+		}
+		if handler == nil {
+			seen[row.Id] = row.Created
+			continue
+		}
+		ids = append(ids, row.Id)
+	}
+
+	for len(ids) > 0 {
+		batch := ids
+		if len(batch) > sqlReadBatchSize {
+			batch = batch[:sqlReadBatchSize]
+		}
+		ids = ids[len(batch):]
+
+		var rows []*liveHAMessage
+		err := t.store.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+			return sess.In("id", batch).Asc("id").Find(&rows)
+		})
+		if err != nil {
+			return err
+		}
+		for _, row := range rows {
+			seen[row.Id] = row.Created
+			var messages []Message
+			if err := json.Unmarshal([]byte(row.Data), &messages); err != nil {
+				logger.Error("Error decoding HA messages", "error", err, "id", row.Id)
+				continue
+			}
+			handler(messages)
+		}
+	}
+	return nil
+}
+
+func (t *SQLTransport) cleanup(ctx context.Context) error {
+	return t.store.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		_, err := sess.Where("created < ?", t.now().Add(-sqlMessageTTL).UnixMilli()).Delete(&liveHAMessage{})
+		return err
+	})
+}
@@ -0,0 +1,94 @@
+package habroker
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIntegrationSQLTransport(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+	store := sqlstore.InitTestDB(t)
+	sender := NewSQLTransport(store)
+	receiver := NewSQLTransport(store)
+
+	// Messages sent before reader started are not delivered.
+	require.NoError(t, sender.Send(context.Background(), []Message{{Type: MessageTypePublication, Channel: "old"}}))
+
+	received := make(chan []Message, 10)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() { _ = receiver.Run(ctx, func(messages []Message) { received <- messages }) }()
+
+	// Wait for reader to skip messages sent before the start.
+	time.Sleep(2 * sqlPollInterval)
+	require.NoError(t, sender.Send(context.Background(), []Message{
+		{Type: MessageTypePublication, Channel: "1/stream/test", Data: []byte("1")},
+		{Type: MessageTypePublication, Channel: "1/stream/test", Data: []byte("2")},
+	}))
+
+	select {
+	case messages := <-received:
+		require.Len(t, messages, 2)
+		require.Equal(t, []byte("1"), messages[0].Data)
+		require.Equal(t, []byte("2"), messages[1].Data)
+	case <-time.After(5 * time.Second):
+		t.Fatal("timeout waiting for messages")
+	}
+
+	t.Run("reads rows committed out of order", func(t *testing.T) {
+		seen := map[int64]int64{}
+		require.NoError(t, receiver.read(context.Background(), seen, nil))
+
+		require.NoError(t, sender.Send(context.Background(), []Message{{Type: MessageTypePublication, Data: []byte("late")}}))
+		require.NoError(t, sender.Send(context.Background(), []Message{{Type: MessageTypePublication, Data: []byte("early")}}))
+		var rows []*liveHAMessage
+		require.NoError(t, store.WithDbSession(context.Background(), func(sess *sqlstore.DBSession) error {
+			return sess.Desc("id").Limit(2).Find(&rows)
+		}))
+		require.Len(t, rows, 2)
+		late := rows[1]
+
+		// Row with lower ID is not visible yet when a row with higher ID is read.
+		require.NoError(t, store.WithDbSession(context.Background(), func(sess *sqlstore.DBSession) error {
+			_, err := sess.ID(late.Id).Delete(&liveHAMessage{})
+			return err
+		}))
+		var data []string
+		handler := func(messages []Message) {
+			for _, m := range messages {
+				data = append(data, string(m.Data))
+			}
+		}
+		require.NoError(t, receiver.read(context.Background(), seen, handler))
+		require.Equal(t, []string{"early"}, data)
+
+		require.NoError(t, store.WithDbSession(context.Background(), func(sess *sqlstore.DBSession) error {
+			_, err := sess.Insert(late)
+			return err
+		}))
+		require.NoError(t, receiver.read(context.Background(), seen, handler))
+		require.Equal(t, []string{"early", "late"}, data)
+
+		// Rows are handled once.
+		require.NoError(t, receiver.read(context.Background(), seen, handler))
+		require.Equal(t, []string{"early", "late"}, data)
+	})
+
+	t.Run("cleanup removes expired messages", func(t *testing.T) {
+		sender.now = func() time.Time { return time.Now().Add(2 * sqlMessageTTL) }
+		require.NoError(t, sender.cleanup(context.Background()))
+		var count int64
+		require.NoError(t, store.WithDbSession(context.Background(), func(sess *sqlstore.DBSession) error {
+			var err error
+			count, err = sess.Count(&liveHAMessage{})
+			return err
+		}))
+		require.Equal(t, int64(0), count)
+	})
+}
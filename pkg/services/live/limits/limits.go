@@ -0,0 +1,255 @@
+// Package limits enforces publish rate limits, maximum publication size and
+// maximum number of channel subscribers for Grafana Live.
+package limits
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"golang.org/x/time/rate"
+)
+
+var (
+	// ErrPublishTooLarge returned when publication data exceeds MaxPublishSize.
+	ErrPublishTooLarge = errors.New("publication too large")
+	// ErrOrgRateLimited returned when organization exceeds OrgPublishRate.
+	ErrOrgRateLimited = errors.New("organization publish rate limit exceeded")
+	// ErrChannelRateLimited returned when channel exceeds ChannelPublishRate.
+	ErrChannelRateLimited = errors.New("channel publish rate limit exceeded")
+	// ErrTooManySubscribers returned when channel already has MaxSubscribers.
+	ErrTooManySubscribers = errors.New("too many channel subscribers")
+)
+
+const (
+	ReasonPublishTooLarge    = "too_large"
+	ReasonOrgRateLimited     = "org_rate_limited"
+	ReasonChannelRateLimited = "channel_rate_limited"
+	ReasonTooManySubscribers = "too_many_subscribers"
+)
+
+var (
+	publishRejectedCounter = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "grafana",
+			Subsystem: "live",
+			Name:      "publish_rejected_total",
+			Help:      "A counter for Live publications rejected by configured limits",
+		},
+		[]string{"protocol", "reason"},
+	)
+	subscribeRejectedCounter = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "grafana",
+			Subsystem: "live",
+			Name:      "subscribe_rejected_total",
+			Help:      "A counter for Live subscriptions rejected by configured limits",
+		},
+		[]string{"reason"},
+	)
+)
+
+// Protocols publications may come over, used as metric label.
+const (
+	ProtocolWebsocket = "ws"
+	ProtocolHTTP      = "http"
+	ProtocolLive      = "live"
+)
+
+// Config of limits. Zero values mean no limit.
+type Config struct {
+	// OrgPublishRate is a maximum number of publications per second into all
+	// channels of an organization.
+	OrgPublishRate float64
+	// OrgPublishBurst is a number of publications allowed above OrgPublishRate
+	// for a short period. Defaults to OrgPublishRate.
+	OrgPublishBurst int
+	// ChannelPublishRate is a maximum number of publications per second into
+	// a single channel.
+	ChannelPublishRate float64
+	// ChannelPublishBurst is a number of publications allowed above
+	// ChannelPublishRate for a short period. Defaults to ChannelPublishRate.
+	ChannelPublishBurst int
+	// MaxPublishSize is a maximum size of publication data in bytes.
+	MaxPublishSize int
+	// MaxSubscribers is a maximum number of subscribers of a channel on a
+	// Grafana instance.
+	MaxSubscribers int
+}
+
+// limiterIdleTimeout defines when unused channel limiters are removed.
+const limiterIdleTimeout = 5 * time.Minute
+
+type limiterEntry struct {
+	limiter  *rate.Limiter
+	lastUsed time.Time
+}
+
+// Limiter checks publications and subscriptions against Config. Limits
+// are kept in memory of each Grafana instance. Nil Limiter allows everything.
+type Limiter struct {
+	config Config
+	now    func() time.Time
+
+	mu          sync.Mutex
+	orgs        map[int64]*limiterEntry
+	channels    map[string]*limiterEntry
+	lastCleanup time.Time
+}
+
+// NewLimiter creates Limiter.
+func NewLimiter(config Config) *Limiter {
+	return &Limiter{
+		config:   config,
+		now:      time.Now,
+		orgs:     map[int64]*limiterEntry{},
+		channels: map[string]*limiterEntry{},
+	}
+}
+
+func newRateLimiter(r float64, burst int) *rate.Limiter {
+	if burst <= 0 {
+		burst = int(r)
+		if burst < 1 {
+			burst = 1
+		}
+	}
+	return rate.NewLimiter(rate.Limit(r), burst)
+}
+
+// MaxPublishSize returns the maximum size of publication data in bytes, zero
+// means no limit.
+func (l *Limiter) MaxPublishSize() int {
+	if l == nil {
+		return 0
+	}
+	return l.config.MaxPublishSize
+}
+
+// CheckPublish returns an error if publication of size bytes into channel of
+// an organization violates configured limits. Protocol is used for metrics.
+func (l *Limiter) CheckPublish(protocol string, orgID int64, channel string, size int) error {
+	if l == nil {
+		return nil
+	}
+	if l.config.MaxPublishSize > 0 && size > l.config.MaxPublishSize {
+		publishRejectedCounter.WithLabelValues(protocol, ReasonPublishTooLarge).Inc()
+		return fmt.Errorf("%w: %d bytes, max %d", ErrPublishTooLarge, size, l.config.MaxPublishSize)
+	}
+	if l.config.OrgPublishRate <= 0 && l.config.ChannelPublishRate <= 0 {
+		return nil
+	}
+
+	now := l.now()
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.cleanupLocked(now)
+
+	// Both limits are checked before a token is taken from either, so
+	// publications rejected by one limit do not consume the other.
+	var channelLimiter, orgLimiter *rate.Limiter
+	if l.config.ChannelPublishRate > 0 {
+		key := fmt.Sprintf("%d/%s", orgID, channel)
+		entry, ok := l.channels[key]
+		if !ok {
+			entry = &limiterEntry{limiter: newRateLimiter(l.config.ChannelPublishRate, l.config.ChannelPublishBurst)}
+			l.channels[key] = entry
+		}
+		entry.lastUsed = now
+		channelLimiter = entry.limiter
+	}
+	if l.config.OrgPublishRate > 0 {
+		entry, ok := l.orgs[orgID]
+		if !ok {
+			entry = &limiterEntry{limiter: newRateLimiter(l.config.OrgPublishRate, l.config.OrgPublishBurst)}
+			l.orgs[orgID] = entry
+		}
+		entry.lastUsed = now
+		orgLimiter = entry.limiter
+	}
+	channelReservation := reserve(channelLimiter, now)
+	orgReservation := reserve(orgLimiter, now)
+	var err error
+	switch {
+	case !allowed(channelReservation, now):
+		publishRejectedCounter.WithLabelValues(protocol, ReasonChannelRateLimited).Inc()
+		err = ErrChannelRateLimited
+	case !allowed(orgReservation, now):
+		publishRejectedCounter.WithLabelValues(protocol, ReasonOrgRateLimited).Inc()
+		err = ErrOrgRateLimited
+	}
+	if err != nil {
+		cancel(channelReservation, now)
+		cancel(orgReservation, now)
+	}
+	return err
+}
+
+func reserve(limiter *rate.Limiter, now time.Time) *rate.Reservation {
+	if limiter == nil {
+		return nil
+	}
+	return limiter.ReserveN(now, 1)
+}
+
+func allowed(r *rate.Reservation, now time.Time) bool {
+	return r == nil || (r.OK() && r.DelayFrom(now) == 0)
+}
+
+func cancel(r *rate.Reservation, now time.Time) {
+	if r != nil {
+		r.CancelAt(now)
+	}
+}
+
+// CheckSubscribe returns an error if a channel with numSubscribers can't
+// accept one more subscriber.
+func (l *Limiter) CheckSubscribe(numSubscribers int) error {
+	if l == nil {
+		return nil
+	}
+	if l.config.MaxSubscribers > 0 && numSubscribers >= l.config.MaxSubscribers {
+		subscribeRejectedCounter.WithLabelValues(ReasonTooManySubscribers).Inc()
+		return fmt.Errorf("%w: max %d", ErrTooManySubscribers, l.config.MaxSubscribers)
+	}
+	return nil
+}
+
+func (l *Limiter) cleanupLocked(now time.Time) {
+	if now.Sub(l.lastCleanup) < limiterIdleTimeout {
+		return
+	}
+	l.lastCleanup = now
+	for k, e := range l.orgs {
+		if now.Sub(e.lastUsed) > limiterIdleTimeout {
+			delete(l.orgs, k)
+		}
+	}
+	for k, e := range l.channels {
+		if now.Sub(e.lastUsed) > limiterIdleTimeout {
+			delete(l.channels, k)
+		}
+	}
+}
+
+// IsRateLimited returns true if error is caused by publish rate limits.
+func IsRateLimited(err error) bool {
+	return errors.Is(err, ErrOrgRateLimited) || errors.Is(err, ErrChannelRateLimited)
+}
+
+// HTTPStatus returns HTTP status code for a limit error. Live uses HTTP
+// status codes for WebSocket errors too.
+func HTTPStatus(err error) int {
+	switch {
+	case errors.Is(err, ErrPublishTooLarge):
+		return http.StatusRequestEntityTooLarge
+	case IsRateLimited(err), errors.Is(err, ErrTooManySubscribers):
+		return http.StatusTooManyRequests
+	default:
+		return http.StatusInternalServerError
+	}
+}
@@ -0,0 +1,102 @@
+package limits
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLimiter_Nil(t *testing.T) {
+	var l *Limiter
+	require.NoError(t, l.CheckPublish(ProtocolHTTP, 1, "stream/test", 1024*1024))
+	require.NoError(t, l.CheckSubscribe(1000))
+	require.Equal(t, 0, l.MaxPublishSize())
+}
+
+func TestLimiter_PublishSize(t *testing.T) {
+	l := NewLimiter(Config{MaxPublishSize: 10})
+	require.Equal(t, 10, l.MaxPublishSize())
+	require.NoError(t, l.CheckPublish(ProtocolHTTP, 1, "stream/test", 10))
+	err := l.CheckPublish(ProtocolHTTP, 1, "stream/test", 11)
+	require.True(t, errors.Is(err, ErrPublishTooLarge))
+	require.Equal(t, http.StatusRequestEntityTooLarge, HTTPStatus(err))
+}
+
+func TestLimiter_ChannelRate(t *testing.T) {
+	now := time.Now()
+	l := NewLimiter(Config{ChannelPublishRate: 1, ChannelPublishBurst: 2})
+	l.now = func() time.Time { return now }
+
+	require.NoError(t, l.CheckPublish(ProtocolWebsocket, 1, "stream/test", 1))
+	require.NoError(t, l.CheckPublish(ProtocolWebsocket, 1, "stream/test", 1))
+	err := l.CheckPublish(ProtocolWebsocket, 1, "stream/test", 1)
+	require.ErrorIs(t, err, ErrChannelRateLimited)
+	require.True(t, IsRateLimited(err))
+	require.Equal(t, http.StatusTooManyRequests, HTTPStatus(err))
+
+	// Other channels and same channel in other organizations have own limits.
+	require.NoError(t, l.CheckPublish(ProtocolWebsocket, 1, "stream/other", 1))
+	require.NoError(t, l.CheckPublish(ProtocolWebsocket, 2, "stream/test", 1))
+
+	now = now.Add(time.Second)
+	require.NoError(t, l.CheckPublish(ProtocolWebsocket, 1, "stream/test", 1))
+}
+
+func TestLimiter_OrgRate(t *testing.T) {
+	now := time.Now()
+	l := NewLimiter(Config{OrgPublishRate: 2})
+	l.now = func() time.Time { return now }
+
+	require.NoError(t, l.CheckPublish(ProtocolLive, 1, "stream/a", 1))
+	require.NoError(t, l.CheckPublish(ProtocolLive, 1, "stream/b", 1))
+	require.ErrorIs(t, l.CheckPublish(ProtocolLive, 1, "stream/c", 1), ErrOrgRateLimited)
+	require.NoError(t, l.CheckPublish(ProtocolLive, 2, "stream/a", 1))
+}
+
+func TestLimiter_ChannelRejectDoesNotConsumeOrgLimit(t *testing.T) {
+	now := time.Now()
+	l := NewLimiter(Config{OrgPublishRate: 2, ChannelPublishRate: 1})
+	l.now = func() time.Time { return now }
+
+	require.NoError(t, l.CheckPublish(ProtocolLive, 1, "stream/a", 1))
+	require.ErrorIs(t, l.CheckPublish(ProtocolLive, 1, "stream/a", 1), ErrChannelRateLimited)
+	require.NoError(t, l.CheckPublish(ProtocolLive, 1, "stream/b", 1))
+}
+
+func TestLimiter_OrgRejectDoesNotConsumeChannelLimit(t *testing.T) {
+	now := time.Now()
+	l := NewLimiter(Config{OrgPublishRate: 1, ChannelPublishRate: 0.1})
+	l.now = func() time.Time { return now }
+
+	require.NoError(t, l.CheckPublish(ProtocolLive, 1, "stream/a", 1))
+	require.ErrorIs(t, l.CheckPublish(ProtocolLive, 1, "stream/b", 1), ErrOrgRateLimited)
+
+	now = now.Add(time.Second)
+	require.NoError(t, l.CheckPublish(ProtocolLive, 1, "stream/b", 1))
+}
+
+func TestLimiter_Cleanup(t *testing.T) {
+	now := time.Now()
+	l := NewLimiter(Config{OrgPublishRate: 1, ChannelPublishRate: 1})
+	l.now = func() time.Time { return now }
+
+	require.NoError(t, l.CheckPublish(ProtocolLive, 1, "stream/a", 1))
+	require.Len(t, l.orgs, 1)
+	require.Len(t, l.channels, 1)
+
+	now = now.Add(2 * limiterIdleTimeout)
+	require.NoError(t, l.CheckPublish(ProtocolLive, 2, "stream/b", 1))
+	require.Len(t, l.orgs, 1)
+	require.Len(t, l.channels, 1)
+}
+
+func TestLimiter_Subscribers(t *testing.T) {
+	l := NewLimiter(Config{MaxSubscribers: 2})
+	require.NoError(t, l.CheckSubscribe(1))
+	err := l.CheckSubscribe(2)
+	require.ErrorIs(t, err, ErrTooManySubscribers)
+	require.Equal(t, http.StatusTooManyRequests, HTTPStatus(err))
+}
@@ -9,6 +9,7 @@ import (
 	"net/http"
 	"net/url"
 	"os"
+	"path/filepath"
 	"strconv"
 	"strings"
 	"sync"
@@ -31,6 +32,8 @@ import (
 	"github.com/grafana/grafana/pkg/services/featuremgmt"
 	"github.com/grafana/grafana/pkg/services/live/database"
 	"github.com/grafana/grafana/pkg/services/live/features"
+	"github.com/grafana/grafana/pkg/services/live/habroker"
+	"github.com/grafana/grafana/pkg/services/live/limits"
 	"github.com/grafana/grafana/pkg/services/live/livecontext"
 	"github.com/grafana/grafana/pkg/services/live/liveplugin"
 	"github.com/grafana/grafana/pkg/services/live/managedstream"
@@ -89,6 +92,14 @@ func ProvideService(plugCtxProvider *plugincontext.Provider, cfg *setting.Cfg, r
 			Features: make(map[string]models.ChannelHandlerFactory),
 		},
 		usageStatsService: usageStatsService,
+		Limiter: limits.NewLimiter(limits.Config{
+			OrgPublishRate:      cfg.LiveOrgPublishRateLimit,
+			OrgPublishBurst:     cfg.LiveOrgPublishBurst,
+			ChannelPublishRate:  cfg.LiveChannelPublishRateLimit,
+			ChannelPublishBurst: cfg.LiveChannelPublishBurst,
+			MaxPublishSize:      cfg.LiveMaxPublishSize,
+			MaxSubscribers:      cfg.LiveMaxChannelSubscribers,
+		}),
 	}
 
 	logger.Debug("GrafanaLive initialization", "ha", g.IsHA())
@@ -111,7 +122,8 @@ func ProvideService(plugCtxProvider *plugincontext.Provider, cfg *setting.Cfg, r
 	}
 	g.node = node
 
-	if g.IsHA() {
+	switch g.Cfg.LiveHAEngine {
+	case "redis":
 		// Configure HA with Redis. In this case Centrifuge nodes
 		// will be connected over Redis PUB/SUB. Presence will work
 		// globally since kept inside Redis.
@@ -153,12 +165,26 @@ func ProvideService(plugCtxProvider *plugincontext.Provider, cfg *setting.Cfg, r
 			return nil, fmt.Errorf("error creating Live Redis presence manager: %v", err)
 		}
 		node.SetPresenceManager(presenceManager)
+	case "sql":
+		// Configure HA over Grafana database. Presence is kept in memory
+		// of each node in this case.
+		g.haBroker = habroker.New(node, habroker.NewSQLTransport(g.SQLStore))
+		node.SetBroker(g.haBroker)
+	case "http":
+		// Configure HA with direct HTTP requests between Grafana instances.
+		// Presence is kept in memory of each node in this case.
+		transport := habroker.NewHTTPTransport(g.Cfg.LiveHAEnginePeers, g.Cfg.SecretKey)
+		g.haBroker = habroker.New(node, transport)
+		node.SetBroker(g.haBroker)
+		g.RouteRegister.Post(habroker.HTTPMessagesPath, func(ctx *models.ReqContext) {
+			transport.ServeHTTP(ctx.Resp, ctx.Req)
+		})
 	}
 
 	channelLocalPublisher := liveplugin.NewChannelLocalPublisher(node, nil)
 
 	var managedStreamRunner *managedstream.Runner
-	if g.IsHA() {
+	if g.Cfg.LiveHAEngine == "redis" {
 		redisClient := redis.NewClient(&redis.Options{
 			Addr: g.Cfg.LiveHAEngineAddress,
 		})
@@ -166,16 +192,31 @@ func ProvideService(plugCtxProvider *plugincontext.Provider, cfg *setting.Cfg, r
 		if _, err := cmd.Result(); err != nil {
 			return nil, fmt.Errorf("error pinging Redis: %v", err)
 		}
+		var frameHistory managedstream.FrameHistory
+		if g.Cfg.LiveHistoryMaxFrames > 0 || g.Cfg.LiveHistoryMaxAge > 0 {
+			frameHistory = managedstream.NewRedisFrameHistory(redisClient, g.Cfg.LiveHistoryMaxFrames, g.Cfg.LiveHistoryMaxAge)
+		}
 		managedStreamRunner = managedstream.NewRunner(
 			g.Publish,
 			channelLocalPublisher,
 			managedstream.NewRedisFrameCache(redisClient),
+			frameHistory,
 		)
 	} else {
+		var frameHistory managedstream.FrameHistory
+		if g.Cfg.LiveHistoryMaxFrames > 0 || g.Cfg.LiveHistoryMaxAge > 0 {
+			frameHistory = managedstream.NewMemoryFrameHistory(g.Cfg.LiveHistoryMaxFrames, g.Cfg.LiveHistoryMaxAge)
+		}
+		var frameCache managedstream.FrameCache = managedstream.NewMemoryFrameCache()
+		if g.IsHA() {
+			// Frame cache is not shared between instances without Redis.
+			frameCache = managedstream.NewSchemaFrameCache(frameCache)
+		}
 		managedStreamRunner = managedstream.NewRunner(
 			g.Publish,
 			channelLocalPublisher,
-			managedstream.NewMemoryFrameCache(),
+			frameCache,
+			frameHistory,
 		)
 	}
 
@@ -195,23 +236,43 @@ func ProvideService(plugCtxProvider *plugincontext.Provider, cfg *setting.Cfg, r
 				SecretsService: g.SecretsService,
 			}
 			g.pipelineStorage = storage
+			if cfg.LiveRemoteWriteBufferMaxSize > 0 {
+				g.remoteWriteQueues = pipeline.NewRemoteWriteQueues(
+					filepath.Join(cfg.DataPath, "pipeline", "remote-write"),
+					cfg.LiveRemoteWriteBufferMaxSize,
+					cfg.LiveRemoteWriteBufferMaxAge,
+				)
+				storage.RemoteWriteQueues = g.remoteWriteQueues
+			}
+			g.pipelineStateStorage = pipeline.NewStateStorage()
 			builder = &pipeline.StorageRuleBuilder{
 				Node:                 node,
 				ManagedStream:        g.ManagedStreamRunner,
 				FrameStorage:         pipeline.NewFrameStorage(),
+				StateStorage:         g.pipelineStateStorage,
 				Storage:              storage,
 				ChannelHandlerGetter: g,
 				SecretsService:       g.SecretsService,
+				RemoteWriteQueues:    g.remoteWriteQueues,
+				HA:                   cfg.LiveHAEngine != "",
 			}
 		}
 		channelRuleGetter := pipeline.NewCacheSegmentedTree(builder)
 
+		var err error
+		g.Pipeline, err = pipeline.New(channelRuleGetter)
+		if err != nil {
+			return nil, err
+		}
+		g.pipelineSourceRunner = pipeline.NewSourceRunner(g.Pipeline)
+		channelRuleGetter.AddListener(g.pipelineSourceRunner)
+
 		// Pre-build/validate channel rules for all organizations on start.
 		// This can be unreasonable to have in production scenario with many
 		// organizations.
 		orgQuery := &models.SearchOrgsQuery{}
 
-		err := sqlStore.SearchOrgs(context.Background(), orgQuery)
+		err = sqlStore.SearchOrgs(context.Background(), orgQuery)
 		if err != nil {
 			return nil, fmt.Errorf("can't get org list: %w", err)
 		}
@@ -221,11 +282,6 @@ func ProvideService(plugCtxProvider *plugincontext.Provider, cfg *setting.Cfg, r
 				return nil, fmt.Errorf("error building channel rules for org %d: %w", org.Id, err)
 			}
 		}
-
-		g.Pipeline, err = pipeline.New(channelRuleGetter)
-		if err != nil {
-			return nil, err
-		}
 	}
 
 	g.contextGetter = liveplugin.NewContextGetter(g.PluginContextProvider, g.DataSourceCache)
@@ -342,12 +398,14 @@ func ProvideService(plugCtxProvider *plugincontext.Provider, cfg *setting.Cfg, r
 		ReadBufferSize:  1024,
 		WriteBufferSize: 1024,
 		CheckOrigin:     checkOrigin,
+		Limiter:         g.Limiter,
 	})
 
 	pushPipelineWSHandler := pushws.NewPipelinePushHandler(g.Pipeline, pushws.Config{
 		ReadBufferSize:  1024,
 		WriteBufferSize: 1024,
 		CheckOrigin:     checkOrigin,
+		Limiter:         g.Limiter,
 	})
 
 	g.websocketHandler = func(ctx *models.ReqContext) {
@@ -410,6 +468,9 @@ type GrafanaLive struct {
 	pluginStore           plugins.Store
 	queryDataService      *query.Service
 
+	// Limiter enforces publish and subscribe limits.
+	Limiter *limits.Limiter
+
 	node         *centrifuge.Node
 	surveyCaller *survey.Caller
 
@@ -428,6 +489,14 @@ type GrafanaLive struct {
 	ManagedStreamRunner *managedstream.Runner
 	Pipeline            *pipeline.Pipeline
 	pipelineStorage     pipeline.Storage
+	// pipelineSourceRunner consumes messages from Kafka, MQTT and NATS sources of channel rules.
+	pipelineSourceRunner *pipeline.SourceRunner
+	// pipelineStateStorage keeps state of stateful frame processors of channel rules.
+	pipelineStateStorage *pipeline.StateStorage
+	// remoteWriteQueues keep remote write output data on disk until it is sent.
+	remoteWriteQueues *pipeline.RemoteWriteQueues
+	// haBroker connects instances when SQL or HTTP HA engine is used.
+	haBroker *habroker.Broker
 
 	contextGetter    *liveplugin.ContextGetter
 	runStreamManager *runstream.Manager
@@ -472,6 +541,32 @@ func (g *GrafanaLive) Run(ctx context.Context) error {
 		})
 	}
 
+	if g.pipelineSourceRunner != nil {
+		eGroup.Go(func() error {
+			return g.pipelineSourceRunner.Run(eCtx)
+		})
+	}
+
+	if g.pipelineStateStorage != nil {
+		eGroup.Go(func() error {
+			return g.Pipeline.RunFlush(eCtx, g.pipelineStateStorage)
+		})
+	}
+
+	if g.haBroker != nil {
+		eGroup.Go(func() error {
+			return g.haBroker.RunTransport(eCtx)
+		})
+	}
+
+	if g.remoteWriteQueues != nil {
+		eGroup.Go(func() error {
+			<-eCtx.Done()
+			g.remoteWriteQueues.Close()
+			return eCtx.Err()
+		})
+	}
+
 	return eGroup.Wait()
 }
 
@@ -532,6 +627,12 @@ func (g *GrafanaLive) IsHA() bool {
 	return g.Cfg != nil && g.Cfg.LiveHAEngine != ""
 }
 
+// PipelineStorage returns the storage of pipeline channel rules and write configs, it is nil when
+// the pipeline is disabled or uses the dev rule builder.
+func (g *GrafanaLive) PipelineStorage() pipeline.Storage {
+	return g.pipelineStorage
+}
+
 func runConcurrentlyIfNeeded(ctx context.Context, semaphore chan struct{}, fn func()) error {
 	if cap(semaphore) > 1 {
 		select {
@@ -633,6 +734,11 @@ func (g *GrafanaLive) handleOnSubscribe(ctx context.Context, client *centrifuge.
 		return centrifuge.SubscribeReply{}, centrifuge.ErrorPermissionDenied
 	}
 
+	if err := g.Limiter.CheckSubscribe(g.node.Hub().NumSubscribers(e.Channel)); err != nil {
+		logger.Debug("Subscription rejected by limits", "user", client.UserID(), "client", client.ID(), "channel", e.Channel, "error", err)
+		return centrifuge.SubscribeReply{}, &centrifuge.Error{Code: uint32(limits.HTTPStatus(err)), Message: err.Error()}
+	}
+
 	var reply models.SubscribeReply
 	var status backend.SubscribeStreamStatus
 	var ruleFound bool
@@ -733,6 +839,11 @@ func (g *GrafanaLive) handleOnPublish(ctx context.Context, client *centrifuge.Cl
 		return centrifuge.PublishReply{}, centrifuge.ErrorPermissionDenied
 	}
 
+	if err := g.Limiter.CheckPublish(limits.ProtocolLive, orgID, channel, len(e.Data)); err != nil {
+		logger.Debug("Publication rejected by limits", "user", client.UserID(), "client", client.ID(), "channel", e.Channel, "error", err)
+		return centrifuge.PublishReply{}, &centrifuge.Error{Code: uint32(limits.HTTPStatus(err)), Message: err.Error()}
+	}
+
 	if g.Pipeline != nil {
 		rule, ok, err := g.Pipeline.Get(user.OrgId, channel)
 		if err != nil {
@@ -967,6 +1078,11 @@ func (g *GrafanaLive) HandleHTTPPublish(ctx *models.ReqContext) response.Respons
 	user := ctx.SignedInUser
 	channel := cmd.Channel
 
+	if err := g.Limiter.CheckPublish(limits.ProtocolHTTP, user.OrgId, channel, len(cmd.Data)); err != nil {
+		logger.Debug("Publication rejected by limits", "user", user.UserId, "channel", channel, "error", err)
+		return response.Error(limits.HTTPStatus(err), err.Error(), nil)
+	}
+
 	if g.Pipeline != nil {
 		rule, ok, err := g.Pipeline.Get(user.OrgId, channel)
 		if err != nil {
@@ -1065,8 +1181,12 @@ func (g *GrafanaLive) HandleChannelRulesListHTTP(c *models.ReqContext) response.
 	if err != nil {
 		return response.Error(http.StatusInternalServerError, "Failed to get channel rules", err)
 	}
+	rules := make([]pipeline.ChannelRuleDto, 0, len(result))
+	for _, r := range result {
+		rules = append(rules, pipeline.ChannelRuleToDto(r))
+	}
 	return response.JSON(http.StatusOK, util.DynMap{
-		"rules": result,
+		"rules": rules,
 	})
 }
 
@@ -1082,6 +1202,7 @@ type ConvertDryRunResponse struct {
 
 type DryRunRuleStorage struct {
 	ChannelRules []pipeline.ChannelRule
+	WriteConfigs []pipeline.WriteConfig
 }
 
 func (s *DryRunRuleStorage) GetWriteConfig(_ context.Context, _ int64, _ pipeline.WriteConfigGetCmd) (pipeline.WriteConfig, bool, error) {
@@ -1113,7 +1234,7 @@ func (s *DryRunRuleStorage) DeleteChannelRule(_ context.Context, _ int64, _ pipe
 }
 
 func (s *DryRunRuleStorage) ListWriteConfigs(_ context.Context, _ int64) ([]pipeline.WriteConfig, error) {
-	return nil, nil
+	return s.WriteConfigs, nil
 }
 
 func (s *DryRunRuleStorage) ListChannelRules(_ context.Context, _ int64) ([]pipeline.ChannelRule, error) {
@@ -1141,7 +1262,7 @@ func (g *GrafanaLive) HandlePipelineConvertTestHTTP(c *models.ReqContext) respon
 		Storage:              storage,
 		ChannelHandlerGetter: g,
 	}
-	channelRuleGetter := pipeline.NewCacheSegmentedTree(builder)
+	channelRuleGetter := pipeline.NewStaticCacheSegmentedTree(builder)
 	pipe, err := pipeline.New(channelRuleGetter)
 	if err != nil {
 		return response.Error(http.StatusInternalServerError, "Error creating pipeline", err)
@@ -1165,6 +1286,95 @@ func (g *GrafanaLive) HandlePipelineConvertTestHTTP(c *models.ReqContext) respon
 	})
 }
 
+type PipelineDryRunRequest struct {
+	ChannelRules []pipeline.ChannelRule `json:"channelRules"`
+	Channel      string                 `json:"channel"`
+	Data         string                 `json:"data"`
+}
+
+type PipelineDryRunResponse struct {
+	ChannelFrames []*pipeline.ChannelFrame `json:"channelFrames"`
+	Outputs       []pipeline.DryRunOutput  `json:"outputs"`
+}
+
+// HandlePipelineTestHTTP runs sample data through channel rules from request
+// and returns converted frames and outputs which would have been performed.
+// Side-effecting outputs are only recorded, existing write configs of an
+// organization are used to validate rules.
+func (g *GrafanaLive) HandlePipelineTestHTTP(c *models.ReqContext) response.Response {
+	body, err := ioutil.ReadAll(c.Req.Body)
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Error reading body", err)
+	}
+	var req PipelineDryRunRequest
+	err = json.Unmarshal(body, &req)
+	if err != nil {
+		return response.Error(http.StatusBadRequest, "Error decoding request", err)
+	}
+	writeConfigs, err := g.pipelineStorage.ListWriteConfigs(c.Req.Context(), c.OrgId)
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to get write configs", err)
+	}
+	storage := &DryRunRuleStorage{
+		ChannelRules: req.ChannelRules,
+		WriteConfigs: writeConfigs,
+	}
+	recorder := pipeline.NewDryRunRecorder()
+	builder := &pipeline.StorageRuleBuilder{
+		FrameStorage:   pipeline.NewFrameStorage(),
+		StateStorage:   pipeline.NewStateStorage(),
+		Storage:        storage,
+		SecretsService: g.SecretsService,
+		DryRunRecorder: recorder,
+	}
+	channelRuleGetter := pipeline.NewStaticCacheSegmentedTree(builder)
+	pipe, err := pipeline.New(channelRuleGetter)
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Error creating pipeline", err)
+	}
+	rule, ok, err := channelRuleGetter.Get(c.OrgId, req.Channel)
+	if err != nil {
+		return response.Error(http.StatusBadRequest, "Error building channel rules", err)
+	}
+	if !ok {
+		return response.Error(http.StatusNotFound, "No rule found", nil)
+	}
+	var channelFrames []*pipeline.ChannelFrame
+	if rule.Converter != nil {
+		channelFrames, err = pipe.DataToChannelFrames(c.Req.Context(), *rule, c.OrgId, req.Channel, []byte(req.Data))
+		if err != nil {
+			return response.Error(http.StatusBadRequest, "Error converting data", err)
+		}
+	}
+	_, err = pipe.ProcessInput(c.Req.Context(), c.OrgId, req.Channel, []byte(req.Data))
+	if err != nil {
+		return response.Error(http.StatusBadRequest, "Error processing data", err)
+	}
+	return response.JSON(http.StatusOK, PipelineDryRunResponse{
+		ChannelFrames: channelFrames,
+		Outputs:       recorder.Outputs(),
+	})
+}
+
+// validateChannelRule makes sure channel rule can be built against existing
+// write configs of an organization without running any side effects.
+func (g *GrafanaLive) validateChannelRule(ctx context.Context, orgID int64, rule pipeline.ChannelRule) error {
+	writeConfigs, err := g.pipelineStorage.ListWriteConfigs(ctx, orgID)
+	if err != nil {
+		return err
+	}
+	builder := &pipeline.StorageRuleBuilder{
+		FrameStorage:   pipeline.NewFrameStorage(),
+		StateStorage:   pipeline.NewStateStorage(),
+		Storage:        &DryRunRuleStorage{ChannelRules: []pipeline.ChannelRule{rule}, WriteConfigs: writeConfigs},
+		SecretsService: g.SecretsService,
+		DryRunRecorder: pipeline.NewDryRunRecorder(),
+		HA:             g.Cfg.LiveHAEngine != "",
+	}
+	_, err = builder.BuildRules(ctx, orgID)
+	return err
+}
+
 // HandleChannelRulesPostHTTP ...
 func (g *GrafanaLive) HandleChannelRulesPostHTTP(c *models.ReqContext) response.Response {
 	body, err := ioutil.ReadAll(c.Req.Body)
@@ -1176,12 +1386,16 @@ func (g *GrafanaLive) HandleChannelRulesPostHTTP(c *models.ReqContext) response.
 	if err != nil {
 		return response.Error(http.StatusBadRequest, "Error decoding channel rule", err)
 	}
+	err = g.validateChannelRule(c.Req.Context(), c.OrgId, pipeline.ChannelRule{Pattern: cmd.Pattern, Settings: cmd.Settings})
+	if err != nil {
+		return response.Error(http.StatusBadRequest, "Invalid channel rule", err)
+	}
 	rule, err := g.pipelineStorage.CreateChannelRule(c.Req.Context(), c.OrgId, cmd)
 	if err != nil {
 		return response.Error(http.StatusInternalServerError, "Failed to create channel rule", err)
 	}
 	return response.JSON(http.StatusOK, util.DynMap{
-		"rule": rule,
+		"rule": pipeline.ChannelRuleToDto(rule),
 	})
 }
 
@@ -1199,12 +1413,16 @@ func (g *GrafanaLive) HandleChannelRulesPutHTTP(c *models.ReqContext) response.R
 	if cmd.Pattern == "" {
 		return response.Error(http.StatusBadRequest, "Rule pattern required", nil)
 	}
+	err = g.validateChannelRule(c.Req.Context(), c.OrgId, pipeline.ChannelRule{Pattern: cmd.Pattern, Settings: cmd.Settings})
+	if err != nil {
+		return response.Error(http.StatusBadRequest, "Invalid channel rule", err)
+	}
 	rule, err := g.pipelineStorage.UpdateChannelRule(c.Req.Context(), c.OrgId, cmd)
 	if err != nil {
 		return response.Error(http.StatusInternalServerError, "Failed to update channel rule", err)
 	}
 	return response.JSON(http.StatusOK, util.DynMap{
-		"rule": rule,
+		"rule": pipeline.ChannelRuleToDto(rule),
 	})
 }
 
@@ -1237,6 +1455,7 @@ func (g *GrafanaLive) HandlePipelineEntitiesListHTTP(_ *models.ReqContext) respo
 		"converters":      pipeline.ConvertersRegistry,
 		"frameProcessors": pipeline.FrameProcessorsRegistry,
 		"frameOutputs":    pipeline.FrameOutputsRegistry,
+		"sources":         pipeline.SourcesRegistry,
 	})
 }
 
@@ -0,0 +1,27 @@
+package managedstream
+
+import (
+	"context"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+)
+
+// SchemaFrameCache wraps FrameCache which is not shared between Grafana
+// instances. Update always reports schema change, so every frame is published
+// together with its schema and subscribers connected to other instances can
+// decode frames without having the schema in their local cache.
+type SchemaFrameCache struct {
+	FrameCache
+}
+
+// NewSchemaFrameCache ...
+func NewSchemaFrameCache(cache FrameCache) *SchemaFrameCache {
+	return &SchemaFrameCache{FrameCache: cache}
+}
+
+func (c *SchemaFrameCache) Update(ctx context.Context, orgID int64, channel string, jsonFrame data.FrameJSONCache) (bool, error) {
+	if _, err := c.FrameCache.Update(ctx, orgID, channel, jsonFrame); err != nil {
+		return false, err
+	}
+	return true, nil
+}
@@ -0,0 +1,68 @@
+package managedstream
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+)
+
+// FrameHistory keeps recent frames pushed into managed stream channels, so they
+// can be replayed to new subscribers.
+type FrameHistory interface {
+	// Append adds full JSON frame to channel history.
+	Append(ctx context.Context, orgID int64, channel string, frameJSON json.RawMessage) error
+	// Get returns channel history in chronological order.
+	Get(ctx context.Context, orgID int64, channel string) ([]json.RawMessage, error)
+}
+
+// mergeHistory joins history frames into a single frame. Only the trailing frames
+// with the same schema as the latest one are merged, older frames with another
+// schema can not be represented in one frame.
+func mergeHistory(history []json.RawMessage) (json.RawMessage, bool, error) {
+	if len(history) == 0 {
+		return nil, false, nil
+	}
+
+	frames := make([]*data.Frame, 0, len(history))
+	for i := len(history) - 1; i >= 0; i-- {
+		var frame data.Frame
+		if err := json.Unmarshal(history[i], &frame); err != nil {
+			return nil, false, fmt.Errorf("error unmarshaling history frame: %w", err)
+		}
+		if len(frames) > 0 && !sameFrameSchema(frames[0], &frame) {
+			break
+		}
+		frames = append(frames, &frame)
+	}
+
+	merged := frames[0].EmptyCopy()
+	for i := len(frames) - 1; i >= 0; i-- {
+		rowLen, err := frames[i].RowLen()
+		if err != nil {
+			return nil, false, err
+		}
+		for row := 0; row < rowLen; row++ {
+			merged.AppendRow(frames[i].RowCopy(row)...)
+		}
+	}
+
+	frameJSON, err := json.Marshal(merged)
+	if err != nil {
+		return nil, false, err
+	}
+	return frameJSON, true, nil
+}
+
+func sameFrameSchema(a, b *data.Frame) bool {
+	if a.Name != b.Name || len(a.Fields) != len(b.Fields) {
+		return false
+	}
+	for i := range a.Fields {
+		if a.Fields[i].Name != b.Fields[i].Name || a.Fields[i].Type() != b.Fields[i].Type() {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,100 @@
+package managedstream
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+)
+
+// historyIdleTimeout defines when history of channels without publications is removed.
+// History kept for longer maxAge is removed after maxAge instead.
+const historyIdleTimeout = 5 * time.Minute
+
+type historyEntry struct {
+	time  time.Time
+	frame json.RawMessage
+}
+
+// MemoryFrameHistory keeps channel history in memory of the current instance.
+type MemoryFrameHistory struct {
+	mu        sync.RWMutex
+	maxFrames int
+	maxAge    time.Duration
+	now       func() time.Time
+	history   map[int64]map[string][]historyEntry
+
+	lastCleanup time.Time
+}
+
+// NewMemoryFrameHistory creates MemoryFrameHistory which keeps up to maxFrames frames
+// not older than maxAge per channel. Zero value disables corresponding limit.
+func NewMemoryFrameHistory(maxFrames int, maxAge time.Duration) *MemoryFrameHistory {
+	return &MemoryFrameHistory{
+		maxFrames: maxFrames,
+		maxAge:    maxAge,
+		now:       time.Now,
+		history:   map[int64]map[string][]historyEntry{},
+	}
+}
+
+func (h *MemoryFrameHistory) Append(_ context.Context, orgID int64, channel string, frameJSON json.RawMessage) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if _, ok := h.history[orgID]; !ok {
+		h.history[orgID] = map[string][]historyEntry{}
+	}
+	now := h.now()
+	entries := append(h.history[orgID][channel], historyEntry{time: now, frame: frameJSON})
+	h.history[orgID][channel] = h.trim(entries, now)
+	h.cleanupLocked(now)
+	return nil
+}
+
+func (h *MemoryFrameHistory) Get(_ context.Context, orgID int64, channel string) ([]json.RawMessage, error) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	entries := h.trim(h.history[orgID][channel], h.now())
+	frames := make([]json.RawMessage, 0, len(entries))
+	for _, e := range entries {
+		frames = append(frames, e.frame)
+	}
+	return frames, nil
+}
+
+// trim drops entries exceeding configured limits. Entries are sorted by time.
+func (h *MemoryFrameHistory) trim(entries []historyEntry, now time.Time) []historyEntry {
+	if h.maxFrames > 0 && len(entries) > h.maxFrames {
+		entries = entries[len(entries)-h.maxFrames:]
+	}
+	if h.maxAge > 0 {
+		i := 0
+		for i < len(entries) && now.Sub(entries[i].time) > h.maxAge {
+			i++
+		}
+		entries = entries[i:]
+	}
+	return entries
+}
+
+// cleanupLocked removes history of channels which had no publications for the idle timeout.
+func (h *MemoryFrameHistory) cleanupLocked(now time.Time) {
+	if now.Sub(h.lastCleanup) < historyIdleTimeout {
+		return
+	}
+	h.lastCleanup = now
+	idleTimeout := historyIdleTimeout
+	if h.maxAge > idleTimeout {
+		idleTimeout = h.maxAge
+	}
+	for orgID, channels := range h.history {
+		for channel, entries := range channels {
+			if len(entries) == 0 || now.Sub(entries[len(entries)-1].time) > idleTimeout {
+				delete(channels, channel)
+			}
+		}
+		if len(channels) == 0 {
+			delete(h.history, orgID)
+		}
+	}
+}
@@ -0,0 +1,77 @@
+package managedstream
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func testFrameHistory(t *testing.T, h FrameHistory, maxFrames int) {
+	for i := 0; i < maxFrames+2; i++ {
+		err := h.Append(context.Background(), 1, "test", json.RawMessage(`{"i":`+strconv.Itoa(i)+`}`))
+		require.NoError(t, err)
+	}
+
+	// Only the latest frames kept in chronological order.
+	history, err := h.Get(context.Background(), 1, "test")
+	require.NoError(t, err)
+	require.Len(t, history, maxFrames)
+	require.JSONEq(t, `{"i":2}`, string(history[0]))
+	require.JSONEq(t, `{"i":`+strconv.Itoa(maxFrames+1)+`}`, string(history[maxFrames-1]))
+
+	// Not affected by other org.
+	history, err = h.Get(context.Background(), 2, "test")
+	require.NoError(t, err)
+	require.Len(t, history, 0)
+}
+
+func TestMemoryFrameHistory(t *testing.T) {
+	h := NewMemoryFrameHistory(3, 0)
+	require.NotNil(t, h)
+	testFrameHistory(t, h, 3)
+}
+
+func TestMemoryFrameHistoryMaxAge(t *testing.T) {
+	now := time.Now()
+	h := NewMemoryFrameHistory(0, time.Minute)
+	h.now = func() time.Time { return now }
+
+	require.NoError(t, h.Append(context.Background(), 1, "test", json.RawMessage(`{"i":1}`)))
+	now = now.Add(50 * time.Second)
+	require.NoError(t, h.Append(context.Background(), 1, "test", json.RawMessage(`{"i":2}`)))
+
+	history, err := h.Get(context.Background(), 1, "test")
+	require.NoError(t, err)
+	require.Len(t, history, 2)
+
+	now = now.Add(20 * time.Second)
+	history, err = h.Get(context.Background(), 1, "test")
+	require.NoError(t, err)
+	require.Len(t, history, 1)
+	require.JSONEq(t, `{"i":2}`, string(history[0]))
+}
+
+func TestMemoryFrameHistoryIdleChannelsRemoved(t *testing.T) {
+	now := time.Now()
+	h := NewMemoryFrameHistory(10, 0)
+	h.now = func() time.Time { return now }
+
+	require.NoError(t, h.Append(context.Background(), 1, "idle", json.RawMessage(`{"i":1}`)))
+	require.NoError(t, h.Append(context.Background(), 2, "idle", json.RawMessage(`{"i":1}`)))
+	now = now.Add(historyIdleTimeout / 2)
+	require.NoError(t, h.Append(context.Background(), 1, "active", json.RawMessage(`{"i":1}`)))
+
+	now = now.Add(historyIdleTimeout/2 + time.Second)
+	require.NoError(t, h.Append(context.Background(), 1, "active", json.RawMessage(`{"i":2}`)))
+
+	require.Len(t, h.history, 1)
+	require.NotContains(t, h.history[1], "idle")
+
+	history, err := h.Get(context.Background(), 1, "active")
+	require.NoError(t, err)
+	require.Len(t, history, 2)
+}
@@ -0,0 +1,91 @@
+package managedstream
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana/pkg/services/live/orgchannel"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// RedisFrameHistory keeps channel history in Redis sorted sets, so it's shared
+// between all Grafana instances.
+type RedisFrameHistory struct {
+	redisClient *redis.Client
+	maxFrames   int
+	maxAge      time.Duration
+	now         func() time.Time
+}
+
+// NewRedisFrameHistory creates RedisFrameHistory which keeps up to maxFrames frames
+// not older than maxAge per channel. Zero value disables corresponding limit.
+func NewRedisFrameHistory(redisClient *redis.Client, maxFrames int, maxAge time.Duration) *RedisFrameHistory {
+	return &RedisFrameHistory{
+		redisClient: redisClient,
+		maxFrames:   maxFrames,
+		maxAge:      maxAge,
+		now:         time.Now,
+	}
+}
+
+func (h *RedisFrameHistory) Append(ctx context.Context, orgID int64, channel string, frameJSON json.RawMessage) error {
+	key := getHistoryKey(orgchannel.PrependOrgID(orgID, channel))
+	now := h.now()
+	nowMs := now.UnixNano() / int64(time.Millisecond)
+
+	pipe := h.redisClient.TxPipeline()
+	defer func() { _ = pipe.Close() }()
+
+	// Members of a sorted set must be unique, so frame is prefixed with nanosecond timestamp.
+	pipe.ZAdd(ctx, key, &redis.Z{
+		Score:  float64(nowMs),
+		Member: strconv.FormatInt(now.UnixNano(), 10) + ":" + string(frameJSON),
+	})
+	if h.maxAge > 0 {
+		minMs := nowMs - h.maxAge.Milliseconds()
+		pipe.ZRemRangeByScore(ctx, key, "-inf", "("+strconv.FormatInt(minMs, 10))
+	}
+	if h.maxFrames > 0 {
+		pipe.ZRemRangeByRank(ctx, key, 0, int64(-h.maxFrames-1))
+	}
+	pipe.Expire(ctx, key, h.ttl())
+
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+func (h *RedisFrameHistory) Get(ctx context.Context, orgID int64, channel string) ([]json.RawMessage, error) {
+	key := getHistoryKey(orgchannel.PrependOrgID(orgID, channel))
+	min := "-inf"
+	if h.maxAge > 0 {
+		min = strconv.FormatInt(h.now().Add(-h.maxAge).UnixNano()/int64(time.Millisecond), 10)
+	}
+	members, err := h.redisClient.ZRangeByScore(ctx, key, &redis.ZRangeBy{Min: min, Max: "+inf"}).Result()
+	if err != nil {
+		return nil, err
+	}
+	frames := make([]json.RawMessage, 0, len(members))
+	for _, member := range members {
+		parts := strings.SplitN(member, ":", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		frames = append(frames, json.RawMessage(parts[1]))
+	}
+	return frames, nil
+}
+
+func (h *RedisFrameHistory) ttl() time.Duration {
+	if h.maxAge > 0 {
+		return h.maxAge
+	}
+	return frameCacheTTL
+}
+
+func getHistoryKey(channelID string) string {
+	return "gf_live.managed_stream_history." + channelID
+}
@@ -0,0 +1,21 @@
+//go:build redis
+// +build redis
+
+package managedstream
+
+import (
+	"testing"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRedisFrameHistory(t *testing.T) {
+	redisClient := redis.NewClient(&redis.Options{
+		Addr: "localhost:6379",
+	})
+	require.NoError(t, redisClient.Del(redisClient.Context(), getHistoryKey("1/test")).Err())
+	h := NewRedisFrameHistory(redisClient, 3, 0)
+	require.NotNil(t, h)
+	testFrameHistory(t, h, 3)
+}
@@ -41,19 +41,22 @@ type Runner struct {
 	publisher      models.ChannelPublisher
 	localPublisher LocalPublisher
 	frameCache     FrameCache
+	frameHistory   FrameHistory
 }
 
 type LocalPublisher interface {
 	PublishLocal(channel string, data []byte) error
 }
 
-// NewRunner creates new Runner.
-func NewRunner(publisher models.ChannelPublisher, localPublisher LocalPublisher, frameCache FrameCache) *Runner {
+// NewRunner creates new Runner. frameHistory is optional, when nil only the latest
+// frame is sent to new subscribers.
+func NewRunner(publisher models.ChannelPublisher, localPublisher LocalPublisher, frameCache FrameCache, frameHistory FrameHistory) *Runner {
 	return &Runner{
 		publisher:      publisher,
 		localPublisher: localPublisher,
 		streams:        map[int64]map[string]*NamespaceStream{},
 		frameCache:     frameCache,
+		frameHistory:   frameHistory,
 	}
 }
 
@@ -124,7 +127,7 @@ func (r *Runner) GetOrCreateStream(orgID int64, scope string, namespace string)
 	prefix := scope + "/" + namespace
 	s, ok := r.streams[orgID][prefix]
 	if !ok {
-		s = NewNamespaceStream(orgID, scope, namespace, r.publisher, r.localPublisher, r.frameCache)
+		s = NewNamespaceStream(orgID, scope, namespace, r.publisher, r.localPublisher, r.frameCache, r.frameHistory)
 		r.streams[orgID][prefix] = s
 	}
 	return s, nil
@@ -138,6 +141,7 @@ type NamespaceStream struct {
 	publisher      models.ChannelPublisher
 	localPublisher LocalPublisher
 	frameCache     FrameCache
+	frameHistory   FrameHistory
 	rateMu         sync.RWMutex
 	rates          map[string][60]rateEntry
 }
@@ -155,7 +159,7 @@ type ManagedChannel struct {
 }
 
 // NewNamespaceStream creates new NamespaceStream.
-func NewNamespaceStream(orgID int64, scope string, namespace string, publisher models.ChannelPublisher, localPublisher LocalPublisher, schemaUpdater FrameCache) *NamespaceStream {
+func NewNamespaceStream(orgID int64, scope string, namespace string, publisher models.ChannelPublisher, localPublisher LocalPublisher, schemaUpdater FrameCache, frameHistory FrameHistory) *NamespaceStream {
 	return &NamespaceStream{
 		orgID:          orgID,
 		scope:          scope,
@@ -163,12 +167,14 @@ func NewNamespaceStream(orgID int64, scope string, namespace string, publisher m
 		publisher:      publisher,
 		localPublisher: localPublisher,
 		frameCache:     schemaUpdater,
+		frameHistory:   frameHistory,
 		rates:          map[string][60]rateEntry{},
 	}
 }
 
 // Push sends frame to the stream and saves it for later retrieval by subscribers.
 // * Saves the entire frame to cache.
+// * Appends the entire frame to channel history if history is enabled.
 // * If schema has been changed sends entire frame to channel, otherwise only data.
 func (s *NamespaceStream) Push(ctx context.Context, path string, frame *data.Frame) error {
 	jsonFrameCache, err := data.FrameToJSONCache(frame)
@@ -185,6 +191,13 @@ func (s *NamespaceStream) Push(ctx context.Context, path string, frame *data.Fra
 		return err
 	}
 
+	if s.frameHistory != nil {
+		if err := s.frameHistory.Append(ctx, s.orgID, channel, jsonFrameCache.Bytes(data.IncludeAll)); err != nil {
+			logger.Error("Error appending frame to managed stream history", "error", err)
+			return err
+		}
+	}
+
 	// When the schema has not changed, just send the data.
 	include := data.IncludeDataOnly
 	if isUpdated {
@@ -240,6 +253,20 @@ func (s *NamespaceStream) GetHandlerForPath(_ string) (models.ChannelHandler, er
 
 func (s *NamespaceStream) OnSubscribe(ctx context.Context, u *models.SignedInUser, e models.SubscribeEvent) (models.SubscribeReply, backend.SubscribeStreamStatus, error) {
 	reply := models.SubscribeReply{}
+	if s.frameHistory != nil {
+		history, err := s.frameHistory.Get(ctx, u.OrgId, e.Channel)
+		if err != nil {
+			return reply, 0, err
+		}
+		frameJSON, ok, err := mergeHistory(history)
+		if err != nil {
+			return reply, 0, err
+		}
+		if ok {
+			reply.Data = frameJSON
+			return reply, backend.SubscribeStreamStatusOK, nil
+		}
+	}
 	frameJSON, ok, err := s.frameCache.GetFrame(ctx, u.OrgId, e.Channel)
 	if err != nil {
 		return reply, 0, err
@@ -2,10 +2,13 @@ package managedstream
 
 import (
 	"context"
+	"encoding/json"
 	"testing"
 	"time"
 
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
 	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/grafana/grafana/pkg/models"
 	"github.com/stretchr/testify/require"
 )
 
@@ -19,13 +22,13 @@ func (p *testPublisher) publish(_ int64, _ string, _ []byte) error {
 
 func TestNewManagedStream(t *testing.T) {
 	publisher := &testPublisher{t: t}
-	c := NewNamespaceStream(1, "stream", "a", publisher.publish, nil, NewMemoryFrameCache())
+	c := NewNamespaceStream(1, "stream", "a", publisher.publish, nil, NewMemoryFrameCache(), nil)
 	require.NotNil(t, c)
 }
 
 func TestManagedStreamMinuteRate(t *testing.T) {
 	publisher := &testPublisher{t: t}
-	c := NewNamespaceStream(1, "stream", "a", publisher.publish, nil, NewMemoryFrameCache())
+	c := NewNamespaceStream(1, "stream", "a", publisher.publish, nil, NewMemoryFrameCache(), nil)
 	require.NotNil(t, c)
 
 	c.incRate("test1", time.Now().Unix())
@@ -48,7 +51,7 @@ func TestManagedStreamMinuteRate(t *testing.T) {
 func TestGetManagedStreams(t *testing.T) {
 	publisher := &testPublisher{t: t}
 	frameCache := NewMemoryFrameCache()
-	runner := NewRunner(publisher.publish, nil, frameCache)
+	runner := NewRunner(publisher.publish, nil, frameCache, nil)
 	s1, err := runner.GetOrCreateStream(1, "stream", "test1")
 	require.NoError(t, err)
 	s2, err := runner.GetOrCreateStream(1, "stream", "test2")
@@ -83,3 +86,30 @@ func TestGetManagedStreams(t *testing.T) {
 	require.NoError(t, err)
 	require.Len(t, managedChannels, 7) // Not affected by other org.
 }
+
+func TestManagedStreamHistoryReplay(t *testing.T) {
+	publisher := &testPublisher{t: t}
+	s := NewNamespaceStream(1, "stream", "a", publisher.publish, nil, NewMemoryFrameCache(), NewMemoryFrameHistory(3, 0))
+
+	push := func(frame *data.Frame) {
+		require.NoError(t, s.Push(context.Background(), "cpu", frame))
+	}
+	push(data.NewFrame("cpu", data.NewField("value", nil, []string{"old schema"})))
+	for i := 1; i <= 3; i++ {
+		push(data.NewFrame("cpu",
+			data.NewField("time", nil, []time.Time{time.Unix(int64(i), 0)}),
+			data.NewField("value", nil, []float64{float64(i)}),
+		))
+	}
+
+	reply, status, err := s.OnSubscribe(context.Background(), &models.SignedInUser{OrgId: 1}, models.SubscribeEvent{Channel: "stream/a/cpu"})
+	require.NoError(t, err)
+	require.Equal(t, backend.SubscribeStreamStatusOK, status)
+
+	var frame data.Frame
+	require.NoError(t, json.Unmarshal(reply.Data, &frame))
+	require.Len(t, frame.Fields, 2)
+	require.Equal(t, 3, frame.Fields[1].Len())
+	require.Equal(t, 1.0, frame.Fields[1].At(0))
+	require.Equal(t, 3.0, frame.Fields[1].At(2))
+}
@@ -25,12 +25,14 @@ type ChannelRuleSettings struct {
 	Converter       *ConverterConfig        `json:"converter,omitempty"`
 	FrameProcessors []*FrameProcessorConfig `json:"frameProcessors,omitempty"`
 	FrameOutputters []*FrameOutputterConfig `json:"frameOutputs,omitempty"`
+	Sources         []*SourceConfig         `json:"sources,omitempty"`
 }
 
 type ChannelRule struct {
-	OrgId    int64               `json:"-"`
-	Pattern  string              `json:"pattern"`
-	Settings ChannelRuleSettings `json:"settings"`
+	OrgId          int64               `json:"-"`
+	Pattern        string              `json:"pattern"`
+	Settings       ChannelRuleSettings `json:"settings"`
+	SecureSettings map[string][]byte   `json:"secureSettings,omitempty"`
 }
 
 type ConverterConfig struct {
@@ -49,11 +51,42 @@ type KeepFieldsFrameProcessorConfig struct {
 	FieldNames []string `json:"fieldNames"`
 }
 
+type AggregateFrameProcessorConfig struct {
+	WindowSeconds int64 `json:"windowSeconds"`
+	// Functions is a list of avg, min, max, count. Default is avg.
+	Functions []string `json:"functions,omitempty"`
+	// FieldNames to aggregate, all numeric fields are aggregated if empty.
+	FieldNames []string `json:"fieldNames,omitempty"`
+}
+
+type RateFrameProcessorConfig struct {
+	// FieldNames to compute rate for, all numeric fields are used if empty.
+	FieldNames []string `json:"fieldNames,omitempty"`
+}
+
+type FieldUpdate struct {
+	FieldName string `json:"fieldName"`
+	Name      string `json:"name,omitempty"`
+	Unit      string `json:"unit,omitempty"`
+}
+
+type UpdateFieldsFrameProcessorConfig struct {
+	Fields []FieldUpdate `json:"fields"`
+}
+
+type ScriptFrameProcessorConfig struct {
+	Script string `json:"script"`
+}
+
 type FrameProcessorConfig struct {
-	Type                      string                          `json:"type" ts_type:"Omit<keyof FrameProcessorConfig, 'type'>"`
-	DropFieldsProcessorConfig *DropFieldsFrameProcessorConfig `json:"dropFields,omitempty"`
-	KeepFieldsProcessorConfig *KeepFieldsFrameProcessorConfig `json:"keepFields,omitempty"`
-	MultipleProcessorConfig   *MultipleFrameProcessorConfig   `json:"multiple,omitempty"`
+	Type                        string                            `json:"type" ts_type:"Omit<keyof FrameProcessorConfig, 'type'>"`
+	DropFieldsProcessorConfig   *DropFieldsFrameProcessorConfig   `json:"dropFields,omitempty"`
+	KeepFieldsProcessorConfig   *KeepFieldsFrameProcessorConfig   `json:"keepFields,omitempty"`
+	MultipleProcessorConfig     *MultipleFrameProcessorConfig     `json:"multiple,omitempty"`
+	AggregateProcessorConfig    *AggregateFrameProcessorConfig    `json:"aggregate,omitempty"`
+	RateProcessorConfig         *RateFrameProcessorConfig         `json:"rate,omitempty"`
+	UpdateFieldsProcessorConfig *UpdateFieldsFrameProcessorConfig `json:"updateFields,omitempty"`
+	ScriptProcessorConfig       *ScriptFrameProcessorConfig       `json:"script,omitempty"`
 }
 
 type MultipleFrameProcessorConfig struct {
@@ -151,4 +184,43 @@ type AutoInfluxConverterConfig struct {
 
 type JsonFrameConverterConfig struct{}
 
+type KafkaSourceConfig struct {
+	Brokers []string `json:"brokers"`
+	Topic   string   `json:"topic"`
+	// GroupID is a consumer group, if not set then the topic is consumed from the
+	// latest offset by each Grafana instance. Required when Live HA engine is
+	// enabled, so each message is processed by one of Grafana instances only.
+	GroupID string `json:"groupId,omitempty"`
+}
+
+type MQTTSourceConfig struct {
+	Broker string `json:"broker"`
+	Topic  string `json:"topic"`
+	QoS    byte   `json:"qos,omitempty"`
+	// ClientID is suffixed with an ID of the Grafana instance, so instances
+	// of an HA setup do not take over each other's connection.
+	ClientID string `json:"clientId,omitempty"`
+	// SharedGroup subscribes to the topic with a shared subscription
+	// ($share/<group>/<topic>), so each message is processed by one of Grafana
+	// instances only. Required when Live HA engine is enabled.
+	SharedGroup string `json:"sharedGroup,omitempty"`
+	Username    string `json:"username,omitempty"`
+	// Password is kept in the mqttPassword secure setting of the channel rule.
+}
+
+type NATSSourceConfig struct {
+	URL     string `json:"url"`
+	Subject string `json:"subject"`
+	// Queue is a queue group, if set then each message is processed by one of
+	// Grafana instances only. Required when Live HA engine is enabled.
+	Queue string `json:"queue,omitempty"`
+}
+
+type SourceConfig struct {
+	Type              string             `json:"type" ts_type:"Omit<keyof SourceConfig, 'type'>"`
+	KafkaSourceConfig *KafkaSourceConfig `json:"kafka,omitempty"`
+	MQTTSourceConfig  *MQTTSourceConfig  `json:"mqtt,omitempty"`
+	NATSSourceConfig  *NATSSourceConfig  `json:"nats,omitempty"`
+}
+
 type ManagedStreamOutputConfig struct{}
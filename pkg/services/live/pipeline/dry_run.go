@@ -0,0 +1,90 @@
+package pipeline
+
+import (
+	"context"
+	"sync"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+)
+
+// DryRunOutput is an output which would have been performed by a side-effecting
+// outputter if pipeline was not running in dry run mode.
+type DryRunOutput struct {
+	Type    string      `json:"type"`
+	Channel string      `json:"channel"`
+	Frame   *data.Frame `json:"frame,omitempty"`
+	Data    string      `json:"data,omitempty"`
+}
+
+// DryRunRecorder collects outputs of a pipeline running in dry run mode.
+type DryRunRecorder struct {
+	mu      sync.Mutex
+	outputs []DryRunOutput
+}
+
+func NewDryRunRecorder() *DryRunRecorder {
+	return &DryRunRecorder{}
+}
+
+func (r *DryRunRecorder) record(output DryRunOutput) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.outputs = append(r.outputs, output)
+}
+
+// Outputs returns outputs recorded so far.
+func (r *DryRunRecorder) Outputs() []DryRunOutput {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	outputs := make([]DryRunOutput, len(r.outputs))
+	copy(outputs, r.outputs)
+	return outputs
+}
+
+// DryRunFrameOutput replaces side-effecting frame outputter in dry run mode.
+// It records frame instead of sending it anywhere.
+type DryRunFrameOutput struct {
+	recorder   *DryRunRecorder
+	outputType string
+}
+
+func NewDryRunFrameOutput(recorder *DryRunRecorder, outputType string) *DryRunFrameOutput {
+	return &DryRunFrameOutput{recorder: recorder, outputType: outputType}
+}
+
+func (out *DryRunFrameOutput) Type() string {
+	return out.outputType
+}
+
+func (out *DryRunFrameOutput) OutputFrame(_ context.Context, vars Vars, frame *data.Frame) ([]*ChannelFrame, error) {
+	out.recorder.record(DryRunOutput{
+		Type:    out.outputType,
+		Channel: vars.Channel,
+		Frame:   frame,
+	})
+	return nil, nil
+}
+
+// DryRunDataOutput replaces side-effecting data outputter in dry run mode.
+// It records data instead of sending it anywhere.
+type DryRunDataOutput struct {
+	recorder   *DryRunRecorder
+	outputType string
+}
+
+func NewDryRunDataOutput(recorder *DryRunRecorder, outputType string) *DryRunDataOutput {
+	return &DryRunDataOutput{recorder: recorder, outputType: outputType}
+}
+
+func (out *DryRunDataOutput) Type() string {
+	return out.outputType
+}
+
+func (out *DryRunDataOutput) OutputData(_ context.Context, vars Vars, data []byte) ([]*ChannelData, error) {
+	out.recorder.record(DryRunOutput{
+		Type:    out.outputType,
+		Channel: vars.Channel,
+		Data:    string(data),
+	})
+	return nil, nil
+}
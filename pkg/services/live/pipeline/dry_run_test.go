@@ -0,0 +1,55 @@
+package pipeline
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestStorageRuleBuilderDryRun(t *testing.T) {
+	recorder := NewDryRunRecorder()
+	builder := &StorageRuleBuilder{
+		FrameStorage:   NewFrameStorage(),
+		StateStorage:   NewStateStorage(),
+		DryRunRecorder: recorder,
+		Storage: &testStorage{
+			rules: []ChannelRule{{
+				Pattern: "stream/test/dry",
+				Settings: ChannelRuleSettings{
+					Converter: &ConverterConfig{
+						Type:                    ConverterTypeJsonAuto,
+						AutoJsonConverterConfig: &AutoJsonConverterConfig{},
+					},
+					DataOutputters: []*DataOutputterConfig{{Type: DataOutputTypeBuiltin}},
+					FrameOutputters: []*FrameOutputterConfig{
+						{Type: FrameOutputTypeManagedStream},
+						{
+							Type:                    FrameOutputTypeRemoteWrite,
+							RemoteWriteOutputConfig: &RemoteWriteOutputConfig{UID: "prom"},
+						},
+					},
+				},
+			}},
+			writeConfigs: []WriteConfig{{
+				UID:      "prom",
+				Settings: WriteSettings{Endpoint: "http://localhost:9090/api/v1/write"},
+			}},
+		},
+	}
+	pipe, err := New(NewStaticCacheSegmentedTree(builder))
+	require.NoError(t, err)
+
+	ok, err := pipe.ProcessInput(context.Background(), 1, "stream/test/dry", []byte(`{"value": 1}`))
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	outputs := recorder.Outputs()
+	require.Len(t, outputs, 3)
+	require.Equal(t, DataOutputTypeBuiltin, outputs[0].Type)
+	require.Equal(t, `{"value": 1}`, outputs[0].Data)
+	require.Equal(t, FrameOutputTypeManagedStream, outputs[1].Type)
+	require.NotNil(t, outputs[1].Frame)
+	require.Equal(t, FrameOutputTypeRemoteWrite, outputs[2].Type)
+	require.Equal(t, "stream/test/dry", outputs[2].Channel)
+}
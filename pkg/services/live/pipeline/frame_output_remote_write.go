@@ -3,7 +3,6 @@ package pipeline
 import (
 	"bytes"
 	"context"
-	"errors"
 	"fmt"
 	"net/http"
 	"sync"
@@ -36,6 +35,10 @@ type RemoteWriteFrameOutput struct {
 
 	httpClient *http.Client
 	buffer     []prompb.TimeSeries
+
+	// queue when set stores frames on disk before sending them, so data is
+	// not lost while remote write endpoint is unavailable.
+	queue *RemoteWriteQueue
 }
 
 func NewRemoteWriteFrameOutput(endpoint string, basicAuth *BasicAuth, sampleMilliseconds int64) *RemoteWriteFrameOutput {
@@ -51,6 +54,12 @@ func NewRemoteWriteFrameOutput(endpoint string, basicAuth *BasicAuth, sampleMill
 	return out
 }
 
+// NewQueuedRemoteWriteFrameOutput creates remote write output which passes
+// frames to a queue. Queue takes care of batching, down-sampling and retries.
+func NewQueuedRemoteWriteFrameOutput(queue *RemoteWriteQueue) *RemoteWriteFrameOutput {
+	return &RemoteWriteFrameOutput{queue: queue}
+}
+
 const FrameOutputTypeRemoteWrite = "remoteWrite"
 
 func (out *RemoteWriteFrameOutput) Type() string {
@@ -81,6 +90,12 @@ func (out *RemoteWriteFrameOutput) flushPeriodically() {
 }
 
 func (out *RemoteWriteFrameOutput) sample(timeSeries []prompb.TimeSeries) []prompb.TimeSeries {
+	return sampleTimeSeries(timeSeries, out.SampleMilliseconds)
+}
+
+// sampleTimeSeries keeps one point per sampleMilliseconds interval for each
+// __name__ label.
+func sampleTimeSeries(timeSeries []prompb.TimeSeries, sampleMilliseconds int64) []prompb.TimeSeries {
 	samples := map[string]prompb.TimeSeries{}
 	timestamps := map[string]int64{}
 
@@ -104,7 +119,7 @@ func (out *RemoteWriteFrameOutput) sample(timeSeries []prompb.TimeSeries) []prom
 		// In-place filtering, see https://github.com/golang/go/wiki/SliceTricks#filter-in-place.
 		n := 0
 		for _, s := range ts.Samples {
-			if lastTimestamp == 0 || s.Timestamp > lastTimestamp+out.SampleMilliseconds {
+			if lastTimestamp == 0 || s.Timestamp > lastTimestamp+sampleMilliseconds {
 				ts.Samples[n] = s
 				n++
 				lastTimestamp = s.Timestamp
@@ -144,33 +159,42 @@ func (out *RemoteWriteFrameOutput) flush(timeSeries []prompb.TimeSeries) error {
 	if err != nil {
 		return fmt.Errorf("error converting time series to bytes: %v", err)
 	}
-	logger.Debug("Sending to remote write endpoint", "url", out.Endpoint, "bodyLength", len(remoteWriteData))
-	req, err := http.NewRequest(http.MethodPost, out.Endpoint, bytes.NewReader(remoteWriteData))
+	return sendRemoteWrite(context.Background(), out.httpClient, out.Endpoint, out.BasicAuth, remoteWriteData)
+}
+
+// sendRemoteWrite posts remote write request body to endpoint.
+func sendRemoteWrite(ctx context.Context, client *http.Client, endpoint string, basicAuth *BasicAuth, remoteWriteData []byte) error {
+	logger.Debug("Sending to remote write endpoint", "url", endpoint, "bodyLength", len(remoteWriteData))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(remoteWriteData))
 	if err != nil {
 		return fmt.Errorf("error constructing remote write request: %w", err)
 	}
 	req.Header.Set("Content-Type", "application/x-protobuf")
 	req.Header.Set("Content-Encoding", "snappy")
 	req.Header.Set("X-Prometheus-Remote-Write-Version", "0.1.0")
-	if out.BasicAuth != nil {
-		req.SetBasicAuth(out.BasicAuth.User, out.BasicAuth.Password)
+	if basicAuth != nil {
+		req.SetBasicAuth(basicAuth.User, basicAuth.Password)
 	}
 
 	started := time.Now()
-	resp, err := out.httpClient.Do(req)
+	resp, err := client.Do(req)
 	if err != nil {
 		return fmt.Errorf("error sending remote write request: %w", err)
 	}
 	_ = resp.Body.Close()
-	if resp.StatusCode != http.StatusOK {
+	if resp.StatusCode/100 != 2 {
 		logger.Error("Unexpected response code from remote write endpoint", "code", resp.StatusCode)
-		return errors.New("unexpected response code from remote write endpoint")
+		return remoteWriteStatusError{StatusCode: resp.StatusCode}
 	}
-	logger.Debug("Successfully sent to remote write endpoint", "url", out.Endpoint, "elapsed", time.Since(started))
+	logger.Debug("Successfully sent to remote write endpoint", "url", endpoint, "elapsed", time.Since(started))
 	return nil
 }
 
 func (out *RemoteWriteFrameOutput) OutputFrame(_ context.Context, _ Vars, frame *data.Frame) ([]*ChannelFrame, error) {
+	if out.queue != nil {
+		out.queue.Append(remotewrite.TimeSeriesFromFramesLabelsColumn(frame))
+		return nil, nil
+	}
 	if out.Endpoint == "" {
 		logger.Debug("Skip sending to remote write: no url")
 		return nil, nil
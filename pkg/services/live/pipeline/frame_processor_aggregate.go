@@ -0,0 +1,324 @@
+package pipeline
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+)
+
+const (
+	AggregateFunctionAvg   = "avg"
+	AggregateFunctionMin   = "min"
+	AggregateFunctionMax   = "max"
+	AggregateFunctionCount = "count"
+)
+
+// AggregateFrameProcessor aggregates numeric fields over tumbling time windows. Frame
+// is passed further only when a window closes, so it can be used to downsample
+// high-frequency data. Rows are grouped by values of string fields (labels).
+type AggregateFrameProcessor struct {
+	config       AggregateFrameProcessorConfig
+	stateStorage *StateStorage
+	stateKey     string
+}
+
+func NewAggregateFrameProcessor(stateStorage *StateStorage, config AggregateFrameProcessorConfig) (*AggregateFrameProcessor, error) {
+	if config.WindowSeconds <= 0 {
+		return nil, errors.New("aggregate window must be positive")
+	}
+	if len(config.Functions) == 0 {
+		config.Functions = []string{AggregateFunctionAvg}
+	}
+	for _, fn := range config.Functions {
+		switch fn {
+		case AggregateFunctionAvg, AggregateFunctionMin, AggregateFunctionMax, AggregateFunctionCount:
+		default:
+			return nil, fmt.Errorf("unknown aggregate function: %s", fn)
+		}
+	}
+	if stateStorage == nil {
+		stateStorage = NewStateStorage()
+	}
+	stateKey, err := json.Marshal(config)
+	if err != nil {
+		return nil, err
+	}
+	return &AggregateFrameProcessor{
+		config:       config,
+		stateStorage: stateStorage,
+		stateKey:     FrameProcessorTypeAggregate + string(stateKey),
+	}, nil
+}
+
+const FrameProcessorTypeAggregate = "aggregate"
+
+func (p *AggregateFrameProcessor) Type() string {
+	return FrameProcessorTypeAggregate
+}
+
+type aggregateFieldKey struct {
+	name   string
+	labels string
+}
+
+type aggregateField struct {
+	name   string
+	labels data.Labels
+}
+
+type aggregateBucket struct {
+	count int64
+	sum   float64
+	min   float64
+	max   float64
+}
+
+type aggregateWindow struct {
+	start  time.Time
+	groups []string
+	// groupLabels are values of string fields by group.
+	groupLabels map[string][]*string
+	buckets     map[string]map[int]*aggregateBucket
+}
+
+type aggregateState struct {
+	mu            sync.Mutex
+	timeFieldName string
+	labelFields   []string
+	fields        []aggregateField
+	fieldIndex    map[aggregateFieldKey]int
+	window        *aggregateWindow
+	// frameName is a name of last processed frame, used for flushed frames.
+	frameName string
+	// flushedUntil is an end of last window flushed by timer, data
+	// before it is late.
+	flushedUntil time.Time
+}
+
+func (s *aggregateState) hasPending() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.window != nil
+}
+
+func newAggregateWindow(start time.Time) *aggregateWindow {
+	return &aggregateWindow{
+		start:       start,
+		groupLabels: map[string][]*string{},
+		buckets:     map[string]map[int]*aggregateBucket{},
+	}
+}
+
+func (p *AggregateFrameProcessor) ProcessFrame(_ context.Context, vars Vars, frame *data.Frame) (*data.Frame, error) {
+	state := p.stateStorage.GetOrCreate(vars.OrgID, vars.Channel, p.stateKey, func() interface{} {
+		return &aggregateState{fieldIndex: map[aggregateFieldKey]int{}}
+	}).(*aggregateState)
+
+	state.mu.Lock()
+	defer state.mu.Unlock()
+
+	state.frameName = frame.Name
+	timeFieldIdx := -1
+	var labelFieldIdx, valueFieldIdx []int
+	for i, f := range frame.Fields {
+		switch {
+		case f.Type().Time() && timeFieldIdx < 0:
+			timeFieldIdx = i
+		case f.Type() == data.FieldTypeString || f.Type() == data.FieldTypeNullableString:
+			labelFieldIdx = append(labelFieldIdx, i)
+		case f.Type().Numeric() && (len(p.config.FieldNames) == 0 || stringInSlice(f.Name, p.config.FieldNames)):
+			valueFieldIdx = append(valueFieldIdx, i)
+		}
+	}
+	if timeFieldIdx >= 0 {
+		state.timeFieldName = frame.Fields[timeFieldIdx].Name
+	}
+	for _, i := range labelFieldIdx {
+		if !stringInSlice(frame.Fields[i].Name, state.labelFields) {
+			state.labelFields = append(state.labelFields, frame.Fields[i].Name)
+		}
+	}
+	stateValueIdx := make([]int, len(valueFieldIdx))
+	for j, i := range valueFieldIdx {
+		f := frame.Fields[i]
+		key := aggregateFieldKey{name: f.Name, labels: f.Labels.String()}
+		idx, ok := state.fieldIndex[key]
+		if !ok {
+			idx = len(state.fields)
+			state.fields = append(state.fields, aggregateField{name: f.Name, labels: f.Labels.Copy()})
+			state.fieldIndex[key] = idx
+		}
+		stateValueIdx[j] = idx
+	}
+
+	window := time.Duration(p.config.WindowSeconds) * time.Second
+	var closed []*aggregateWindow
+
+	rowLen, err := frame.RowLen()
+	if err != nil {
+		return nil, err
+	}
+	for row := 0; row < rowLen; row++ {
+		t := time.Now()
+		if timeFieldIdx >= 0 {
+			if v, ok := frame.Fields[timeFieldIdx].ConcreteAt(row); ok {
+				t = v.(time.Time)
+			}
+		}
+		start := t.Truncate(window)
+		if start.Before(state.flushedUntil) {
+			// Late data for a window flushed by timer.
+			continue
+		}
+		if state.window == nil {
+			state.window = newAggregateWindow(start)
+		}
+		if start.Before(state.window.start) {
+			// Late data for an already closed window.
+			continue
+		}
+		if start.After(state.window.start) {
+			closed = append(closed, state.window)
+			state.window = newAggregateWindow(start)
+		}
+
+		groupLabels := make([]*string, len(state.labelFields))
+		groupKey := make([]string, len(state.labelFields))
+		for _, i := range labelFieldIdx {
+			f := frame.Fields[i]
+			v, ok := f.ConcreteAt(row)
+			if !ok {
+				continue
+			}
+			s := v.(string)
+			for j, name := range state.labelFields {
+				if name == f.Name {
+					groupLabels[j] = &s
+					groupKey[j] = s
+				}
+			}
+		}
+		key := strings.Join(groupKey, "\x00")
+		buckets, ok := state.window.buckets[key]
+		if !ok {
+			buckets = map[int]*aggregateBucket{}
+			state.window.buckets[key] = buckets
+			state.window.groups = append(state.window.groups, key)
+			state.window.groupLabels[key] = groupLabels
+		}
+
+		for j, i := range valueFieldIdx {
+			v, err := frame.Fields[i].FloatAt(row)
+			if err != nil {
+				return nil, err
+			}
+			if math.IsNaN(v) {
+				continue
+			}
+			b, ok := buckets[stateValueIdx[j]]
+			if !ok {
+				b = &aggregateBucket{min: v, max: v}
+				buckets[stateValueIdx[j]] = b
+			}
+			b.count++
+			b.sum += v
+			b.min = math.Min(b.min, v)
+			b.max = math.Max(b.max, v)
+		}
+	}
+
+	if len(closed) == 0 {
+		return nil, nil
+	}
+	return p.windowsToFrame(frame.Name, state, closed), nil
+}
+
+// FlushFrame passes further the current window of a channel if no data arrived
+// for one more window after its end, so the last window of a channel which
+// goes quiet is not held back forever.
+func (p *AggregateFrameProcessor) FlushFrame(_ context.Context, vars Vars, now time.Time) (*data.Frame, error) {
+	s, ok := p.stateStorage.get(vars.OrgID, vars.Channel, p.stateKey)
+	if !ok {
+		return nil, nil
+	}
+	state := s.(*aggregateState)
+
+	state.mu.Lock()
+	defer state.mu.Unlock()
+
+	if state.window == nil {
+		return nil, nil
+	}
+	window := time.Duration(p.config.WindowSeconds) * time.Second
+	end := state.window.start.Add(window)
+	if now.Before(end.Add(window)) {
+		return nil, nil
+	}
+	closed := state.window
+	state.window = nil
+	state.flushedUntil = end
+	return p.windowsToFrame(state.frameName, state, []*aggregateWindow{closed}), nil
+}
+
+func (p *AggregateFrameProcessor) windowsToFrame(name string, state *aggregateState, windows []*aggregateWindow) *data.Frame {
+	timeFieldName := state.timeFieldName
+	if timeFieldName == "" {
+		timeFieldName = "time"
+	}
+	fields := []*data.Field{data.NewField(timeFieldName, nil, []time.Time{})}
+	for _, labelField := range state.labelFields {
+		fields = append(fields, data.NewField(labelField, nil, []*string{}))
+	}
+	for _, f := range state.fields {
+		for _, fn := range p.config.Functions {
+			fields = append(fields, data.NewField(f.name+"_"+fn, f.labels.Copy(), []*float64{}))
+		}
+	}
+	resultFrame := data.NewFrame(name, fields...)
+
+	for _, w := range windows {
+		for _, group := range w.groups {
+			values := []interface{}{w.start}
+			for _, label := range w.groupLabels[group] {
+				values = append(values, label)
+			}
+			// Labels discovered after the window group was created.
+			for i := len(w.groupLabels[group]); i < len(state.labelFields); i++ {
+				values = append(values, (*string)(nil))
+			}
+			for idx := range state.fields {
+				b := w.buckets[group][idx]
+				for _, fn := range p.config.Functions {
+					values = append(values, b.value(fn))
+				}
+			}
+			resultFrame.AppendRow(values...)
+		}
+	}
+	return resultFrame
+}
+
+func (b *aggregateBucket) value(fn string) *float64 {
+	if b == nil || b.count == 0 {
+		return nil
+	}
+	var v float64
+	switch fn {
+	case AggregateFunctionAvg:
+		v = b.sum / float64(b.count)
+	case AggregateFunctionMin:
+		v = b.min
+	case AggregateFunctionMax:
+		v = b.max
+	case AggregateFunctionCount:
+		v = float64(b.count)
+	}
+	return &v
+}
@@ -0,0 +1,140 @@
+package pipeline
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAggregateFrameProcessor(t *testing.T) {
+	stateStorage := NewStateStorage()
+	p, err := NewAggregateFrameProcessor(stateStorage, AggregateFrameProcessorConfig{
+		WindowSeconds: 10,
+		Functions:     []string{AggregateFunctionAvg, AggregateFunctionMax, AggregateFunctionCount},
+	})
+	require.NoError(t, err)
+
+	vars := Vars{OrgID: 1, Channel: "stream/sensors/temperature"}
+	start := time.Unix(1000, 0)
+	frame := data.NewFrame("temperature",
+		data.NewField("time", nil, []time.Time{start, start.Add(time.Second), start.Add(2 * time.Second)}),
+		data.NewField("room", nil, []string{"a", "b", "a"}),
+		data.NewField("value", nil, []float64{1, 10, 3}),
+	)
+
+	// Window is not closed yet.
+	result, err := p.ProcessFrame(context.Background(), vars, frame)
+	require.NoError(t, err)
+	require.Nil(t, result)
+
+	// State survives processor recreation upon rules rebuild.
+	p, err = NewAggregateFrameProcessor(stateStorage, p.config)
+	require.NoError(t, err)
+
+	result, err = p.ProcessFrame(context.Background(), vars, data.NewFrame("temperature",
+		data.NewField("time", nil, []time.Time{start.Add(11 * time.Second)}),
+		data.NewField("room", nil, []string{"a"}),
+		data.NewField("value", nil, []float64{100}),
+	))
+	require.NoError(t, err)
+	require.NotNil(t, result)
+	require.Len(t, result.Fields, 5)
+	require.Equal(t, 2, result.Fields[0].Len())
+	require.Equal(t, time.Unix(1000, 0), result.Fields[0].At(0))
+	require.Equal(t, "value_avg", result.Fields[2].Name)
+
+	room, _ := result.Fields[1].ConcreteAt(0)
+	require.Equal(t, "a", room)
+	avg, _ := result.Fields[2].ConcreteAt(0)
+	require.Equal(t, 2.0, avg)
+	max, _ := result.Fields[3].ConcreteAt(0)
+	require.Equal(t, 3.0, max)
+	count, _ := result.Fields[4].ConcreteAt(0)
+	require.Equal(t, 2.0, count)
+
+	room, _ = result.Fields[1].ConcreteAt(1)
+	require.Equal(t, "b", room)
+	avg, _ = result.Fields[2].ConcreteAt(1)
+	require.Equal(t, 10.0, avg)
+}
+
+func TestAggregateFrameProcessorConfig(t *testing.T) {
+	_, err := NewAggregateFrameProcessor(nil, AggregateFrameProcessorConfig{})
+	require.Error(t, err)
+	_, err = NewAggregateFrameProcessor(nil, AggregateFrameProcessorConfig{WindowSeconds: 1, Functions: []string{"median"}})
+	require.Error(t, err)
+}
+
+func TestAggregateFrameProcessor_FlushFrame(t *testing.T) {
+	p, err := NewAggregateFrameProcessor(NewStateStorage(), AggregateFrameProcessorConfig{
+		WindowSeconds: 10,
+	})
+	require.NoError(t, err)
+
+	vars := Vars{OrgID: 1, Channel: "stream/sensors/temperature"}
+	start := time.Unix(1000, 0)
+
+	result, err := p.FlushFrame(context.Background(), vars, start)
+	require.NoError(t, err)
+	require.Nil(t, result)
+
+	result, err = p.ProcessFrame(context.Background(), vars, data.NewFrame("temperature",
+		data.NewField("time", nil, []time.Time{start, start.Add(time.Second)}),
+		data.NewField("value", nil, []float64{1, 3}),
+	))
+	require.NoError(t, err)
+	require.Nil(t, result)
+
+	// Window is flushed only after one more window passed.
+	result, err = p.FlushFrame(context.Background(), vars, start.Add(15*time.Second))
+	require.NoError(t, err)
+	require.Nil(t, result)
+
+	result, err = p.FlushFrame(context.Background(), vars, start.Add(20*time.Second))
+	require.NoError(t, err)
+	require.NotNil(t, result)
+	require.Equal(t, "temperature", result.Name)
+	require.Equal(t, 1, result.Fields[0].Len())
+	avg, _ := result.Fields[1].ConcreteAt(0)
+	require.Equal(t, 2.0, avg)
+
+	// Late data for flushed window is dropped.
+	result, err = p.ProcessFrame(context.Background(), vars, data.NewFrame("temperature",
+		data.NewField("time", nil, []time.Time{start.Add(5 * time.Second)}),
+		data.NewField("value", nil, []float64{5}),
+	))
+	require.NoError(t, err)
+	require.Nil(t, result)
+	result, err = p.FlushFrame(context.Background(), vars, start.Add(time.Minute))
+	require.NoError(t, err)
+	require.Nil(t, result)
+}
+
+func TestMultipleFrameProcessor_FlushFrame(t *testing.T) {
+	stateStorage := NewStateStorage()
+	aggregate, err := NewAggregateFrameProcessor(stateStorage, AggregateFrameProcessorConfig{
+		WindowSeconds: 10,
+	})
+	require.NoError(t, err)
+	p := NewMultipleFrameProcessor(aggregate, NewDropFieldsFrameProcessor(DropFieldsFrameProcessorConfig{
+		FieldNames: []string{"value_avg"},
+	}))
+
+	vars := Vars{OrgID: 1, Channel: "stream/sensors/temperature"}
+	start := time.Unix(1000, 0)
+	_, err = p.ProcessFrame(context.Background(), vars, data.NewFrame("temperature",
+		data.NewField("time", nil, []time.Time{start}),
+		data.NewField("value", nil, []float64{1}),
+	))
+	require.NoError(t, err)
+
+	result, err := p.FlushFrame(context.Background(), vars, start.Add(time.Minute))
+	require.NoError(t, err)
+	require.NotNil(t, result)
+	require.Len(t, result.Fields, 1)
+	require.Equal(t, "time", result.Fields[0].Name)
+	require.Equal(t, []stateStorageKey{{orgID: 1, channel: vars.Channel}}, stateStorage.channels())
+}
@@ -2,6 +2,7 @@ package pipeline
 
 import (
 	"context"
+	"time"
 
 	"github.com/grafana/grafana-plugin-sdk-go/data"
 )
@@ -26,10 +27,20 @@ func (p *MultipleFrameProcessor) ProcessFrame(ctx context.Context, vars Vars, fr
 			logger.Error("Error processing frame", "error", err)
 			return nil, err
 		}
+		if frame == nil {
+			// Stateful processors may hold the frame back.
+			return nil, nil
+		}
 	}
 	return frame, nil
 }
 
+// FlushFrame flushes frames held back by stateful processors and passes them
+// through the processors which follow.
+func (p *MultipleFrameProcessor) FlushFrame(ctx context.Context, vars Vars, now time.Time) (*data.Frame, error) {
+	return flushFrameProcessors(ctx, p.Processors, vars, now)
+}
+
 func NewMultipleFrameProcessor(processors ...FrameProcessor) *MultipleFrameProcessor {
 	return &MultipleFrameProcessor{Processors: processors}
 }
@@ -0,0 +1,132 @@
+package pipeline
+
+import (
+	"context"
+	"encoding/json"
+	"math"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+)
+
+// RateFrameProcessor replaces values of numeric fields with per-second rate of
+// their change, useful for counters. Previous values are tracked per field and
+// per values of string fields (labels). The first value has no rate, so null is
+// returned for it. Counter resets are handled like in Prometheus.
+type RateFrameProcessor struct {
+	config       RateFrameProcessorConfig
+	stateStorage *StateStorage
+	stateKey     string
+}
+
+func NewRateFrameProcessor(stateStorage *StateStorage, config RateFrameProcessorConfig) (*RateFrameProcessor, error) {
+	if stateStorage == nil {
+		stateStorage = NewStateStorage()
+	}
+	stateKey, err := json.Marshal(config)
+	if err != nil {
+		return nil, err
+	}
+	return &RateFrameProcessor{
+		config:       config,
+		stateStorage: stateStorage,
+		stateKey:     FrameProcessorTypeRate + string(stateKey),
+	}, nil
+}
+
+const FrameProcessorTypeRate = "rate"
+
+func (p *RateFrameProcessor) Type() string {
+	return FrameProcessorTypeRate
+}
+
+type rateSample struct {
+	time  time.Time
+	value float64
+}
+
+type rateState struct {
+	mu      sync.Mutex
+	samples map[string]rateSample
+}
+
+func (p *RateFrameProcessor) ProcessFrame(_ context.Context, vars Vars, frame *data.Frame) (*data.Frame, error) {
+	state := p.stateStorage.GetOrCreate(vars.OrgID, vars.Channel, p.stateKey, func() interface{} {
+		return &rateState{samples: map[string]rateSample{}}
+	}).(*rateState)
+
+	state.mu.Lock()
+	defer state.mu.Unlock()
+
+	timeFieldIdx := -1
+	var labelFieldIdx []int
+	for i, f := range frame.Fields {
+		if f.Type().Time() && timeFieldIdx < 0 {
+			timeFieldIdx = i
+		} else if f.Type() == data.FieldTypeString || f.Type() == data.FieldTypeNullableString {
+			labelFieldIdx = append(labelFieldIdx, i)
+		}
+	}
+
+	rowLen, err := frame.RowLen()
+	if err != nil {
+		return nil, err
+	}
+
+	fields := make([]*data.Field, 0, len(frame.Fields))
+	for _, f := range frame.Fields {
+		if !f.Type().Numeric() || (len(p.config.FieldNames) > 0 && !stringInSlice(f.Name, p.config.FieldNames)) {
+			fields = append(fields, f)
+			continue
+		}
+		rateField := data.NewField(f.Name, f.Labels, make([]*float64, rowLen))
+		rateField.Config = f.Config
+		fieldKey := f.Name + f.Labels.String()
+		for row := 0; row < rowLen; row++ {
+			t := time.Now()
+			if timeFieldIdx >= 0 {
+				if v, ok := frame.Fields[timeFieldIdx].ConcreteAt(row); ok {
+					t = v.(time.Time)
+				}
+			}
+			v, err := f.FloatAt(row)
+			if err != nil {
+				return nil, err
+			}
+			if math.IsNaN(v) {
+				continue
+			}
+			key := fieldKey + "\x00" + rowLabels(frame, labelFieldIdx, row)
+			prev, ok := state.samples[key]
+			state.samples[key] = rateSample{time: t, value: v}
+			if !ok {
+				continue
+			}
+			seconds := t.Sub(prev.time).Seconds()
+			if seconds <= 0 {
+				continue
+			}
+			delta := v - prev.value
+			if delta < 0 {
+				// Counter reset.
+				delta = v
+			}
+			rate := delta / seconds
+			rateField.Set(row, &rate)
+		}
+		fields = append(fields, rateField)
+	}
+	return data.NewFrame(frame.Name, fields...), nil
+}
+
+func rowLabels(frame *data.Frame, labelFieldIdx []int, row int) string {
+	values := make([]string, 0, len(labelFieldIdx))
+	for _, i := range labelFieldIdx {
+		v, _ := frame.Fields[i].ConcreteAt(row)
+		s, _ := v.(string)
+		values = append(values, frame.Fields[i].Name+"="+s)
+	}
+	return strings.Join(values, ",")
+}
@@ -0,0 +1,44 @@
+package pipeline
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRateFrameProcessor(t *testing.T) {
+	p, err := NewRateFrameProcessor(NewStateStorage(), RateFrameProcessorConfig{FieldNames: []string{"requests"}})
+	require.NoError(t, err)
+
+	vars := Vars{OrgID: 1, Channel: "stream/app/requests"}
+	start := time.Unix(1000, 0)
+	result, err := p.ProcessFrame(context.Background(), vars, data.NewFrame("requests",
+		data.NewField("time", nil, []time.Time{start, start.Add(2 * time.Second), start.Add(4 * time.Second)}),
+		data.NewField("requests", nil, []int64{10, 20, 5}),
+		data.NewField("errors", nil, []int64{1, 2, 3}),
+	))
+	require.NoError(t, err)
+	require.Len(t, result.Fields, 3)
+	require.Equal(t, data.FieldTypeNullableFloat64, result.Fields[1].Type())
+	require.Equal(t, data.FieldTypeInt64, result.Fields[2].Type())
+
+	_, ok := result.Fields[1].ConcreteAt(0)
+	require.False(t, ok)
+	rate, _ := result.Fields[1].ConcreteAt(1)
+	require.Equal(t, 5.0, rate)
+	// Counter reset.
+	rate, _ = result.Fields[1].ConcreteAt(2)
+	require.Equal(t, 2.5, rate)
+
+	// Previous value is kept between frames.
+	result, err = p.ProcessFrame(context.Background(), vars, data.NewFrame("requests",
+		data.NewField("time", nil, []time.Time{start.Add(5 * time.Second)}),
+		data.NewField("requests", nil, []int64{8}),
+	))
+	require.NoError(t, err)
+	rate, _ = result.Fields[1].ConcreteAt(0)
+	require.Equal(t, 3.0, rate)
+}
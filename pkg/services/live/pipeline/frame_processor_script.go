@@ -0,0 +1,216 @@
+package pipeline
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+)
+
+// ScriptFrameProcessor transforms frame rows with a JavaScript expression. The
+// expression is evaluated for each row, the row is available as x object (time
+// values are in milliseconds since epoch). The expression must return a new row
+// object or null to drop the row, for example:
+//
+//	x.temperature > -100 ? Object.assign(x, {fahrenheit: x.temperature * 1.8 + 32}) : null
+//
+// Returned numbers become nullable float64 fields, except time fields of the
+// original frame which keep time type.
+type ScriptFrameProcessor struct {
+	config ScriptFrameProcessorConfig
+}
+
+func NewScriptFrameProcessor(config ScriptFrameProcessorConfig) (*ScriptFrameProcessor, error) {
+	if config.Script == "" {
+		return nil, errors.New("script required")
+	}
+	return &ScriptFrameProcessor{config: config}, nil
+}
+
+const FrameProcessorTypeScript = "script"
+
+func (p *ScriptFrameProcessor) Type() string {
+	return FrameProcessorTypeScript
+}
+
+type scriptColumn struct {
+	name     string
+	isTime   bool
+	field    *data.Field
+	original *data.Field
+}
+
+func (p *ScriptFrameProcessor) ProcessFrame(_ context.Context, _ Vars, frame *data.Frame) (*data.Frame, error) {
+	rowLen, err := frame.RowLen()
+	if err != nil {
+		return nil, err
+	}
+
+	var columns []*scriptColumn
+	columnIndex := map[string]int{}
+	for _, f := range frame.Fields {
+		columnIndex[f.Name] = len(columns)
+		columns = append(columns, &scriptColumn{name: f.Name, isTime: f.Type().Time(), original: f})
+	}
+
+	var r *gojaRuntime
+	outRows := 0
+	for row := 0; row < rowLen; row++ {
+		payload, err := rowToJSON(frame, row)
+		if err != nil {
+			return nil, err
+		}
+		if r == nil {
+			r, err = getRuntime(payload)
+		} else {
+			err = r.init(payload)
+		}
+		if err != nil {
+			return nil, err
+		}
+		obj, err := r.getObject(p.config.Script)
+		if err != nil {
+			return nil, err
+		}
+		if obj == nil {
+			continue
+		}
+		for _, key := range obj.Keys() {
+			if _, ok := columnIndex[key]; !ok {
+				columnIndex[key] = len(columns)
+				columns = append(columns, &scriptColumn{name: key})
+			}
+		}
+		for _, c := range columns {
+			var value interface{}
+			if v := obj.Get(c.name); v != nil {
+				value = v.Export()
+			}
+			if err := c.append(value, outRows); err != nil {
+				return nil, err
+			}
+		}
+		outRows++
+	}
+
+	if outRows == 0 {
+		return nil, nil
+	}
+	fields := make([]*data.Field, 0, len(columns))
+	for _, c := range columns {
+		if c.field == nil {
+			// Column never returned by the script.
+			continue
+		}
+		fields = append(fields, c.field)
+	}
+	return data.NewFrame(frame.Name, fields...), nil
+}
+
+// append adds value to the column, the column field is created upon the first
+// non-null value, previous rows are filled with nulls.
+func (c *scriptColumn) append(value interface{}, rowIdx int) error {
+	if c.field == nil {
+		if value == nil {
+			return nil
+		}
+		switch value.(type) {
+		case int64, float64:
+			if c.isTime {
+				c.field = data.NewField(c.name, nil, make([]*time.Time, rowIdx))
+			} else {
+				c.field = data.NewField(c.name, nil, make([]*float64, rowIdx))
+			}
+		case string:
+			c.field = data.NewField(c.name, nil, make([]*string, rowIdx))
+		case bool:
+			c.field = data.NewField(c.name, nil, make([]*bool, rowIdx))
+		default:
+			return fmt.Errorf("unsupported value type of %s: %T", c.name, value)
+		}
+		if c.original != nil {
+			c.field.Labels = c.original.Labels
+			c.field.Config = c.original.Config
+		}
+	}
+
+	if value == nil {
+		c.field.Append(nil)
+		return nil
+	}
+	switch c.field.Type() {
+	case data.FieldTypeNullableTime:
+		ms, ok := toFloat64(value)
+		if !ok {
+			return fmt.Errorf("unexpected value type of time field %s: %T", c.name, value)
+		}
+		t := time.UnixMilli(int64(ms))
+		c.field.Append(&t)
+	case data.FieldTypeNullableFloat64:
+		v, ok := toFloat64(value)
+		if !ok {
+			return fmt.Errorf("unexpected value type of number field %s: %T", c.name, value)
+		}
+		c.field.Append(&v)
+	case data.FieldTypeNullableString:
+		v, ok := value.(string)
+		if !ok {
+			return fmt.Errorf("unexpected value type of string field %s: %T", c.name, value)
+		}
+		c.field.Append(&v)
+	case data.FieldTypeNullableBool:
+		v, ok := value.(bool)
+		if !ok {
+			return fmt.Errorf("unexpected value type of boolean field %s: %T", c.name, value)
+		}
+		c.field.Append(&v)
+	}
+	return nil
+}
+
+func toFloat64(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case int64:
+		return float64(v), true
+	case float64:
+		return v, true
+	default:
+		return 0, false
+	}
+}
+
+// rowToJSON encodes frame row as JSON object. Time values are encoded as
+// milliseconds since epoch, NaN and Inf values as null.
+func rowToJSON(frame *data.Frame, row int) ([]byte, error) {
+	values := make(map[string]interface{}, len(frame.Fields))
+	for _, f := range frame.Fields {
+		v, ok := f.ConcreteAt(row)
+		if !ok {
+			values[f.Name] = nil
+			continue
+		}
+		switch val := v.(type) {
+		case time.Time:
+			values[f.Name] = val.UnixMilli()
+		case float64:
+			if math.IsNaN(val) || math.IsInf(val, 0) {
+				values[f.Name] = nil
+			} else {
+				values[f.Name] = val
+			}
+		case float32:
+			if math.IsNaN(float64(val)) || math.IsInf(float64(val), 0) {
+				values[f.Name] = nil
+			} else {
+				values[f.Name] = val
+			}
+		default:
+			values[f.Name] = val
+		}
+	}
+	return json.Marshal(values)
+}
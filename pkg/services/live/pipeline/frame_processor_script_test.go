@@ -0,0 +1,60 @@
+package pipeline
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScriptFrameProcessor(t *testing.T) {
+	p, err := NewScriptFrameProcessor(ScriptFrameProcessorConfig{
+		Script: "x.celsius > -100 ? Object.assign(x, {fahrenheit: x.celsius * 1.8 + 32, hot: x.celsius > 30}) : null",
+	})
+	require.NoError(t, err)
+
+	frame := data.NewFrame("temperature",
+		data.NewField("time", nil, []time.Time{time.UnixMilli(1000), time.UnixMilli(2000), time.UnixMilli(3000)}),
+		data.NewField("celsius", nil, []float64{10, -200, 40}),
+	)
+	result, err := p.ProcessFrame(context.Background(), Vars{}, frame)
+	require.NoError(t, err)
+	require.Len(t, result.Fields, 4)
+	require.Equal(t, 2, result.Fields[0].Len())
+
+	ts, _ := result.Fields[0].ConcreteAt(1)
+	require.Equal(t, time.UnixMilli(3000), ts)
+	require.Equal(t, "fahrenheit", result.Fields[2].Name)
+	f, _ := result.Fields[2].ConcreteAt(0)
+	require.Equal(t, 50.0, f)
+	hot, _ := result.Fields[3].ConcreteAt(1)
+	require.Equal(t, true, hot)
+
+	// All rows dropped.
+	result, err = p.ProcessFrame(context.Background(), Vars{}, data.NewFrame("temperature",
+		data.NewField("celsius", nil, []float64{-200}),
+	))
+	require.NoError(t, err)
+	require.Nil(t, result)
+
+	p, err = NewScriptFrameProcessor(ScriptFrameProcessorConfig{Script: "1"})
+	require.NoError(t, err)
+	_, err = p.ProcessFrame(context.Background(), Vars{}, frame)
+	require.Error(t, err)
+}
+
+func TestUpdateFieldsFrameProcessor(t *testing.T) {
+	p := NewUpdateFieldsFrameProcessor(UpdateFieldsFrameProcessorConfig{
+		Fields: []FieldUpdate{{FieldName: "temp", Name: "temperature", Unit: "celsius"}},
+	})
+	frame := data.NewFrame("sensor", data.NewField("temp", nil, []float64{1}))
+	result, err := p.ProcessFrame(context.Background(), Vars{}, frame)
+	require.NoError(t, err)
+	require.Equal(t, "temperature", result.Fields[0].Name)
+	require.Equal(t, "celsius", result.Fields[0].Config.Unit)
+	// Original frame not modified.
+	require.Equal(t, "temp", frame.Fields[0].Name)
+	require.Nil(t, frame.Fields[0].Config)
+}
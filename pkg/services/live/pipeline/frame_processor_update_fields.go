@@ -0,0 +1,51 @@
+package pipeline
+
+import (
+	"context"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+)
+
+// UpdateFieldsFrameProcessor can rename fields and set their units.
+type UpdateFieldsFrameProcessor struct {
+	config UpdateFieldsFrameProcessorConfig
+}
+
+func NewUpdateFieldsFrameProcessor(config UpdateFieldsFrameProcessorConfig) *UpdateFieldsFrameProcessor {
+	return &UpdateFieldsFrameProcessor{config: config}
+}
+
+const FrameProcessorTypeUpdateFields = "updateFields"
+
+func (p *UpdateFieldsFrameProcessor) Type() string {
+	return FrameProcessorTypeUpdateFields
+}
+
+func (p *UpdateFieldsFrameProcessor) ProcessFrame(_ context.Context, _ Vars, frame *data.Frame) (*data.Frame, error) {
+	fields := make([]*data.Field, 0, len(frame.Fields))
+	for _, field := range frame.Fields {
+		f := field
+		for _, update := range p.config.Fields {
+			if update.FieldName != field.Name {
+				continue
+			}
+			// Frame may be shared by several rules, so fields are copied before update.
+			f = &data.Field{}
+			*f = *field
+			if update.Name != "" {
+				f.Name = update.Name
+			}
+			if update.Unit != "" {
+				config := data.FieldConfig{}
+				if field.Config != nil {
+					config = *field.Config
+				}
+				config.Unit = update.Unit
+				f.Config = &config
+			}
+			break
+		}
+		fields = append(fields, f)
+	}
+	return data.NewFrame(frame.Name, fields...), nil
+}
@@ -55,6 +55,23 @@ func (r *gojaRuntime) runString(script string) (goja.Value, error) {
 	return r.vm.RunString(script)
 }
 
+// getObject returns an object returned by the script, nil is returned if script
+// returned null or undefined.
+func (r *gojaRuntime) getObject(script string) (*goja.Object, error) {
+	v, err := r.runString(script)
+	if err != nil {
+		return nil, err
+	}
+	if goja.IsNull(v) || goja.IsUndefined(v) {
+		return nil, nil
+	}
+	obj, ok := v.(*goja.Object)
+	if !ok || obj.ClassName() != "Object" {
+		return nil, fmt.Errorf("unexpected return value: %v (%T), script: %s", v.Export(), v.Export(), script)
+	}
+	return obj, nil
+}
+
 func (r *gojaRuntime) getBool(script string) (bool, error) {
 	v, err := r.runString(script)
 	if err != nil {
@@ -2,6 +2,7 @@ package pipeline
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/grafana/grafana/pkg/services/live/pipeline/pattern"
 	"github.com/grafana/grafana/pkg/services/live/pipeline/tree"
@@ -38,6 +39,16 @@ func (r ChannelRule) Valid() (bool, string) {
 			}
 		}
 	}
+	if len(r.Settings.Sources) > 0 {
+		if r.Settings.Converter == nil {
+			return false, "sources require converter"
+		}
+		for _, source := range r.Settings.Sources {
+			if !typeRegistered(source.Type, SourcesRegistry) {
+				return false, fmt.Sprintf("unknown source type: %s", source.Type)
+			}
+		}
+	}
 	return true, ""
 }
 
@@ -100,6 +111,9 @@ func (r WriteConfig) Valid() (bool, string) {
 	if r.UID == "" {
 		return false, "uid required"
 	}
+	if r.UID == "." || r.UID == ".." || strings.ContainsAny(r.UID, `/\`) {
+		return false, "uid must not be . or .. and must not contain path separators"
+	}
 	if r.Settings.Endpoint == "" {
 		return false, "endpoint required"
 	}
@@ -146,14 +160,35 @@ func checkRulesValid(orgID int64, rules []ChannelRule) (ok bool, reason string)
 	return ok, reason
 }
 
+func ChannelRuleToDto(r ChannelRule) ChannelRuleDto {
+	secureFields := make(map[string]bool, len(r.SecureSettings))
+	for k := range r.SecureSettings {
+		secureFields[k] = true
+	}
+	return ChannelRuleDto{
+		Pattern:      r.Pattern,
+		Settings:     r.Settings,
+		SecureFields: secureFields,
+	}
+}
+
+type ChannelRuleDto struct {
+	Pattern      string              `json:"pattern"`
+	Settings     ChannelRuleSettings `json:"settings"`
+	SecureFields map[string]bool     `json:"secureFields"`
+}
+
 type ChannelRuleCreateCmd struct {
-	Pattern  string              `json:"pattern"`
-	Settings ChannelRuleSettings `json:"settings"`
+	Pattern        string              `json:"pattern"`
+	Settings       ChannelRuleSettings `json:"settings"`
+	SecureSettings map[string]string   `json:"secureSettings"`
 }
 
+// ChannelRuleUpdateCmd keeps the secure settings of the rule which are not set.
 type ChannelRuleUpdateCmd struct {
-	Pattern  string              `json:"pattern"`
-	Settings ChannelRuleSettings `json:"settings"`
+	Pattern        string              `json:"pattern"`
+	Settings       ChannelRuleSettings `json:"settings"`
+	SecureSettings map[string]string   `json:"secureSettings"`
 }
 
 type ChannelRuleDeleteCmd struct {
@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"time"
 
 	"github.com/grafana/grafana/pkg/models"
 
@@ -97,6 +98,12 @@ type FrameProcessor interface {
 	ProcessFrame(ctx context.Context, vars Vars, frame *data.Frame) (*data.Frame, error)
 }
 
+// FrameFlusher may be implemented by FrameProcessor which holds frames back,
+// so held frames are passed further even if no new data arrives to a channel.
+type FrameFlusher interface {
+	FlushFrame(ctx context.Context, vars Vars, now time.Time) (*data.Frame, error)
+}
+
 // FrameOutputter outputs data.Frame to a custom destination. Or simply
 // do nothing if some conditions not met.
 type FrameOutputter interface {
@@ -110,6 +117,20 @@ type Subscriber interface {
 	Subscribe(ctx context.Context, vars Vars, data []byte) (models.SubscribeReply, backend.SubscribeStreamStatus, error)
 }
 
+// SourceMessageHandler handles a message consumed by Source.
+type SourceMessageHandler func(ctx context.Context, data []byte) error
+
+// Source consumes messages from an external system (Kafka, MQTT, NATS). Each message
+// is processed by a rule as if it was published into the rule channel.
+type Source interface {
+	Type() string
+	// Key identifies source configuration. Sources with unchanged key keep running
+	// when channel rules are rebuilt.
+	Key() string
+	// Run consumes messages until context is canceled or an error occurs.
+	Run(ctx context.Context, handler SourceMessageHandler) error
+}
+
 // PublishAuthChecker checks whether current user can publish to a channel.
 type PublishAuthChecker interface {
 	CanPublish(ctx context.Context, u *models.SignedInUser) (bool, error)
@@ -161,6 +182,9 @@ type LiveChannelRule struct {
 	// can optionally return a slice of ChannelFrame to pass the control to a rule defined
 	// by ChannelFrame.Channel.
 	FrameOutputters []FrameOutputter
+	// Sources if set consume messages from external systems and pass them to the rule
+	// as input data. Rules with sources must have a pattern without parameters.
+	Sources []Source
 }
 
 // Label ...
@@ -417,6 +441,10 @@ func (p *Pipeline) processFrame(ctx context.Context, orgID int64, channelID stri
 		}
 	}
 
+	return p.outputFrame(ctx, rule, vars, frame)
+}
+
+func (p *Pipeline) outputFrame(ctx context.Context, rule *LiveChannelRule, vars Vars, frame *data.Frame) ([]*ChannelFrame, error) {
 	if len(rule.FrameOutputters) > 0 {
 		var resultingFrames []*ChannelFrame
 		for _, out := range rule.FrameOutputters {
@@ -433,6 +461,80 @@ func (p *Pipeline) processFrame(ctx context.Context, orgID int64, channelID stri
 	return nil, nil
 }
 
+// frameFlushInterval is how often frames held back by stateful frame processors are
+// checked for flush.
+const frameFlushInterval = time.Second
+
+// RunFlush periodically passes further frames held back by stateful frame
+// processors of channels with state in stateStorage until ctx is done.
+func (p *Pipeline) RunFlush(ctx context.Context, stateStorage *StateStorage) error {
+	ticker := time.NewTicker(frameFlushInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case now := <-ticker.C:
+			for _, ch := range stateStorage.channels() {
+				if err := p.flushChannel(ctx, ch.orgID, ch.channel, now); err != nil {
+					logger.Error("Error flushing channel frames", "error", err, "orgId", ch.orgID, "channel", ch.channel)
+				}
+			}
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+func (p *Pipeline) flushChannel(ctx context.Context, orgID int64, channelID string, now time.Time) error {
+	rule, ruleOk, err := p.ruleGetter.Get(orgID, channelID)
+	if err != nil {
+		return err
+	}
+	if !ruleOk {
+		return nil
+	}
+
+	ch, err := live.ParseChannel(channelID)
+	if err != nil {
+		return err
+	}
+
+	vars := Vars{
+		OrgID:     orgID,
+		Channel:   channelID,
+		Scope:     ch.Scope,
+		Namespace: ch.Namespace,
+		Path:      ch.Path,
+	}
+
+	frame, err := flushFrameProcessors(ctx, rule.FrameProcessors, vars, now)
+	if err != nil || frame == nil {
+		return err
+	}
+	frames, err := p.outputFrame(ctx, rule, vars, frame)
+	if err != nil {
+		return err
+	}
+	return p.processChannelFrames(ctx, orgID, channelID, frames, map[string]struct{}{channelID: {}})
+}
+
+// flushFrameProcessors flushes frames held back by processors implementing
+// FrameFlusher and passes flushed frames through the processors which follow.
+func flushFrameProcessors(ctx context.Context, processors []FrameProcessor, vars Vars, now time.Time) (*data.Frame, error) {
+	var frame *data.Frame
+	for _, proc := range processors {
+		var err error
+		if frame != nil {
+			frame, err = proc.ProcessFrame(ctx, vars, frame)
+		} else if flusher, ok := proc.(FrameFlusher); ok {
+			frame, err = flusher.FlushFrame(ctx, vars, now)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return frame, nil
+}
+
 func (p *Pipeline) execProcessor(ctx context.Context, proc FrameProcessor, vars Vars, frame *data.Frame) (*data.Frame, error) {
 	var span trace.Span
 	if p.tracer != nil {
@@ -0,0 +1,136 @@
+package pipeline
+
+import (
+	"sync"
+	"time"
+)
+
+// stateIdleTimeout is a time after which state of a channel which is not
+// processed anymore is removed from StateStorage.
+const stateIdleTimeout = 10 * time.Minute
+
+// StateStorage keeps state of stateful frame processors in memory, so the state
+// survives periodic channel rule rebuilds. Not usable in HA setup.
+type StateStorage struct {
+	mu          sync.Mutex
+	states      map[stateStorageKey]*stateEntry
+	lastCleanup time.Time
+}
+
+type stateStorageKey struct {
+	orgID   int64
+	channel string
+	key     string
+}
+
+type stateEntry struct {
+	state    interface{}
+	lastUsed time.Time
+}
+
+// pendingState may be implemented by processor state which holds data not
+// passed further yet. Such state is never removed as idle.
+type pendingState interface {
+	hasPending() bool
+}
+
+func NewStateStorage() *StateStorage {
+	return &StateStorage{
+		states:      map[stateStorageKey]*stateEntry{},
+		lastCleanup: time.Now(),
+	}
+}
+
+// GetOrCreate returns state stored under the key for a channel, state created with
+// newState if it does not exist yet.
+func (s *StateStorage) GetOrCreate(orgID int64, channel string, key string, newState func() interface{}) interface{} {
+	k := stateStorageKey{orgID: orgID, channel: channel, key: key}
+	now := time.Now()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if now.Sub(s.lastCleanup) > stateIdleTimeout {
+		s.cleanupLocked(now)
+	}
+	entry, ok := s.states[k]
+	if !ok {
+		entry = &stateEntry{state: newState()}
+		s.states[k] = entry
+	}
+	entry.lastUsed = now
+	return entry.state
+}
+
+// get returns state stored under the key for a channel without marking it as used.
+func (s *StateStorage) get(orgID int64, channel string, key string) (interface{}, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	entry, ok := s.states[stateStorageKey{orgID: orgID, channel: channel, key: key}]
+	if !ok {
+		return nil, false
+	}
+	return entry.state, true
+}
+
+// Retain removes state of organization stored under keys which are not in
+// the provided list. Called after channel rules of organization rebuilt to
+// drop state of removed or changed processors.
+func (s *StateStorage) Retain(orgID int64, keys []string) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for k := range s.states {
+		if k.orgID == orgID && !stringInSlice(k.key, keys) {
+			delete(s.states, k)
+		}
+	}
+}
+
+// channels returns channels with state which may be flushed.
+func (s *StateStorage) channels() []stateStorageKey {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	seen := map[stateStorageKey]struct{}{}
+	var channels []stateStorageKey
+	for k, entry := range s.states {
+		if _, ok := entry.state.(pendingState); !ok {
+			continue
+		}
+		ch := stateStorageKey{orgID: k.orgID, channel: k.channel}
+		if _, ok := seen[ch]; ok {
+			continue
+		}
+		seen[ch] = struct{}{}
+		channels = append(channels, ch)
+	}
+	return channels
+}
+
+func (s *StateStorage) cleanupLocked(now time.Time) {
+	s.lastCleanup = now
+	for k, entry := range s.states {
+		if now.Sub(entry.lastUsed) <= stateIdleTimeout {
+			continue
+		}
+		if p, ok := entry.state.(pendingState); ok && p.hasPending() {
+			continue
+		}
+		delete(s.states, k)
+	}
+}
+
+func stateKeys(processors []FrameProcessor) []string {
+	var keys []string
+	for _, proc := range processors {
+		switch p := proc.(type) {
+		case *AggregateFrameProcessor:
+			keys = append(keys, p.stateKey)
+		case *RateFrameProcessor:
+			keys = append(keys, p.stateKey)
+		case *MultipleFrameProcessor:
+			keys = append(keys, stateKeys(p.Processors)...)
+		}
+	}
+	return keys
+}
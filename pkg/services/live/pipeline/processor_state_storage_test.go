@@ -0,0 +1,54 @@
+package pipeline
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestStateStorage_IdleCleanup(t *testing.T) {
+	s := NewStateStorage()
+	newState := func() interface{} { return &rateState{} }
+	s.GetOrCreate(1, "stream/test/idle", "rate", newState)
+	s.GetOrCreate(1, "stream/test/pending", "aggregate", func() interface{} {
+		return &aggregateState{window: newAggregateWindow(time.Now())}
+	})
+	active := s.GetOrCreate(1, "stream/test/active", "rate", newState)
+
+	now := time.Now()
+	for k, entry := range s.states {
+		if k.channel != "stream/test/active" {
+			entry.lastUsed = now.Add(-2 * stateIdleTimeout)
+		}
+	}
+	s.lastCleanup = now.Add(-2 * stateIdleTimeout)
+
+	require.Same(t, active, s.GetOrCreate(1, "stream/test/active", "rate", newState))
+	require.Len(t, s.states, 2)
+	_, ok := s.get(1, "stream/test/idle", "rate")
+	require.False(t, ok)
+	// State with not flushed data is kept.
+	_, ok = s.get(1, "stream/test/pending", "aggregate")
+	require.True(t, ok)
+}
+
+func TestStateStorage_Retain(t *testing.T) {
+	s := NewStateStorage()
+	newState := func() interface{} { return &rateState{} }
+	s.GetOrCreate(1, "stream/test/a", "rate1", newState)
+	s.GetOrCreate(1, "stream/test/b", "rate2", newState)
+	s.GetOrCreate(2, "stream/test/a", "rate2", newState)
+
+	s.Retain(1, []string{"rate1"})
+	_, ok := s.get(1, "stream/test/a", "rate1")
+	require.True(t, ok)
+	_, ok = s.get(1, "stream/test/b", "rate2")
+	require.False(t, ok)
+	// Other organizations are not affected.
+	_, ok = s.get(2, "stream/test/a", "rate2")
+	require.True(t, ok)
+
+	var nilStorage *StateStorage
+	require.NotPanics(t, func() { nilStorage.Retain(1, nil) })
+}
@@ -83,6 +83,33 @@ var FrameProcessorsRegistry = []EntityInfo{
 		Description: "list the fields that should be removed",
 		Example:     DropFieldsFrameProcessorConfig{},
 	},
+	{
+		Type:        FrameProcessorTypeAggregate,
+		Description: "aggregate numeric fields over tumbling time windows per label set",
+		Example: AggregateFrameProcessorConfig{
+			WindowSeconds: 10,
+			Functions:     []string{AggregateFunctionAvg, AggregateFunctionMax},
+		},
+	},
+	{
+		Type:        FrameProcessorTypeRate,
+		Description: "replace numeric field values with per-second rate",
+		Example:     RateFrameProcessorConfig{},
+	},
+	{
+		Type:        FrameProcessorTypeUpdateFields,
+		Description: "rename fields and set field units",
+		Example: UpdateFieldsFrameProcessorConfig{
+			Fields: []FieldUpdate{{FieldName: "temp", Name: "temperature", Unit: "celsius"}},
+		},
+	},
+	{
+		Type:        FrameProcessorTypeScript,
+		Description: "transform each row with a JavaScript expression",
+		Example: ScriptFrameProcessorConfig{
+			Script: "Object.assign(x, {fahrenheit: x.celsius * 1.8 + 32})",
+		},
+	},
 }
 
 var DataOutputsRegistry = []EntityInfo{
@@ -99,3 +126,30 @@ var DataOutputsRegistry = []EntityInfo{
 		Description: "output data to Loki as logs",
 	},
 }
+
+var SourcesRegistry = []EntityInfo{
+	{
+		Type:        SourceTypeKafka,
+		Description: "consume messages from a Kafka topic",
+		Example: KafkaSourceConfig{
+			Brokers: []string{"localhost:9092"},
+			Topic:   "metrics",
+		},
+	},
+	{
+		Type:        SourceTypeMQTT,
+		Description: "consume messages from an MQTT topic",
+		Example: MQTTSourceConfig{
+			Broker: "tcp://localhost:1883",
+			Topic:  "sensors/#",
+		},
+	},
+	{
+		Type:        SourceTypeNATS,
+		Description: "consume messages from a NATS subject",
+		Example: NATSSourceConfig{
+			URL:     "nats://localhost:4222",
+			Subject: "metrics.>",
+		},
+	},
+}
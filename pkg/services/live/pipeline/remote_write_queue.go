@@ -0,0 +1,558 @@
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/grafana/grafana/pkg/services/live/remotewrite"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/prometheus/prompb"
+)
+
+const (
+	// remoteWriteMaxBatchSamples is a number of buffered samples which makes
+	// queue write a segment before flushInterval passes.
+	remoteWriteMaxBatchSamples = 10000
+	remoteWriteMinBackoff      = time.Second
+	remoteWriteMaxBackoff      = time.Minute
+	remoteWriteSegmentSuffix   = ".rw"
+	remoteWriteTmpSuffix       = ".tmp"
+)
+
+const (
+	remoteWriteDropReasonSize     = "max_size"
+	remoteWriteDropReasonAge      = "max_age"
+	remoteWriteDropReasonRejected = "rejected"
+	remoteWriteDropReasonCorrupt  = "corrupt"
+)
+
+var remoteWriteDropReasons = []string{
+	remoteWriteDropReasonSize,
+	remoteWriteDropReasonAge,
+	remoteWriteDropReasonRejected,
+	remoteWriteDropReasonCorrupt,
+}
+
+var (
+	remoteWriteQueueBytes = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "grafana",
+			Subsystem: "live",
+			Name:      "remote_write_queue_bytes",
+			Help:      "Size of data waiting in Live remote write on-disk queue",
+		},
+		[]string{"org_id", "uid"},
+	)
+	remoteWriteQueueSegments = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "grafana",
+			Subsystem: "live",
+			Name:      "remote_write_queue_segments",
+			Help:      "Number of batches waiting in Live remote write on-disk queue",
+		},
+		[]string{"org_id", "uid"},
+	)
+	remoteWriteSendFailures = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "grafana",
+			Subsystem: "live",
+			Name:      "remote_write_send_failures_total",
+			Help:      "A counter for failed Live remote write requests",
+		},
+		[]string{"org_id", "uid"},
+	)
+	remoteWriteSegmentsSent = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "grafana",
+			Subsystem: "live",
+			Name:      "remote_write_batches_sent_total",
+			Help:      "A counter for batches sent by Live remote write",
+		},
+		[]string{"org_id", "uid"},
+	)
+	remoteWriteSegmentsDropped = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "grafana",
+			Subsystem: "live",
+			Name:      "remote_write_batches_dropped_total",
+			Help:      "A counter for batches dropped by Live remote write without sending",
+		},
+		[]string{"org_id", "uid", "reason"},
+	)
+)
+
+// RemoteWriteQueues keeps on-disk queues of remote write outputs. Queues are
+// shared by all outputs using the same write config, so they survive rebuilding
+// of channel rules.
+type RemoteWriteQueues struct {
+	dir     string
+	maxSize int64
+	maxAge  time.Duration
+
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	mu     sync.Mutex
+	queues map[string]*RemoteWriteQueue
+}
+
+// NewRemoteWriteQueues creates RemoteWriteQueues keeping data in dir. Each
+// queue is limited by maxSize bytes, batches older than maxAge are dropped.
+// Zero maxAge means no age limit.
+func NewRemoteWriteQueues(dir string, maxSize int64, maxAge time.Duration) *RemoteWriteQueues {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &RemoteWriteQueues{
+		dir:     dir,
+		maxSize: maxSize,
+		maxAge:  maxAge,
+		ctx:     ctx,
+		cancel:  cancel,
+		queues:  map[string]*RemoteWriteQueue{},
+	}
+}
+
+// Get returns a queue for a write config creating it if needed. Endpoint
+// and basic auth of existing queue are updated since write config could
+// change since queue creation.
+func (q *RemoteWriteQueues) Get(orgID int64, uid string, sampleMilliseconds int64, endpoint string, basicAuth *BasicAuth) (*RemoteWriteQueue, error) {
+	configDir, err := q.configDir(orgID, uid)
+	if err != nil {
+		return nil, err
+	}
+	dir := filepath.Join(configDir, strconv.FormatInt(sampleMilliseconds, 10))
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if queue, ok := q.queues[dir]; ok {
+		queue.setTarget(endpoint, basicAuth)
+		return queue, nil
+	}
+	queue, err := newRemoteWriteQueue(remoteWriteQueueConfig{
+		Dir:                dir,
+		OrgID:              orgID,
+		UID:                uid,
+		SampleMilliseconds: sampleMilliseconds,
+		MaxSize:            q.maxSize,
+		MaxAge:             q.maxAge,
+	})
+	if err != nil {
+		return nil, err
+	}
+	queue.setTarget(endpoint, basicAuth)
+	ctx, cancel := context.WithCancel(q.ctx)
+	queue.stop = cancel
+	go func() {
+		defer close(queue.stopped)
+		queue.run(ctx)
+	}()
+	q.queues[dir] = queue
+	return queue, nil
+}
+
+// configDir returns a directory keeping queues of a write config, queues
+// with different sampling are kept in its subdirectories.
+func (q *RemoteWriteQueues) configDir(orgID int64, uid string) (string, error) {
+	name := url.PathEscape(uid)
+	if name == "" || name == "." || name == ".." {
+		return "", fmt.Errorf("invalid write config uid: %q", uid)
+	}
+	return filepath.Join(q.dir, strconv.FormatInt(orgID, 10), name), nil
+}
+
+// Remove stops queues of a deleted write config and removes their data.
+func (q *RemoteWriteQueues) Remove(orgID int64, uid string) error {
+	configDir, err := q.configDir(orgID, uid)
+	if err != nil {
+		return err
+	}
+
+	var removed []*RemoteWriteQueue
+	q.mu.Lock()
+	for dir, queue := range q.queues {
+		if queue.config.OrgID == orgID && queue.config.UID == uid {
+			removed = append(removed, queue)
+			delete(q.queues, dir)
+		}
+	}
+	q.mu.Unlock()
+
+	for _, queue := range removed {
+		queue.stop()
+		<-queue.stopped
+	}
+	orgLabel := strconv.FormatInt(orgID, 10)
+	remoteWriteQueueBytes.DeleteLabelValues(orgLabel, uid)
+	remoteWriteQueueSegments.DeleteLabelValues(orgLabel, uid)
+	remoteWriteSendFailures.DeleteLabelValues(orgLabel, uid)
+	remoteWriteSegmentsSent.DeleteLabelValues(orgLabel, uid)
+	for _, reason := range remoteWriteDropReasons {
+		remoteWriteSegmentsDropped.DeleteLabelValues(orgLabel, uid, reason)
+	}
+	if err := os.RemoveAll(configDir); err != nil {
+		return fmt.Errorf("error removing remote write queue directory: %w", err)
+	}
+	return nil
+}
+
+// Close stops sending and writes buffered data of all queues to disk, so it
+// will be sent after restart.
+func (q *RemoteWriteQueues) Close() {
+	q.cancel()
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for _, queue := range q.queues {
+		if err := queue.Flush(); err != nil {
+			logger.Error("Error writing remote write batch", "error", err, "dir", queue.config.Dir)
+		}
+	}
+}
+
+type remoteWriteQueueConfig struct {
+	Dir                string
+	OrgID              int64
+	UID                string
+	SampleMilliseconds int64
+	MaxSize            int64
+	MaxAge             time.Duration
+}
+
+type remoteWriteSegment struct {
+	seq     uint64
+	size    int64
+	created time.Time
+}
+
+// RemoteWriteQueue buffers time series in memory, periodically writes them
+// to disk as remote write requests and sends those requests in order. When
+// endpoint is not available requests are retried with exponential backoff.
+// When queue exceeds maximum size or age the oldest requests are dropped.
+type RemoteWriteQueue struct {
+	config     remoteWriteQueueConfig
+	orgLabel   string
+	now        func() time.Time
+	httpClient *http.Client
+	notify     chan struct{}
+	// stop and stopped are set for queues run by RemoteWriteQueues.
+	stop    context.CancelFunc
+	stopped chan struct{}
+	// flushMu keeps segments written in sequence order.
+	flushMu sync.Mutex
+
+	mu         sync.Mutex
+	endpoint   string
+	basicAuth  *BasicAuth
+	buffer     []prompb.TimeSeries
+	numSamples int
+	segments   []remoteWriteSegment
+	size       int64
+	nextSeq    uint64
+}
+
+func newRemoteWriteQueue(config remoteWriteQueueConfig) (*RemoteWriteQueue, error) {
+	if err := os.MkdirAll(config.Dir, 0750); err != nil {
+		return nil, fmt.Errorf("error creating remote write queue directory: %w", err)
+	}
+	q := &RemoteWriteQueue{
+		config:     config,
+		orgLabel:   strconv.FormatInt(config.OrgID, 10),
+		now:        time.Now,
+		httpClient: &http.Client{Timeout: 10 * time.Second},
+		notify:     make(chan struct{}, 1),
+		stopped:    make(chan struct{}),
+	}
+	if err := q.load(); err != nil {
+		return nil, err
+	}
+	return q, nil
+}
+
+// load restores segments left from previous run and removes segments which
+// were not completely written.
+func (q *RemoteWriteQueue) load() error {
+	entries, err := ioutil.ReadDir(q.config.Dir)
+	if err != nil {
+		return fmt.Errorf("error reading remote write queue directory: %w", err)
+	}
+	for _, e := range entries {
+		if e.IsDir() {
+			continue
+		}
+		if strings.HasSuffix(e.Name(), remoteWriteSegmentSuffix+remoteWriteTmpSuffix) {
+			if err := os.Remove(filepath.Join(q.config.Dir, e.Name())); err != nil {
+				logger.Error("Error removing incomplete remote write batch", "error", err, "dir", q.config.Dir)
+			}
+			continue
+		}
+		if !strings.HasSuffix(e.Name(), remoteWriteSegmentSuffix) {
+			continue
+		}
+		seq, err := strconv.ParseUint(strings.TrimSuffix(e.Name(), remoteWriteSegmentSuffix), 10, 64)
+		if err != nil {
+			continue
+		}
+		q.segments = append(q.segments, remoteWriteSegment{seq: seq, size: e.Size(), created: e.ModTime()})
+		q.size += e.Size()
+		if seq >= q.nextSeq {
+			q.nextSeq = seq + 1
+		}
+	}
+	sort.Slice(q.segments, func(i, j int) bool {
+		return q.segments[i].seq < q.segments[j].seq
+	})
+	if len(q.segments) > 0 {
+		logger.Info("Restored remote write queue", "dir", q.config.Dir, "numBatches", len(q.segments), "size", q.size)
+	}
+	q.updateMetricsLocked()
+	return nil
+}
+
+func (q *RemoteWriteQueue) setTarget(endpoint string, basicAuth *BasicAuth) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.endpoint = endpoint
+	q.basicAuth = basicAuth
+}
+
+func (q *RemoteWriteQueue) segmentPath(seq uint64) string {
+	return filepath.Join(q.config.Dir, fmt.Sprintf("%020d%s", seq, remoteWriteSegmentSuffix))
+}
+
+// Append adds time series to a batch which will be written to disk on next
+// flush.
+func (q *RemoteWriteQueue) Append(timeSeries []prompb.TimeSeries) {
+	q.mu.Lock()
+	q.buffer = append(q.buffer, timeSeries...)
+	for _, ts := range timeSeries {
+		q.numSamples += len(ts.Samples)
+	}
+	full := q.numSamples >= remoteWriteMaxBatchSamples
+	q.mu.Unlock()
+	if full {
+		if err := q.Flush(); err != nil {
+			logger.Error("Error writing remote write batch", "error", err, "dir", q.config.Dir)
+		}
+	}
+}
+
+// Flush writes buffered time series to disk as a single remote write request.
+func (q *RemoteWriteQueue) Flush() error {
+	q.flushMu.Lock()
+	defer q.flushMu.Unlock()
+
+	q.mu.Lock()
+	if len(q.buffer) == 0 {
+		q.mu.Unlock()
+		return nil
+	}
+	timeSeries := q.buffer
+	q.buffer = nil
+	q.numSamples = 0
+	seq := q.nextSeq
+	q.nextSeq++
+	q.mu.Unlock()
+
+	if q.config.SampleMilliseconds > 0 {
+		timeSeries = sampleTimeSeries(timeSeries, q.config.SampleMilliseconds)
+	}
+	remoteWriteData, err := remotewrite.TimeSeriesToBytes(timeSeries)
+	if err != nil {
+		return fmt.Errorf("error converting time series to bytes: %v", err)
+	}
+	path := q.segmentPath(seq)
+	tmpPath := path + remoteWriteTmpSuffix
+	if err := ioutil.WriteFile(tmpPath, remoteWriteData, 0640); err != nil {
+		return fmt.Errorf("error writing remote write batch: %w", err)
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		return fmt.Errorf("error writing remote write batch: %w", err)
+	}
+
+	q.mu.Lock()
+	q.segments = append(q.segments, remoteWriteSegment{seq: seq, size: int64(len(remoteWriteData)), created: q.now()})
+	q.size += int64(len(remoteWriteData))
+	q.enforceLimitsLocked()
+	q.updateMetricsLocked()
+	q.mu.Unlock()
+
+	select {
+	case q.notify <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+// enforceLimitsLocked drops the oldest segments exceeding size and age limits.
+// Last segment is always kept so that a single large batch is not lost.
+func (q *RemoteWriteQueue) enforceLimitsLocked() {
+	now := q.now()
+	for len(q.segments) > 1 {
+		oldest := q.segments[0]
+		var reason string
+		switch {
+		case q.config.MaxSize > 0 && q.size > q.config.MaxSize:
+			reason = remoteWriteDropReasonSize
+		case q.config.MaxAge > 0 && now.Sub(oldest.created) > q.config.MaxAge:
+			reason = remoteWriteDropReasonAge
+		default:
+			return
+		}
+		logger.Warn("Dropping remote write batch", "reason", reason, "dir", q.config.Dir, "size", oldest.size)
+		q.removeOldestLocked()
+		remoteWriteSegmentsDropped.WithLabelValues(q.orgLabel, q.config.UID, reason).Inc()
+	}
+}
+
+func (q *RemoteWriteQueue) removeOldestLocked() {
+	oldest := q.segments[0]
+	if err := os.Remove(q.segmentPath(oldest.seq)); err != nil && !os.IsNotExist(err) {
+		logger.Error("Error removing remote write batch", "error", err, "dir", q.config.Dir)
+	}
+	q.segments = q.segments[1:]
+	q.size -= oldest.size
+}
+
+func (q *RemoteWriteQueue) updateMetricsLocked() {
+	remoteWriteQueueBytes.WithLabelValues(q.orgLabel, q.config.UID).Set(float64(q.size))
+	remoteWriteQueueSegments.WithLabelValues(q.orgLabel, q.config.UID).Set(float64(len(q.segments)))
+}
+
+// Len returns a number of batches waiting to be sent.
+func (q *RemoteWriteQueue) Len() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return len(q.segments)
+}
+
+func (q *RemoteWriteQueue) run(ctx context.Context) {
+	flushTicker := time.NewTicker(flushInterval)
+	defer flushTicker.Stop()
+
+	var retryTimer *time.Timer
+	var retryC <-chan time.Time
+	backoff := remoteWriteMinBackoff
+
+	for {
+		select {
+		case <-ctx.Done():
+			if retryTimer != nil {
+				retryTimer.Stop()
+			}
+			return
+		case <-flushTicker.C:
+			if err := q.Flush(); err != nil {
+				logger.Error("Error writing remote write batch", "error", err, "dir", q.config.Dir)
+			}
+			if retryC != nil {
+				// Waiting for a retry, newly written batch will be sent after it.
+				continue
+			}
+		case <-q.notify:
+			if retryC != nil {
+				continue
+			}
+		case <-retryC:
+			retryC = nil
+		}
+
+		err := q.sendAll(ctx)
+		if err == nil {
+			backoff = remoteWriteMinBackoff
+			continue
+		}
+		logger.Error("Error sending to remote write, will retry", "error", err, "endpoint", q.currentEndpoint(), "retryIn", backoff)
+		retryTimer = time.NewTimer(backoff)
+		retryC = retryTimer.C
+		backoff *= 2
+		if backoff > remoteWriteMaxBackoff {
+			backoff = remoteWriteMaxBackoff
+		}
+	}
+}
+
+func (q *RemoteWriteQueue) currentEndpoint() string {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.endpoint
+}
+
+// sendAll sends segments in order until queue is empty or a retryable error
+// happens.
+func (q *RemoteWriteQueue) sendAll(ctx context.Context) error {
+	for {
+		q.mu.Lock()
+		q.enforceLimitsLocked()
+		q.updateMetricsLocked()
+		if len(q.segments) == 0 {
+			q.mu.Unlock()
+			return nil
+		}
+		segment := q.segments[0]
+		endpoint, basicAuth := q.endpoint, q.basicAuth
+		q.mu.Unlock()
+
+		body, err := ioutil.ReadFile(q.segmentPath(segment.seq))
+		if err != nil {
+			logger.Error("Error reading remote write batch, dropping", "error", err, "dir", q.config.Dir)
+			q.completeSegment(segment.seq, remoteWriteDropReasonCorrupt)
+			continue
+		}
+		err = sendRemoteWrite(ctx, q.httpClient, endpoint, basicAuth, body)
+		if err != nil {
+			remoteWriteSendFailures.WithLabelValues(q.orgLabel, q.config.UID).Inc()
+			if isRetryableRemoteWriteError(err) {
+				return err
+			}
+			logger.Error("Remote write batch rejected, dropping", "error", err, "endpoint", endpoint)
+			q.completeSegment(segment.seq, remoteWriteDropReasonRejected)
+			continue
+		}
+		q.completeSegment(segment.seq, "")
+	}
+}
+
+// completeSegment removes segment after it was sent or dropped with a reason.
+func (q *RemoteWriteQueue) completeSegment(seq uint64, dropReason string) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	// Segment could be already dropped by limits while it was being sent.
+	if len(q.segments) == 0 || q.segments[0].seq != seq {
+		return
+	}
+	q.removeOldestLocked()
+	q.updateMetricsLocked()
+	if dropReason != "" {
+		remoteWriteSegmentsDropped.WithLabelValues(q.orgLabel, q.config.UID, dropReason).Inc()
+	} else {
+		remoteWriteSegmentsSent.WithLabelValues(q.orgLabel, q.config.UID).Inc()
+	}
+}
+
+type remoteWriteStatusError struct {
+	StatusCode int
+}
+
+func (e remoteWriteStatusError) Error() string {
+	return fmt.Sprintf("unexpected response code from remote write endpoint: %d", e.StatusCode)
+}
+
+// isRetryableRemoteWriteError follows Prometheus remote write: server errors,
+// 429 and network errors are retried, other client errors are not.
+func isRetryableRemoteWriteError(err error) bool {
+	var statusErr remoteWriteStatusError
+	if errors.As(err, &statusErr) {
+		return statusErr.StatusCode >= 500 || statusErr.StatusCode == http.StatusTooManyRequests
+	}
+	return true
+}
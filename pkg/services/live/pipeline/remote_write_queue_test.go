@@ -0,0 +1,233 @@
+package pipeline
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang/snappy"
+	"github.com/prometheus/prometheus/prompb"
+	"github.com/stretchr/testify/require"
+)
+
+type testRemoteWriteServer struct {
+	mu       sync.Mutex
+	status   int
+	requests []prompb.WriteRequest
+}
+
+func (s *testRemoteWriteServer) setStatus(status int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.status = status
+}
+
+func (s *testRemoteWriteServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.status != http.StatusOK {
+		w.WriteHeader(s.status)
+		return
+	}
+	body, _ := ioutil.ReadAll(r.Body)
+	decoded, err := snappy.Decode(nil, body)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	var req prompb.WriteRequest
+	if err := req.Unmarshal(decoded); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	s.requests = append(s.requests, req)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func testTimeSeries(name string, ts int64) []prompb.TimeSeries {
+	return []prompb.TimeSeries{{
+		Labels:  []prompb.Label{{Name: "__name__", Value: name}},
+		Samples: []prompb.Sample{{Timestamp: ts, Value: 1}},
+	}}
+}
+
+func newTestRemoteWriteQueue(t *testing.T, dir string, maxSize int64, maxAge time.Duration, endpoint string) *RemoteWriteQueue {
+	t.Helper()
+	q, err := newRemoteWriteQueue(remoteWriteQueueConfig{
+		Dir:     dir,
+		OrgID:   1,
+		UID:     "test",
+		MaxSize: maxSize,
+		MaxAge:  maxAge,
+	})
+	require.NoError(t, err)
+	q.setTarget(endpoint, nil)
+	return q
+}
+
+func TestRemoteWriteQueue_RetryUntilAvailable(t *testing.T) {
+	srv := &testRemoteWriteServer{status: http.StatusServiceUnavailable}
+	ts := httptest.NewServer(srv)
+	defer ts.Close()
+
+	q := newTestRemoteWriteQueue(t, t.TempDir(), 0, 0, ts.URL)
+	q.Append(testTimeSeries("test1", 1))
+	require.NoError(t, q.Flush())
+	q.Append(testTimeSeries("test2", 2))
+	require.NoError(t, q.Flush())
+	require.Equal(t, 2, q.Len())
+
+	require.Error(t, q.sendAll(context.Background()))
+	require.Equal(t, 2, q.Len())
+
+	srv.setStatus(http.StatusOK)
+	require.NoError(t, q.sendAll(context.Background()))
+	require.Equal(t, 0, q.Len())
+	require.Len(t, srv.requests, 2)
+	require.Equal(t, "test1", srv.requests[0].Timeseries[0].Labels[0].Value)
+	require.Equal(t, "test2", srv.requests[1].Timeseries[0].Labels[0].Value)
+}
+
+func TestRemoteWriteQueue_DropRejected(t *testing.T) {
+	srv := &testRemoteWriteServer{status: http.StatusBadRequest}
+	ts := httptest.NewServer(srv)
+	defer ts.Close()
+
+	q := newTestRemoteWriteQueue(t, t.TempDir(), 0, 0, ts.URL)
+	q.Append(testTimeSeries("test1", 1))
+	require.NoError(t, q.Flush())
+
+	require.NoError(t, q.sendAll(context.Background()))
+	require.Equal(t, 0, q.Len())
+}
+
+func TestRemoteWriteQueue_DropOldest(t *testing.T) {
+	q := newTestRemoteWriteQueue(t, t.TempDir(), 1, 0, "")
+	for i := 0; i < 3; i++ {
+		q.Append(testTimeSeries("test", int64(i)))
+		require.NoError(t, q.Flush())
+	}
+	// The newest batch is kept even if it exceeds maximum size alone.
+	require.Equal(t, 1, q.Len())
+	require.Equal(t, uint64(2), q.segments[0].seq)
+}
+
+func TestRemoteWriteQueue_DropExpired(t *testing.T) {
+	now := time.Now()
+	q := newTestRemoteWriteQueue(t, t.TempDir(), 0, time.Hour, "")
+	q.now = func() time.Time { return now }
+
+	q.Append(testTimeSeries("test1", 1))
+	require.NoError(t, q.Flush())
+	now = now.Add(2 * time.Hour)
+	q.Append(testTimeSeries("test2", 2))
+	require.NoError(t, q.Flush())
+
+	require.Equal(t, 1, q.Len())
+	require.Equal(t, uint64(1), q.segments[0].seq)
+}
+
+func TestRemoteWriteQueue_Restore(t *testing.T) {
+	srv := &testRemoteWriteServer{status: http.StatusOK}
+	ts := httptest.NewServer(srv)
+	defer ts.Close()
+
+	dir := t.TempDir()
+	q := newTestRemoteWriteQueue(t, dir, 0, 0, ts.URL)
+	q.Append(testTimeSeries("test1", 1))
+	require.NoError(t, q.Flush())
+	q.Append(testTimeSeries("test2", 2))
+	require.NoError(t, q.Flush())
+
+	tmpPath := q.segmentPath(2) + remoteWriteTmpSuffix
+	require.NoError(t, ioutil.WriteFile(tmpPath, []byte("partial"), 0640))
+
+	restored := newTestRemoteWriteQueue(t, dir, 0, 0, ts.URL)
+	require.Equal(t, 2, restored.Len())
+	require.NoFileExists(t, tmpPath)
+	require.Equal(t, uint64(2), restored.nextSeq)
+
+	require.NoError(t, restored.sendAll(context.Background()))
+	require.Len(t, srv.requests, 2)
+	require.Equal(t, "test1", srv.requests[0].Timeseries[0].Labels[0].Value)
+}
+
+func TestRemoteWriteQueues_Get(t *testing.T) {
+	queues := NewRemoteWriteQueues(t.TempDir(), 0, 0)
+	defer queues.Close()
+
+	q1, err := queues.Get(1, "test", 0, "http://a", nil)
+	require.NoError(t, err)
+	q2, err := queues.Get(1, "test", 0, "http://b", nil)
+	require.NoError(t, err)
+	require.Same(t, q1, q2)
+	require.Equal(t, "http://b", q1.currentEndpoint())
+
+	q3, err := queues.Get(2, "test", 0, "http://a", nil)
+	require.NoError(t, err)
+	require.NotSame(t, q1, q3)
+
+	// sampled queue of a config doesn't share a directory with another config
+	sampled, err := queues.Get(1, "abc", 1000, "http://a", nil)
+	require.NoError(t, err)
+	other, err := queues.Get(1, "abc_1000", 0, "http://a", nil)
+	require.NoError(t, err)
+	require.NotEqual(t, sampled.config.Dir, other.config.Dir)
+
+	for _, uid := range []string{"", ".", ".."} {
+		_, err = queues.Get(1, uid, 0, "http://a", nil)
+		require.Error(t, err, uid)
+	}
+}
+
+func TestRemoteWriteQueues_Remove(t *testing.T) {
+	dir := t.TempDir()
+	queues := NewRemoteWriteQueues(dir, 0, 0)
+	defer queues.Close()
+
+	q1, err := queues.Get(1, "test", 0, "http://a", nil)
+	require.NoError(t, err)
+	q1.Append(testTimeSeries("test", 1))
+	require.NoError(t, q1.Flush())
+	q2, err := queues.Get(1, "test", 1000, "http://a", nil)
+	require.NoError(t, err)
+	q3, err := queues.Get(1, "other", 0, "http://a", nil)
+	require.NoError(t, err)
+
+	remoteWriteSegmentsSent.WithLabelValues("1", "test").Inc()
+	remoteWriteSendFailures.WithLabelValues("1", "test").Inc()
+	remoteWriteSegmentsDropped.WithLabelValues("1", "test", remoteWriteDropReasonRejected).Inc()
+	remoteWriteSegmentsSent.WithLabelValues("1", "other").Inc()
+
+	require.NoError(t, queues.Remove(1, "test"))
+	// Counters of the removed config are deleted, counters of other configs are kept.
+	require.False(t, remoteWriteSegmentsSent.DeleteLabelValues("1", "test"))
+	require.False(t, remoteWriteSendFailures.DeleteLabelValues("1", "test"))
+	require.False(t, remoteWriteSegmentsDropped.DeleteLabelValues("1", "test", remoteWriteDropReasonRejected))
+	require.True(t, remoteWriteSegmentsSent.DeleteLabelValues("1", "other"))
+	require.NoDirExists(t, q1.config.Dir)
+	require.NoDirExists(t, q2.config.Dir)
+	require.DirExists(t, q3.config.Dir)
+
+	q4, err := queues.Get(1, "test", 0, "http://a", nil)
+	require.NoError(t, err)
+	require.NotSame(t, q1, q4)
+	require.Equal(t, 0, q4.Len())
+
+	require.Error(t, queues.Remove(1, ".."))
+	require.DirExists(t, q3.config.Dir)
+}
+
+func TestWriteConfigValidUID(t *testing.T) {
+	settings := WriteSettings{Endpoint: "http://a"}
+	ok, _ := WriteConfig{UID: "test", Settings: settings}.Valid()
+	require.True(t, ok)
+	for _, uid := range []string{"", ".", "..", "a/b", `a\b`} {
+		ok, _ := WriteConfig{UID: uid, Settings: settings}.Valid()
+		require.False(t, ok, uid)
+	}
+}
@@ -2,7 +2,9 @@ package pipeline
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"strings"
 
 	"github.com/centrifugal/centrifuge"
 	"github.com/grafana/grafana/pkg/services/live/managedstream"
@@ -13,9 +15,33 @@ type StorageRuleBuilder struct {
 	Node                 *centrifuge.Node
 	ManagedStream        *managedstream.Runner
 	FrameStorage         *FrameStorage
+	StateStorage         *StateStorage
 	Storage              Storage
 	ChannelHandlerGetter ChannelHandlerGetter
 	SecretsService       secrets.Service
+	// DryRunRecorder when set makes builder replace all side-effecting
+	// outputters with ones which only record outputs.
+	DryRunRecorder *DryRunRecorder
+	// RemoteWriteQueues when set makes remote write outputs store data on
+	// disk before sending.
+	RemoteWriteQueues *RemoteWriteQueues
+	// HA is set when Live HA engine is enabled. Every Grafana instance runs
+	// sources of channel rules then, so sources are required to share messages
+	// between instances instead of consuming each message on every instance.
+	HA bool
+}
+
+// instanceID returns a short ID of the Grafana instance, used to tell apart
+// connections of HA instances to external systems.
+func (f *StorageRuleBuilder) instanceID() string {
+	if f.Node == nil {
+		return ""
+	}
+	id := f.Node.ID()
+	if len(id) > 8 {
+		id = id[:8]
+	}
+	return id
 }
 
 func (f *StorageRuleBuilder) extractSubscriber(config *SubscriberConfig) (Subscriber, error) {
@@ -47,6 +73,49 @@ func (f *StorageRuleBuilder) extractSubscriber(config *SubscriberConfig) (Subscr
 	}
 }
 
+func (f *StorageRuleBuilder) extractSource(config *SourceConfig, secureSettings map[string][]byte) (Source, error) {
+	if config == nil {
+		return nil, nil
+	}
+	missingConfiguration := fmt.Errorf("missing configuration for %s", config.Type)
+	switch config.Type {
+	case SourceTypeKafka:
+		if config.KafkaSourceConfig == nil {
+			return nil, missingConfiguration
+		}
+		if f.HA && config.KafkaSourceConfig.GroupID == "" {
+			return nil, errors.New("kafka source requires groupId when Live HA engine is enabled")
+		}
+		return NewKafkaSource(*config.KafkaSourceConfig)
+	case SourceTypeMQTT:
+		if config.MQTTSourceConfig == nil {
+			return nil, missingConfiguration
+		}
+		if f.HA && config.MQTTSourceConfig.SharedGroup == "" {
+			return nil, errors.New("mqtt source requires sharedGroup when Live HA engine is enabled")
+		}
+		var password string
+		if len(secureSettings[MQTTPasswordSecureSetting]) > 0 {
+			passwordBytes, err := f.SecretsService.Decrypt(context.Background(), secureSettings[MQTTPasswordSecureSetting])
+			if err != nil {
+				return nil, fmt.Errorf("%s can't be decrypted: %w", MQTTPasswordSecureSetting, err)
+			}
+			password = string(passwordBytes)
+		}
+		return NewMQTTSource(*config.MQTTSourceConfig, password, f.instanceID())
+	case SourceTypeNATS:
+		if config.NATSSourceConfig == nil {
+			return nil, missingConfiguration
+		}
+		if f.HA && config.NATSSourceConfig.Queue == "" {
+			return nil, errors.New("nats source requires queue when Live HA engine is enabled")
+		}
+		return NewNATSSource(*config.NATSSourceConfig)
+	default:
+		return nil, fmt.Errorf("unknown source type: %s", config.Type)
+	}
+}
+
 func (f *StorageRuleBuilder) extractConverter(config *ConverterConfig) (Converter, error) {
 	if config == nil {
 		return nil, nil
@@ -108,6 +177,26 @@ func (f *StorageRuleBuilder) extractFrameProcessor(config *FrameProcessorConfig)
 			processors = append(processors, proc)
 		}
 		return NewMultipleFrameProcessor(processors...), nil
+	case FrameProcessorTypeAggregate:
+		if config.AggregateProcessorConfig == nil {
+			return nil, missingConfiguration
+		}
+		return NewAggregateFrameProcessor(f.StateStorage, *config.AggregateProcessorConfig)
+	case FrameProcessorTypeRate:
+		if config.RateProcessorConfig == nil {
+			config.RateProcessorConfig = &RateFrameProcessorConfig{}
+		}
+		return NewRateFrameProcessor(f.StateStorage, *config.RateProcessorConfig)
+	case FrameProcessorTypeUpdateFields:
+		if config.UpdateFieldsProcessorConfig == nil {
+			return nil, missingConfiguration
+		}
+		return NewUpdateFieldsFrameProcessor(*config.UpdateFieldsProcessorConfig), nil
+	case FrameProcessorTypeScript:
+		if config.ScriptProcessorConfig == nil {
+			return nil, missingConfiguration
+		}
+		return NewScriptFrameProcessor(*config.ScriptProcessorConfig)
 	default:
 		return nil, fmt.Errorf("unknown processor type: %s", config.Type)
 	}
@@ -194,8 +283,14 @@ func (f *StorageRuleBuilder) extractFrameOutputter(config *FrameOutputterConfig,
 		}
 		return NewMultipleFrameOutput(outputters...), nil
 	case FrameOutputTypeManagedStream:
+		if f.DryRunRecorder != nil {
+			return NewDryRunFrameOutput(f.DryRunRecorder, config.Type), nil
+		}
 		return NewManagedStreamFrameOutput(f.ManagedStream), nil
 	case FrameOutputTypeLocalSubscribers:
+		if f.DryRunRecorder != nil {
+			return NewDryRunFrameOutput(f.DryRunRecorder, config.Type), nil
+		}
 		return NewLocalSubscribersFrameOutput(f.Node), nil
 	case FrameOutputTypeConditional:
 		if config.ConditionalOutputConfig == nil {
@@ -227,6 +322,27 @@ func (f *StorageRuleBuilder) extractFrameOutputter(config *FrameOutputterConfig,
 		if err != nil {
 			return nil, fmt.Errorf("error getting password: %w", err)
 		}
+		if f.DryRunRecorder != nil {
+			return NewDryRunFrameOutput(f.DryRunRecorder, config.Type), nil
+		}
+		if f.RemoteWriteQueues != nil {
+			orgID := writeConfig.OrgId
+			if orgID == 0 {
+				// Write configs created before organization support belong to main org.
+				orgID = 1
+			}
+			queue, err := f.RemoteWriteQueues.Get(
+				orgID,
+				writeConfig.UID,
+				config.RemoteWriteOutputConfig.SampleMilliseconds,
+				writeConfig.Settings.Endpoint,
+				basicAuth,
+			)
+			if err != nil {
+				return nil, fmt.Errorf("error getting remote write queue: %w", err)
+			}
+			return NewQueuedRemoteWriteFrameOutput(queue), nil
+		}
 		return NewRemoteWriteFrameOutput(
 			writeConfig.Settings.Endpoint,
 			basicAuth,
@@ -244,6 +360,9 @@ func (f *StorageRuleBuilder) extractFrameOutputter(config *FrameOutputterConfig,
 		if err != nil {
 			return nil, fmt.Errorf("error getting password: %w", err)
 		}
+		if f.DryRunRecorder != nil {
+			return NewDryRunFrameOutput(f.DryRunRecorder, config.Type), nil
+		}
 		return NewLokiFrameOutput(
 			writeConfig.Settings.Endpoint,
 			basicAuth,
@@ -281,13 +400,22 @@ func (f *StorageRuleBuilder) extractDataOutputter(config *DataOutputterConfig, w
 		if err != nil {
 			return nil, fmt.Errorf("error constructing basicAuth: %w", err)
 		}
+		if f.DryRunRecorder != nil {
+			return NewDryRunDataOutput(f.DryRunRecorder, config.Type), nil
+		}
 		return NewLokiDataOutput(
 			writeConfig.Settings.Endpoint,
 			basicAuth,
 		), nil
 	case DataOutputTypeBuiltin:
+		if f.DryRunRecorder != nil {
+			return NewDryRunDataOutput(f.DryRunRecorder, config.Type), nil
+		}
 		return NewBuiltinDataOutput(f.ChannelHandlerGetter), nil
 	case DataOutputTypeLocalSubscribers:
+		if f.DryRunRecorder != nil {
+			return NewDryRunDataOutput(f.DryRunRecorder, config.Type), nil
+		}
 		return NewLocalSubscribersDataOutput(f.Node), nil
 	default:
 		return nil, fmt.Errorf("unknown data output type: %s", config.Type)
@@ -315,6 +443,7 @@ func (f *StorageRuleBuilder) BuildRules(ctx context.Context, orgID int64) ([]*Li
 	}
 
 	var rules []*LiveChannelRule
+	var processorStateKeys []string
 
 	for _, ruleConfig := range channelRules {
 		rule := &LiveChannelRule{
@@ -346,6 +475,7 @@ func (f *StorageRuleBuilder) BuildRules(ctx context.Context, orgID int64) ([]*Li
 			processors = append(processors, proc)
 		}
 		rule.FrameProcessors = processors
+		processorStateKeys = append(processorStateKeys, stateKeys(processors)...)
 
 		var dataOutputters []DataOutputter
 		for _, outConfig := range ruleConfig.Settings.DataOutputters {
@@ -377,8 +507,24 @@ func (f *StorageRuleBuilder) BuildRules(ctx context.Context, orgID int64) ([]*Li
 		}
 		rule.Subscribers = subscribers
 
+		if len(ruleConfig.Settings.Sources) > 0 && strings.ContainsAny(rule.Pattern, ":*") {
+			return nil, fmt.Errorf("error building sources for %s: pattern with parameters not supported", rule.Pattern)
+		}
+		var sources []Source
+		for _, sourceConfig := range ruleConfig.Settings.Sources {
+			source, err := f.extractSource(sourceConfig, ruleConfig.SecureSettings)
+			if err != nil {
+				return nil, fmt.Errorf("error building source for %s: %w", rule.Pattern, err)
+			}
+			sources = append(sources, source)
+		}
+		rule.Sources = sources
+
 		rules = append(rules, rule)
 	}
 
+	// Drop state of processors removed from rules or changed.
+	f.StateStorage.Retain(orgID, processorStateKeys)
+
 	return rules, nil
 }
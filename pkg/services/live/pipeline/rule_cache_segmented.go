@@ -9,11 +9,18 @@ import (
 	"github.com/grafana/grafana/pkg/services/live/pipeline/tree"
 )
 
+// RulesListener is notified each time channel rules of an organization are built.
+type RulesListener interface {
+	RulesUpdated(orgID int64, rules []*LiveChannelRule)
+}
+
 // CacheSegmentedTree provides a fast access to channel rule configuration.
 type CacheSegmentedTree struct {
 	radixMu     sync.RWMutex
 	radix       map[int64]*tree.Node
 	ruleBuilder RuleBuilder
+	listenersMu sync.RWMutex
+	listeners   []RulesListener
 }
 
 func NewCacheSegmentedTree(storage RuleBuilder) *CacheSegmentedTree {
@@ -25,6 +32,24 @@ func NewCacheSegmentedTree(storage RuleBuilder) *CacheSegmentedTree {
 	return s
 }
 
+// NewStaticCacheSegmentedTree creates CacheSegmentedTree which builds rules of
+// an organization only once and never updates them. Useful for short-lived
+// pipelines, for example for dry runs.
+func NewStaticCacheSegmentedTree(storage RuleBuilder) *CacheSegmentedTree {
+	return &CacheSegmentedTree{
+		radix:       map[int64]*tree.Node{},
+		ruleBuilder: storage,
+	}
+}
+
+// AddListener registers RulesListener. Listener is notified about rules built
+// after registration.
+func (s *CacheSegmentedTree) AddListener(l RulesListener) {
+	s.listenersMu.Lock()
+	defer s.listenersMu.Unlock()
+	s.listeners = append(s.listeners, l)
+}
+
 func (s *CacheSegmentedTree) updatePeriodically() {
 	for {
 		var orgIDs []int64
@@ -51,11 +76,17 @@ func (s *CacheSegmentedTree) fillOrg(orgID int64) error {
 		return err
 	}
 	s.radixMu.Lock()
-	defer s.radixMu.Unlock()
 	s.radix[orgID] = tree.New()
 	for _, ch := range channels {
 		s.radix[orgID].AddRoute("/"+ch.Pattern, ch)
 	}
+	s.radixMu.Unlock()
+
+	s.listenersMu.RLock()
+	defer s.listenersMu.RUnlock()
+	for _, l := range s.listeners {
+		l.RulesUpdated(orgID, channels)
+	}
 	return nil
 }
 
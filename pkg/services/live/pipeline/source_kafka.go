@@ -0,0 +1,65 @@
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/segmentio/kafka-go"
+)
+
+// KafkaSource consumes messages from a Kafka topic.
+type KafkaSource struct {
+	config KafkaSourceConfig
+}
+
+const SourceTypeKafka = "kafka"
+
+func NewKafkaSource(config KafkaSourceConfig) (*KafkaSource, error) {
+	if len(config.Brokers) == 0 {
+		return nil, errors.New("kafka source requires brokers")
+	}
+	if config.Topic == "" {
+		return nil, errors.New("kafka source requires topic")
+	}
+	return &KafkaSource{config: config}, nil
+}
+
+func (s *KafkaSource) Type() string {
+	return SourceTypeKafka
+}
+
+func (s *KafkaSource) Key() string {
+	return fmt.Sprintf("%s|%s|%s|%s", SourceTypeKafka, strings.Join(s.config.Brokers, ","), s.config.Topic, s.config.GroupID)
+}
+
+func (s *KafkaSource) Run(ctx context.Context, handler SourceMessageHandler) error {
+	readerConfig := kafka.ReaderConfig{
+		Brokers: s.config.Brokers,
+		Topic:   s.config.Topic,
+		GroupID: s.config.GroupID,
+	}
+	reader := kafka.NewReader(readerConfig)
+	defer func() { _ = reader.Close() }()
+	if s.config.GroupID == "" {
+		// Without a consumer group there is no committed offset, skip history.
+		if err := reader.SetOffset(kafka.LastOffset); err != nil {
+			return err
+		}
+	}
+
+	for {
+		// With a consumer group offset is committed automatically.
+		msg, err := reader.ReadMessage(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			return fmt.Errorf("error reading kafka message: %w", err)
+		}
+		if err := handler(ctx, msg.Value); err != nil {
+			logger.Error("Error handling kafka message", "error", err, "topic", s.config.Topic)
+		}
+	}
+}
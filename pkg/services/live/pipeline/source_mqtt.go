@@ -0,0 +1,117 @@
+package pipeline
+
+import (
+	"context"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	mqtt "github.com/eclipse/paho.mqtt.golang"
+)
+
+// MQTTSource consumes messages from an MQTT topic.
+type MQTTSource struct {
+	config     MQTTSourceConfig
+	password   string
+	instanceID string
+}
+
+const SourceTypeMQTT = "mqtt"
+
+// MQTTPasswordSecureSetting is the secure setting of a channel rule holding the password of its MQTT sources.
+const MQTTPasswordSecureSetting = "mqttPassword"
+
+const mqttTimeout = 10 * time.Second
+
+// NewMQTTSource creates a new MQTTSource, instanceID identifies the Grafana instance
+// running the source and is appended to the configured client ID.
+func NewMQTTSource(config MQTTSourceConfig, password string, instanceID string) (*MQTTSource, error) {
+	if config.Broker == "" {
+		return nil, errors.New("mqtt source requires broker")
+	}
+	if config.Topic == "" {
+		return nil, errors.New("mqtt source requires topic")
+	}
+	if strings.ContainsAny(config.SharedGroup, "/+#") {
+		return nil, fmt.Errorf("invalid mqtt shared group: %s", config.SharedGroup)
+	}
+	if config.QoS > 2 {
+		return nil, fmt.Errorf("unsupported mqtt qos: %d", config.QoS)
+	}
+	return &MQTTSource{config: config, password: password, instanceID: instanceID}, nil
+}
+
+func (s *MQTTSource) Type() string {
+	return SourceTypeMQTT
+}
+
+func (s *MQTTSource) Key() string {
+	// the source is restarted when the password changes, without keeping it in the key
+	passwordHash := sha256.Sum256([]byte(s.password))
+	return fmt.Sprintf("%s|%s|%s|%d|%s|%s|%s|%x", SourceTypeMQTT, s.config.Broker, s.config.Topic, s.config.QoS, s.config.ClientID, s.config.SharedGroup, s.config.Username, passwordHash[:8])
+}
+
+// topic returns the topic filter to subscribe to, a shared subscription
+// when the shared group is configured.
+func (s *MQTTSource) topic() string {
+	if s.config.SharedGroup == "" {
+		return s.config.Topic
+	}
+	return "$share/" + s.config.SharedGroup + "/" + s.config.Topic
+}
+
+// clientID returns the configured client ID suffixed with the instance ID. An empty
+// client ID makes the broker assign one.
+func (s *MQTTSource) clientID() string {
+	if s.config.ClientID == "" || s.instanceID == "" {
+		return s.config.ClientID
+	}
+	return s.config.ClientID + "-" + s.instanceID
+}
+
+func (s *MQTTSource) Run(ctx context.Context, handler SourceMessageHandler) error {
+	connectionLost := make(chan error, 1)
+	opts := mqtt.NewClientOptions().
+		AddBroker(s.config.Broker).
+		SetClientID(s.clientID()).
+		SetUsername(s.config.Username).
+		SetPassword(s.password).
+		SetAutoReconnect(false).
+		SetConnectionLostHandler(func(_ mqtt.Client, err error) {
+			select {
+			case connectionLost <- err:
+			default:
+			}
+		})
+
+	client := mqtt.NewClient(opts)
+	if err := waitMQTTToken(client.Connect()); err != nil {
+		return fmt.Errorf("error connecting to mqtt broker: %w", err)
+	}
+	defer client.Disconnect(250)
+
+	token := client.Subscribe(s.topic(), s.config.QoS, func(_ mqtt.Client, msg mqtt.Message) {
+		if err := handler(ctx, msg.Payload()); err != nil {
+			logger.Error("Error handling mqtt message", "error", err, "topic", msg.Topic())
+		}
+	})
+	if err := waitMQTTToken(token); err != nil {
+		return fmt.Errorf("error subscribing to mqtt topic: %w", err)
+	}
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case err := <-connectionLost:
+		return fmt.Errorf("mqtt connection lost: %w", err)
+	}
+}
+
+func waitMQTTToken(token mqtt.Token) error {
+	if !token.WaitTimeout(mqttTimeout) {
+		return errors.New("timeout")
+	}
+	return token.Error()
+}
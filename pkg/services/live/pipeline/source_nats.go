@@ -0,0 +1,67 @@
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/nats-io/nats.go"
+)
+
+// NATSSource consumes messages from a NATS subject.
+type NATSSource struct {
+	config NATSSourceConfig
+}
+
+const SourceTypeNATS = "nats"
+
+func NewNATSSource(config NATSSourceConfig) (*NATSSource, error) {
+	if config.URL == "" {
+		return nil, errors.New("nats source requires url")
+	}
+	if config.Subject == "" {
+		return nil, errors.New("nats source requires subject")
+	}
+	return &NATSSource{config: config}, nil
+}
+
+func (s *NATSSource) Type() string {
+	return SourceTypeNATS
+}
+
+func (s *NATSSource) Key() string {
+	return fmt.Sprintf("%s|%s|%s|%s", SourceTypeNATS, s.config.URL, s.config.Subject, s.config.Queue)
+}
+
+func (s *NATSSource) Run(ctx context.Context, handler SourceMessageHandler) error {
+	closed := make(chan struct{})
+	conn, err := nats.Connect(s.config.URL,
+		nats.MaxReconnects(-1),
+		nats.ClosedHandler(func(_ *nats.Conn) { close(closed) }),
+	)
+	if err != nil {
+		return fmt.Errorf("error connecting to nats: %w", err)
+	}
+	defer conn.Close()
+
+	msgHandler := func(msg *nats.Msg) {
+		if err := handler(ctx, msg.Data); err != nil {
+			logger.Error("Error handling nats message", "error", err, "subject", msg.Subject)
+		}
+	}
+	if s.config.Queue != "" {
+		_, err = conn.QueueSubscribe(s.config.Subject, s.config.Queue, msgHandler)
+	} else {
+		_, err = conn.Subscribe(s.config.Subject, msgHandler)
+	}
+	if err != nil {
+		return fmt.Errorf("error subscribing to nats subject: %w", err)
+	}
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-closed:
+		return errors.New("nats connection closed")
+	}
+}
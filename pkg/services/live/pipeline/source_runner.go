@@ -0,0 +1,127 @@
+package pipeline
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// InputProcessor processes input data published into a channel.
+type InputProcessor interface {
+	ProcessInput(ctx context.Context, orgID int64, channelID string, body []byte) (bool, error)
+}
+
+// SourceRunner keeps sources of channel rules running. It's notified about
+// rebuilt rules and starts new sources and stops removed ones.
+type SourceRunner struct {
+	mu        sync.Mutex
+	processor InputProcessor
+	ctx       context.Context
+	sources   map[int64]map[string]*runningSource
+}
+
+type runningSource struct {
+	orgID   int64
+	channel string
+	source  Source
+	cancel  context.CancelFunc
+}
+
+const (
+	sourceMinBackoff = time.Second
+	sourceMaxBackoff = 30 * time.Second
+)
+
+// NewSourceRunner creates new SourceRunner.
+func NewSourceRunner(processor InputProcessor) *SourceRunner {
+	return &SourceRunner{
+		processor: processor,
+		sources:   map[int64]map[string]*runningSource{},
+	}
+}
+
+// RulesUpdated syncs running sources with rules of an organization.
+func (r *SourceRunner) RulesUpdated(orgID int64, rules []*LiveChannelRule) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	current := r.sources[orgID]
+	updated := map[string]*runningSource{}
+	for _, rule := range rules {
+		for _, source := range rule.Sources {
+			key := rule.Pattern + "|" + source.Key()
+			if s, ok := current[key]; ok {
+				updated[key] = s
+				continue
+			}
+			s := &runningSource{orgID: orgID, channel: rule.Pattern, source: source}
+			if r.ctx != nil {
+				r.start(s)
+			}
+			updated[key] = s
+		}
+	}
+	for key, s := range current {
+		if _, ok := updated[key]; !ok && s.cancel != nil {
+			s.cancel()
+		}
+	}
+	r.sources[orgID] = updated
+}
+
+// Run starts sources and blocks until context is canceled.
+func (r *SourceRunner) Run(ctx context.Context) error {
+	r.mu.Lock()
+	r.ctx = ctx
+	for _, orgSources := range r.sources {
+		for _, s := range orgSources {
+			r.start(s)
+		}
+	}
+	r.mu.Unlock()
+
+	<-ctx.Done()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, orgSources := range r.sources {
+		for _, s := range orgSources {
+			s.cancel()
+		}
+	}
+	return ctx.Err()
+}
+
+func (r *SourceRunner) start(s *runningSource) {
+	ctx, cancel := context.WithCancel(r.ctx)
+	s.cancel = cancel
+	go r.run(ctx, s)
+}
+
+func (r *SourceRunner) run(ctx context.Context, s *runningSource) {
+	handler := func(ctx context.Context, data []byte) error {
+		_, err := r.processor.ProcessInput(ctx, s.orgID, s.channel, data)
+		return err
+	}
+	backoff := sourceMinBackoff
+	for {
+		startedAt := time.Now()
+		err := s.source.Run(ctx, handler)
+		if ctx.Err() != nil {
+			return
+		}
+		if time.Since(startedAt) > sourceMaxBackoff {
+			backoff = sourceMinBackoff
+		}
+		logger.Error("Source stopped, restarting", "error", err, "type", s.source.Type(), "orgId", s.orgID, "channel", s.channel, "backoff", backoff)
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+		if backoff > sourceMaxBackoff {
+			backoff = sourceMaxBackoff
+		}
+	}
+}
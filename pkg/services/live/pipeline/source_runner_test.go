@@ -0,0 +1,242 @@
+package pipeline
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana/pkg/services/secrets/fakes"
+	"github.com/stretchr/testify/require"
+)
+
+type testSource struct {
+	key      string
+	messages [][]byte
+	stopped  chan struct{}
+}
+
+func (s *testSource) Type() string {
+	return "test"
+}
+
+func (s *testSource) Key() string {
+	return s.key
+}
+
+func (s *testSource) Run(ctx context.Context, handler SourceMessageHandler) error {
+	for _, m := range s.messages {
+		if err := handler(ctx, m); err != nil {
+			return err
+		}
+	}
+	<-ctx.Done()
+	close(s.stopped)
+	return ctx.Err()
+}
+
+type testInputProcessor struct {
+	mu    sync.Mutex
+	input map[string][]string
+}
+
+func (p *testInputProcessor) ProcessInput(_ context.Context, _ int64, channelID string, body []byte) (bool, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.input[channelID] = append(p.input[channelID], string(body))
+	return true, nil
+}
+
+func (p *testInputProcessor) get(channelID string) []string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.input[channelID]
+}
+
+func TestSourceRunner(t *testing.T) {
+	processor := &testInputProcessor{input: map[string][]string{}}
+	runner := NewSourceRunner(processor)
+
+	source1 := &testSource{key: "1", messages: [][]byte{[]byte("a"), []byte("b")}, stopped: make(chan struct{})}
+	runner.RulesUpdated(1, []*LiveChannelRule{{OrgId: 1, Pattern: "stream/kafka/test", Sources: []Source{source1}}})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- runner.Run(ctx) }()
+
+	require.Eventually(t, func() bool {
+		return len(processor.get("stream/kafka/test")) == 2
+	}, time.Second, 10*time.Millisecond)
+	require.Equal(t, []string{"a", "b"}, processor.get("stream/kafka/test"))
+
+	// Unchanged source keeps running, removed source stopped, new source started.
+	source2 := &testSource{key: "2", messages: [][]byte{[]byte("c")}, stopped: make(chan struct{})}
+	runner.RulesUpdated(1, []*LiveChannelRule{
+		{OrgId: 1, Pattern: "stream/kafka/test", Sources: []Source{&testSource{key: "1", stopped: make(chan struct{})}}},
+		{OrgId: 1, Pattern: "stream/nats/test", Sources: []Source{source2}},
+	})
+	require.Eventually(t, func() bool {
+		return len(processor.get("stream/nats/test")) == 1
+	}, time.Second, 10*time.Millisecond)
+	require.Len(t, processor.get("stream/kafka/test"), 2)
+
+	runner.RulesUpdated(1, nil)
+	select {
+	case <-source1.stopped:
+	case <-time.After(time.Second):
+		t.Fatal("source was not stopped")
+	}
+
+	cancel()
+	require.ErrorIs(t, <-done, context.Canceled)
+	select {
+	case <-source2.stopped:
+	case <-time.After(time.Second):
+		t.Fatal("source was not stopped")
+	}
+}
+
+type testStorage struct {
+	Storage
+	rules        []ChannelRule
+	writeConfigs []WriteConfig
+}
+
+func (s *testStorage) ListChannelRules(_ context.Context, _ int64) ([]ChannelRule, error) {
+	return s.rules, nil
+}
+
+func (s *testStorage) ListWriteConfigs(_ context.Context, _ int64) ([]WriteConfig, error) {
+	return s.writeConfigs, nil
+}
+
+func TestStorageRuleBuilderSources(t *testing.T) {
+	builder := &StorageRuleBuilder{Storage: &testStorage{rules: []ChannelRule{{
+		Pattern: "stream/mqtt/sensors",
+		Settings: ChannelRuleSettings{
+			Converter: &ConverterConfig{Type: ConverterTypeJsonAuto},
+			Sources: []*SourceConfig{{
+				Type:             SourceTypeMQTT,
+				MQTTSourceConfig: &MQTTSourceConfig{Broker: "tcp://localhost:1883", Topic: "sensors/#"},
+			}},
+		},
+	}}}}
+	rules, err := builder.BuildRules(context.Background(), 1)
+	require.NoError(t, err)
+	require.Len(t, rules, 1)
+	require.Len(t, rules[0].Sources, 1)
+	require.Equal(t, SourceTypeMQTT, rules[0].Sources[0].Type())
+
+	builder.Storage = &testStorage{rules: []ChannelRule{{
+		Pattern: "stream/mqtt/:sensor",
+		Settings: ChannelRuleSettings{
+			Sources: []*SourceConfig{{
+				Type:             SourceTypeNATS,
+				NATSSourceConfig: &NATSSourceConfig{URL: "nats://localhost:4222", Subject: "sensors"},
+			}},
+		},
+	}}}
+	_, err = builder.BuildRules(context.Background(), 1)
+	require.Error(t, err)
+
+	builder.Storage = &testStorage{rules: []ChannelRule{{
+		Pattern: "stream/kafka/metrics",
+		Settings: ChannelRuleSettings{
+			Sources: []*SourceConfig{{
+				Type:              SourceTypeKafka,
+				KafkaSourceConfig: &KafkaSourceConfig{Topic: "metrics"},
+			}},
+		},
+	}}}
+	_, err = builder.BuildRules(context.Background(), 1)
+	require.Error(t, err)
+}
+
+func TestStorageRuleBuilderMQTTPassword(t *testing.T) {
+	builder := &StorageRuleBuilder{
+		SecretsService: fakes.NewFakeSecretsService(),
+		Storage: &testStorage{rules: []ChannelRule{{
+			Pattern: "stream/mqtt/sensors",
+			Settings: ChannelRuleSettings{
+				Converter: &ConverterConfig{Type: ConverterTypeJsonAuto},
+				Sources: []*SourceConfig{{
+					Type:             SourceTypeMQTT,
+					MQTTSourceConfig: &MQTTSourceConfig{Broker: "tcp://localhost:1883", Topic: "sensors/#", ClientID: "grafana"},
+				}},
+			},
+			SecureSettings: map[string][]byte{MQTTPasswordSecureSetting: []byte("secret")},
+		}}},
+	}
+	rules, err := builder.BuildRules(context.Background(), 1)
+	require.NoError(t, err)
+	require.Len(t, rules[0].Sources, 1)
+
+	source := rules[0].Sources[0].(*MQTTSource)
+	require.Equal(t, "secret", source.password)
+	require.NotContains(t, source.Key(), "secret")
+}
+
+func TestMQTTSourceClientID(t *testing.T) {
+	config := MQTTSourceConfig{Broker: "tcp://localhost:1883", Topic: "sensors/#", ClientID: "grafana"}
+
+	a, err := NewMQTTSource(config, "secret", "instance-a")
+	require.NoError(t, err)
+	b, err := NewMQTTSource(config, "secret", "instance-b")
+	require.NoError(t, err)
+	require.Equal(t, "grafana-instance-a", a.clientID())
+	require.Equal(t, "grafana-instance-b", b.clientID())
+	require.Equal(t, a.Key(), b.Key())
+
+	changed, err := NewMQTTSource(config, "changed", "instance-a")
+	require.NoError(t, err)
+	require.NotEqual(t, a.Key(), changed.Key())
+
+	config.ClientID = ""
+	broker, err := NewMQTTSource(config, "", "instance-a")
+	require.NoError(t, err)
+	require.Equal(t, "", broker.clientID())
+}
+
+func TestStorageRuleBuilderSourcesHA(t *testing.T) {
+	sources := []*SourceConfig{
+		{Type: SourceTypeKafka, KafkaSourceConfig: &KafkaSourceConfig{Brokers: []string{"localhost:9092"}, Topic: "metrics"}},
+		{Type: SourceTypeMQTT, MQTTSourceConfig: &MQTTSourceConfig{Broker: "tcp://localhost:1883", Topic: "sensors/#"}},
+		{Type: SourceTypeNATS, NATSSourceConfig: &NATSSourceConfig{URL: "nats://localhost:4222", Subject: "sensors"}},
+	}
+	build := func(ha bool, source *SourceConfig) error {
+		builder := &StorageRuleBuilder{HA: ha, Storage: &testStorage{rules: []ChannelRule{{
+			Pattern:  "stream/test/sensors",
+			Settings: ChannelRuleSettings{Sources: []*SourceConfig{source}},
+		}}}}
+		_, err := builder.BuildRules(context.Background(), 1)
+		return err
+	}
+	for _, source := range sources {
+		require.NoError(t, build(false, source), source.Type)
+		require.Error(t, build(true, source), source.Type)
+	}
+
+	sources[0].KafkaSourceConfig.GroupID = "grafana"
+	sources[1].MQTTSourceConfig.SharedGroup = "grafana"
+	sources[2].NATSSourceConfig.Queue = "grafana"
+	for _, source := range sources {
+		require.NoError(t, build(true, source), source.Type)
+	}
+}
+
+func TestMQTTSourceSharedGroup(t *testing.T) {
+	config := MQTTSourceConfig{Broker: "tcp://localhost:1883", Topic: "sensors/#"}
+	s, err := NewMQTTSource(config, "", "instance-a")
+	require.NoError(t, err)
+	require.Equal(t, "sensors/#", s.topic())
+
+	config.SharedGroup = "grafana"
+	shared, err := NewMQTTSource(config, "", "instance-a")
+	require.NoError(t, err)
+	require.Equal(t, "$share/grafana/sensors/#", shared.topic())
+	require.NotEqual(t, s.Key(), shared.Key())
+
+	config.SharedGroup = "grafana/a"
+	_, err = NewMQTTSource(config, "", "instance-a")
+	require.Error(t, err)
+}
@@ -17,6 +17,8 @@ import (
 type FileStorage struct {
 	DataPath       string
 	SecretsService secrets.Service
+	// RemoteWriteQueues, when set, get queues of deleted write configs removed.
+	RemoteWriteQueues *RemoteWriteQueues
 }
 
 func (f *FileStorage) ListWriteConfigs(_ context.Context, orgID int64) ([]WriteConfig, error) {
@@ -142,7 +144,15 @@ func (f *FileStorage) DeleteWriteConfig(_ context.Context, orgID int64, cmd Writ
 		return fmt.Errorf("write config not found")
 	}
 
-	return f.saveWriteConfigs(orgID, writeConfigs)
+	if err := f.saveWriteConfigs(orgID, writeConfigs); err != nil {
+		return err
+	}
+	if f.RemoteWriteQueues != nil {
+		if err := f.RemoteWriteQueues.Remove(orgID, cmd.UID); err != nil {
+			logger.Error("Error removing remote write queue of deleted write config", "error", err, "uid", cmd.UID)
+		}
+	}
+	return nil
 }
 
 func (f *FileStorage) ListChannelRules(_ context.Context, orgID int64) ([]ChannelRule, error) {
@@ -159,16 +169,22 @@ func (f *FileStorage) ListChannelRules(_ context.Context, orgID int64) ([]Channe
 	return rules, nil
 }
 
-func (f *FileStorage) CreateChannelRule(_ context.Context, orgID int64, cmd ChannelRuleCreateCmd) (ChannelRule, error) {
+func (f *FileStorage) CreateChannelRule(ctx context.Context, orgID int64, cmd ChannelRuleCreateCmd) (ChannelRule, error) {
 	channelRules, err := f.readRules()
 	if err != nil {
 		return ChannelRule{}, fmt.Errorf("can't read channel rules: %w", err)
 	}
 
+	secureSettings, err := f.SecretsService.EncryptJsonData(ctx, cmd.SecureSettings, secrets.WithoutScope())
+	if err != nil {
+		return ChannelRule{}, fmt.Errorf("error encrypting data: %w", err)
+	}
+
 	rule := ChannelRule{
-		OrgId:    orgID,
-		Pattern:  cmd.Pattern,
-		Settings: cmd.Settings,
+		OrgId:          orgID,
+		Pattern:        cmd.Pattern,
+		Settings:       cmd.Settings,
+		SecureSettings: secureSettings,
 	}
 
 	ok, reason := rule.Valid()
@@ -199,31 +215,44 @@ func (f *FileStorage) UpdateChannelRule(ctx context.Context, orgID int64, cmd Ch
 		return ChannelRule{}, fmt.Errorf("can't read channel rules: %w", err)
 	}
 
-	rule := ChannelRule{
-		OrgId:    orgID,
-		Pattern:  cmd.Pattern,
-		Settings: cmd.Settings,
-	}
-
-	ok, reason := rule.Valid()
-	if !ok {
-		return rule, fmt.Errorf("invalid channel rule: %s", reason)
-	}
-
 	index := -1
 
 	for i, existingRule := range channelRules.Rules {
-		if patternMatch(orgID, rule.Pattern, existingRule) {
+		if patternMatch(orgID, cmd.Pattern, existingRule) {
 			index = i
 			break
 		}
 	}
-	if index > -1 {
-		channelRules.Rules[index] = rule
-	} else {
+	if index < 0 {
 		return f.CreateChannelRule(ctx, orgID, ChannelRuleCreateCmd(cmd))
 	}
 
+	secureSettings, err := f.SecretsService.EncryptJsonData(ctx, cmd.SecureSettings, secrets.WithoutScope())
+	if err != nil {
+		return ChannelRule{}, fmt.Errorf("error encrypting data: %w", err)
+	}
+	if secureSettings == nil {
+		secureSettings = map[string][]byte{}
+	}
+	for k, v := range channelRules.Rules[index].SecureSettings {
+		if _, ok := secureSettings[k]; !ok {
+			secureSettings[k] = v
+		}
+	}
+
+	rule := ChannelRule{
+		OrgId:          orgID,
+		Pattern:        cmd.Pattern,
+		Settings:       cmd.Settings,
+		SecureSettings: secureSettings,
+	}
+
+	ok, reason := rule.Valid()
+	if !ok {
+		return rule, fmt.Errorf("invalid channel rule: %s", reason)
+	}
+
+	channelRules.Rules[index] = rule
 	err = f.saveChannelRules(orgID, channelRules)
 	return rule, err
 }
@@ -0,0 +1,57 @@
+package pipeline
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/grafana/grafana/pkg/services/secrets/fakes"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileStorageChannelRuleSecureSettings(t *testing.T) {
+	dataPath := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(dataPath, "pipeline"), 0750))
+	require.NoError(t, os.WriteFile(filepath.Join(dataPath, "pipeline", "live-channel-rules.json"), []byte(`{"rules": []}`), 0600))
+
+	storage := &FileStorage{DataPath: dataPath, SecretsService: fakes.NewFakeSecretsService()}
+	ctx := context.Background()
+	settings := ChannelRuleSettings{
+		Converter: &ConverterConfig{Type: ConverterTypeJsonAuto},
+		Sources: []*SourceConfig{{
+			Type:             SourceTypeMQTT,
+			MQTTSourceConfig: &MQTTSourceConfig{Broker: "tcp://localhost:1883", Topic: "sensors/#", Username: "grafana"},
+		}},
+	}
+
+	rule, err := storage.CreateChannelRule(ctx, 1, ChannelRuleCreateCmd{
+		Pattern:        "stream/mqtt/sensors",
+		Settings:       settings,
+		SecureSettings: map[string]string{MQTTPasswordSecureSetting: "secret"},
+	})
+	require.NoError(t, err)
+	require.Equal(t, map[string]bool{MQTTPasswordSecureSetting: true}, ChannelRuleToDto(rule).SecureFields)
+
+	contents, err := os.ReadFile(storage.ruleFilePath())
+	require.NoError(t, err)
+	require.NotContains(t, string(contents), `"password"`)
+
+	// secure settings which are not sent are kept
+	_, err = storage.UpdateChannelRule(ctx, 1, ChannelRuleUpdateCmd{Pattern: "stream/mqtt/sensors", Settings: settings})
+	require.NoError(t, err)
+	rules, err := storage.ListChannelRules(ctx, 1)
+	require.NoError(t, err)
+	require.Len(t, rules, 1)
+	require.Equal(t, []byte("secret"), rules[0].SecureSettings[MQTTPasswordSecureSetting])
+
+	_, err = storage.UpdateChannelRule(ctx, 1, ChannelRuleUpdateCmd{
+		Pattern:        "stream/mqtt/sensors",
+		Settings:       settings,
+		SecureSettings: map[string]string{MQTTPasswordSecureSetting: "changed"},
+	})
+	require.NoError(t, err)
+	rules, err = storage.ListChannelRules(ctx, 1)
+	require.NoError(t, err)
+	require.Equal(t, []byte("changed"), rules[0].SecureSettings[MQTTPasswordSecureSetting])
+}
@@ -10,6 +10,7 @@ import (
 	"github.com/grafana/grafana/pkg/models"
 	"github.com/grafana/grafana/pkg/services/live"
 	"github.com/grafana/grafana/pkg/services/live/convert"
+	"github.com/grafana/grafana/pkg/services/live/limits"
 	"github.com/grafana/grafana/pkg/services/live/pushurl"
 	"github.com/grafana/grafana/pkg/setting"
 
@@ -59,7 +60,7 @@ func (g *Gateway) Handle(ctx *models.ReqContext) {
 	urlValues := ctx.Req.URL.Query()
 	frameFormat := pushurl.FrameFormatFromValues(urlValues)
 
-	body, err := io.ReadAll(ctx.Req.Body)
+	body, err := readBody(ctx, g.GrafanaLive.Limiter)
 	if err != nil {
 		logger.Error("Error reading body", "error", err)
 		ctx.Resp.WriteHeader(http.StatusInternalServerError)
@@ -72,6 +73,12 @@ func (g *Gateway) Handle(ctx *models.ReqContext) {
 		"frameFormat", frameFormat,
 	)
 
+	if err := g.GrafanaLive.Limiter.CheckPublish(limits.ProtocolHTTP, ctx.SignedInUser.OrgId, liveDto.ScopeStream+"/"+streamID, len(body)); err != nil {
+		logger.Debug("Push rejected by limits", "streamId", streamID, "orgId", ctx.SignedInUser.OrgId, "error", err)
+		http.Error(ctx.Resp, err.Error(), limits.HTTPStatus(err))
+		return
+	}
+
 	metricFrames, err := g.converter.Convert(body, frameFormat)
 	if err != nil {
 		logger.Error("Error converting metrics", "error", err, "frameFormat", frameFormat)
@@ -99,7 +106,7 @@ func (g *Gateway) Handle(ctx *models.ReqContext) {
 func (g *Gateway) HandlePipelinePush(ctx *models.ReqContext) {
 	channelID := web.Params(ctx.Req)["*"]
 
-	body, err := io.ReadAll(ctx.Req.Body)
+	body, err := readBody(ctx, g.GrafanaLive.Limiter)
 	if err != nil {
 		logger.Error("Error reading body", "error", err)
 		ctx.Resp.WriteHeader(http.StatusInternalServerError)
@@ -111,6 +118,12 @@ func (g *Gateway) HandlePipelinePush(ctx *models.ReqContext) {
 		"bodyLength", len(body),
 	)
 
+	if err := g.GrafanaLive.Limiter.CheckPublish(limits.ProtocolHTTP, ctx.OrgId, channelID, len(body)); err != nil {
+		logger.Debug("Push rejected by limits", "channel", channelID, "orgId", ctx.OrgId, "error", err)
+		http.Error(ctx.Resp, err.Error(), limits.HTTPStatus(err))
+		return
+	}
+
 	ruleFound, err := g.GrafanaLive.Pipeline.ProcessInput(ctx.Req.Context(), ctx.OrgId, channelID, body)
 	if err != nil {
 		logger.Error("Pipeline input processing error", "error", err, "body", string(body))
@@ -127,3 +140,17 @@ func (g *Gateway) HandlePipelinePush(ctx *models.ReqContext) {
 		return
 	}
 }
+
+// readBody reads the request body. Reading stops right after MaxPublishSize of
+// the limiter, so oversized bodies are not buffered and CheckPublish rejects them.
+func readBody(ctx *models.ReqContext, limiter *limits.Limiter) ([]byte, error) {
+	maxSize := limiter.MaxPublishSize()
+	if maxSize <= 0 {
+		return io.ReadAll(ctx.Req.Body)
+	}
+	body, err := io.ReadAll(http.MaxBytesReader(ctx.Resp, ctx.Req.Body, int64(maxSize)+1))
+	if err != nil && len(body) > maxSize {
+		return body, nil
+	}
+	return body, err
+}
@@ -0,0 +1,35 @@
+package pushhttp
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/live/limits"
+	"github.com/grafana/grafana/pkg/web"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadBody(t *testing.T) {
+	read := func(body string, limiter *limits.Limiter) ([]byte, error) {
+		req := httptest.NewRequest("POST", "/api/live/push/test", strings.NewReader(body))
+		ctx := &models.ReqContext{Context: &web.Context{Req: req, Resp: web.NewResponseWriter("POST", httptest.NewRecorder())}}
+		return readBody(ctx, limiter)
+	}
+
+	body, err := read("0123456789", nil)
+	require.NoError(t, err)
+	require.Len(t, body, 10)
+
+	limiter := limits.NewLimiter(limits.Config{MaxPublishSize: 4})
+	body, err = read("0123", limiter)
+	require.NoError(t, err)
+	require.Len(t, body, 4)
+
+	// reading stops after the limit, the body is then rejected by CheckPublish
+	body, err = read(strings.Repeat("0", 1024), limiter)
+	require.NoError(t, err)
+	require.Len(t, body, 5)
+	require.ErrorIs(t, limiter.CheckPublish(limits.ProtocolHTTP, 1, "stream/test", len(body)), limits.ErrPublishTooLarge)
+}
@@ -4,6 +4,7 @@ import (
 	"net/http"
 
 	"github.com/grafana/grafana/pkg/services/live/convert"
+	"github.com/grafana/grafana/pkg/services/live/limits"
 	"github.com/grafana/grafana/pkg/services/live/livecontext"
 	"github.com/grafana/grafana/pkg/services/live/pipeline"
 
@@ -71,6 +72,11 @@ func (s *PipelinePushHandler) ServeHTTP(rw http.ResponseWriter, r *http.Request)
 			"bodyLength", len(body),
 		)
 
+		if err := s.config.Limiter.CheckPublish(limits.ProtocolWebsocket, user.OrgId, channelID, len(body)); err != nil {
+			logger.Debug("Push dropped by limits", "channel", channelID, "orgId", user.OrgId, "error", err)
+			continue
+		}
+
 		ruleFound, err := s.pipeline.ProcessInput(r.Context(), user.OrgId, channelID, body)
 		if err != nil {
 			logger.Error("Pipeline input processing error", "error", err, "body", string(body))
@@ -4,6 +4,7 @@ import (
 	"net/http"
 
 	"github.com/grafana/grafana/pkg/services/live/convert"
+	"github.com/grafana/grafana/pkg/services/live/limits"
 	"github.com/grafana/grafana/pkg/services/live/livecontext"
 	"github.com/grafana/grafana/pkg/services/live/managedstream"
 	"github.com/grafana/grafana/pkg/services/live/pushurl"
@@ -67,6 +68,11 @@ func (s *Handler) ServeHTTP(rw http.ResponseWriter, r *http.Request) {
 			break
 		}
 
+		if err := s.config.Limiter.CheckPublish(limits.ProtocolWebsocket, user.OrgId, liveDto.ScopeStream+"/"+streamID, len(body)); err != nil {
+			logger.Debug("Push dropped by limits", "streamId", streamID, "orgId", user.OrgId, "error", err)
+			continue
+		}
+
 		stream, err := s.managedStreamRunner.GetOrCreateStream(user.OrgId, liveDto.ScopeStream, streamID)
 		if err != nil {
 			logger.Error("Error getting stream", "error", err)
@@ -11,6 +11,7 @@ import (
 	"github.com/gorilla/websocket"
 
 	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/live/limits"
 )
 
 var (
@@ -38,6 +39,9 @@ type Config struct {
 	// PingInterval sets interval server will send ping messages to clients.
 	// By default DefaultWebsocketPingInterval will be used.
 	PingInterval time.Duration
+
+	// Limiter enforces publish limits, nil means no limits.
+	Limiter *limits.Limiter
 }
 
 func sameHostOriginCheck() func(r *http.Request) bool {
@@ -82,6 +86,10 @@ func setupWSConn(ctx context.Context, conn *websocket.Conn, config Config) {
 		messageSizeLimit = DefaultWebsocketMessageSizeLimit
 	}
 
+	// Publications above the limits are rejected while reading from the socket.
+	if maxPublishSize := config.Limiter.MaxPublishSize(); maxPublishSize > 0 && (messageSizeLimit <= 0 || maxPublishSize < messageSizeLimit) {
+		messageSizeLimit = maxPublishSize
+	}
 	if messageSizeLimit > 0 {
 		conn.SetReadLimit(int64(messageSizeLimit))
 	}
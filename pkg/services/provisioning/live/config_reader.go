@@ -0,0 +1,141 @@
+package live
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"gopkg.in/yaml.v2"
+)
+
+type configReader interface {
+	readConfig(path string) ([]*liveAsConfig, error)
+}
+
+type configReaderImpl struct {
+	log log.Logger
+}
+
+func newConfigReader(logger log.Logger) configReader {
+	return &configReaderImpl{log: logger}
+}
+
+func (cr *configReaderImpl) readConfig(path string) ([]*liveAsConfig, error) {
+	var configs []*liveAsConfig
+	cr.log.Debug("Looking for Live pipeline provisioning files", "path", path)
+
+	files, err := ioutil.ReadDir(path)
+	if err != nil {
+		cr.log.Error("Failed to read Live pipeline provisioning files from directory", "path", path, "error", err)
+		return configs, nil
+	}
+
+	for _, file := range files {
+		if strings.HasSuffix(file.Name(), ".yaml") || strings.HasSuffix(file.Name(), ".yml") {
+			cr.log.Debug("Parsing Live pipeline provisioning file", "path", path, "file.Name", file.Name())
+			cfg, err := cr.parseConfig(path, file)
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse %s: %w", file.Name(), err)
+			}
+			if cfg != nil {
+				configs = append(configs, cfg)
+			}
+		}
+	}
+
+	if err := validateRequiredFields(configs); err != nil {
+		return nil, err
+	}
+	setDefaultOrgIDs(configs)
+
+	return configs, nil
+}
+
+func (cr *configReaderImpl) parseConfig(path string, file os.FileInfo) (*liveAsConfig, error) {
+	filename, err := filepath.Abs(filepath.Join(path, file.Name()))
+	if err != nil {
+		return nil, err
+	}
+
+	// nolint:gosec
+	// We can ignore the gosec G304 warning on this one because `filename` comes from ps.Cfg.ProvisioningPath
+	yamlFile, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	var apiVersion *configVersion
+	err = yaml.Unmarshal(yamlFile, &apiVersion)
+	if err != nil {
+		return nil, err
+	}
+	if apiVersion == nil || apiVersion.APIVersion != 1 {
+		return nil, fmt.Errorf("unsupported apiVersion, expected 1")
+	}
+
+	var cfg *liveAsConfigV1
+	err = yaml.Unmarshal(yamlFile, &cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	return cfg.mapToLiveFromConfig()
+}
+
+func validateRequiredFields(configs []*liveAsConfig) error {
+	var errStrings []string
+	for _, cfg := range configs {
+		for i, wc := range cfg.WriteConfigs {
+			if wc.UID == "" {
+				errStrings = append(errStrings, fmt.Sprintf("write config %d in configuration doesn't contain required field uid", i+1))
+			}
+		}
+		for i, wc := range cfg.DeleteWriteConfigs {
+			if wc.UID == "" {
+				errStrings = append(errStrings, fmt.Sprintf("deleted write config %d in configuration doesn't contain required field uid", i+1))
+			}
+		}
+		for i, rule := range cfg.ChannelRules {
+			if rule.Pattern == "" {
+				errStrings = append(errStrings, fmt.Sprintf("channel rule %d in configuration doesn't contain required field pattern", i+1))
+			}
+		}
+		for i, rule := range cfg.DeleteChannelRules {
+			if rule.Pattern == "" {
+				errStrings = append(errStrings, fmt.Sprintf("deleted channel rule %d in configuration doesn't contain required field pattern", i+1))
+			}
+		}
+	}
+	if len(errStrings) != 0 {
+		return fmt.Errorf(strings.Join(errStrings, "\n"))
+	}
+	return nil
+}
+
+func setDefaultOrgIDs(configs []*liveAsConfig) {
+	for _, cfg := range configs {
+		for _, wc := range cfg.WriteConfigs {
+			if wc.OrgID < 1 {
+				wc.OrgID = 1
+			}
+		}
+		for _, wc := range cfg.DeleteWriteConfigs {
+			if wc.OrgID < 1 {
+				wc.OrgID = 1
+			}
+		}
+		for _, rule := range cfg.ChannelRules {
+			if rule.OrgID < 1 {
+				rule.OrgID = 1
+			}
+		}
+		for _, rule := range cfg.DeleteChannelRules {
+			if rule.OrgID < 1 {
+				rule.OrgID = 1
+			}
+		}
+	}
+}
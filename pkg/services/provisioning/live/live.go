@@ -0,0 +1,121 @@
+package live
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/live/pipeline"
+	"github.com/grafana/grafana/pkg/services/provisioning/utils"
+)
+
+// Provision scans a directory for provisioning config files
+// and provisions Live pipeline write configs and channel rules in those files.
+func Provision(ctx context.Context, configDirectory string, storage pipeline.Storage, orgStore utils.OrgStore) error {
+	logger := log.New("provisioning.live")
+	p := Provisioner{
+		log:         logger,
+		cfgProvider: newConfigReader(logger),
+		storage:     storage,
+		orgStore:    orgStore,
+	}
+	return p.applyChanges(ctx, configDirectory)
+}
+
+// Provisioner is responsible for provisioning Live pipeline entities based on
+// configuration read by the `configReader`.
+type Provisioner struct {
+	log         log.Logger
+	cfgProvider configReader
+	storage     pipeline.Storage
+	orgStore    utils.OrgStore
+}
+
+func (p *Provisioner) apply(ctx context.Context, cfg *liveAsConfig) error {
+	// Delete first, so that entities could be recreated in the same file.
+	for _, rule := range cfg.DeleteChannelRules {
+		if err := p.deleteChannelRule(ctx, rule); err != nil {
+			return err
+		}
+	}
+	for _, wc := range cfg.DeleteWriteConfigs {
+		if err := p.deleteWriteConfig(ctx, wc); err != nil {
+			return err
+		}
+	}
+
+	// Write configs go before channel rules since rules may reference them.
+	for _, wc := range cfg.WriteConfigs {
+		if err := utils.CheckOrgExists(ctx, p.orgStore, wc.OrgID); err != nil {
+			return err
+		}
+		p.log.Info("Updating write config from configuration", "uid", wc.UID, "orgId", wc.OrgID)
+		_, err := p.storage.UpdateWriteConfig(ctx, wc.OrgID, pipeline.WriteConfigUpdateCmd{
+			UID:            wc.UID,
+			Settings:       wc.Settings,
+			SecureSettings: wc.SecureSettings,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to provision write config %q: %w", wc.UID, err)
+		}
+	}
+
+	for _, rule := range cfg.ChannelRules {
+		if err := utils.CheckOrgExists(ctx, p.orgStore, rule.OrgID); err != nil {
+			return err
+		}
+		p.log.Info("Updating channel rule from configuration", "pattern", rule.Pattern, "orgId", rule.OrgID)
+		_, err := p.storage.UpdateChannelRule(ctx, rule.OrgID, pipeline.ChannelRuleUpdateCmd{
+			Pattern:        rule.Pattern,
+			Settings:       rule.Settings,
+			SecureSettings: rule.SecureSettings,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to provision channel rule %q: %w", rule.Pattern, err)
+		}
+	}
+
+	return nil
+}
+
+func (p *Provisioner) deleteChannelRule(ctx context.Context, rule *deleteChannelRuleFromConfig) error {
+	rules, err := p.storage.ListChannelRules(ctx, rule.OrgID)
+	if err != nil {
+		return err
+	}
+	for _, existing := range rules {
+		if existing.Pattern != rule.Pattern {
+			continue
+		}
+		p.log.Info("Deleting channel rule from configuration", "pattern", rule.Pattern, "orgId", rule.OrgID)
+		return p.storage.DeleteChannelRule(ctx, rule.OrgID, pipeline.ChannelRuleDeleteCmd{Pattern: rule.Pattern})
+	}
+	return nil
+}
+
+func (p *Provisioner) deleteWriteConfig(ctx context.Context, wc *deleteWriteConfigFromConfig) error {
+	_, ok, err := p.storage.GetWriteConfig(ctx, wc.OrgID, pipeline.WriteConfigGetCmd{UID: wc.UID})
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return nil
+	}
+	p.log.Info("Deleting write config from configuration", "uid", wc.UID, "orgId", wc.OrgID)
+	return p.storage.DeleteWriteConfig(ctx, wc.OrgID, pipeline.WriteConfigDeleteCmd{UID: wc.UID})
+}
+
+func (p *Provisioner) applyChanges(ctx context.Context, configPath string) error {
+	configs, err := p.cfgProvider.readConfig(configPath)
+	if err != nil {
+		return err
+	}
+
+	for _, cfg := range configs {
+		if err := p.apply(ctx, cfg); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
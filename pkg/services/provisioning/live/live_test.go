@@ -0,0 +1,182 @@
+package live
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/live/pipeline"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	correctProperties = "./testdata/correct-properties"
+	missingPattern    = "./testdata/missing-pattern"
+	brokenYaml        = "./testdata/broken-yaml"
+	emptyFolder       = "./testdata/empty-folder"
+	deleteWriteConfig = "./testdata/delete-write-config"
+)
+
+func TestConfigReader(t *testing.T) {
+	t.Run("Can read correct properties", func(t *testing.T) {
+		err := os.Setenv("PROM_PASSWORD", "secret")
+		require.NoError(t, err)
+		t.Cleanup(func() { _ = os.Unsetenv("PROM_PASSWORD") })
+
+		cfgs, err := newConfigReader(log.New("test")).readConfig(correctProperties)
+		require.NoError(t, err)
+		require.Len(t, cfgs, 1)
+		cfg := cfgs[0]
+
+		require.Len(t, cfg.WriteConfigs, 1)
+		wc := cfg.WriteConfigs[0]
+		require.Equal(t, int64(1), wc.OrgID)
+		require.Equal(t, "prometheus", wc.UID)
+		require.Equal(t, "http://localhost:9090/api/v1/write", wc.Settings.Endpoint)
+		require.Equal(t, "admin", wc.Settings.BasicAuth.User)
+		require.Equal(t, map[string]string{"basicAuthPassword": "secret"}, wc.SecureSettings)
+
+		require.Len(t, cfg.ChannelRules, 1)
+		rule := cfg.ChannelRules[0]
+		require.Equal(t, int64(2), rule.OrgID)
+		require.Equal(t, "stream/telegraf/cpu", rule.Pattern)
+		require.Equal(t, pipeline.ConverterTypeInfluxAuto, rule.Settings.Converter.Type)
+		require.Equal(t, "labels_column", rule.Settings.Converter.AutoInfluxConverterConfig.FrameFormat)
+		require.Len(t, rule.Settings.FrameOutputters, 2)
+		require.Equal(t, "prometheus", rule.Settings.FrameOutputters[1].RemoteWriteOutputConfig.UID)
+		require.Equal(t, int64(1000), rule.Settings.FrameOutputters[1].RemoteWriteOutputConfig.SampleMilliseconds)
+
+		require.Len(t, cfg.DeleteChannelRules, 1)
+		require.Equal(t, int64(1), cfg.DeleteChannelRules[0].OrgID)
+		require.Equal(t, "stream/old/metrics", cfg.DeleteChannelRules[0].Pattern)
+	})
+
+	t.Run("Should fail when pattern is missing", func(t *testing.T) {
+		_, err := newConfigReader(log.New("test")).readConfig(missingPattern)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "required field pattern")
+	})
+
+	t.Run("Should fail on broken yaml", func(t *testing.T) {
+		_, err := newConfigReader(log.New("test")).readConfig(brokenYaml)
+		require.Error(t, err)
+	})
+
+	t.Run("Should return nothing for missing folder", func(t *testing.T) {
+		cfgs, err := newConfigReader(log.New("test")).readConfig(emptyFolder)
+		require.NoError(t, err)
+		require.Len(t, cfgs, 0)
+	})
+}
+
+func TestProvisioner(t *testing.T) {
+	newProvisioner := func(storage *fakeStorage) *Provisioner {
+		return &Provisioner{
+			log:         log.New("test"),
+			cfgProvider: newConfigReader(log.New("test")),
+			storage:     storage,
+			orgStore:    &fakeOrgStore{orgs: map[int64]struct{}{1: {}, 2: {}}},
+		}
+	}
+
+	t.Run("Should apply configuration", func(t *testing.T) {
+		storage := &fakeStorage{rules: map[int64][]pipeline.ChannelRule{
+			1: {{Pattern: "stream/old/metrics"}},
+		}}
+		err := newProvisioner(storage).applyChanges(context.Background(), correctProperties)
+		require.NoError(t, err)
+
+		require.Len(t, storage.deletedRules, 1)
+		require.Equal(t, "stream/old/metrics", storage.deletedRules[0].Pattern)
+		require.Len(t, storage.updatedWriteConfigs, 1)
+		require.Equal(t, "prometheus", storage.updatedWriteConfigs[0].UID)
+		require.Len(t, storage.updatedRules, 1)
+		require.Equal(t, "stream/telegraf/cpu", storage.updatedRules[0].Pattern)
+	})
+
+	t.Run("Should skip deleting missing channel rule", func(t *testing.T) {
+		storage := &fakeStorage{}
+		err := newProvisioner(storage).applyChanges(context.Background(), correctProperties)
+		require.NoError(t, err)
+		require.Len(t, storage.deletedRules, 0)
+		require.Len(t, storage.updatedRules, 1)
+	})
+
+	t.Run("Should fail for unknown organization", func(t *testing.T) {
+		storage := &fakeStorage{}
+		p := newProvisioner(storage)
+		p.orgStore = &fakeOrgStore{orgs: map[int64]struct{}{1: {}}}
+		err := p.applyChanges(context.Background(), correctProperties)
+		require.ErrorIs(t, err, models.ErrOrgNotFound)
+	})
+
+	t.Run("Should remove remote write queue of deleted write config", func(t *testing.T) {
+		dataPath := t.TempDir()
+		require.NoError(t, os.MkdirAll(filepath.Join(dataPath, "pipeline"), 0750))
+		require.NoError(t, os.WriteFile(filepath.Join(dataPath, "pipeline", "write-configs.json"),
+			[]byte(`{"writeConfigs":[{"orgId":1,"uid":"prometheus","settings":{"endpoint":"http://localhost:9090/api/v1/write"}}]}`), 0600))
+
+		queues := pipeline.NewRemoteWriteQueues(filepath.Join(dataPath, "pipeline", "remote-write"), 1024, 0)
+		defer queues.Close()
+		_, err := queues.Get(1, "prometheus", 0, "http://localhost:9090/api/v1/write", nil)
+		require.NoError(t, err)
+		queueDir := filepath.Join(dataPath, "pipeline", "remote-write", "1", "prometheus")
+		require.DirExists(t, queueDir)
+
+		storage := &pipeline.FileStorage{DataPath: dataPath, RemoteWriteQueues: queues}
+		p := &Provisioner{
+			log:         log.New("test"),
+			cfgProvider: newConfigReader(log.New("test")),
+			storage:     storage,
+			orgStore:    &fakeOrgStore{orgs: map[int64]struct{}{1: {}}},
+		}
+		require.NoError(t, p.applyChanges(context.Background(), deleteWriteConfig))
+
+		configs, err := storage.ListWriteConfigs(context.Background(), 1)
+		require.NoError(t, err)
+		require.Empty(t, configs)
+		require.NoDirExists(t, queueDir)
+	})
+}
+
+type fakeOrgStore struct {
+	orgs map[int64]struct{}
+}
+
+func (s *fakeOrgStore) GetOrgById(_ context.Context, query *models.GetOrgByIdQuery) error {
+	if _, ok := s.orgs[query.Id]; !ok {
+		return models.ErrOrgNotFound
+	}
+	query.Result = &models.Org{Id: query.Id}
+	return nil
+}
+
+type fakeStorage struct {
+	pipeline.Storage
+	rules               map[int64][]pipeline.ChannelRule
+	updatedRules        []pipeline.ChannelRuleUpdateCmd
+	deletedRules        []pipeline.ChannelRuleDeleteCmd
+	updatedWriteConfigs []pipeline.WriteConfigUpdateCmd
+}
+
+func (s *fakeStorage) ListChannelRules(_ context.Context, orgID int64) ([]pipeline.ChannelRule, error) {
+	return s.rules[orgID], nil
+}
+
+func (s *fakeStorage) UpdateChannelRule(_ context.Context, _ int64, cmd pipeline.ChannelRuleUpdateCmd) (pipeline.ChannelRule, error) {
+	s.updatedRules = append(s.updatedRules, cmd)
+	return pipeline.ChannelRule{Pattern: cmd.Pattern, Settings: cmd.Settings}, nil
+}
+
+func (s *fakeStorage) DeleteChannelRule(_ context.Context, _ int64, cmd pipeline.ChannelRuleDeleteCmd) error {
+	s.deletedRules = append(s.deletedRules, cmd)
+	return nil
+}
+
+func (s *fakeStorage) UpdateWriteConfig(_ context.Context, _ int64, cmd pipeline.WriteConfigUpdateCmd) (pipeline.WriteConfig, error) {
+	s.updatedWriteConfigs = append(s.updatedWriteConfigs, cmd)
+	return pipeline.WriteConfig{UID: cmd.UID, Settings: cmd.Settings}, nil
+}
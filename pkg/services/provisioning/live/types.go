@@ -0,0 +1,145 @@
+package live
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/grafana/grafana/pkg/services/live/pipeline"
+	"github.com/grafana/grafana/pkg/services/provisioning/values"
+)
+
+// configVersion is used to figure out which API version a config uses.
+type configVersion struct {
+	APIVersion int64 `json:"apiVersion" yaml:"apiVersion"`
+}
+
+// liveAsConfig is a normalized data object for Live pipeline config data.
+// Any config version should be mappable to this type.
+type liveAsConfig struct {
+	WriteConfigs       []*writeConfigFromConfig
+	DeleteWriteConfigs []*deleteWriteConfigFromConfig
+	ChannelRules       []*channelRuleFromConfig
+	DeleteChannelRules []*deleteChannelRuleFromConfig
+}
+
+type writeConfigFromConfig struct {
+	OrgID          int64
+	UID            string
+	Settings       pipeline.WriteSettings
+	SecureSettings map[string]string
+}
+
+type deleteWriteConfigFromConfig struct {
+	OrgID int64
+	UID   string
+}
+
+type channelRuleFromConfig struct {
+	OrgID          int64
+	Pattern        string
+	Settings       pipeline.ChannelRuleSettings
+	SecureSettings map[string]string
+}
+
+type deleteChannelRuleFromConfig struct {
+	OrgID   int64
+	Pattern string
+}
+
+// liveAsConfigV1 is a mapping for first version configs. This is mapped to its normalised version.
+type liveAsConfigV1 struct {
+	configVersion
+
+	WriteConfigs       []*writeConfigFromConfigV1       `json:"writeConfigs" yaml:"writeConfigs"`
+	DeleteWriteConfigs []*deleteWriteConfigFromConfigV1 `json:"deleteWriteConfigs" yaml:"deleteWriteConfigs"`
+	ChannelRules       []*channelRuleFromConfigV1       `json:"channelRules" yaml:"channelRules"`
+	DeleteChannelRules []*deleteChannelRuleFromConfigV1 `json:"deleteChannelRules" yaml:"deleteChannelRules"`
+}
+
+type writeConfigFromConfigV1 struct {
+	OrgID          values.Int64Value     `json:"orgId" yaml:"orgId"`
+	UID            values.StringValue    `json:"uid" yaml:"uid"`
+	Settings       values.JSONValue      `json:"settings" yaml:"settings"`
+	SecureSettings values.StringMapValue `json:"secureSettings" yaml:"secureSettings"`
+}
+
+type deleteWriteConfigFromConfigV1 struct {
+	OrgID values.Int64Value  `json:"orgId" yaml:"orgId"`
+	UID   values.StringValue `json:"uid" yaml:"uid"`
+}
+
+type channelRuleFromConfigV1 struct {
+	OrgID          values.Int64Value     `json:"orgId" yaml:"orgId"`
+	Pattern        values.StringValue    `json:"pattern" yaml:"pattern"`
+	Settings       values.JSONValue      `json:"settings" yaml:"settings"`
+	SecureSettings values.StringMapValue `json:"secureSettings" yaml:"secureSettings"`
+}
+
+type deleteChannelRuleFromConfigV1 struct {
+	OrgID   values.Int64Value  `json:"orgId" yaml:"orgId"`
+	Pattern values.StringValue `json:"pattern" yaml:"pattern"`
+}
+
+// mapToLiveFromConfig maps config syntax to a normalized liveAsConfig object. Every version
+// of the config syntax should have this function.
+func (cfg *liveAsConfigV1) mapToLiveFromConfig() (*liveAsConfig, error) {
+	r := &liveAsConfig{}
+	if cfg == nil {
+		return r, nil
+	}
+
+	for _, wc := range cfg.WriteConfigs {
+		var settings pipeline.WriteSettings
+		if err := convertSettings(wc.Settings.Value(), &settings); err != nil {
+			return nil, fmt.Errorf("invalid settings of write config %q: %w", wc.UID.Value(), err)
+		}
+		r.WriteConfigs = append(r.WriteConfigs, &writeConfigFromConfig{
+			OrgID:          wc.OrgID.Value(),
+			UID:            wc.UID.Value(),
+			Settings:       settings,
+			SecureSettings: wc.SecureSettings.Value(),
+		})
+	}
+
+	for _, wc := range cfg.DeleteWriteConfigs {
+		r.DeleteWriteConfigs = append(r.DeleteWriteConfigs, &deleteWriteConfigFromConfig{
+			OrgID: wc.OrgID.Value(),
+			UID:   wc.UID.Value(),
+		})
+	}
+
+	for _, rule := range cfg.ChannelRules {
+		var settings pipeline.ChannelRuleSettings
+		if err := convertSettings(rule.Settings.Value(), &settings); err != nil {
+			return nil, fmt.Errorf("invalid settings of channel rule %q: %w", rule.Pattern.Value(), err)
+		}
+		r.ChannelRules = append(r.ChannelRules, &channelRuleFromConfig{
+			OrgID:          rule.OrgID.Value(),
+			Pattern:        rule.Pattern.Value(),
+			Settings:       settings,
+			SecureSettings: rule.SecureSettings.Value(),
+		})
+	}
+
+	for _, rule := range cfg.DeleteChannelRules {
+		r.DeleteChannelRules = append(r.DeleteChannelRules, &deleteChannelRuleFromConfig{
+			OrgID:   rule.OrgID.Value(),
+			Pattern: rule.Pattern.Value(),
+		})
+	}
+
+	return r, nil
+}
+
+// convertSettings converts interpolated YAML settings into pipeline types
+// which are defined in terms of JSON.
+func convertSettings(settings map[string]interface{}, v interface{}) error {
+	if settings == nil {
+		return nil
+	}
+	data, err := json.Marshal(settings)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
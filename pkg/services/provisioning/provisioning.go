@@ -15,6 +15,9 @@ import (
 	dashboardservice "github.com/grafana/grafana/pkg/services/dashboards"
 	datasourceservice "github.com/grafana/grafana/pkg/services/datasources"
 	"github.com/grafana/grafana/pkg/services/encryption"
+	"github.com/grafana/grafana/pkg/services/featuremgmt"
+	"github.com/grafana/grafana/pkg/services/live"
+	"github.com/grafana/grafana/pkg/services/live/pipeline"
 	"github.com/grafana/grafana/pkg/services/ngalert/provisioning"
 	"github.com/grafana/grafana/pkg/services/ngalert/store"
 	"github.com/grafana/grafana/pkg/services/notifications"
@@ -22,6 +25,7 @@ import (
 	prov_alerting "github.com/grafana/grafana/pkg/services/provisioning/alerting"
 	"github.com/grafana/grafana/pkg/services/provisioning/dashboards"
 	"github.com/grafana/grafana/pkg/services/provisioning/datasources"
+	prov_live "github.com/grafana/grafana/pkg/services/provisioning/live"
 	"github.com/grafana/grafana/pkg/services/provisioning/notifiers"
 	"github.com/grafana/grafana/pkg/services/provisioning/plugins"
 	"github.com/grafana/grafana/pkg/services/provisioning/utils"
@@ -49,6 +53,7 @@ func ProvideService(
 	searchService searchV2.SearchService,
 	quotaService quota.Service,
 	secrectService secrets.Service,
+	grafanaLive *live.GrafanaLive,
 ) (*ProvisioningServiceImpl, error) {
 	s := &ProvisioningServiceImpl{
 		Cfg:                          cfg,
@@ -62,6 +67,7 @@ func ProvideService(
 		provisionDatasources:         datasources.Provision,
 		provisionPlugins:             plugins.Provision,
 		provisionAlerting:            prov_alerting.Provision,
+		provisionLive:                prov_live.Provision,
 		dashboardProvisioningService: dashboardProvisioningService,
 		dashboardService:             dashboardService,
 		datasourceService:            datasourceService,
@@ -71,6 +77,7 @@ func ProvideService(
 		searchService:                searchService,
 		quotaService:                 quotaService,
 		secretService:                secrectService,
+		grafanaLive:                  grafanaLive,
 		log:                          log.New("provisioning"),
 	}
 	return s, nil
@@ -84,6 +91,7 @@ type ProvisioningService interface {
 	ProvisionNotifications(ctx context.Context) error
 	ProvisionDashboards(ctx context.Context) error
 	ProvisionAlerting(ctx context.Context) error
+	ProvisionLivePipeline(ctx context.Context) error
 	GetDashboardProvisionerResolvedPath(name string) string
 	GetAllowUIUpdatesFromConfig(name string) bool
 }
@@ -131,6 +139,7 @@ type ProvisioningServiceImpl struct {
 	provisionDatasources         func(context.Context, string, datasources.Store, datasources.CorrelationsStore, utils.OrgStore) error
 	provisionPlugins             func(context.Context, string, plugins.Store, plugifaces.Store, pluginsettings.Service) error
 	provisionAlerting            func(context.Context, prov_alerting.ProvisionerConfig) error
+	provisionLive                func(context.Context, string, pipeline.Storage, utils.OrgStore) error
 	mutex                        sync.Mutex
 	dashboardProvisioningService dashboardservice.DashboardProvisioningService
 	dashboardService             dashboardservice.DashboardService
@@ -141,6 +150,7 @@ type ProvisioningServiceImpl struct {
 	searchService                searchV2.SearchService
 	quotaService                 quota.Service
 	secretService                secrets.Service
+	grafanaLive                  *live.GrafanaLive
 }
 
 func (ps *ProvisioningServiceImpl) RunInitProvisioners(ctx context.Context) error {
@@ -164,6 +174,11 @@ func (ps *ProvisioningServiceImpl) RunInitProvisioners(ctx context.Context) erro
 		return err
 	}
 
+	err = ps.ProvisionLivePipeline(ctx)
+	if err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -219,6 +234,32 @@ func (ps *ProvisioningServiceImpl) ProvisionPlugins(ctx context.Context) error {
 	return nil
 }
 
+// ProvisionLivePipeline provisions Live pipeline write configs and channel
+// rules. Secure settings of write configs are encrypted with secrets service.
+func (ps *ProvisioningServiceImpl) ProvisionLivePipeline(ctx context.Context) error {
+	if ps.provisionLive == nil || ps.Cfg.IsFeatureToggleEnabled == nil || !ps.Cfg.IsFeatureToggleEnabled(featuremgmt.FlagLivePipeline) {
+		return nil
+	}
+	livePath := filepath.Join(ps.Cfg.ProvisioningPath, "live")
+	// Use the storage of the running Live service, so deleted write configs also get their remote write queues removed.
+	var storage pipeline.Storage
+	if ps.grafanaLive != nil {
+		storage = ps.grafanaLive.PipelineStorage()
+	}
+	if storage == nil {
+		storage = &pipeline.FileStorage{
+			DataPath:       ps.Cfg.DataPath,
+			SecretsService: ps.secretService,
+		}
+	}
+	if err := ps.provisionLive(ctx, livePath, storage, ps.SQLStore); err != nil {
+		err = fmt.Errorf("%v: %w", "Live pipeline provisioning error", err)
+		ps.log.Error("Failed to provision Live pipeline", "error", err)
+		return err
+	}
+	return nil
+}
+
 func (ps *ProvisioningServiceImpl) ProvisionNotifications(ctx context.Context) error {
 	alertNotificationsPath := filepath.Join(ps.Cfg.ProvisioningPath, "notifiers")
 	if err := ps.provisionNotifiers(ctx, alertNotificationsPath, ps.alertingService, ps.SQLStore, ps.EncryptionService, ps.NotificationService); err != nil {
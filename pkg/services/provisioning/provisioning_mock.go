@@ -9,6 +9,7 @@ type Calls struct {
 	ProvisionNotifications              []interface{}
 	ProvisionDashboards                 []interface{}
 	ProvisionAlerting                   []interface{}
+	ProvisionLivePipeline               []interface{}
 	GetDashboardProvisionerResolvedPath []interface{}
 	GetAllowUIUpdatesFromConfig         []interface{}
 	Run                                 []interface{}
@@ -77,6 +78,11 @@ func (mock *ProvisioningServiceMock) ProvisionAlerting(ctx context.Context) erro
 	return nil
 }
 
+func (mock *ProvisioningServiceMock) ProvisionLivePipeline(ctx context.Context) error {
+	mock.Calls.ProvisionLivePipeline = append(mock.Calls.ProvisionLivePipeline, nil)
+	return nil
+}
+
 func (mock *ProvisioningServiceMock) GetDashboardProvisionerResolvedPath(name string) string {
 	mock.Calls.GetDashboardProvisionerResolvedPath = append(mock.Calls.GetDashboardProvisionerResolvedPath, name)
 	if mock.GetDashboardProvisionerResolvedPathFunc != nil {
@@ -10,6 +10,7 @@ import (
 	"github.com/grafana/grafana/pkg/models"
 	"github.com/grafana/grafana/pkg/registry"
 	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/dashboardusage"
 	"github.com/grafana/grafana/pkg/services/featuremgmt"
 	"github.com/grafana/grafana/pkg/services/sqlstore"
 	"github.com/grafana/grafana/pkg/services/store"
@@ -59,11 +60,17 @@ type StandardSearchService struct {
 	logger         log.Logger
 	dashboardIndex *searchIndex
 	extender       DashboardIndexExtender
+	usage          dashboardusage.Service
 	reIndexCh      chan struct{}
 }
 
-func ProvideService(cfg *setting.Cfg, sql *sqlstore.SQLStore, entityEventStore store.EntityEventsService, ac accesscontrol.AccessControl) SearchService {
-	extender := &NoopExtender{}
+func ProvideService(cfg *setting.Cfg, sql *sqlstore.SQLStore, entityEventStore store.EntityEventsService, ac accesscontrol.AccessControl, usage dashboardusage.Service) SearchService {
+	var extender DashboardIndexExtender = &NoopExtender{}
+	if cfg != nil && cfg.DashboardUsage.Enabled && usage != nil {
+		extender = newUsageExtender(usage, extender)
+	} else {
+		usage = nil
+	}
 	s := &StandardSearchService{
 		cfg: cfg,
 		sql: sql,
@@ -80,6 +87,7 @@ func ProvideService(cfg *setting.Cfg, sql *sqlstore.SQLStore, entityEventStore s
 		),
 		logger:    log.New("searchV2"),
 		extender:  extender,
+		usage:     usage,
 		reIndexCh: make(chan struct{}, 1),
 	}
 	return s
@@ -114,6 +122,10 @@ func (s *StandardSearchService) TriggerReIndex() {
 }
 
 func (s *StandardSearchService) RegisterDashboardIndexExtender(ext DashboardIndexExtender) {
+	if s.usage != nil {
+		// Keep usage fields available when an external extender is registered.
+		ext = newUsageExtender(s.usage, ext)
+	}
 	s.extender = ext
 	s.dashboardIndex.extender = ext.GetDocumentExtender()
 }
@@ -0,0 +1,116 @@
+package searchV2
+
+import (
+	"context"
+	"time"
+
+	"github.com/blugelabs/bluge"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/dashboardusage"
+)
+
+const (
+	DocumentFieldViews      = "views_last_30_days"
+	DocumentFieldQueries    = "queries_last_30_days"
+	DocumentFieldErrors     = "errors_last_30_days"
+	DocumentFieldLastViewed = "last_viewed"
+
+	usageWindow = 30 * 24 * time.Hour
+)
+
+// usageExtender adds dashboard usage counters as sortable fields to dashboard
+// documents, and delegates everything else to the wrapped extender. The fields
+// are read when a document is indexed, so they are refreshed by the periodic
+// full re-index rather than by usage flushes.
+type usageExtender struct {
+	usage  dashboardusage.Service
+	next   DashboardIndexExtender
+	logger log.Logger
+	now    func() time.Time
+}
+
+func newUsageExtender(usage dashboardusage.Service, next DashboardIndexExtender) *usageExtender {
+	return &usageExtender{
+		usage:  usage,
+		next:   next,
+		logger: log.New("searchV2.usage"),
+		now:    time.Now,
+	}
+}
+
+func (u *usageExtender) GetDocumentExtender() DocumentExtender {
+	return &usageDocumentExtender{u}
+}
+
+func (u *usageExtender) GetQueryExtender(query DashboardQuery) QueryExtender {
+	return &usageQueryExtender{next: u.next.GetQueryExtender(query)}
+}
+
+type usageDocumentExtender struct {
+	*usageExtender
+}
+
+func (u *usageDocumentExtender) GetDashboardExtender(orgID int64, uids ...string) ExtendDashboardFunc {
+	next := u.next.GetDocumentExtender().GetDashboardExtender(orgID, uids...)
+
+	lookup := make(map[string]dashboardusage.DashboardUsage)
+	usage, err := u.usage.GetDashboardUsage(context.Background(), dashboardusage.GetDashboardUsageQuery{
+		OrgID:         orgID,
+		DashboardUIDs: uids,
+		Since:         u.now().Add(-usageWindow),
+	})
+	if err != nil {
+		// Usage is not critical for search, index dashboards with zero counters.
+		u.logger.Error("Failed to load dashboard usage", "orgId", orgID, "error", err)
+	}
+	for _, item := range usage {
+		lookup[item.DashboardUID] = item
+	}
+
+	return func(uid string, doc *bluge.Document) error {
+		if isDashboardDocument(doc) {
+			item := lookup[uid]
+			doc.AddField(bluge.NewNumericField(DocumentFieldViews, float64(item.Views)).StoreValue().Sortable())
+			doc.AddField(bluge.NewNumericField(DocumentFieldQueries, float64(item.Queries)).StoreValue().Sortable())
+			doc.AddField(bluge.NewNumericField(DocumentFieldErrors, float64(item.Errors)).StoreValue().Sortable())
+			doc.AddField(bluge.NewNumericField(DocumentFieldLastViewed, float64(item.LastViewed)).StoreValue().Sortable())
+		}
+		return next(uid, doc)
+	}
+}
+
+func isDashboardDocument(doc *bluge.Document) bool {
+	for _, f := range *doc {
+		if f.Name() == documentFieldKind {
+			return string(f.Value()) == string(entityKindDashboard)
+		}
+	}
+	return false
+}
+
+type usageQueryExtender struct {
+	next QueryExtender
+}
+
+func (u *usageQueryExtender) GetFramer(frame *data.Frame) FramerFunc {
+	fields := make(map[string]*data.Field, 4)
+	for _, name := range []string{DocumentFieldViews, DocumentFieldQueries, DocumentFieldErrors, DocumentFieldLastViewed} {
+		f := data.NewFieldFromFieldType(data.FieldTypeNullableFloat64, 0)
+		f.Name = name
+		fields[name] = f
+		frame.Fields = append(frame.Fields, f)
+	}
+
+	next := u.next.GetFramer(frame)
+	return func(field string, value []byte) {
+		f, ok := fields[field]
+		if !ok {
+			next(field, value)
+			return
+		}
+		if num, err := bluge.DecodeNumericFloat64(value); err == nil {
+			f.Append(&num)
+		}
+	}
+}
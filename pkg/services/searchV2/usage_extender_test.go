@@ -0,0 +1,70 @@
+package searchV2
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana/pkg/services/dashboardusage"
+	"github.com/grafana/grafana/pkg/services/searchV2/extract"
+)
+
+type testUsageService struct {
+	dashboardusage.Service
+	usage []dashboardusage.DashboardUsage
+}
+
+func (t *testUsageService) GetDashboardUsage(_ context.Context, _ dashboardusage.GetDashboardUsageQuery) ([]dashboardusage.DashboardUsage, error) {
+	return t.usage, nil
+}
+
+var testUsageDashboards = []dashboard{
+	{
+		id:  1,
+		uid: "1",
+		info: &extract.DashboardInfo{
+			Title: "rarely used",
+			Panels: []extract.PanelInfo{
+				{ID: 1, Title: "panel"},
+			},
+		},
+	},
+	{
+		id:  2,
+		uid: "2",
+		info: &extract.DashboardInfo{
+			Title: "popular",
+		},
+	},
+	{
+		id:  3,
+		uid: "3",
+		info: &extract.DashboardInfo{
+			Title: "never viewed",
+		},
+	},
+}
+
+func TestDashboardIndexUsageSort(t *testing.T) {
+	usage := &testUsageService{
+		usage: []dashboardusage.DashboardUsage{
+			{DashboardUID: "1", Views: 2, Queries: 10, Errors: 1, LastViewed: 1654041600},
+			{DashboardUID: "2", Views: 42, Queries: 300, LastViewed: 1654128000},
+		},
+	}
+	extender := newUsageExtender(usage, &NoopExtender{})
+	extender.now = func() time.Time { return time.Unix(1654128000, 0) }
+
+	t.Run("usage-sort-views-desc", func(t *testing.T) {
+		query := DashboardQuery{Query: "*", Kind: []string{"dashboard"}, Sort: "-" + DocumentFieldViews}
+		index := initTestOrgIndexFromDashesExtended(t, testUsageDashboards, extender.GetDocumentExtender())
+		checkSearchResponseExtended(t, filepath.Base(t.Name()), index, testAllowAllFilter, query, extender.GetQueryExtender(query))
+	})
+
+	t.Run("usage-sort-last-viewed-asc", func(t *testing.T) {
+		query := DashboardQuery{Query: "*", Kind: []string{"dashboard"}, Sort: DocumentFieldLastViewed}
+		index := initTestOrgIndexFromDashesExtended(t, testUsageDashboards, extender.GetDocumentExtender())
+		checkSearchResponseExtended(t, filepath.Base(t.Name()), index, testAllowAllFilter, query, extender.GetQueryExtender(query))
+	})
+}
@@ -0,0 +1,30 @@
+package migrations
+
+import (
+	. "github.com/grafana/grafana/pkg/services/sqlstore/migrator"
+)
+
+func addDashboardUsageMigrations(mg *Migrator) {
+	dashboardUsageDailyV1 := Table{
+		Name: "dashboard_usage_daily",
+		Columns: []*Column{
+			{Name: "id", Type: DB_BigInt, Nullable: false, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "org_id", Type: DB_BigInt, Nullable: false},
+			{Name: "dashboard_uid", Type: DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "panel_id", Type: DB_BigInt, Nullable: false},
+			{Name: "day", Type: DB_BigInt, Nullable: false},
+			{Name: "views", Type: DB_BigInt, Nullable: false},
+			{Name: "queries", Type: DB_BigInt, Nullable: false},
+			{Name: "errors", Type: DB_BigInt, Nullable: false},
+		},
+		Indices: []*Index{
+			{Cols: []string{"org_id", "dashboard_uid", "panel_id", "day"}, Type: UniqueIndex},
+			{Cols: []string{"org_id", "day"}},
+		},
+	}
+
+	mg.AddMigration("create dashboard_usage_daily table v1", NewAddTableMigration(dashboardUsageDailyV1))
+
+	mg.AddMigration("add unique index dashboard_usage_daily.org_id-dashboard_uid-panel_id-day", NewAddIndexMigration(dashboardUsageDailyV1, dashboardUsageDailyV1.Indices[0]))
+	mg.AddMigration("add index dashboard_usage_daily.org_id-day", NewAddIndexMigration(dashboardUsageDailyV1, dashboardUsageDailyV1.Indices[1]))
+}
@@ -65,3 +65,29 @@ func addDbFileStorageMigration(mg *migrator.Migrator) {
 		// SQLite uses a `BINARY` collation by default
 		Postgres("ALTER TABLE file ALTER COLUMN path TYPE VARCHAR(1024) COLLATE \"C\";")) // Collate C - sorting done based on character code byte values
 }
+
+func addDbFileVersionMigration(mg *migrator.Migrator) {
+	fileVersionTable := migrator.Table{
+		Name: "file_version",
+		Columns: []*migrator.Column{
+			{Name: "id", Type: migrator.DB_BigInt, Nullable: false, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "path", Type: migrator.DB_NVarchar, Length: 1024, Nullable: false},
+			// path_hash matches file.path_hash, versions are kept after the file is deleted
+			{Name: "path_hash", Type: migrator.DB_NVarchar, Length: 64, Nullable: false},
+			{Name: "version", Type: migrator.DB_BigInt, Nullable: false},
+			{Name: "contents", Type: migrator.DB_Blob, Nullable: false},
+			{Name: "etag", Type: migrator.DB_NVarchar, Length: 32, Nullable: false},
+			{Name: "mime_type", Type: migrator.DB_NVarchar, Length: 255, Nullable: false},
+			{Name: "size", Type: migrator.DB_BigInt, Nullable: false},
+			{Name: "created", Type: migrator.DB_DateTime, Nullable: false},
+		},
+		Indices: []*migrator.Index{
+			{Cols: []string{"path_hash", "version"}, Type: migrator.UniqueIndex},
+			{Cols: []string{"created"}},
+		},
+	}
+
+	mg.AddMigration("create file_version table", migrator.NewAddTableMigration(fileVersionTable))
+	mg.AddMigration("file_version table idx: path_hash version", migrator.NewAddIndexMigration(fileVersionTable, fileVersionTable.Indices[0]))
+	mg.AddMigration("file_version table idx: created", migrator.NewAddIndexMigration(fileVersionTable, fileVersionTable.Indices[1]))
+}
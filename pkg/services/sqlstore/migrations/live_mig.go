@@ -22,3 +22,20 @@ func addLiveChannelMigrations(mg *migrator.Migrator) {
 	//mg.AddMigration("create live message table", migrator.NewAddTableMigration(liveMessage))
 	//mg.AddMigration("add index live_message.org_id_channel_unique", migrator.NewAddIndexMigration(liveMessage, liveMessage.Indices[0]))
 }
+
+func addLiveHAMessageMigrations(mg *migrator.Migrator) {
+	liveHAMessage := migrator.Table{
+		Name: "live_ha_message",
+		Columns: []*migrator.Column{
+			{Name: "id", Type: migrator.DB_BigInt, Nullable: false, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "data", Type: migrator.DB_MediumText, Nullable: false},
+			{Name: "created", Type: migrator.DB_BigInt, Nullable: false},
+		},
+		Indices: []*migrator.Index{
+			{Cols: []string{"created"}},
+		},
+	}
+
+	mg.AddMigration("create live ha message table", migrator.NewAddTableMigration(liveHAMessage))
+	mg.AddMigration("add index live_ha_message.created", migrator.NewAddIndexMigration(liveHAMessage, liveHAMessage.Indices[0]))
+}
@@ -97,6 +97,10 @@ func (*OSSMigrations) AddMigration(mg *Migrator) {
 
 	ualert.UpdateRuleGroupIndexMigration(mg)
 	accesscontrol.AddManagedFolderAlertActionsRepeatMigration(mg)
+
+	addDashboardUsageMigrations(mg)
+	addDbFileVersionMigration(mg)
+	addLiveHAMessageMigrations(mg)
 }
 
 func addMigrationLogMigrations(mg *Migrator) {
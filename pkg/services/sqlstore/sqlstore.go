@@ -48,6 +48,7 @@ type SQLStore struct {
 
 	bus                         bus.Bus
 	dbCfg                       DatabaseConfig
+	connectionString            string
 	engine                      *xorm.Engine
 	log                         log.Logger
 	Dialect                     migrator.Dialect
@@ -174,6 +175,13 @@ func (ss *SQLStore) Quote(value string) string {
 	return ss.engine.Quote(value)
 }
 
+// ConnectionString returns a connection string the store was initialized with.
+// Useful for components which need a dedicated database connection, for example
+// to LISTEN for Postgres notifications.
+func (ss *SQLStore) ConnectionString() string {
+	return ss.connectionString
+}
+
 // GetDialect return the dialect
 func (ss *SQLStore) GetDialect() migrator.Dialect {
 	return ss.Dialect
@@ -328,6 +336,7 @@ func (ss *SQLStore) initEngine(engine *xorm.Engine) error {
 	if err != nil {
 		return err
 	}
+	ss.connectionString = connectionString
 
 	if ss.Cfg.IsFeatureToggleEnabled(featuremgmt.FlagDatabaseMetrics) {
 		ss.dbCfg.Type = WrapDatabaseDriverWithHooks(ss.dbCfg.Type, ss.tracer)
@@ -112,6 +112,7 @@ type RootStorageConfig struct {
 	SQL  *StorageSQLConfig       `json:"sql,omitempty"`
 	S3   *StorageS3Config        `json:"s3,omitempty"`
 	GCS  *StorageGCSConfig       `json:"gcs,omitempty"`
+	Blob *StorageBlobConfig      `json:"blob,omitempty"`
 }
 
 type StorageLocalDiskConfig struct {
@@ -129,12 +130,19 @@ type StorageGitConfig struct {
 	RequirePullRequest bool   `json:"requirePullRequest"`
 	PullInterval       string `json:"pullInterval"`
 
+	// Link to a branch in the web UI of remotes other than GitHub, {branch} is replaced with the branch name.
+	// For example: https://gitlab.example.com/org/dashboards/-/tree/{branch}
+	BranchURL string `json:"branchUrl,omitempty"`
+
 	// SECURE JSON :grimicing:
 	AccessToken string `json:"accessToken,omitempty"` // Simplest auth method for github
 }
 
 type StorageSQLConfig struct {
 	// SQLStorage will prefix all paths with orgId for isolation between orgs
+
+	// Versioned storages keep the history of all file writes
+	Versioned bool `json:"versioned,omitempty"`
 }
 
 type StorageS3Config struct {
@@ -154,12 +162,28 @@ type StorageGCSConfig struct {
 	CredentialsFile string `json:"credentialsFile"`
 }
 
+// StorageBlobConfig configures a root backed by an object storage bucket (S3, GCS or Azure blob storage)
+type StorageBlobConfig struct {
+	URL    string `json:"url"`              // s3://bucket?region=us-east-1, gs://bucket, azblob://container
+	Folder string `json:"folder,omitempty"` // folder within the bucket
+
+	// SECURE, never returned to the client
+	AccessKey       string `json:"-"` // S3
+	SecretKey       string `json:"-"` // S3
+	CredentialsFile string `json:"-"` // GCS
+	CredentialsJSON string `json:"-"` // GCS
+	AccountName     string `json:"-"` // Azure
+	AccountKey      string `json:"-"` // Azure
+}
+
 func newStorage(cfg RootStorageConfig, localWorkCache string) (storageRuntime, error) {
 	switch cfg.Type {
 	case rootStorageTypeDisk:
 		return newDiskStorage(RootStorageMeta{}, cfg), nil
 	case rootStorageTypeGit:
 		return newGitStorage(RootStorageMeta{}, cfg, localWorkCache), nil
+	case rootStorageTypeBlob:
+		return newBlobStorage(RootStorageMeta{}, cfg), nil
 	}
 
 	return nil, fmt.Errorf("unsupported store: " + cfg.Type)
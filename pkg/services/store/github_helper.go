@@ -9,6 +9,8 @@ import (
 
 	"github.com/google/go-github/v45/github"
 	"golang.org/x/oauth2"
+
+	"github.com/grafana/grafana/pkg/models"
 )
 
 type githubHelper struct {
@@ -89,6 +91,9 @@ func (g *githubHelper) pushCommit(ctx context.Context, ref *github.Reference, cm
 	parent.Commit.SHA = parent.SHA
 
 	user := cmd.User
+	if user == nil {
+		user = &models.SignedInUser{}
+	}
 	name := firstRealString(user.Name, user.Login, user.Email, "?")
 	email := firstRealString(user.Email, user.Login, user.Name, "?")
 
@@ -130,6 +135,14 @@ func (g *githubHelper) createPR(ctx context.Context, cmd makePRCommand) (*github
 	return g.client.PullRequests.Create(ctx, g.repoOwner, g.repoName, newPR)
 }
 
+func (g *githubHelper) getPR(ctx context.Context, number int) (*github.PullRequest, *github.Response, error) {
+	return g.client.PullRequests.Get(ctx, g.repoOwner, g.repoName, number)
+}
+
+func (g *githubHelper) getBranchURL(branch string) string {
+	return fmt.Sprintf("https://github.com/%s/%s/tree/%s", g.repoOwner, g.repoName, url.PathEscape(branch))
+}
+
 // func (g *githubHelper) getPR(config *Config, prSubject string) (*github.PullRequest, error) {
 
 // 	opts := github.PullRequestListOptions{}
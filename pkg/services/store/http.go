@@ -7,10 +7,12 @@ import (
 	"io"
 	"io/ioutil"
 	"net/http"
+	"strconv"
 	"strings"
 
 	"github.com/grafana/grafana/pkg/api/response"
 	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/infra/filestorage"
 	"github.com/grafana/grafana/pkg/middleware"
 	"github.com/grafana/grafana/pkg/models"
 	"github.com/grafana/grafana/pkg/util"
@@ -37,6 +39,15 @@ func UploadErrorToStatusCode(err error) int {
 	case errors.Is(err, ErrAccessDenied):
 		return 403
 
+	case errors.Is(err, filestorage.ErrVersioningNotSupported):
+		return 400
+
+	case errors.Is(err, filestorage.ErrVersionNotFound):
+		return 404
+
+	case errors.Is(err, filestorage.ErrVersionAccessDenied):
+		return 403
+
 	default:
 		return 500
 	}
@@ -47,11 +58,13 @@ func (s *standardStorageService) RegisterHTTPRoutes(storageRoute routing.RouteRe
 	storageRoute.Get("/list/*", routing.Wrap(s.list))
 	storageRoute.Get("/read/*", routing.Wrap(s.read))
 	storageRoute.Get("/options/*", routing.Wrap(s.getOptions))
+	storageRoute.Get("/versions/*", routing.Wrap(s.listVersions))
 
 	// Write paths
 	reqGrafanaAdmin := middleware.ReqGrafanaAdmin
 	storageRoute.Post("/write/*", reqGrafanaAdmin, routing.Wrap(s.doWrite))
 	storageRoute.Post("/delete/*", reqGrafanaAdmin, routing.Wrap(s.doDelete))
+	storageRoute.Post("/restore/*", reqGrafanaAdmin, routing.Wrap(s.doRestoreVersion))
 	storageRoute.Post("/upload", reqGrafanaAdmin, routing.Wrap(s.doUpload))
 	storageRoute.Post("/createFolder", reqGrafanaAdmin, routing.Wrap(s.doCreateFolder))
 	storageRoute.Post("/deleteFolder", reqGrafanaAdmin, routing.Wrap(s.doDeleteFolder))
@@ -162,7 +175,19 @@ func getMultipartFormValue(req *http.Request, key string) string {
 func (s *standardStorageService) read(c *models.ReqContext) response.Response {
 	// full path is api/storage/read/upload/example.jpg, but we only want the part after read
 	scope, path := getPathAndScope(c)
-	file, err := s.Read(c.Req.Context(), c.SignedInUser, scope+"/"+path)
+
+	var file *filestorage.File
+	var err error
+	if version := c.Query("version"); version != "" {
+		// api/storage/read/upload/example.jpg?version=2 reads a previous version of the file
+		v, parseErr := strconv.ParseInt(version, 10, 64)
+		if parseErr != nil {
+			return response.Error(400, "invalid version", parseErr)
+		}
+		file, err = s.ReadVersion(c.Req.Context(), c.SignedInUser, scope+"/"+path, v)
+	} else {
+		file, err = s.Read(c.Req.Context(), c.SignedInUser, scope+"/"+path)
+	}
 	if err != nil {
 		return response.Error(400, "cannot call read", err)
 	}
@@ -202,6 +227,45 @@ func (s *standardStorageService) doDelete(c *models.ReqContext) response.Respons
 	})
 }
 
+func (s *standardStorageService) listVersions(c *models.ReqContext) response.Response {
+	scope, path := getPathAndScope(c)
+	versions, err := s.ListVersions(c.Req.Context(), c.SignedInUser, scope+"/"+path)
+	if err != nil {
+		return response.Error(UploadErrorToStatusCode(err), "failed to list versions: "+err.Error(), err)
+	}
+	return response.JSON(200, versions)
+}
+
+func (s *standardStorageService) doRestoreVersion(c *models.ReqContext) response.Response {
+	body, err := io.ReadAll(c.Req.Body)
+	if err != nil {
+		return response.Error(500, "error reading bytes", err)
+	}
+
+	cmd := &RestoreVersionCmd{}
+	err = json.Unmarshal(body, cmd)
+	if err != nil {
+		return response.Error(400, "error parsing body", err)
+	}
+
+	if cmd.Version <= 0 {
+		return response.Error(400, "invalid version", nil)
+	}
+
+	// full path is api/storage/restore/upload/example.jpg, but we only want the part after restore
+	scope, path := getPathAndScope(c)
+	if err := s.RestoreVersion(c.Req.Context(), c.SignedInUser, scope+"/"+path, cmd.Version); err != nil {
+		return response.Error(UploadErrorToStatusCode(err), "failed to restore the file: "+err.Error(), err)
+	}
+
+	return response.JSON(200, map[string]interface{}{
+		"message": "Restored file version",
+		"success": true,
+		"path":    path,
+		"version": cmd.Version,
+	})
+}
+
 func (s *standardStorageService) doDeleteFolder(c *models.ReqContext) response.Response {
 	body, err := io.ReadAll(c.Req.Body)
 	if err != nil {
@@ -8,6 +8,9 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/grafana/grafana/pkg/api/routing"
 	"github.com/grafana/grafana/pkg/infra/filestorage"
@@ -19,6 +22,7 @@ import (
 	"github.com/grafana/grafana/pkg/services/quota"
 	"github.com/grafana/grafana/pkg/services/sqlstore"
 	"github.com/grafana/grafana/pkg/setting"
+	"golang.org/x/sync/errgroup"
 )
 
 var grafanaStorageLogger = log.New("grafanaStorageLogger")
@@ -29,6 +33,7 @@ var ErrQuotaReached = errors.New("file quota reached")
 var ErrValidationFailed = errors.New("request validation failed")
 var ErrFileAlreadyExists = errors.New("file exists")
 var ErrStorageNotFound = errors.New("storage not found")
+var ErrStorageNotReady = errors.New("storage not ready")
 var ErrAccessDenied = errors.New("access denied")
 var ErrOnlyDashboardSaveSupported = errors.New("only dashboard save is currently supported")
 
@@ -43,8 +48,35 @@ const SystemBrandingStorage = "system/" + brandingStorage
 var (
 	SystemBrandingReader = &models.SignedInUser{OrgId: ac.GlobalOrgID}
 	SystemBrandingAdmin  = &models.SignedInUser{OrgId: ac.GlobalOrgID}
+
+	datasourceFileReaders sync.Map // orgId -> *models.SignedInUser
 )
 
+// DatasourceFileReader returns the identity data sources use to read their files from the resources storage of an org
+func DatasourceFileReader(orgId int64) *models.SignedInUser {
+	user, _ := datasourceFileReaders.LoadOrStore(orgId, &models.SignedInUser{OrgId: orgId})
+	return user.(*models.SignedInUser)
+}
+
+func isDatasourceFileReader(user *models.SignedInUser) bool {
+	reader, ok := datasourceFileReaders.Load(user.OrgId)
+	return ok && reader == user
+}
+
+// DatasourceResources reads files of the resources storage with the identity of the data sources of an org
+type DatasourceResources struct {
+	storage StorageService
+}
+
+func ProvideDatasourceResources(storage StorageService) *DatasourceResources {
+	return &DatasourceResources{storage: storage}
+}
+
+// ReadResource reads a file by its path relative to the resources storage root
+func (r *DatasourceResources) ReadResource(ctx context.Context, orgID int64, path string) (*filestorage.File, error) {
+	return r.storage.Read(ctx, DatasourceFileReader(orgID), RootResources+"/"+strings.TrimPrefix(path, "/"))
+}
+
 const MAX_UPLOAD_SIZE = 1 * 1024 * 1024 // 3MB
 
 type DeleteFolderCmd struct {
@@ -56,6 +88,10 @@ type CreateFolderCmd struct {
 	Path string `json:"path"`
 }
 
+type RestoreVersionCmd struct {
+	Version int64 `json:"version"`
+}
+
 type StorageService interface {
 	registry.BackgroundService
 
@@ -76,6 +112,18 @@ type StorageService interface {
 
 	CreateFolder(ctx context.Context, user *models.SignedInUser, cmd *CreateFolderCmd) error
 
+	// List the recorded versions of a file, newest first
+	ListVersions(ctx context.Context, user *models.SignedInUser, path string) ([]*filestorage.FileVersion, error)
+
+	// Read the contents of a file as they were stored in the given version
+	ReadVersion(ctx context.Context, user *models.SignedInUser, path string, version int64) (*filestorage.File, error)
+
+	// Restore the file contents to the given version
+	RestoreVersion(ctx context.Context, user *models.SignedInUser, path string, version int64) error
+
+	// Delete versions older than `olderThan`, keeping the `keepLatest` most recent versions of each file
+	DeleteStaleVersions(ctx context.Context, olderThan time.Time, keepLatest int) (int64, error)
+
 	validateUploadRequest(ctx context.Context, user *models.SignedInUser, req *UploadRequest, storagePath string) validationResult
 
 	// sanitizeUploadRequest sanitizes the upload request and converts it into a command accepted by the FileStorage API
@@ -156,6 +204,15 @@ func ProvideService(
 		}
 	}
 
+	// Object storage roots configured in [storage.blob.<prefix>] sections
+	for _, root := range cfg.Storage.BlobRoots {
+		if root.Prefix == "" {
+			grafanaStorageLogger.Warn("Invalid root configuration", "prefix", root.Prefix)
+			continue
+		}
+		globalRoots = append(globalRoots, newBlobStorage(blobRootFromSettings(root)))
+	}
+
 	initializeOrgStorages := func(orgId int64) []storageRuntime {
 		storages := make([]storageRuntime, 0)
 
@@ -166,7 +223,7 @@ func ProvideService(
 			}, RootResources,
 				"Resources",
 				"Upload custom resource files",
-				&StorageSQLConfig{}, sql, orgId))
+				&StorageSQLConfig{Versioned: cfg.Storage.VersionsEnabled}, sql, orgId))
 
 		// System settings
 		storages = append(storages,
@@ -175,7 +232,7 @@ func ProvideService(
 			}, RootSystem,
 				"System",
 				"Grafana system storage",
-				&StorageSQLConfig{}, sql, orgId))
+				&StorageSQLConfig{Versioned: cfg.Storage.VersionsEnabled}, sql, orgId))
 
 		return storages
 	}
@@ -196,6 +253,17 @@ func ProvideService(
 			return nil
 		}
 
+		if isDatasourceFileReader(user) {
+			if storageName != RootResources {
+				return nil
+			}
+			return map[string]filestorage.PathFilter{
+				ActionFilesRead:   allowAllPathFilter,
+				ActionFilesWrite:  denyAllPathFilter,
+				ActionFilesDelete: denyAllPathFilter,
+			}
+		}
+
 		if storageName == RootSystem {
 			if user == SystemBrandingReader {
 				return map[string]filestorage.PathFilter{
@@ -265,7 +333,17 @@ func newStandardStorageService(
 
 func (s *standardStorageService) Run(ctx context.Context) error {
 	grafanaStorageLogger.Info("storage starting")
-	return nil
+
+	// Roots with background work, such as git roots pulling their remote, run until the service is stopped
+	g, ctx := errgroup.WithContext(ctx)
+	for _, root := range s.tree.roots() {
+		if runner, ok := root.(storageRunner); ok {
+			g.Go(func() error {
+				return runner.Run(ctx)
+			})
+		}
+	}
+	return g.Wait()
 }
 
 func getOrgId(user *models.SignedInUser) int64 {
@@ -301,10 +379,6 @@ type UploadRequest struct {
 }
 
 func (s *standardStorageService) Upload(ctx context.Context, user *models.SignedInUser, req *UploadRequest) error {
-	if err := s.checkFileQuota(ctx, req.Path); err != nil {
-		return err
-	}
-
 	guardian := s.authService.newGuardian(ctx, user, getFirstSegment(req.Path))
 	if !guardian.canWrite(req.Path) {
 		return ErrAccessDenied
@@ -319,6 +393,13 @@ func (s *standardStorageService) Upload(ctx context.Context, user *models.Signed
 		return ErrUnsupportedStorage
 	}
 
+	// file quota only applies to files stored in the database
+	if root.Meta().Config.Type == rootStorageTypeSQL {
+		if err := s.checkFileQuota(ctx, req.Path); err != nil {
+			return err
+		}
+	}
+
 	validationResult := s.validateUploadRequest(ctx, user, req, storagePath)
 	if !validationResult.ok {
 		grafanaStorageLogger.Warn("file upload validation failed", "path", req.Path, "reason", validationResult.reason)
@@ -354,7 +435,6 @@ func (s *standardStorageService) Upload(ctx context.Context, user *models.Signed
 }
 
 func (s *standardStorageService) checkFileQuota(ctx context.Context, path string) error {
-	// assumes we are only uploading to the SQL database - TODO: refactor once we introduce object stores
 	quotaReached, err := s.quotaService.CheckQuotaReached(ctx, "file", nil)
 	if err != nil {
 		grafanaStorageLogger.Error("failed while checking upload quota", "path", path, "error", err)
@@ -438,6 +518,67 @@ func (s *standardStorageService) Delete(ctx context.Context, user *models.Signed
 	return nil
 }
 
+func (s *standardStorageService) getVersionStorage(user *models.SignedInUser, path string) (filestorage.FileVersionStorage, string, error) {
+	root, storagePath := s.tree.getRoot(getOrgId(user), path)
+	if root == nil {
+		return nil, "", ErrStorageNotFound
+	}
+
+	versioned, ok := root.Store().(filestorage.FileVersionStorage)
+	if !ok {
+		return nil, "", ErrUnsupportedStorage
+	}
+	return versioned, storagePath, nil
+}
+
+func (s *standardStorageService) ListVersions(ctx context.Context, user *models.SignedInUser, path string) ([]*filestorage.FileVersion, error) {
+	guardian := s.authService.newGuardian(ctx, user, getFirstSegment(path))
+	if !guardian.canView(path) {
+		return nil, ErrAccessDenied
+	}
+
+	versioned, storagePath, err := s.getVersionStorage(user, path)
+	if err != nil {
+		return nil, err
+	}
+	return versioned.ListVersions(ctx, storagePath)
+}
+
+func (s *standardStorageService) ReadVersion(ctx context.Context, user *models.SignedInUser, path string, version int64) (*filestorage.File, error) {
+	guardian := s.authService.newGuardian(ctx, user, getFirstSegment(path))
+	if !guardian.canView(path) {
+		return nil, ErrAccessDenied
+	}
+
+	versioned, storagePath, err := s.getVersionStorage(user, path)
+	if err != nil {
+		return nil, err
+	}
+	return versioned.GetVersion(ctx, storagePath, version)
+}
+
+func (s *standardStorageService) RestoreVersion(ctx context.Context, user *models.SignedInUser, path string, version int64) error {
+	guardian := s.authService.newGuardian(ctx, user, getFirstSegment(path))
+	if !guardian.canWrite(path) {
+		return ErrAccessDenied
+	}
+
+	root, _ := s.tree.getRoot(getOrgId(user), path)
+	if root != nil && root.Meta().ReadOnly {
+		return ErrUnsupportedStorage
+	}
+
+	versioned, storagePath, err := s.getVersionStorage(user, path)
+	if err != nil {
+		return err
+	}
+	return versioned.RestoreVersion(ctx, storagePath, version)
+}
+
+func (s *standardStorageService) DeleteStaleVersions(ctx context.Context, olderThan time.Time, keepLatest int) (int64, error) {
+	return filestorage.DeleteStaleFileVersions(ctx, s.sql, olderThan, keepLatest)
+}
+
 func (s *standardStorageService) write(ctx context.Context, user *models.SignedInUser, req *WriteValueRequest) (*WriteValueResponse, error) {
 	guardian := s.authService.newGuardian(ctx, user, getFirstSegment(req.Path))
 	if !guardian.canWrite(req.Path) {
@@ -496,11 +637,19 @@ func (s *standardStorageService) getWorkflowOptions(ctx context.Context, user *m
 	meta := root.Meta()
 	if meta.Config.Type == rootStorageTypeGit && meta.Config.Git != nil {
 		cfg := meta.Config.Git
-		options.Workflows = append(options.Workflows, workflowInfo{
-			Type:        WriteValueWorkflow_PR,
-			Label:       "Create pull request",
-			Description: "Create a new upstream pull request",
-		})
+		if gitRoot, ok := root.(*rootStorageGit); ok && gitRoot.github == nil {
+			options.Workflows = append(options.Workflows, workflowInfo{
+				Type:        WriteValueWorkflow_Branch,
+				Label:       "Save as branch",
+				Description: "Push the changes to a new upstream branch",
+			})
+		} else {
+			options.Workflows = append(options.Workflows, workflowInfo{
+				Type:        WriteValueWorkflow_PR,
+				Label:       "Create pull request",
+				Description: "Create a new upstream pull request",
+			})
+		}
 		if !cfg.RequirePullRequest {
 			options.Workflows = append(options.Workflows, workflowInfo{
 				Type:        WriteValueWorkflow_Push,
@@ -0,0 +1,214 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"strings"
+
+	"github.com/Azure/azure-storage-blob-go/azblob"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"gocloud.dev/blob"
+	"gocloud.dev/blob/azureblob"
+	"gocloud.dev/blob/gcsblob"
+	"gocloud.dev/blob/s3blob"
+	"gocloud.dev/gcp"
+	"golang.org/x/oauth2/google"
+
+	"github.com/grafana/grafana/pkg/infra/filestorage"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+const rootStorageTypeBlob = "blob"
+
+var _ storageRuntime = &rootStorageBlob{}
+
+type rootStorageBlob struct {
+	settings *StorageBlobConfig
+	meta     RootStorageMeta
+	store    filestorage.FileStorage
+}
+
+// blobRootFromSettings converts a [storage.blob.<prefix>] section into a root configuration
+func blobRootFromSettings(s setting.StorageBlobRootSettings) (RootStorageMeta, RootStorageConfig) {
+	meta := RootStorageMeta{
+		ReadOnly: s.ReadOnly,
+	}
+	cfg := RootStorageConfig{
+		Type:        rootStorageTypeBlob,
+		Prefix:      s.Prefix,
+		Name:        s.Name,
+		Description: s.Description,
+		Blob: &StorageBlobConfig{
+			URL:             s.URL,
+			Folder:          s.Folder,
+			AccessKey:       s.AccessKey,
+			SecretKey:       s.SecretKey,
+			CredentialsFile: s.CredentialsFile,
+			CredentialsJSON: s.CredentialsJSON,
+			AccountName:     s.AccountName,
+			AccountKey:      s.AccountKey,
+		},
+	}
+	return meta, cfg
+}
+
+func newBlobStorage(meta RootStorageMeta, scfg RootStorageConfig) *rootStorageBlob {
+	cfg := scfg.Blob
+	if cfg == nil {
+		cfg = &StorageBlobConfig{}
+		scfg.Blob = cfg
+	}
+	scfg.Type = rootStorageTypeBlob
+	meta.Config = scfg
+	if scfg.Prefix == "" {
+		meta.Notice = append(meta.Notice, data.Notice{
+			Severity: data.NoticeSeverityError,
+			Text:     "Missing prefix",
+		})
+	}
+	if cfg.URL == "" {
+		meta.Notice = append(meta.Notice, data.Notice{
+			Severity: data.NoticeSeverityError,
+			Text:     "Missing bucket URL",
+		})
+	}
+
+	s := &rootStorageBlob{
+		settings: cfg,
+	}
+
+	if meta.Notice == nil {
+		bucket, err := openBlobBucket(context.Background(), cfg)
+		if err != nil {
+			grafanaStorageLogger.Warn("error loading storage", "prefix", scfg.Prefix, "err", err)
+			meta.Notice = append(meta.Notice, data.Notice{
+				Severity: data.NoticeSeverityError,
+				Text:     "Failed to initialize storage",
+			})
+		} else {
+			s.store = filestorage.NewCdkBlobStorage(grafanaStorageLogger,
+				bucket, getBlobRootFolder(cfg.Folder), nil)
+
+			meta.Ready = true
+		}
+	}
+
+	s.meta = meta
+	return s
+}
+
+// getBlobRootFolder converts the configured folder into the key prefix used within the bucket.
+// example:
+//   folder: "/grafana/branding"
+//     => prefix: "grafana/branding/"
+func getBlobRootFolder(folder string) string {
+	folder = strings.Trim(folder, filestorage.Delimiter)
+	if folder == "" {
+		return ""
+	}
+	return folder + filestorage.Delimiter
+}
+
+// openBlobBucket opens the bucket using explicit credentials when they are configured,
+// and falls back to the default credential chain of each provider otherwise.
+func openBlobBucket(ctx context.Context, cfg *StorageBlobConfig) (*blob.Bucket, error) {
+	u, err := url.Parse(cfg.URL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid bucket URL: %w", err)
+	}
+
+	switch u.Scheme {
+	case s3blob.Scheme:
+		if cfg.AccessKey == "" {
+			break
+		}
+		sess, err := session.NewSession(&aws.Config{
+			Credentials: credentials.NewStaticCredentials(cfg.AccessKey, cfg.SecretKey, ""),
+		})
+		if err != nil {
+			return nil, err
+		}
+		opener := &s3blob.URLOpener{ConfigProvider: sess}
+		return opener.OpenBucketURL(ctx, u)
+
+	case gcsblob.Scheme:
+		credentialsJSON := []byte(cfg.CredentialsJSON)
+		if len(credentialsJSON) == 0 && cfg.CredentialsFile != "" {
+			// nolint:gosec
+			// The path is configured by the administrator
+			credentialsJSON, err = ioutil.ReadFile(cfg.CredentialsFile)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read GCS credentials file: %w", err)
+			}
+		}
+		if len(credentialsJSON) == 0 {
+			break
+		}
+		creds, err := google.CredentialsFromJSON(ctx, credentialsJSON, "https://www.googleapis.com/auth/devstorage.read_write")
+		if err != nil {
+			return nil, fmt.Errorf("invalid GCS credentials: %w", err)
+		}
+		client, err := gcp.NewHTTPClient(gcp.DefaultTransport(), gcp.CredentialsTokenSource(creds))
+		if err != nil {
+			return nil, err
+		}
+		opener := &gcsblob.URLOpener{Client: client}
+		return opener.OpenBucketURL(ctx, u)
+
+	case azureblob.Scheme:
+		if cfg.AccountName == "" || cfg.AccountKey == "" {
+			break
+		}
+		credential, err := azureblob.NewCredential(azureblob.AccountName(cfg.AccountName), azureblob.AccountKey(cfg.AccountKey))
+		if err != nil {
+			return nil, fmt.Errorf("invalid Azure credentials: %w", err)
+		}
+		opener := &azureblob.URLOpener{
+			AccountName: azureblob.AccountName(cfg.AccountName),
+			Pipeline:    azureblob.NewPipeline(credential, azblob.PipelineOptions{}),
+		}
+		return opener.OpenBucketURL(ctx, u)
+	}
+
+	return blob.OpenBucket(ctx, cfg.URL)
+}
+
+func (s *rootStorageBlob) Meta() RootStorageMeta {
+	return s.meta
+}
+
+func (s *rootStorageBlob) Store() filestorage.FileStorage {
+	return s.store
+}
+
+func (s *rootStorageBlob) Sync() error {
+	return nil // already in sync
+}
+
+// with object storage user metadata and messages are lost
+func (s *rootStorageBlob) Write(ctx context.Context, cmd *WriteValueRequest) (*WriteValueResponse, error) {
+	if s.meta.ReadOnly {
+		return nil, ErrUnsupportedStorage
+	}
+	if s.store == nil {
+		return nil, ErrStorageNotReady
+	}
+
+	path := cmd.Path
+	if !strings.HasPrefix(path, filestorage.Delimiter) {
+		path = filestorage.Delimiter + path
+	}
+	err := s.store.Upsert(ctx, &filestorage.UpsertFileCommand{
+		Path:     path,
+		Contents: []byte(cmd.Body),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &WriteValueResponse{Code: 200}, nil
+}
@@ -0,0 +1,103 @@
+package store
+
+import (
+	"context"
+	"testing"
+
+	"github.com/grafana/grafana/pkg/services/quota/quotatest"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/stretchr/testify/require"
+)
+
+func setupBlobStore(t *testing.T, readOnly bool) StorageService {
+	t.Helper()
+
+	blobStorage := newBlobStorage(blobRootFromSettings(setting.StorageBlobRootSettings{
+		Prefix:   "bucket",
+		Name:     "Bucket",
+		URL:      "mem://",
+		Folder:   "/grafana/",
+		ReadOnly: readOnly,
+	}))
+	require.True(t, blobStorage.Meta().Ready)
+
+	store := newStandardStorageService(sqlstore.InitTestDB(t), []storageRuntime{blobStorage}, func(orgId int64) []storageRuntime {
+		return make([]storageRuntime, 0)
+	}, allowAllAuthService, cfg)
+	store.cfg = &GlobalStorageConfig{}
+	store.quotaService = quotatest.NewQuotaServiceFake()
+	return store
+}
+
+func TestBlobStorageUploadAndRead(t *testing.T) {
+	service := setupBlobStore(t, false)
+
+	err := service.Upload(context.Background(), dummyUser, &UploadRequest{
+		EntityType: EntityTypeImage,
+		Contents:   jpgBytes,
+		Path:       "bucket/branding/logo.jpg",
+	})
+	require.NoError(t, err)
+
+	file, err := service.Read(context.Background(), dummyUser, "bucket/branding/logo.jpg")
+	require.NoError(t, err)
+	require.NotNil(t, file)
+	require.Equal(t, jpgBytes, file.Contents)
+
+	frame, err := service.List(context.Background(), dummyUser, "bucket/branding")
+	require.NoError(t, err)
+	require.Equal(t, []string{"logo.jpg"}, frame.GetFileNames())
+}
+
+func TestBlobStorageReadOnly(t *testing.T) {
+	service := setupBlobStore(t, true)
+
+	err := service.Upload(context.Background(), dummyUser, &UploadRequest{
+		EntityType: EntityTypeImage,
+		Contents:   jpgBytes,
+		Path:       "bucket/logo.jpg",
+	})
+	require.ErrorIs(t, err, ErrUnsupportedStorage)
+}
+
+func TestBlobStorageConfigValidation(t *testing.T) {
+	s := newBlobStorage(blobRootFromSettings(setting.StorageBlobRootSettings{Prefix: "bucket"}))
+	require.False(t, s.Meta().Ready)
+	require.Len(t, s.Meta().Notice, 1)
+
+	s = newBlobStorage(blobRootFromSettings(setting.StorageBlobRootSettings{Prefix: "bucket", URL: "unknown://bucket"}))
+	require.False(t, s.Meta().Ready)
+	require.Equal(t, "Failed to initialize storage", s.Meta().Notice[0].Text)
+}
+
+func TestBlobStorageNotReady(t *testing.T) {
+	blobStorage := newBlobStorage(blobRootFromSettings(setting.StorageBlobRootSettings{
+		Prefix: "bucket",
+		URL:    "unknown://bucket",
+	}))
+	require.False(t, blobStorage.Meta().Ready)
+
+	_, err := blobStorage.Write(context.Background(), &WriteValueRequest{Path: "a.json", Body: []byte("{}")})
+	require.ErrorIs(t, err, ErrStorageNotReady)
+
+	service := newStandardStorageService(sqlstore.InitTestDB(t), []storageRuntime{blobStorage}, func(orgId int64) []storageRuntime {
+		return make([]storageRuntime, 0)
+	}, allowAllAuthService, cfg)
+	service.cfg = &GlobalStorageConfig{}
+	service.quotaService = quotatest.NewQuotaServiceFake()
+
+	err = service.Upload(context.Background(), dummyUser, &UploadRequest{
+		EntityType: EntityTypeImage,
+		Contents:   jpgBytes,
+		Path:       "bucket/logo.jpg",
+	})
+	require.ErrorIs(t, err, ErrStorageNotFound)
+}
+
+func TestGetBlobRootFolder(t *testing.T) {
+	require.Equal(t, "", getBlobRootFolder(""))
+	require.Equal(t, "", getBlobRootFolder("/"))
+	require.Equal(t, "grafana/", getBlobRootFolder("grafana"))
+	require.Equal(t, "grafana/branding/", getBlobRootFolder("/grafana/branding/"))
+}
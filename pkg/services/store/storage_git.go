@@ -2,15 +2,22 @@ package store
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"net/url"
 	"os"
 	"path/filepath"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/go-git/go-git/v5"
+	gitconfig "github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
 	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	githttp "github.com/go-git/go-git/v5/plumbing/transport/http"
 
 	"github.com/grafana/grafana-plugin-sdk-go/data"
 	"github.com/grafana/grafana/pkg/infra/filestorage"
@@ -23,14 +30,35 @@ const rootStorageTypeGit = "git"
 
 var _ storageRuntime = &rootStorageGit{}
 
+// How often branches waiting for review are checked when no pull interval is configured
+const defaultPendingChangesInterval = time.Minute
+
+// Pending changes are kept next to the local repository, so they are tracked across restarts
+const pendingChangesFile = "grafana_pending_changes.json"
+
 type rootStorageGit struct {
 	settings *StorageGitConfig
 	repo     *git.Repository
 	root     string // repostitory root
+	token    string
 
 	github *githubHelper
 	meta   RootStorageMeta
 	store  filestorage.FileStorage
+
+	pullInterval time.Duration // zero when the remote is not pulled periodically
+
+	// guards the local worktree and the pending changes
+	mu          sync.Mutex
+	pending     map[string]*pendingChange // keyed by branch name
+	pendingPath string                    // file the pending changes are saved to
+}
+
+// pendingChange is a change saved to a branch. The root is synced once the branch is merged into the base branch
+type pendingChange struct {
+	Branch   string `json:"branch"`
+	Hash     string `json:"hash"`
+	PRNumber int    `json:"prNumber,omitempty"` // only set for GitHub pull requests
 }
 
 func newGitStorage(meta RootStorageMeta, scfg RootStorageConfig, localWorkCache string) *rootStorageGit {
@@ -67,6 +95,7 @@ func newGitStorage(meta RootStorageMeta, scfg RootStorageConfig, localWorkCache
 
 	s := &rootStorageGit{
 		settings: cfg,
+		pending:  make(map[string]*pendingChange),
 	}
 	if meta.Notice == nil {
 		err := os.MkdirAll(localWorkCache, 0750)
@@ -141,7 +170,10 @@ func newGitStorage(meta RootStorageMeta, scfg RootStorageConfig, localWorkCache
 					}
 				}
 
-				if token != "" {
+				s.token = token
+
+				// Other remotes use the generic git workflow, which pushes branches without opening pull requests
+				if token != "" && isGithubRemote(cfg.Remote) {
 					s.github, err = newGithubHelper(context.Background(), cfg.Remote, token)
 					if err != nil {
 						meta.Notice = append(meta.Notice, data.Notice{
@@ -166,6 +198,18 @@ func newGitStorage(meta RootStorageMeta, scfg RootStorageConfig, localWorkCache
 		}
 		s.repo = repo
 
+		if repo != nil {
+			s.pendingPath = filepath.Join(localWorkCache, git.GitDirName, pendingChangesFile)
+			s.pending, err = loadPendingChanges(s.pendingPath)
+			if err != nil {
+				meta.Notice = append(meta.Notice, data.Notice{
+					Severity: data.NoticeSeverityError,
+					Text:     "unable to load pending changes: " + err.Error(),
+				})
+				s.pending = make(map[string]*pendingChange)
+			}
+		}
+
 		// Try pulling after init
 		if s.repo != nil && !scfg.Disabled {
 			err = s.Sync()
@@ -182,16 +226,7 @@ func newGitStorage(meta RootStorageMeta, scfg RootStorageConfig, localWorkCache
 						Text:     "Invalid pull interval " + cfg.PullInterval,
 					})
 				} else {
-					ticker := time.NewTicker(t)
-					go func() {
-						for range ticker.C {
-							grafanaStorageLogger.Info("try git pull", "branch", s.settings.Remote)
-							err = s.Sync()
-							if err != nil {
-								grafanaStorageLogger.Info("error pulling", "error", err)
-							}
-						}
-					}()
+					s.pullInterval = t
 				}
 			}
 		}
@@ -201,6 +236,46 @@ func newGitStorage(meta RootStorageMeta, scfg RootStorageConfig, localWorkCache
 	return s
 }
 
+// Run pulls the remote and checks the pending changes periodically until the context is done
+func (s *rootStorageGit) Run(ctx context.Context) error {
+	if s.repo == nil || s.meta.Config.Disabled {
+		return nil
+	}
+
+	var pull <-chan time.Time
+	if s.pullInterval > 0 {
+		pullTicker := time.NewTicker(s.pullInterval)
+		defer pullTicker.Stop()
+		pull = pullTicker.C
+	}
+
+	pendingInterval := defaultPendingChangesInterval
+	if s.pullInterval > 0 {
+		pendingInterval = s.pullInterval
+	}
+	pendingTicker := time.NewTicker(pendingInterval)
+	defer pendingTicker.Stop()
+
+	for {
+		select {
+		case <-pull:
+			grafanaStorageLogger.Info("try git pull", "branch", s.settings.Remote)
+			s.mu.Lock()
+			err := s.Sync()
+			s.mu.Unlock()
+			if err != nil {
+				grafanaStorageLogger.Info("error pulling", "error", err)
+			}
+		case <-pendingTicker.C:
+			if err := s.checkPendingChanges(ctx); err != nil {
+				grafanaStorageLogger.Info("error checking pending changes", "remote", s.settings.Remote, "error", err)
+			}
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
 func (s *rootStorageGit) Meta() RootStorageMeta {
 	return s.meta
 }
@@ -215,29 +290,108 @@ func (s *rootStorageGit) Pull() error {
 		return err
 	}
 
-	err = w.Pull(&git.PullOptions{
+	opts := &git.PullOptions{
+		Auth: s.auth(),
 		// Depth: 1,
 		//SingleBranch: true,
-	})
+	}
+	if s.settings.Branch != "" {
+		opts.ReferenceName = plumbing.NewBranchReferenceName(s.settings.Branch)
+	}
+
+	err = w.Pull(opts)
 	if err != nil {
 		return err
 	}
 	return nil
 }
 
+// auth returns the credentials used with http remotes, the token is sent as the basic auth password
+// which is supported by GitHub, GitLab, Bitbucket and Gitea
+func (s *rootStorageGit) auth() transport.AuthMethod {
+	if s.token == "" || !strings.HasPrefix(s.settings.Remote, "http") {
+		return nil
+	}
+	return &githttp.BasicAuth{
+		Username: "grafana",
+		Password: s.token,
+	}
+}
+
+func (s *rootStorageGit) baseBranch() string {
+	if s.settings.Branch != "" {
+		return s.settings.Branch
+	}
+	if s.repo != nil {
+		if head, err := s.repo.Head(); err == nil {
+			return head.Name().Short()
+		}
+	}
+	return "main"
+}
+
+// getBranchURL returns a link to the branch in the remote web UI, when it can be resolved
+func (s *rootStorageGit) getBranchURL(branch string) string {
+	if s.github != nil {
+		return s.github.getBranchURL(branch)
+	}
+	if s.settings.BranchURL != "" {
+		return strings.ReplaceAll(s.settings.BranchURL, "{branch}", url.PathEscape(branch))
+	}
+	if strings.HasPrefix(s.settings.Remote, "http") {
+		return strings.TrimSuffix(s.settings.Remote, ".git") + "/tree/" + branch
+	}
+	return ""
+}
+
+func newBranchName() string {
+	return fmt.Sprintf("grafana_ui_%d", time.Now().UnixMilli())
+}
+
 func (s *rootStorageGit) Write(ctx context.Context, cmd *WriteValueRequest) (*WriteValueResponse, error) {
-	if s.github == nil {
-		return nil, fmt.Errorf("github client not initialized")
+	if s.repo == nil {
+		return nil, fmt.Errorf("git repository not initialized")
+	}
+
+	if err := filestorage.ValidatePath(cmd.Path); err != nil {
+		return &WriteValueResponse{
+			Code:    400,
+			Message: "invalid path: " + err.Error(),
+		}, nil
 	}
+
 	// Write to the correct subfolder
-	if s.settings.Root != "" {
-		cmd.Path = s.settings.Root + cmd.Path
+	cmd.Path = strings.TrimPrefix(filepath.ToSlash(filepath.Join(s.settings.Root, cmd.Path)), "/")
+	if cmd.Message == "" {
+		cmd.Message = "changes from grafana ui"
+	}
+	if cmd.User == nil {
+		cmd.User = &models.SignedInUser{}
 	}
 
-	if cmd.Workflow == WriteValueWorkflow_PR {
+	switch cmd.Workflow {
+	case WriteValueWorkflow_PR, WriteValueWorkflow_Branch:
+	default:
+		if s.settings.RequirePullRequest {
+			return &WriteValueResponse{
+				Code:    400,
+				Message: "changes to " + s.baseBranch() + " require a pull request",
+			}, nil
+		}
+	}
+
+	if s.github != nil {
+		return s.writeGithub(ctx, cmd)
+	}
+	return s.writeGit(ctx, cmd)
+}
+
+// writeGithub saves changes with the GitHub API
+func (s *rootStorageGit) writeGithub(ctx context.Context, cmd *WriteValueRequest) (*WriteValueResponse, error) {
+	if cmd.Workflow == WriteValueWorkflow_PR || cmd.Workflow == WriteValueWorkflow_Branch {
 		prcmd := makePRCommand{
-			baseBranch: s.settings.Branch,
-			headBranch: fmt.Sprintf("grafana_ui_%d", time.Now().UnixMilli()),
+			baseBranch: s.baseBranch(),
+			headBranch: newBranchName(),
 			title:      cmd.Title,
 			body:       cmd.Message,
 		}
@@ -259,111 +413,363 @@ func (s *rootStorageGit) Write(ctx context.Context, cmd *WriteValueRequest) (*Wr
 			return res, nil
 		}
 
-		if prcmd.title == "" {
-			prcmd.title = "Dashboard save: " + time.Now().String()
+		res.Code = 200
+		res.Pending = true
+		res.Hash = *ref.Object.SHA
+		res.URL = s.getBranchURL(prcmd.headBranch)
+		change := &pendingChange{
+			Branch: prcmd.headBranch,
+			Hash:   res.Hash,
 		}
-		if prcmd.body == "" {
-			prcmd.body = "Dashboard save: " + time.Now().String()
+
+		if cmd.Workflow == WriteValueWorkflow_PR {
+			if prcmd.title == "" {
+				prcmd.title = "Dashboard save: " + time.Now().String()
+			}
+			if prcmd.body == "" {
+				prcmd.body = "Dashboard save: " + time.Now().String()
+			}
+
+			pr, _, err := s.github.createPR(ctx, prcmd)
+			if err != nil {
+				res.Code = 500
+				res.Message = "error creating PR: " + err.Error()
+				return res, nil
+			}
+			res.URL = pr.GetHTMLURL()
+			change.PRNumber = pr.GetNumber()
 		}
 
-		pr, _, err := s.github.createPR(ctx, prcmd)
-		if err != nil {
+		if err := s.addPendingChange(change); err != nil {
 			res.Code = 500
-			res.Message = "error creating PR: " + err.Error()
-			return res, nil
+			res.Message = "error saving pending change: " + err.Error()
 		}
-
-		res.Code = 200
-		res.URL = pr.GetHTMLURL()
-		res.Pending = true
-		res.Hash = *ref.Object.SHA
-		res.Branch = prcmd.headBranch
 		return res, nil
 	}
 
 	// Push to remote branch (save)
-	if cmd.Workflow == WriteValueWorkflow_Push || true {
-		res := &WriteValueResponse{
-			Branch: s.settings.Branch,
-		}
-		ref, _, err := s.github.getRef(ctx, s.settings.Branch)
-		if err != nil {
-			res.Code = 500
-			res.Message = "unable to create branch"
-			return res, nil
-		}
-		err = s.github.pushCommit(ctx, ref, cmd)
+	res := &WriteValueResponse{
+		Branch: s.baseBranch(),
+	}
+	ref, _, err := s.github.getRef(ctx, res.Branch)
+	if err != nil {
+		res.Code = 500
+		res.Message = "unable to create branch"
+		return res, nil
+	}
+	err = s.github.pushCommit(ctx, ref, cmd)
+	if err != nil {
+		res.Code = 500
+		res.Message = "error creating commit"
+		return res, nil
+	}
+	ref, _, _ = s.github.getRef(ctx, res.Branch)
+	if ref != nil {
+		res.Hash = *ref.Object.SHA
+	}
+	res.URL = s.getBranchURL(res.Branch)
+
+	s.mu.Lock()
+	err = s.Sync()
+	s.mu.Unlock()
+	if err != nil {
+		res.Message = "error pulling: " + err.Error()
+	}
+
+	res.Code = 200
+	return res, nil
+}
+
+// writeGit saves changes with plain git operations, so it works with any remote that accepts pushes
+func (s *rootStorageGit) writeGit(ctx context.Context, cmd *WriteValueRequest) (*WriteValueResponse, error) {
+	if cmd.Workflow == WriteValueWorkflow_PR {
+		return &WriteValueResponse{
+			Code:    400,
+			Message: "pull requests are only supported for GitHub remotes, save as a branch instead",
+		}, nil
+	}
+
+	res, err := s.pushGitCommit(ctx, cmd)
+	if err == nil && res.Pending {
+		err := s.addPendingChange(&pendingChange{
+			Branch: res.Branch,
+			Hash:   res.Hash,
+		})
 		if err != nil {
 			res.Code = 500
-			res.Message = "error creating commit"
-			return res, nil
-		}
-		ref, _, _ = s.github.getRef(ctx, s.settings.Branch)
-		if ref != nil {
-			res.Hash = *ref.Object.SHA
-			res.URL = ref.GetURL()
+			res.Message = "error saving pending change: " + err.Error()
 		}
+	}
+	return res, err
+}
+
+// pushGitCommit commits the file to the base branch, or to a new branch for the branch workflow, and pushes it
+func (s *rootStorageGit) pushGitCommit(ctx context.Context, cmd *WriteValueRequest) (*WriteValueResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	base := s.baseBranch()
+	if err := s.Sync(); err != nil {
+		grafanaStorageLogger.Warn("error pulling before write", "remote", s.settings.Remote, "error", err)
+	}
 
-		err = s.Pull()
+	w, err := s.repo.Worktree()
+	if err != nil {
+		return nil, err
+	}
+
+	branch := base
+	if cmd.Workflow == WriteValueWorkflow_Branch {
+		branch = newBranchName()
+		err = w.Checkout(&git.CheckoutOptions{
+			Branch: plumbing.NewBranchReferenceName(branch),
+			Create: true,
+		})
 		if err != nil {
-			res.Message = "error pulling: " + err.Error()
+			return nil, err
 		}
 
-		res.Code = 200
-		return res, nil
+		// Always leave the worktree on the base branch, the storage serves files from it
+		defer func() {
+			err := w.Checkout(&git.CheckoutOptions{
+				Branch: plumbing.NewBranchReferenceName(base),
+				Force:  true,
+			})
+			if err != nil {
+				grafanaStorageLogger.Error("error restoring the base branch", "branch", base, "error", err)
+			}
+		}()
 	}
 
-	rel := cmd.Path
-	if s.meta.Config.Git.Root != "" {
-		rel = filepath.Join(s.meta.Config.Git.Root, cmd.Path)
+	res := &WriteValueResponse{
+		Branch: branch,
 	}
 
-	fpath := filepath.Join(s.root, rel)
-	err := os.WriteFile(fpath, cmd.Body, 0644)
+	head, err := s.repo.Head()
 	if err != nil {
 		return nil, err
 	}
 
-	w, err := s.repo.Worktree()
+	hash, err := s.commitFile(w, cmd)
 	if err != nil {
 		return nil, err
 	}
+	res.Hash = hash.String()
 
-	// The file we just wrote
-	_, err = w.Add(rel)
+	refSpec := gitconfig.RefSpec(fmt.Sprintf("refs/heads/%s:refs/heads/%s", branch, branch))
+	err = s.repo.PushContext(ctx, &git.PushOptions{
+		RefSpecs: []gitconfig.RefSpec{refSpec},
+		Auth:     s.auth(),
+	})
 	if err != nil {
-		return nil, err
+		// Drop the local commit, the storage must not serve content that is not on the remote
+		if branch == base {
+			if rerr := w.Reset(&git.ResetOptions{Commit: head.Hash(), Mode: git.HardReset}); rerr != nil {
+				grafanaStorageLogger.Error("error resetting the base branch", "branch", base, "error", rerr)
+			}
+		} else if rerr := s.repo.Storer.RemoveReference(plumbing.NewBranchReferenceName(branch)); rerr != nil {
+			grafanaStorageLogger.Error("error removing the local branch", "branch", branch, "error", rerr)
+		}
+		res.Code = 500
+		res.Message = "error pushing: " + err.Error()
+		res.Hash = ""
+		return res, nil
 	}
 
-	msg := cmd.Message
-	if msg == "" {
-		msg = "changes from grafana ui"
+	res.Code = 200
+	res.URL = s.getBranchURL(branch)
+	res.Pending = branch != base
+	return res, nil
+}
+
+// commitFile writes the file into the local worktree and commits it to the current branch
+func (s *rootStorageGit) commitFile(w *git.Worktree, cmd *WriteValueRequest) (plumbing.Hash, error) {
+	// the path is relative to the worktree, it must not escape it
+	if err := filestorage.ValidatePath(filestorage.Delimiter + cmd.Path); err != nil {
+		return plumbing.ZeroHash, fmt.Errorf("invalid path %q: %w", cmd.Path, err)
+	}
+	fpath := filepath.Join(w.Filesystem.Root(), filepath.FromSlash(cmd.Path))
+	if err := os.MkdirAll(filepath.Dir(fpath), 0750); err != nil {
+		return plumbing.ZeroHash, err
 	}
-	user := cmd.User
-	if user == nil {
-		user = &models.SignedInUser{}
+
+	if err := os.WriteFile(fpath, cmd.Body, 0600); err != nil {
+		return plumbing.ZeroHash, err
 	}
 
-	hash, err := w.Commit(msg, &git.CommitOptions{
+	// The file we just wrote
+	if _, err := w.Add(cmd.Path); err != nil {
+		return plumbing.ZeroHash, err
+	}
+
+	hash, err := w.Commit(cmd.Message, &git.CommitOptions{
 		Author: &object.Signature{
-			Name:  firstRealString(user.Name, user.Login, user.Email, "?"),
-			Email: firstRealString(user.Email, user.Login, user.Name, "?"),
+			Name:  firstRealString(cmd.User.Name, cmd.User.Login, cmd.User.Email, "?"),
+			Email: firstRealString(cmd.User.Email, cmd.User.Login, cmd.User.Name, "?"),
 			When:  time.Now(),
 		},
 	})
+	if err != nil {
+		return plumbing.ZeroHash, err
+	}
+
+	grafanaStorageLogger.Info("made commit", "hash", hash, "path", cmd.Path)
+	return hash, nil
+}
+
+// addPendingChange tracks a branch waiting for review, the change is saved before the write is acknowledged
+func (s *rootStorageGit) addPendingChange(change *pendingChange) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.pending[change.Branch] = change
+	if err := s.savePendingChangesLocked(); err != nil {
+		delete(s.pending, change.Branch)
+		return err
+	}
+	return nil
+}
+
+func (s *rootStorageGit) savePendingChangesLocked() error {
+	if s.pendingPath == "" {
+		return nil
+	}
+	changes := make([]*pendingChange, 0, len(s.pending))
+	for _, change := range s.pending {
+		changes = append(changes, change)
+	}
+	b, err := json.Marshal(changes)
+	if err != nil {
+		return err
+	}
+
+	// Write to a temporary file first, so a crash never leaves a truncated file behind
+	tmp := s.pendingPath + ".tmp"
+	if err := os.WriteFile(tmp, b, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, s.pendingPath)
+}
+
+func loadPendingChanges(path string) (map[string]*pendingChange, error) {
+	pending := make(map[string]*pendingChange)
+	// nolint:gosec
+	// We can ignore the gosec G304 warning since the path is built from the configured local cache folder
+	b, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return pending, nil
+	}
 	if err != nil {
 		return nil, err
 	}
+	var changes []*pendingChange
+	if err := json.Unmarshal(b, &changes); err != nil {
+		return nil, err
+	}
+	for _, change := range changes {
+		pending[change.Branch] = change
+	}
+	return pending, nil
+}
+
+// checkPendingChanges syncs the root when any of the pending changes was merged.
+// Changes which are merged or were closed without merging are no longer tracked.
+func (s *rootStorageGit) checkPendingChanges(ctx context.Context) error {
+	s.mu.Lock()
+	pending := make([]*pendingChange, 0, len(s.pending))
+	for _, change := range s.pending {
+		pending = append(pending, change)
+	}
+	s.mu.Unlock()
+
+	if len(pending) == 0 {
+		return nil
+	}
+
+	merged := false
+	for _, change := range pending {
+		done, isMerged, err := s.isChangeDone(ctx, change)
+		if err != nil {
+			grafanaStorageLogger.Info("error checking pending change", "branch", change.Branch, "error", err)
+			continue
+		}
+		if !done {
+			continue
+		}
+
+		grafanaStorageLogger.Info("pending change completed", "branch", change.Branch, "merged", isMerged)
+		merged = merged || isMerged
+		s.mu.Lock()
+		delete(s.pending, change.Branch)
+		err = s.savePendingChangesLocked()
+		s.mu.Unlock()
+		if err != nil {
+			grafanaStorageLogger.Warn("error saving pending changes", "remote", s.settings.Remote, "error", err)
+		}
+	}
+
+	if !merged {
+		return nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.Sync()
+}
+
+func (s *rootStorageGit) isChangeDone(ctx context.Context, change *pendingChange) (done bool, merged bool, err error) {
+	if s.github != nil && change.PRNumber > 0 {
+		pr, _, err := s.github.getPR(ctx, change.PRNumber)
+		if err != nil {
+			return false, false, err
+		}
+		return pr.GetState() == "closed", pr.GetMerged(), nil
+	}
 
-	grafanaStorageLogger.Info("made commit", "hash", hash)
-	// err = s.repo.Push(&git.PushOptions{
-	// 	InsecureSkipTLS: true,
-	// })
+	err = s.repo.FetchContext(ctx, &git.FetchOptions{
+		Auth: s.auth(),
+	})
+	if err != nil && !errors.Is(err, git.NoErrAlreadyUpToDate) {
+		return false, false, err
+	}
+
+	baseRef, err := s.repo.Reference(plumbing.NewRemoteReferenceName(git.DefaultRemoteName, s.baseBranch()), true)
+	if err != nil {
+		return false, false, err
+	}
+	baseCommit, err := s.repo.CommitObject(baseRef.Hash())
+	if err != nil {
+		return false, false, err
+	}
+
+	changeCommit, err := s.repo.CommitObject(plumbing.NewHash(change.Hash))
+	if errors.Is(err, plumbing.ErrObjectNotFound) {
+		return false, false, fmt.Errorf("commit %s of branch %s not found", change.Hash, change.Branch)
+	}
+	if err != nil {
+		return false, false, err
+	}
 
-	return &WriteValueResponse{
-		Hash:    hash.String(),
-		Message: "made commit",
-	}, nil
+	merged, err = changeCommit.IsAncestor(baseCommit)
+	if err != nil || merged {
+		return merged, merged, err
+	}
+
+	// Branches merged with squash or rebase are not ancestors of the base branch, but they are usually deleted
+	remote, err := s.repo.Remote(git.DefaultRemoteName)
+	if err != nil {
+		return false, false, err
+	}
+	refs, err := remote.ListContext(ctx, &git.ListOptions{Auth: s.auth()})
+	if err != nil {
+		return false, false, err
+	}
+	for _, ref := range refs {
+		if ref.Name() == plumbing.NewBranchReferenceName(change.Branch) {
+			return false, false, nil
+		}
+	}
+	return true, true, nil
 }
 
 func (s *rootStorageGit) Sync() error {
@@ -377,6 +783,14 @@ func (s *rootStorageGit) Sync() error {
 	return err
 }
 
+func isGithubRemote(remote string) bool {
+	u, err := url.Parse(remote)
+	if err != nil {
+		return false
+	}
+	return u.Host == "github.com" || u.Host == "www.github.com"
+}
+
 func firstRealString(vals ...string) string {
 	for _, v := range vals {
 		if v != "" {
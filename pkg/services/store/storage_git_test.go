@@ -0,0 +1,264 @@
+package store
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/stretchr/testify/require"
+)
+
+// setupGitRemote creates a repository with a single dashboard which is used as the remote of the storage
+func setupGitRemote(t *testing.T) (*git.Repository, string) {
+	t.Helper()
+
+	dir := t.TempDir()
+	repo, err := git.PlainInit(dir, false)
+	require.NoError(t, err)
+
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "dashboards"), 0750))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "dashboards", "a.json"), []byte(`{"title":"A"}`), 0600))
+
+	w, err := repo.Worktree()
+	require.NoError(t, err)
+	_, err = w.Add("dashboards/a.json")
+	require.NoError(t, err)
+	_, err = w.Commit("initial commit", &git.CommitOptions{
+		Author: &object.Signature{Name: "test", Email: "test@example.com", When: time.Now()},
+	})
+	require.NoError(t, err)
+	return repo, dir
+}
+
+func TestGitStorageBranchWorkflow(t *testing.T) {
+	remote, remoteDir := setupGitRemote(t)
+
+	cfg := RootStorageConfig{
+		Prefix: "git",
+		Git: &StorageGitConfig{
+			Remote:    remoteDir,
+			Branch:    "master",
+			Root:      "dashboards",
+			BranchURL: "https://git.example.com/dashboards/-/tree/{branch}",
+		},
+	}
+	cacheDir := filepath.Join(t.TempDir(), "cache")
+	s := newGitStorage(RootStorageMeta{}, cfg, cacheDir)
+	require.True(t, s.Meta().Ready, s.Meta().Notice)
+	require.Nil(t, s.github)
+
+	ctx := context.Background()
+	res, err := s.Write(ctx, &WriteValueRequest{
+		Path:     "/a.json",
+		Body:     []byte(`{"title":"B"}`),
+		Workflow: WriteValueWorkflow_Branch,
+		Message:  "update A",
+	})
+	require.NoError(t, err)
+	require.Equal(t, 200, res.Code, res.Message)
+	require.True(t, res.Pending)
+	require.Equal(t, "https://git.example.com/dashboards/-/tree/"+res.Branch, res.URL)
+
+	// the change is pushed to the remote branch, while the storage still serves the base branch
+	ref, err := remote.Reference(plumbing.NewBranchReferenceName(res.Branch), true)
+	require.NoError(t, err)
+	require.Equal(t, res.Hash, ref.Hash().String())
+
+	file, err := s.Store().Get(ctx, "/a.json")
+	require.NoError(t, err)
+	require.Equal(t, `{"title":"A"}`, string(file.Contents))
+
+	// nothing happens until the branch is merged
+	require.NoError(t, s.checkPendingChanges(ctx))
+	require.Len(t, s.pending, 1)
+
+	// pending changes are tracked across restarts
+	s = newGitStorage(RootStorageMeta{}, cfg, cacheDir)
+	require.True(t, s.Meta().Ready, s.Meta().Notice)
+	require.Equal(t, map[string]*pendingChange{
+		res.Branch: {Branch: res.Branch, Hash: res.Hash},
+	}, s.pending)
+
+	w, err := remote.Worktree()
+	require.NoError(t, err)
+	require.NoError(t, w.Reset(&git.ResetOptions{Commit: ref.Hash(), Mode: git.HardReset}))
+
+	require.NoError(t, s.checkPendingChanges(ctx))
+	require.Empty(t, s.pending)
+
+	file, err = s.Store().Get(ctx, "/a.json")
+	require.NoError(t, err)
+	require.Equal(t, `{"title":"B"}`, string(file.Contents))
+}
+
+func TestGitStorageRunStopsWithContext(t *testing.T) {
+	_, remoteDir := setupGitRemote(t)
+
+	s := newGitStorage(RootStorageMeta{}, RootStorageConfig{
+		Prefix: "git",
+		Git: &StorageGitConfig{
+			Remote:       remoteDir,
+			Branch:       "master",
+			PullInterval: "10ms",
+		},
+	}, filepath.Join(t.TempDir(), "cache"))
+	require.True(t, s.Meta().Ready, s.Meta().Notice)
+	require.Equal(t, 10*time.Millisecond, s.pullInterval)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	require.ErrorIs(t, s.Run(ctx), context.DeadlineExceeded)
+}
+
+func TestGitStorageRejectedPush(t *testing.T) {
+	_, remoteDir := setupGitRemote(t)
+
+	s := newGitStorage(RootStorageMeta{}, RootStorageConfig{
+		Prefix: "git",
+		Git: &StorageGitConfig{
+			Remote: remoteDir,
+			Branch: "master",
+			Root:   "dashboards",
+		},
+	}, filepath.Join(t.TempDir(), "cache"))
+	require.True(t, s.Meta().Ready, s.Meta().Notice)
+
+	head, err := s.repo.Head()
+	require.NoError(t, err)
+
+	// the remote is gone, so the push is rejected
+	require.NoError(t, os.RemoveAll(remoteDir))
+
+	ctx := context.Background()
+	for _, workflow := range []WriteValueWorkflow{WriteValueWorkflow_Push, WriteValueWorkflow_Branch} {
+		res, err := s.Write(ctx, &WriteValueRequest{
+			Path:     "/a.json",
+			Body:     []byte(`{"title":"B"}`),
+			Workflow: workflow,
+			Message:  "update A",
+		})
+		require.NoError(t, err)
+		require.Equal(t, 500, res.Code, workflow)
+		require.Contains(t, res.Message, "error pushing", workflow)
+
+		// the commit is dropped and the storage keeps serving what is on the remote
+		current, err := s.repo.Head()
+		require.NoError(t, err)
+		require.Equal(t, head.Hash(), current.Hash(), workflow)
+		require.Equal(t, head.Name(), current.Name(), workflow)
+
+		file, err := s.Store().Get(ctx, "/a.json")
+		require.NoError(t, err)
+		require.Equal(t, `{"title":"A"}`, string(file.Contents), workflow)
+
+		if workflow == WriteValueWorkflow_Branch {
+			_, err = s.repo.Reference(plumbing.NewBranchReferenceName(res.Branch), true)
+			require.ErrorIs(t, err, plumbing.ErrReferenceNotFound)
+		}
+	}
+	require.Empty(t, s.pending)
+}
+
+func TestGitStoragePendingChangeWithMissingCommit(t *testing.T) {
+	_, remoteDir := setupGitRemote(t)
+
+	s := newGitStorage(RootStorageMeta{}, RootStorageConfig{
+		Prefix: "git",
+		Git: &StorageGitConfig{
+			Remote: remoteDir,
+			Branch: "master",
+		},
+	}, filepath.Join(t.TempDir(), "cache"))
+	require.True(t, s.Meta().Ready, s.Meta().Notice)
+
+	done, merged, err := s.isChangeDone(context.Background(), &pendingChange{
+		Branch: "grafana_ui_1",
+		Hash:   "0123456789012345678901234567890123456789",
+	})
+	require.Error(t, err)
+	require.False(t, done)
+	require.False(t, merged)
+}
+
+func TestGitStorageRequirePullRequest(t *testing.T) {
+	_, remoteDir := setupGitRemote(t)
+
+	s := newGitStorage(RootStorageMeta{}, RootStorageConfig{
+		Prefix: "git",
+		Git: &StorageGitConfig{
+			Remote:             remoteDir,
+			Branch:             "master",
+			RequirePullRequest: true,
+		},
+	}, filepath.Join(t.TempDir(), "cache"))
+	require.True(t, s.Meta().Ready, s.Meta().Notice)
+
+	res, err := s.Write(context.Background(), &WriteValueRequest{
+		Path:     "/dashboards/a.json",
+		Body:     []byte(`{"title":"B"}`),
+		Workflow: WriteValueWorkflow_Push,
+	})
+	require.NoError(t, err)
+	require.Equal(t, 400, res.Code)
+
+	// pull requests need the GitHub API
+	res, err = s.Write(context.Background(), &WriteValueRequest{
+		Path:     "/dashboards/a.json",
+		Body:     []byte(`{"title":"B"}`),
+		Workflow: WriteValueWorkflow_PR,
+	})
+	require.NoError(t, err)
+	require.Equal(t, 400, res.Code)
+}
+
+func TestGitStorageRejectsPathsOutsideTheRoot(t *testing.T) {
+	_, remoteDir := setupGitRemote(t)
+
+	s := newGitStorage(RootStorageMeta{}, RootStorageConfig{
+		Prefix: "git",
+		Git: &StorageGitConfig{
+			Remote: remoteDir,
+			Branch: "master",
+			Root:   "dashboards",
+		},
+	}, filepath.Join(t.TempDir(), "cache"))
+	require.True(t, s.Meta().Ready, s.Meta().Notice)
+
+	for _, path := range []string{"/../../outside.json", "/a/../../../outside.json", "relative.json"} {
+		res, err := s.Write(context.Background(), &WriteValueRequest{
+			Path:     path,
+			Body:     []byte(`{}`),
+			Workflow: WriteValueWorkflow_Push,
+		})
+		require.NoError(t, err)
+		require.Equal(t, 400, res.Code, path)
+	}
+
+	w, err := s.repo.Worktree()
+	require.NoError(t, err)
+	_, err = s.commitFile(w, &WriteValueRequest{Path: "../outside.json", Body: []byte(`{}`)})
+	require.Error(t, err)
+	_, err = os.Stat(filepath.Join(filepath.Dir(w.Filesystem.Root()), "outside.json"))
+	require.True(t, os.IsNotExist(err))
+}
+
+func TestGitStorageBranchURL(t *testing.T) {
+	s := &rootStorageGit{settings: &StorageGitConfig{Remote: "https://gitea.example.com/org/dashboards.git"}}
+	require.Equal(t, "https://gitea.example.com/org/dashboards/tree/grafana_ui_1", s.getBranchURL("grafana_ui_1"))
+
+	s.settings.Remote = "git@example.com:org/dashboards.git"
+	require.Equal(t, "", s.getBranchURL("grafana_ui_1"))
+
+	github, err := newGithubHelper(context.Background(), "https://github.com/grafana/dashboards.git", "token")
+	require.NoError(t, err)
+	s.github = github
+	require.Equal(t, "https://github.com/grafana/dashboards/tree/grafana_ui_1", s.getBranchURL("grafana_ui_1"))
+
+	require.True(t, isGithubRemote("https://github.com/grafana/dashboards.git"))
+	require.False(t, isGithubRemote("https://gitlab.com/grafana/dashboards.git"))
+}
@@ -1,10 +1,16 @@
 package store
 
 import (
+	"context"
 	"fmt"
 	"testing"
+	"time"
 
+	"github.com/grafana/grafana/pkg/infra/filestorage"
+	"github.com/grafana/grafana/pkg/services/quota/quotatest"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestGetDbStoragePathPrefix(t *testing.T) {
@@ -25,3 +31,79 @@ func TestGetDbStoragePathPrefix(t *testing.T) {
 		})
 	}
 }
+
+func setupVersionedStore(t *testing.T, versioned bool, authService storageAuthService) StorageService {
+	t.Helper()
+
+	sql := sqlstore.InitTestDB(t)
+	sqlStorage := newSQLStorage(
+		RootStorageMeta{},
+		RootResources, "Resources", "dummy descr",
+		&StorageSQLConfig{Versioned: versioned},
+		sql,
+		1, // orgID (prefix init)
+	)
+
+	store := newStandardStorageService(sql, []storageRuntime{sqlStorage}, func(orgId int64) []storageRuntime {
+		return make([]storageRuntime, 0)
+	}, authService, cfg)
+	store.cfg = &GlobalStorageConfig{}
+	store.quotaService = quotatest.NewQuotaServiceFake()
+	return store
+}
+
+func TestIntegrationSQLStorageVersions(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	t.Run("versions can be listed, read and restored", func(t *testing.T) {
+		ctx := context.Background()
+		service := setupVersionedStore(t, true, allowAllAuthService)
+
+		path := RootResources + "/images/logo.jpg"
+		updatedJpgBytes := append(append([]byte{}, jpgBytes...), 0)
+		for _, contents := range [][]byte{jpgBytes, updatedJpgBytes} {
+			require.NoError(t, service.Upload(ctx, dummyUser, &UploadRequest{
+				EntityType:            EntityTypeImage,
+				Contents:              contents,
+				Path:                  path,
+				OverwriteExistingFile: true,
+			}))
+		}
+
+		versions, err := service.ListVersions(ctx, dummyUser, path)
+		require.NoError(t, err)
+		require.Len(t, versions, 2)
+		require.Equal(t, int64(len(updatedJpgBytes)), versions[0].Size)
+
+		file, err := service.ReadVersion(ctx, dummyUser, path, 1)
+		require.NoError(t, err)
+		require.Equal(t, jpgBytes, file.Contents)
+		require.Equal(t, "/images/logo.jpg", file.FullPath)
+
+		require.NoError(t, service.RestoreVersion(ctx, dummyUser, path, 1))
+		file, err = service.Read(ctx, dummyUser, path)
+		require.NoError(t, err)
+		require.Equal(t, jpgBytes, file.Contents)
+
+		require.ErrorIs(t, service.RestoreVersion(ctx, dummyUser, path, 5), filestorage.ErrVersionNotFound)
+
+		deleted, err := service.DeleteStaleVersions(ctx, time.Now().Add(time.Hour), 1)
+		require.NoError(t, err)
+		require.Equal(t, int64(2), deleted)
+	})
+
+	t.Run("versions are not available when versioning is disabled", func(t *testing.T) {
+		service := setupVersionedStore(t, false, allowAllAuthService)
+		_, err := service.ListVersions(context.Background(), dummyUser, RootResources+"/logo.jpg")
+		require.ErrorIs(t, err, filestorage.ErrVersioningNotSupported)
+	})
+
+	t.Run("versions require access to the file", func(t *testing.T) {
+		service := setupVersionedStore(t, true, denyAllAuthService)
+		_, err := service.ListVersions(context.Background(), dummyUser, RootResources+"/logo.jpg")
+		require.ErrorIs(t, err, ErrAccessDenied)
+		require.ErrorIs(t, service.RestoreVersion(context.Background(), dummyUser, RootResources+"/logo.jpg", 1), ErrAccessDenied)
+	})
+}
@@ -41,6 +41,18 @@ func (t *nestedTree) init() {
 	}
 }
 
+// roots returns the storages of all initialized organizations
+func (t *nestedTree) roots() []storageRuntime {
+	t.orgInitMutex.Lock()
+	defer t.orgInitMutex.Unlock()
+
+	var roots []storageRuntime
+	for _, storages := range t.rootsByOrgId {
+		roots = append(roots, storages...)
+	}
+	return roots
+}
+
 func (t *nestedTree) assureOrgIsInitialized(orgId int64) {
 	t.orgInitMutex.Lock()
 	defer t.orgInitMutex.Unlock()
@@ -61,12 +73,15 @@ func (t *nestedTree) getRoot(orgId int64, path string) (storageRuntime, string)
 	rootKey, path := splitFirstSegment(path)
 	root, ok := t.lookup[orgId][rootKey]
 	if ok && root != nil {
+		if root.Store() == nil {
+			return nil, path // registered, but failed to initialize
+		}
 		return root, filestorage.Delimiter + path
 	}
 
 	if orgId != ac.GlobalOrgID {
 		globalRoot, ok := t.lookup[ac.GlobalOrgID][rootKey]
-		if ok && globalRoot != nil {
+		if ok && globalRoot != nil && globalRoot.Store() != nil {
 			return globalRoot, filestorage.Delimiter + path
 		}
 	}
@@ -15,6 +15,9 @@ var (
 	WriteValueWorkflow_Save WriteValueWorkflow = "save" // or empty
 	WriteValueWorkflow_PR   WriteValueWorkflow = "pr"
 	WriteValueWorkflow_Push WriteValueWorkflow = "push"
+
+	// Push a new branch without opening a pull request, used with remotes other than GitHub
+	WriteValueWorkflow_Branch WriteValueWorkflow = "branch"
 )
 
 type WriteValueRequest struct {
@@ -24,7 +27,7 @@ type WriteValueRequest struct {
 	Body       json.RawMessage    `json:"body,omitempty"`
 	Message    string             `json:"message,omitempty"`
 	Title      string             `json:"title,omitempty"`    // For PRs
-	Workflow   WriteValueWorkflow `json:"workflow,omitempty"` // save | pr | push
+	Workflow   WriteValueWorkflow `json:"workflow,omitempty"` // save | pr | push | branch
 }
 
 type WriteValueResponse struct {
@@ -57,6 +60,11 @@ type storageRuntime interface {
 	Write(ctx context.Context, cmd *WriteValueRequest) (*WriteValueResponse, error)
 }
 
+// storageRunner is implemented by storage runtimes doing background work
+type storageRunner interface {
+	Run(ctx context.Context) error
+}
+
 type RootStorageMeta struct {
 	ReadOnly bool          `json:"editable,omitempty"`
 	Builtin  bool          `json:"builtin,omitempty"`
@@ -377,6 +377,8 @@ type Cfg struct {
 
 	// Data sources
 	DataSourceLimit int
+	// Directories the SQLite data source may open database files from
+	SQLiteDataSourcePaths []string
 
 	// Snapshots
 	SnapshotPublicMode bool
@@ -425,9 +427,39 @@ type Cfg struct {
 	LiveHAEngine string
 	// LiveHAEngineAddress is a connection address for Live HA engine.
 	LiveHAEngineAddress string
+	// LiveHAEnginePeers are root URLs of Grafana instances used by "http" Live HA engine.
+	LiveHAEnginePeers []string
 	// LiveAllowedOrigins is a set of origins accepted by Live. If not provided
 	// then Live uses AppURL as the only allowed origin.
 	LiveAllowedOrigins []string
+	// LiveHistoryMaxFrames is a maximum number of frames kept per managed
+	// stream channel and replayed to new subscribers. 0 means no limit.
+	LiveHistoryMaxFrames int
+	// LiveHistoryMaxAge is a maximum age of frames kept per managed stream
+	// channel. 0 means no limit. History is disabled when both limits are 0.
+	LiveHistoryMaxAge time.Duration
+	// LiveOrgPublishRateLimit is a maximum number of publications per second
+	// into all channels of an organization. 0 means no limit.
+	LiveOrgPublishRateLimit float64
+	// LiveOrgPublishBurst allows short bursts above LiveOrgPublishRateLimit.
+	LiveOrgPublishBurst int
+	// LiveChannelPublishRateLimit is a maximum number of publications per second
+	// into a single channel. 0 means no limit.
+	LiveChannelPublishRateLimit float64
+	// LiveChannelPublishBurst allows short bursts above LiveChannelPublishRateLimit.
+	LiveChannelPublishBurst int
+	// LiveMaxPublishSize is a maximum size of publication in bytes. 0 means no limit.
+	LiveMaxPublishSize int
+	// LiveMaxChannelSubscribers is a maximum number of subscribers of a channel
+	// on a Grafana server. 0 means no limit.
+	LiveMaxChannelSubscribers int
+	// LiveRemoteWriteBufferMaxSize is a maximum size in bytes of on-disk buffer
+	// kept for each Live pipeline remote write config. 0, the default, disables
+	// the buffer.
+	LiveRemoteWriteBufferMaxSize int64
+	// LiveRemoteWriteBufferMaxAge is a maximum age of data in remote write
+	// buffer. 0 means no age limit.
+	LiveRemoteWriteBufferMaxAge time.Duration
 
 	// Grafana.com URL
 	GrafanaComURL string
@@ -446,6 +478,9 @@ type Cfg struct {
 
 	Storage StorageSettings
 
+	// Dashboard usage tracking
+	DashboardUsage DashboardUsageSettings
+
 	// Access Control
 	RBACEnabled         bool
 	RBACPermissionCache bool
@@ -1018,6 +1053,7 @@ func (cfg *Cfg) Load(args CommandLineArgs) error {
 
 	cfg.DashboardPreviews = readDashboardPreviewsSettings(iniFile)
 	cfg.Storage = readStorageSettings(iniFile)
+	cfg.DashboardUsage = readDashboardUsageSettings(iniFile)
 
 	if VerifyEmailEnabled && !cfg.Smtp.Enabled {
 		cfg.Logger.Warn("require_email_validation is enabled but smtp is disabled")
@@ -1544,6 +1580,14 @@ func (cfg *Cfg) GetContentDeliveryURL(prefix string) string {
 func (cfg *Cfg) readDataSourcesSettings() {
 	datasources := cfg.Raw.Section("datasources")
 	cfg.DataSourceLimit = datasources.Key("datasource_limit").MustInt(5000)
+
+	cfg.SQLiteDataSourcePaths = make([]string, 0)
+	for _, p := range util.SplitString(datasources.Key("sqlite_allowed_paths").MustString("")) {
+		cfg.SQLiteDataSourcePaths = append(cfg.SQLiteDataSourcePaths, makeAbsolute(p, HomePath))
+	}
+	if len(cfg.SQLiteDataSourcePaths) == 0 {
+		cfg.SQLiteDataSourcePaths = append(cfg.SQLiteDataSourcePaths, filepath.Join(cfg.DataPath, "sqlite"))
+	}
 }
 
 func GetAllowedOriginGlobs(originPatterns []string) ([]glob.Glob, error) {
@@ -1567,11 +1611,57 @@ func (cfg *Cfg) readLiveSettings(iniFile *ini.File) error {
 	}
 	cfg.LiveHAEngine = section.Key("ha_engine").MustString("")
 	switch cfg.LiveHAEngine {
-	case "", "redis":
+	case "", "redis", "sql", "http":
 	default:
 		return fmt.Errorf("unsupported live HA engine type: %s", cfg.LiveHAEngine)
 	}
 	cfg.LiveHAEngineAddress = section.Key("ha_engine_address").MustString("127.0.0.1:6379")
+	if cfg.LiveHAEngine == "http" {
+		cfg.LiveHAEnginePeers = nil
+		for _, peer := range strings.Split(cfg.LiveHAEngineAddress, ",") {
+			peer = strings.TrimSpace(peer)
+			if peer == "" {
+				continue
+			}
+			u, err := url.Parse(peer)
+			if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+				return fmt.Errorf("invalid [live] ha_engine_address peer URL for http engine: %s", peer)
+			}
+			cfg.LiveHAEnginePeers = append(cfg.LiveHAEnginePeers, peer)
+		}
+		if len(cfg.LiveHAEnginePeers) == 0 {
+			return errors.New("[live] ha_engine_address must contain peer URLs for http engine")
+		}
+	}
+
+	cfg.LiveHistoryMaxFrames = section.Key("history_max_frames").MustInt(0)
+	if cfg.LiveHistoryMaxFrames < 0 {
+		return fmt.Errorf("unexpected value %d for [live] history_max_frames", cfg.LiveHistoryMaxFrames)
+	}
+	cfg.LiveHistoryMaxAge = section.Key("history_max_age").MustDuration(0)
+	if cfg.LiveHistoryMaxAge < 0 {
+		return fmt.Errorf("unexpected value %s for [live] history_max_age", cfg.LiveHistoryMaxAge)
+	}
+
+	cfg.LiveOrgPublishRateLimit = section.Key("org_publish_rate_limit").MustFloat64(0)
+	cfg.LiveOrgPublishBurst = section.Key("org_publish_burst").MustInt(0)
+	cfg.LiveChannelPublishRateLimit = section.Key("channel_publish_rate_limit").MustFloat64(0)
+	cfg.LiveChannelPublishBurst = section.Key("channel_publish_burst").MustInt(0)
+	cfg.LiveMaxPublishSize = section.Key("max_publish_size").MustInt(0)
+	cfg.LiveMaxChannelSubscribers = section.Key("max_channel_subscribers").MustInt(0)
+	if cfg.LiveOrgPublishRateLimit < 0 || cfg.LiveOrgPublishBurst < 0 || cfg.LiveChannelPublishRateLimit < 0 ||
+		cfg.LiveChannelPublishBurst < 0 || cfg.LiveMaxPublishSize < 0 || cfg.LiveMaxChannelSubscribers < 0 {
+		return errors.New("[live] publish limits must not be negative")
+	}
+
+	cfg.LiveRemoteWriteBufferMaxSize = section.Key("remote_write_buffer_max_size").MustInt64(0)
+	if cfg.LiveRemoteWriteBufferMaxSize < 0 {
+		return fmt.Errorf("unexpected value %d for [live] remote_write_buffer_max_size", cfg.LiveRemoteWriteBufferMaxSize)
+	}
+	cfg.LiveRemoteWriteBufferMaxAge = section.Key("remote_write_buffer_max_age").MustDuration(6 * time.Hour)
+	if cfg.LiveRemoteWriteBufferMaxAge < 0 {
+		return fmt.Errorf("unexpected value %s for [live] remote_write_buffer_max_age", cfg.LiveRemoteWriteBufferMaxAge)
+	}
 
 	var originPatterns []string
 	allowedOrigins := section.Key("allowed_origins").MustString("")
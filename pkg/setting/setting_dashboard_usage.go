@@ -0,0 +1,22 @@
+package setting
+
+import (
+	"time"
+
+	"gopkg.in/ini.v1"
+)
+
+type DashboardUsageSettings struct {
+	Enabled       bool
+	FlushInterval time.Duration
+	RetentionDays int
+}
+
+func readDashboardUsageSettings(iniFile *ini.File) DashboardUsageSettings {
+	s := DashboardUsageSettings{}
+	usageSection := iniFile.Section("dashboard_usage")
+	s.Enabled = usageSection.Key("enabled").MustBool(false)
+	s.FlushInterval = usageSection.Key("flush_interval").MustDuration(30 * time.Second)
+	s.RetentionDays = usageSection.Key("retention_days").MustInt(365)
+	return s
+}
@@ -1,16 +1,90 @@
 package setting
 
 import (
+	"sort"
+	"strings"
+
 	"gopkg.in/ini.v1"
 )
 
+const storageBlobSectionPrefix = "storage.blob."
+
 type StorageSettings struct {
 	AllowUnsanitizedSvgUpload bool
+
+	// Keep the history of files written to the SQL storage roots
+	VersionsEnabled bool
+	// Versions older than this are deleted by the cleanup service, 0 keeps them forever
+	VersionsRetentionDays int
+	// Number of most recent versions of each file which are kept regardless of their age
+	VersionsKeepLatest int
+
+	// Object storage roots configured in [storage.blob.<prefix>] sections
+	BlobRoots []StorageBlobRootSettings
+}
+
+// StorageBlobRootSettings configures a storage root backed by an object storage bucket.
+// Credentials support the $__env{} and $__file{} variable expanders, so they can be read
+// from environment variables or mounted secrets instead of being stored in the ini file.
+type StorageBlobRootSettings struct {
+	Prefix      string
+	Name        string
+	Description string
+
+	// Bucket URL, for example s3://bucket?region=us-east-1, gs://bucket or azblob://container
+	URL string
+	// Folder within the bucket used as the root of the storage
+	Folder   string
+	ReadOnly bool
+
+	// S3
+	AccessKey string
+	SecretKey string
+	// GCS
+	CredentialsFile string
+	CredentialsJSON string
+	// Azure blob storage
+	AccountName string
+	AccountKey  string
 }
 
 func readStorageSettings(iniFile *ini.File) StorageSettings {
 	s := StorageSettings{}
 	storageSection := iniFile.Section("storage")
 	s.AllowUnsanitizedSvgUpload = storageSection.Key("allow_unsanitized_svg_upload").MustBool(false)
+	s.VersionsEnabled = storageSection.Key("versions_enabled").MustBool(false)
+	s.VersionsRetentionDays = storageSection.Key("versions_retention_days").MustInt(90)
+	s.VersionsKeepLatest = storageSection.Key("versions_keep_latest").MustInt(10)
+	s.BlobRoots = readStorageBlobRoots(iniFile.Sections())
 	return s
 }
+
+func readStorageBlobRoots(sections []*ini.Section) []StorageBlobRootSettings {
+	roots := make([]StorageBlobRootSettings, 0)
+	for _, section := range sections {
+		if !strings.HasPrefix(section.Name(), storageBlobSectionPrefix) {
+			continue
+		}
+
+		prefix := strings.TrimPrefix(section.Name(), storageBlobSectionPrefix)
+		roots = append(roots, StorageBlobRootSettings{
+			Prefix:          prefix,
+			Name:            section.Key("name").MustString(prefix),
+			Description:     section.Key("description").String(),
+			URL:             section.Key("url").String(),
+			Folder:          section.Key("folder").String(),
+			ReadOnly:        section.Key("read_only").MustBool(true),
+			AccessKey:       section.Key("access_key").String(),
+			SecretKey:       section.Key("secret_key").String(),
+			CredentialsFile: section.Key("credentials_file").String(),
+			CredentialsJSON: section.Key("credentials_json").String(),
+			AccountName:     section.Key("account_name").String(),
+			AccountKey:      section.Key("account_key").String(),
+		})
+	}
+
+	sort.Slice(roots, func(i, j int) bool {
+		return roots[i].Prefix < roots[j].Prefix
+	})
+	return roots
+}
@@ -0,0 +1,70 @@
+package setting
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"gopkg.in/ini.v1"
+)
+
+func TestReadStorageBlobRoots(t *testing.T) {
+	iniFile, err := ini.Load([]byte(`
+[storage]
+allow_unsanitized_svg_upload = true
+
+[storage.blob.dashboards]
+url = s3://dashboards?region=us-east-1
+folder = grafana
+read_only = false
+access_key = key
+secret_key = secret
+
+[storage.blob.branding]
+name = Branding images
+url = gs://branding
+`))
+	require.NoError(t, err)
+
+	s := readStorageSettings(iniFile)
+	require.True(t, s.AllowUnsanitizedSvgUpload)
+	require.Equal(t, []StorageBlobRootSettings{
+		{
+			Prefix:   "branding",
+			Name:     "Branding images",
+			URL:      "gs://branding",
+			ReadOnly: true,
+		},
+		{
+			Prefix:    "dashboards",
+			Name:      "dashboards",
+			URL:       "s3://dashboards?region=us-east-1",
+			Folder:    "grafana",
+			ReadOnly:  false,
+			AccessKey: "key",
+			SecretKey: "secret",
+		},
+	}, s.BlobRoots)
+}
+
+func TestReadStorageVersionSettings(t *testing.T) {
+	iniFile, err := ini.Load([]byte(``))
+	require.NoError(t, err)
+
+	s := readStorageSettings(iniFile)
+	require.False(t, s.VersionsEnabled)
+	require.Equal(t, 90, s.VersionsRetentionDays)
+	require.Equal(t, 10, s.VersionsKeepLatest)
+
+	iniFile, err = ini.Load([]byte(`
+[storage]
+versions_enabled = true
+versions_retention_days = 0
+versions_keep_latest = 3
+`))
+	require.NoError(t, err)
+
+	s = readStorageSettings(iniFile)
+	require.True(t, s.VersionsEnabled)
+	require.Equal(t, 0, s.VersionsRetentionDays)
+	require.Equal(t, 3, s.VersionsKeepLatest)
+}
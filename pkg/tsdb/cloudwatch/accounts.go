@@ -0,0 +1,80 @@
+package cloudwatch
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/arn"
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+)
+
+// allAccounts is the account ID used by queries and lookups that span the monitoring account and all its
+// source accounts.
+const allAccounts = "all"
+
+// handleGetAccounts returns the monitoring account and the source accounts linked to it through CloudWatch
+// cross-account observability. An empty list is returned if the account is not a monitoring account.
+func (e *cloudWatchExecutor) handleGetAccounts(pluginCtx backend.PluginContext, parameters url.Values) ([]suggestData, error) {
+	region := parameters.Get("region")
+
+	client, err := e.getOAMClient(pluginCtx, region)
+	if err != nil {
+		return nil, err
+	}
+
+	var sinks []*oamSink
+	err = client.ListSinksPages(&oamListSinksInput{}, func(page *oamListSinksOutput, lastPage bool) bool {
+		sinks = append(sinks, page.Items...)
+		return true
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%v: %w", "unable to list the monitoring account sinks", err)
+	}
+
+	result := make([]suggestData, 0)
+	linked := make([]suggestData, 0)
+	dupCheck := make(map[string]bool)
+	for _, sink := range sinks {
+		if accountID, ok := arnAccountID(sink.Arn); ok && !dupCheck[accountID] {
+			dupCheck[accountID] = true
+			result = append(result, suggestData{Text: accountID, Value: accountID, Label: fmt.Sprintf("%s (monitoring account)", accountID)})
+		}
+
+		input := &oamListAttachedLinksInput{SinkIdentifier: sink.Arn}
+		err := client.ListAttachedLinksPages(input, func(page *oamListAttachedLinksOutput, lastPage bool) bool {
+			for _, link := range page.Items {
+				accountID, ok := arnAccountID(link.ResourceArn)
+				if !ok || dupCheck[accountID] {
+					continue
+				}
+				dupCheck[accountID] = true
+
+				label := aws.StringValue(link.Label)
+				if label == "" {
+					label = accountID
+				}
+				linked = append(linked, suggestData{Text: label, Value: accountID, Label: label})
+			}
+			return true
+		})
+		if err != nil {
+			return nil, fmt.Errorf("%v: %w", "unable to list the linked accounts", err)
+		}
+	}
+
+	sort.Slice(linked, func(i, j int) bool {
+		return linked[i].Label < linked[j].Label
+	})
+
+	return append(result, linked...), nil
+}
+
+func arnAccountID(value *string) (string, bool) {
+	parsed, err := arn.Parse(aws.StringValue(value))
+	if err != nil || parsed.AccountID == "" {
+		return "", false
+	}
+	return parsed.AccountID, true
+}
@@ -0,0 +1,118 @@
+package cloudwatch
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/client"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/cloudwatch"
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/datasource"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/instancemgmt"
+	"github.com/grafana/grafana/pkg/services/featuremgmt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQuery_Accounts(t *testing.T) {
+	origNewOAMClient := newOAMClient
+	t.Cleanup(func() {
+		newOAMClient = origNewOAMClient
+	})
+
+	var oamClient fakeOAMClient
+	newOAMClient = func(provider client.ConfigProvider) oamAPI {
+		return &oamClient
+	}
+
+	im := datasource.NewInstanceManager(func(s backend.DataSourceInstanceSettings) (instancemgmt.Instance, error) {
+		return datasourceInfo{}, nil
+	})
+	pluginCtx := backend.PluginContext{DataSourceInstanceSettings: &backend.DataSourceInstanceSettings{}}
+
+	t.Run("should return the monitoring account first followed by the linked accounts sorted by label", func(t *testing.T) {
+		sinkArn := "arn:aws:oam:us-east-1:111111111111:sink/abc"
+		oamClient = fakeOAMClient{
+			sinks: []*oamSink{{Arn: aws.String(sinkArn)}},
+			links: map[string][]*oamAttachedLink{
+				sinkArn: {
+					{Label: aws.String("prod"), ResourceArn: aws.String("arn:aws:oam:us-east-1:333333333333:link/def")},
+					{Label: aws.String("dev"), ResourceArn: aws.String("arn:aws:oam:us-east-1:222222222222:link/ghi")},
+					{Label: aws.String("prod copy"), ResourceArn: aws.String("arn:aws:oam:us-east-1:333333333333:link/jkl")},
+					{ResourceArn: aws.String("arn:aws:oam:us-east-1:444444444444:link/mno")},
+				},
+			},
+		}
+
+		executor := newExecutor(im, newTestConfig(), &fakeSessionCache{}, featuremgmt.WithFeatures())
+		resp, err := executor.handleGetAccounts(pluginCtx, url.Values{"region": []string{"us-east-1"}})
+		require.NoError(t, err)
+
+		assert.Equal(t, []suggestData{
+			{Text: "111111111111", Value: "111111111111", Label: "111111111111 (monitoring account)"},
+			{Text: "444444444444", Value: "444444444444", Label: "444444444444"},
+			{Text: "dev", Value: "222222222222", Label: "dev"},
+			{Text: "prod", Value: "333333333333", Label: "prod"},
+		}, resp)
+		require.Len(t, oamClient.callsListAttachedLinks, 1)
+		assert.Equal(t, sinkArn, *oamClient.callsListAttachedLinks[0].SinkIdentifier)
+	})
+
+	t.Run("should return an empty list when the account is not a monitoring account", func(t *testing.T) {
+		oamClient = fakeOAMClient{}
+
+		executor := newExecutor(im, newTestConfig(), &fakeSessionCache{}, featuremgmt.WithFeatures())
+		resp, err := executor.handleGetAccounts(pluginCtx, url.Values{"region": []string{"us-east-1"}})
+		require.NoError(t, err)
+
+		assert.Empty(t, resp)
+		assert.NotNil(t, resp)
+	})
+
+	t.Run("should return an error when sinks can not be listed", func(t *testing.T) {
+		oamClient = fakeOAMClient{listSinksErr: fmt.Errorf("access denied")}
+
+		executor := newExecutor(im, newTestConfig(), &fakeSessionCache{}, featuremgmt.WithFeatures())
+		_, err := executor.handleGetAccounts(pluginCtx, url.Values{"region": []string{"us-east-1"}})
+
+		assert.EqualError(t, err, "unable to list the monitoring account sinks: access denied")
+	})
+}
+
+func TestWithLinkedAccounts(t *testing.T) {
+	sess := session.Must(session.NewSession(&aws.Config{
+		Region:      aws.String("us-east-1"),
+		Credentials: credentials.NewStaticCredentials("id", "secret", ""),
+	}))
+
+	testCases := map[string]struct {
+		accountID string
+		expected  string
+	}{
+		"single account": {
+			accountID: "123456789012",
+			expected:  "Action=ListMetrics&Namespace=AWS%2FEC2&Version=2010-08-01&IncludeLinkedAccounts=true&OwningAccount=123456789012",
+		},
+		"all accounts": {
+			accountID: allAccounts,
+			expected:  "Action=ListMetrics&Namespace=AWS%2FEC2&Version=2010-08-01&IncludeLinkedAccounts=true",
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			req, _ := cloudwatch.New(sess).ListMetricsRequest(&cloudwatch.ListMetricsInput{Namespace: aws.String("AWS/EC2")})
+			req.ApplyOptions(withLinkedAccounts(tc.accountID))
+			require.NoError(t, req.Build())
+
+			body, err := ioutil.ReadAll(req.GetBody())
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, string(body))
+		})
+	}
+}
@@ -199,7 +199,7 @@ func (e *cloudWatchExecutor) checkHealthMetrics(pluginCtx backend.PluginContext)
 		Namespace:  &namespace,
 		MetricName: &metric,
 	}
-	_, err := e.listMetrics(pluginCtx, defaultRegion, params)
+	_, err := e.listMetrics(pluginCtx, defaultRegion, params, "")
 	return err
 }
 
@@ -306,6 +306,15 @@ func (e *cloudWatchExecutor) getRGTAClient(pluginCtx backend.PluginContext, regi
 	return newRGTAClient(sess), nil
 }
 
+func (e *cloudWatchExecutor) getOAMClient(pluginCtx backend.PluginContext, region string) (oamAPI, error) {
+	sess, err := e.newSession(pluginCtx, region)
+	if err != nil {
+		return nil, err
+	}
+
+	return newOAMClient(sess), nil
+}
+
 func (e *cloudWatchExecutor) alertQuery(ctx context.Context, logsClient cloudwatchlogsiface.CloudWatchLogsAPI,
 	queryContext backend.DataQuery, model LogQueryJson) (*cloudwatchlogs.GetQueryResultsOutput, error) {
 	startQueryOutput, err := e.executeStartQuery(ctx, logsClient, model, queryContext.TimeRange)
@@ -473,3 +482,10 @@ var newEC2Client = func(provider client.ConfigProvider) ec2iface.EC2API {
 var newRGTAClient = func(provider client.ConfigProvider) resourcegroupstaggingapiiface.ResourceGroupsTaggingAPIAPI {
 	return resourcegroupstaggingapi.New(provider)
 }
+
+// OAM client factory.
+//
+// Stubbable by tests.
+var newOAMClient = func(provider client.ConfigProvider) oamAPI {
+	return newOAMClientFromConfig(provider)
+}
@@ -27,6 +27,9 @@ type cloudWatchQuery struct {
 	TimezoneUTCOffset string
 	MetricQueryType   metricQueryType
 	MetricEditorMode  metricEditorMode
+	// AccountId is the source account of the metric with cross-account observability, "all" for
+	// searches across all the linked accounts
+	AccountId string
 }
 
 func (q *cloudWatchQuery) getGMDAPIMode() gmdApiMode {
@@ -45,6 +48,11 @@ func (q *cloudWatchQuery) getGMDAPIMode() gmdApiMode {
 	return GMDApiModeMetricStat
 }
 
+// hasSourceAccount tells whether the query is limited to the metrics of a single account with cross-account observability.
+func (q *cloudWatchQuery) hasSourceAccount() bool {
+	return q.AccountId != "" && q.AccountId != allAccounts
+}
+
 func (q *cloudWatchQuery) isMathExpression() bool {
 	return q.MetricQueryType == MetricQueryTypeSearch && q.MetricEditorMode == MetricEditorModeRaw && !q.isUserDefinedSearchExpression()
 }
@@ -131,6 +139,9 @@ func (q *cloudWatchQuery) buildDeepLink(startTime time.Time, endTime time.Time,
 			Stat:   q.Statistic,
 			Period: q.Period,
 		}
+		if q.hasSourceAccount() {
+			metricStatMeta.AccountId = q.AccountId
+		}
 		if dynamicLabelEnabled {
 			metricStatMeta.Label = q.Label
 		}
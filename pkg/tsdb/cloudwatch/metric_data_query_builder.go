@@ -47,6 +47,9 @@ func (e *cloudWatchExecutor) buildMetricDataQuery(query *cloudWatchQuery) (*clou
 				})
 		}
 		mdq.MetricStat.Stat = aws.String(query.Statistic)
+		if query.hasSourceAccount() {
+			mdq.AccountId = aws.String(query.AccountId)
+		}
 	}
 
 	if mdq.Expression != nil {
@@ -95,6 +98,10 @@ func buildSearchExpression(query *cloudWatchQuery, stat string) string {
 		searchTerm = appendSearch(searchTerm, keyFilter)
 	}
 
+	if query.hasSourceAccount() {
+		searchTerm = appendSearch(searchTerm, fmt.Sprintf(`:aws.AccountId="%s"`, query.AccountId))
+	}
+
 	if query.MatchExact {
 		schema := fmt.Sprintf("%q", query.Namespace)
 		if len(dimensionNames) > 0 {
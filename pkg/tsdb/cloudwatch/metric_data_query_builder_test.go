@@ -34,6 +34,42 @@ func TestMetricDataQueryBuilder(t *testing.T) {
 			assert.Equal(t, `REMOVE_EMPTY(SEARCH('Namespace="AWS/EC2" MetricName="CPUUtilization" "LoadBalancer"="lb1"', '', 300))`, *mdq.Expression)
 		})
 
+		t.Run("should set account id in metric stat when a source account is selected", func(t *testing.T) {
+			executor := newExecutor(nil, newTestConfig(), &fakeSessionCache{}, featuremgmt.WithFeatures())
+			query := getBaseQuery()
+			query.MetricEditorMode = MetricEditorModeBuilder
+			query.MetricQueryType = MetricQueryTypeSearch
+			query.AccountId = "123456789012"
+			mdq, err := executor.buildMetricDataQuery(query)
+			require.NoError(t, err)
+			require.NotNil(t, mdq.AccountId)
+			assert.Equal(t, "123456789012", *mdq.AccountId)
+		})
+
+		t.Run("should not set account id in metric stat when all accounts are selected", func(t *testing.T) {
+			executor := newExecutor(nil, newTestConfig(), &fakeSessionCache{}, featuremgmt.WithFeatures())
+			query := getBaseQuery()
+			query.MetricEditorMode = MetricEditorModeBuilder
+			query.MetricQueryType = MetricQueryTypeSearch
+			query.AccountId = "all"
+			mdq, err := executor.buildMetricDataQuery(query)
+			require.NoError(t, err)
+			assert.Nil(t, mdq.AccountId)
+		})
+
+		t.Run("should filter custom built expression by source account", func(t *testing.T) {
+			executor := newExecutor(nil, newTestConfig(), &fakeSessionCache{}, featuremgmt.WithFeatures())
+			query := getBaseQuery()
+			query.MetricEditorMode = MetricEditorModeBuilder
+			query.MetricQueryType = MetricQueryTypeSearch
+			query.MatchExact = false
+			query.AccountId = "123456789012"
+			mdq, err := executor.buildMetricDataQuery(query)
+			require.NoError(t, err)
+			assert.Nil(t, mdq.AccountId)
+			assert.Equal(t, `REMOVE_EMPTY(SEARCH('Namespace="AWS/EC2" MetricName="CPUUtilization" "LoadBalancer"="lb1" :aws.AccountId="123456789012"', '', 300))`, *mdq.Expression)
+		})
+
 		t.Run("should use sql expression", func(t *testing.T) {
 			executor := newExecutor(nil, newTestConfig(), &fakeSessionCache{}, featuremgmt.WithFeatures())
 			query := getBaseQuery()
@@ -13,6 +13,7 @@ import (
 
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/awsutil"
+	"github.com/aws/aws-sdk-go/aws/request"
 	"github.com/aws/aws-sdk-go/service/cloudwatch"
 	"github.com/aws/aws-sdk-go/service/ec2"
 	"github.com/aws/aws-sdk-go/service/resourcegroupstaggingapi"
@@ -129,6 +130,7 @@ func (e *cloudWatchExecutor) handleGetNamespaces(pluginCtx backend.PluginContext
 func (e *cloudWatchExecutor) handleGetMetrics(pluginCtx backend.PluginContext, parameters url.Values) ([]suggestData, error) {
 	region := parameters.Get("region")
 	namespace := parameters.Get("namespace")
+	accountID := parameters.Get("accountId")
 
 	var namespaceMetrics []string
 	if !isCustomMetrics(namespace) {
@@ -138,7 +140,7 @@ func (e *cloudWatchExecutor) handleGetMetrics(pluginCtx backend.PluginContext, p
 		}
 	} else {
 		var err error
-		if namespaceMetrics, err = e.getMetricsForCustomMetrics(region, namespace, pluginCtx); err != nil {
+		if namespaceMetrics, err = e.getMetricsForCustomMetrics(region, namespace, accountID, pluginCtx); err != nil {
 			return nil, fmt.Errorf("%v: %w", "unable to call AWS API", err)
 		}
 	}
@@ -172,6 +174,7 @@ func (e *cloudWatchExecutor) handleGetDimensionKeys(pluginCtx backend.PluginCont
 	namespace := parameters.Get("namespace")
 	metricName := parameters.Get("metricName")
 	dimensionFilterJson := parameters.Get("dimensionFilters")
+	accountID := parameters.Get("accountId")
 
 	dimensionFilters := map[string]interface{}{}
 	if dimensionFilterJson != "" {
@@ -218,7 +221,7 @@ func (e *cloudWatchExecutor) handleGetDimensionKeys(pluginCtx backend.PluginCont
 			}
 
 			metrics, err := e.listMetrics(pluginCtx,
-				region, input)
+				region, input, accountID)
 
 			if err != nil {
 				return nil, fmt.Errorf("%v: %w", "unable to call AWS API", err)
@@ -248,7 +251,7 @@ func (e *cloudWatchExecutor) handleGetDimensionKeys(pluginCtx backend.PluginCont
 		}
 	} else {
 		var err error
-		if dimensionValues, err = e.getDimensionsForCustomMetrics(region, namespace, pluginCtx); err != nil {
+		if dimensionValues, err = e.getDimensionsForCustomMetrics(region, namespace, accountID, pluginCtx); err != nil {
 			return nil, fmt.Errorf("%v: %w", "unable to call AWS API", err)
 		}
 	}
@@ -270,6 +273,7 @@ func (e *cloudWatchExecutor) handleGetDimensionValues(pluginCtx backend.PluginCo
 	metricName := parameters.Get("metricName")
 	dimensionKey := parameters.Get("dimensionKey")
 	dimensionsJson := parameters.Get("dimensions")
+	accountID := parameters.Get("accountId")
 
 	dimensionsValues := map[string]interface{}{}
 	err := json.Unmarshal([]byte(dimensionsJson), &dimensionsValues)
@@ -308,7 +312,7 @@ func (e *cloudWatchExecutor) handleGetDimensionValues(pluginCtx backend.PluginCo
 	if metricName != "" {
 		params.MetricName = aws.String(metricName)
 	}
-	metrics, err := e.listMetrics(pluginCtx, region, params)
+	metrics, err := e.listMetrics(pluginCtx, region, params, accountID)
 	if err != nil {
 		return nil, err
 	}
@@ -486,17 +490,24 @@ func (e *cloudWatchExecutor) handleGetResourceArns(pluginCtx backend.PluginConte
 	return result, nil
 }
 
-func (e *cloudWatchExecutor) listMetrics(pluginCtx backend.PluginContext, region string, params *cloudwatch.ListMetricsInput) ([]*cloudwatch.Metric, error) {
+// listMetrics lists the metrics matching params. With an accountID, the metrics of the linked source accounts are
+// included, all of them if accountID is "all" or only those of the given account otherwise.
+func (e *cloudWatchExecutor) listMetrics(pluginCtx backend.PluginContext, region string, params *cloudwatch.ListMetricsInput, accountID string) ([]*cloudwatch.Metric, error) {
 	client, err := e.getCWClient(pluginCtx, region)
 	if err != nil {
 		return nil, err
 	}
 
-	plog.Debug("Listing metrics pages")
+	var opts []request.Option
+	if accountID != "" {
+		opts = append(opts, withLinkedAccounts(accountID))
+	}
+
+	plog.Debug("Listing metrics pages", "accountId", accountID)
 	var cloudWatchMetrics []*cloudwatch.Metric
 
 	pageNum := 0
-	err = client.ListMetricsPages(params, func(page *cloudwatch.ListMetricsOutput, lastPage bool) bool {
+	err = client.ListMetricsPagesWithContext(aws.BackgroundContext(), params, func(page *cloudwatch.ListMetricsOutput, lastPage bool) bool {
 		pageNum++
 		metrics.MAwsCloudWatchListMetrics.Inc()
 		metrics, err := awsutil.ValuesAtPath(page, "Metrics")
@@ -506,7 +517,7 @@ func (e *cloudWatchExecutor) listMetrics(pluginCtx backend.PluginContext, region
 			}
 		}
 		return !lastPage && pageNum < e.cfg.AWSListMetricsPageLimit
-	})
+	}, opts...)
 
 	return cloudWatchMetrics, err
 }
@@ -559,7 +570,7 @@ func (e *cloudWatchExecutor) resourceGroupsGetResources(pluginCtx backend.Plugin
 
 var metricsCacheLock sync.Mutex
 
-func (e *cloudWatchExecutor) getMetricsForCustomMetrics(region, namespace string, pluginCtx backend.PluginContext) ([]string, error) {
+func (e *cloudWatchExecutor) getMetricsForCustomMetrics(region, namespace, accountID string, pluginCtx backend.PluginContext) ([]string, error) {
 	plog.Debug("Getting metrics for custom metrics", "region", region, "namespace", namespace)
 	metricsCacheLock.Lock()
 	defer metricsCacheLock.Unlock()
@@ -569,44 +580,50 @@ func (e *cloudWatchExecutor) getMetricsForCustomMetrics(region, namespace string
 		return nil, err
 	}
 
+	// metrics of linked accounts are cached separately
+	cacheKey := namespace
+	if accountID != "" {
+		cacheKey = namespace + "/" + accountID
+	}
+
 	if _, ok := customMetricsMetricsMap[dsInfo.profile]; !ok {
 		customMetricsMetricsMap[dsInfo.profile] = make(map[string]map[string]*customMetricsCache)
 	}
 	if _, ok := customMetricsMetricsMap[dsInfo.profile][dsInfo.region]; !ok {
 		customMetricsMetricsMap[dsInfo.profile][dsInfo.region] = make(map[string]*customMetricsCache)
 	}
-	if _, ok := customMetricsMetricsMap[dsInfo.profile][dsInfo.region][namespace]; !ok {
-		customMetricsMetricsMap[dsInfo.profile][dsInfo.region][namespace] = &customMetricsCache{}
-		customMetricsMetricsMap[dsInfo.profile][dsInfo.region][namespace].Cache = make([]string, 0)
+	if _, ok := customMetricsMetricsMap[dsInfo.profile][dsInfo.region][cacheKey]; !ok {
+		customMetricsMetricsMap[dsInfo.profile][dsInfo.region][cacheKey] = &customMetricsCache{}
+		customMetricsMetricsMap[dsInfo.profile][dsInfo.region][cacheKey].Cache = make([]string, 0)
 	}
 
-	if customMetricsMetricsMap[dsInfo.profile][dsInfo.region][namespace].Expire.After(time.Now()) {
-		return customMetricsMetricsMap[dsInfo.profile][dsInfo.region][namespace].Cache, nil
+	if customMetricsMetricsMap[dsInfo.profile][dsInfo.region][cacheKey].Expire.After(time.Now()) {
+		return customMetricsMetricsMap[dsInfo.profile][dsInfo.region][cacheKey].Cache, nil
 	}
 	metrics, err := e.listMetrics(pluginCtx, region, &cloudwatch.ListMetricsInput{
 		Namespace: aws.String(namespace),
-	})
+	}, accountID)
 	if err != nil {
 		return []string{}, err
 	}
 
-	customMetricsMetricsMap[dsInfo.profile][dsInfo.region][namespace].Cache = make([]string, 0)
-	customMetricsMetricsMap[dsInfo.profile][dsInfo.region][namespace].Expire = time.Now().Add(5 * time.Minute)
+	customMetricsMetricsMap[dsInfo.profile][dsInfo.region][cacheKey].Cache = make([]string, 0)
+	customMetricsMetricsMap[dsInfo.profile][dsInfo.region][cacheKey].Expire = time.Now().Add(5 * time.Minute)
 
 	for _, metric := range metrics {
-		if isDuplicate(customMetricsMetricsMap[dsInfo.profile][dsInfo.region][namespace].Cache, *metric.MetricName) {
+		if isDuplicate(customMetricsMetricsMap[dsInfo.profile][dsInfo.region][cacheKey].Cache, *metric.MetricName) {
 			continue
 		}
-		customMetricsMetricsMap[dsInfo.profile][dsInfo.region][namespace].Cache = append(
-			customMetricsMetricsMap[dsInfo.profile][dsInfo.region][namespace].Cache, *metric.MetricName)
+		customMetricsMetricsMap[dsInfo.profile][dsInfo.region][cacheKey].Cache = append(
+			customMetricsMetricsMap[dsInfo.profile][dsInfo.region][cacheKey].Cache, *metric.MetricName)
 	}
 
-	return customMetricsMetricsMap[dsInfo.profile][dsInfo.region][namespace].Cache, nil
+	return customMetricsMetricsMap[dsInfo.profile][dsInfo.region][cacheKey].Cache, nil
 }
 
 var dimensionsCacheLock sync.Mutex
 
-func (e *cloudWatchExecutor) getDimensionsForCustomMetrics(region, namespace string, pluginCtx backend.PluginContext) ([]string, error) {
+func (e *cloudWatchExecutor) getDimensionsForCustomMetrics(region, namespace, accountID string, pluginCtx backend.PluginContext) ([]string, error) {
 	dimensionsCacheLock.Lock()
 	defer dimensionsCacheLock.Unlock()
 
@@ -615,38 +632,44 @@ func (e *cloudWatchExecutor) getDimensionsForCustomMetrics(region, namespace str
 		return nil, err
 	}
 
+	// metrics of linked accounts are cached separately
+	cacheKey := namespace
+	if accountID != "" {
+		cacheKey = namespace + "/" + accountID
+	}
+
 	if _, ok := customMetricsDimensionsMap[dsInfo.profile]; !ok {
 		customMetricsDimensionsMap[dsInfo.profile] = make(map[string]map[string]*customMetricsCache)
 	}
 	if _, ok := customMetricsDimensionsMap[dsInfo.profile][dsInfo.region]; !ok {
 		customMetricsDimensionsMap[dsInfo.profile][dsInfo.region] = make(map[string]*customMetricsCache)
 	}
-	if _, ok := customMetricsDimensionsMap[dsInfo.profile][dsInfo.region][namespace]; !ok {
-		customMetricsDimensionsMap[dsInfo.profile][dsInfo.region][namespace] = &customMetricsCache{}
-		customMetricsDimensionsMap[dsInfo.profile][dsInfo.region][namespace].Cache = make([]string, 0)
+	if _, ok := customMetricsDimensionsMap[dsInfo.profile][dsInfo.region][cacheKey]; !ok {
+		customMetricsDimensionsMap[dsInfo.profile][dsInfo.region][cacheKey] = &customMetricsCache{}
+		customMetricsDimensionsMap[dsInfo.profile][dsInfo.region][cacheKey].Cache = make([]string, 0)
 	}
 
-	if customMetricsDimensionsMap[dsInfo.profile][dsInfo.region][namespace].Expire.After(time.Now()) {
-		return customMetricsDimensionsMap[dsInfo.profile][dsInfo.region][namespace].Cache, nil
+	if customMetricsDimensionsMap[dsInfo.profile][dsInfo.region][cacheKey].Expire.After(time.Now()) {
+		return customMetricsDimensionsMap[dsInfo.profile][dsInfo.region][cacheKey].Cache, nil
 	}
-	metrics, err := e.listMetrics(pluginCtx, region, &cloudwatch.ListMetricsInput{Namespace: aws.String(namespace)})
+	metrics, err := e.listMetrics(pluginCtx, region, &cloudwatch.ListMetricsInput{Namespace: aws.String(namespace)}, accountID)
 	if err != nil {
 		return []string{}, err
 	}
-	customMetricsDimensionsMap[dsInfo.profile][dsInfo.region][namespace].Cache = make([]string, 0)
-	customMetricsDimensionsMap[dsInfo.profile][dsInfo.region][namespace].Expire = time.Now().Add(5 * time.Minute)
+	customMetricsDimensionsMap[dsInfo.profile][dsInfo.region][cacheKey].Cache = make([]string, 0)
+	customMetricsDimensionsMap[dsInfo.profile][dsInfo.region][cacheKey].Expire = time.Now().Add(5 * time.Minute)
 
 	for _, metric := range metrics {
 		for _, dimension := range metric.Dimensions {
-			if isDuplicate(customMetricsDimensionsMap[dsInfo.profile][dsInfo.region][namespace].Cache, *dimension.Name) {
+			if isDuplicate(customMetricsDimensionsMap[dsInfo.profile][dsInfo.region][cacheKey].Cache, *dimension.Name) {
 				continue
 			}
-			customMetricsDimensionsMap[dsInfo.profile][dsInfo.region][namespace].Cache = append(
-				customMetricsDimensionsMap[dsInfo.profile][dsInfo.region][namespace].Cache, *dimension.Name)
+			customMetricsDimensionsMap[dsInfo.profile][dsInfo.region][cacheKey].Cache = append(
+				customMetricsDimensionsMap[dsInfo.profile][dsInfo.region][cacheKey].Cache, *dimension.Name)
 		}
 	}
 
-	return customMetricsDimensionsMap[dsInfo.profile][dsInfo.region][namespace].Cache, nil
+	return customMetricsDimensionsMap[dsInfo.profile][dsInfo.region][cacheKey].Cache, nil
 }
 
 func isDuplicate(nameList []string, target string) bool {
@@ -553,7 +553,7 @@ func TestQuery_ListMetricsPagination(t *testing.T) {
 			featuremgmt.WithFeatures())
 		response, err := executor.listMetrics(backend.PluginContext{
 			DataSourceInstanceSettings: &backend.DataSourceInstanceSettings{},
-		}, "default", &cloudwatch.ListMetricsInput{})
+		}, "default", &cloudwatch.ListMetricsInput{}, "")
 		require.NoError(t, err)
 
 		expectedMetrics := client.MetricsPerPage * executor.cfg.AWSListMetricsPageLimit
@@ -569,9 +569,57 @@ func TestQuery_ListMetricsPagination(t *testing.T) {
 			featuremgmt.WithFeatures())
 		response, err := executor.listMetrics(backend.PluginContext{
 			DataSourceInstanceSettings: &backend.DataSourceInstanceSettings{},
-		}, "default", &cloudwatch.ListMetricsInput{})
+		}, "default", &cloudwatch.ListMetricsInput{}, "")
 		require.NoError(t, err)
 
 		assert.Equal(t, len(metrics), len(response))
 	})
 }
+
+func TestQuery_ListMetricsLinkedAccounts(t *testing.T) {
+	origNewCWClient := NewCWClient
+	t.Cleanup(func() {
+		NewCWClient = origNewCWClient
+	})
+
+	var client fakeCWClient
+
+	NewCWClient = func(sess *session.Session) cloudwatchiface.CloudWatchAPI {
+		return &client
+	}
+
+	im := datasource.NewInstanceManager(func(s backend.DataSourceInstanceSettings) (instancemgmt.Instance, error) {
+		return datasourceInfo{}, nil
+	})
+	pluginCtx := backend.PluginContext{DataSourceInstanceSettings: &backend.DataSourceInstanceSettings{}}
+
+	t.Run("should not include linked accounts when no account is selected", func(t *testing.T) {
+		client = fakeCWClient{Metrics: []*cloudwatch.Metric{{MetricName: aws.String("Test_MetricName")}}}
+		executor := newExecutor(im, newTestConfig(), &fakeSessionCache{}, featuremgmt.WithFeatures())
+		_, err := executor.listMetrics(pluginCtx, "default", &cloudwatch.ListMetricsInput{}, "")
+		require.NoError(t, err)
+
+		require.Len(t, client.callsListMetricsOptions, 1)
+		assert.Empty(t, client.callsListMetricsOptions[0])
+	})
+
+	t.Run("should include linked accounts when looking up dimension values of an account", func(t *testing.T) {
+		client = fakeCWClient{Metrics: []*cloudwatch.Metric{{MetricName: aws.String("CPUUtilization"), Dimensions: []*cloudwatch.Dimension{
+			{Name: aws.String("InstanceId"), Value: aws.String("i-123")},
+		}}}}
+		executor := newExecutor(im, newTestConfig(), &fakeSessionCache{}, featuremgmt.WithFeatures())
+		resp, err := executor.handleGetDimensionValues(pluginCtx, url.Values{
+			"region":       []string{"us-east-1"},
+			"namespace":    []string{"AWS/EC2"},
+			"metricName":   []string{"CPUUtilization"},
+			"dimensionKey": []string{"InstanceId"},
+			"dimensions":   []string{`{}`},
+			"accountId":    []string{"123456789012"},
+		})
+		require.NoError(t, err)
+
+		assert.Equal(t, []suggestData{{Text: "i-123", Value: "i-123", Label: "i-123"}}, resp)
+		require.Len(t, client.callsListMetricsOptions, 1)
+		assert.Len(t, client.callsListMetricsOptions[0], 1)
+	})
+}
@@ -0,0 +1,163 @@
+package cloudwatch
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/url"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/client"
+	"github.com/aws/aws-sdk-go/aws/client/metadata"
+	"github.com/aws/aws-sdk-go/aws/request"
+	v4 "github.com/aws/aws-sdk-go/aws/signer/v4"
+	"github.com/aws/aws-sdk-go/private/protocol/restjson"
+)
+
+// The version of the AWS SDK used by Grafana predates CloudWatch cross-account observability, so the few
+// Observability Access Manager (OAM) operations needed to list the linked accounts are defined here.
+// See https://docs.aws.amazon.com/OAM/latest/APIReference/Welcome.html
+const (
+	oamServiceName = "oam"
+	oamAPIVersion  = "2022-06-10"
+)
+
+type oamAPI interface {
+	ListSinksPages(input *oamListSinksInput, fn func(*oamListSinksOutput, bool) bool) error
+	ListAttachedLinksPages(input *oamListAttachedLinksInput, fn func(*oamListAttachedLinksOutput, bool) bool) error
+}
+
+type oamListSinksInput struct {
+	_ struct{} `type:"structure"`
+
+	MaxResults *int64  `min:"1" type:"integer"`
+	NextToken  *string `type:"string"`
+}
+
+type oamSink struct {
+	_ struct{} `type:"structure"`
+
+	Arn  *string `type:"string"`
+	Id   *string `type:"string"`
+	Name *string `type:"string"`
+}
+
+type oamListSinksOutput struct {
+	_ struct{} `type:"structure"`
+
+	Items     []*oamSink `type:"list" required:"true"`
+	NextToken *string    `type:"string"`
+}
+
+type oamListAttachedLinksInput struct {
+	_ struct{} `type:"structure"`
+
+	MaxResults     *int64  `min:"1" type:"integer"`
+	NextToken      *string `type:"string"`
+	SinkIdentifier *string `type:"string" required:"true"`
+}
+
+type oamAttachedLink struct {
+	_ struct{} `type:"structure"`
+
+	Label       *string `type:"string"`
+	LinkArn     *string `type:"string"`
+	ResourceArn *string `type:"string"`
+}
+
+type oamListAttachedLinksOutput struct {
+	_ struct{} `type:"structure"`
+
+	Items     []*oamAttachedLink `type:"list" required:"true"`
+	NextToken *string            `type:"string"`
+}
+
+type oamClient struct {
+	*client.Client
+}
+
+func newOAMClientFromConfig(provider client.ConfigProvider) *oamClient {
+	c := provider.ClientConfig(oamServiceName)
+	if c.SigningNameDerived || len(c.SigningName) == 0 {
+		c.SigningName = oamServiceName
+	}
+
+	svc := &oamClient{
+		Client: client.New(
+			*c.Config,
+			metadata.ClientInfo{
+				ServiceName:    oamServiceName,
+				ServiceID:      "OAM",
+				SigningName:    c.SigningName,
+				SigningRegion:  c.SigningRegion,
+				PartitionID:    c.PartitionID,
+				Endpoint:       c.Endpoint,
+				APIVersion:     oamAPIVersion,
+				ResolvedRegion: c.ResolvedRegion,
+			},
+			c.Handlers,
+		),
+	}
+
+	svc.Handlers.Sign.PushBackNamed(v4.SignRequestHandler)
+	svc.Handlers.Build.PushBackNamed(restjson.BuildHandler)
+	svc.Handlers.Unmarshal.PushBackNamed(restjson.UnmarshalHandler)
+	svc.Handlers.UnmarshalMeta.PushBackNamed(restjson.UnmarshalMetaHandler)
+	svc.Handlers.UnmarshalError.PushBackNamed(restjson.UnmarshalErrorHandler)
+
+	return svc
+}
+
+func (c *oamClient) ListSinksPages(input *oamListSinksInput, fn func(*oamListSinksOutput, bool) bool) error {
+	for {
+		output := &oamListSinksOutput{}
+		req := c.NewRequest(&request.Operation{Name: "ListSinks", HTTPMethod: "POST", HTTPPath: "/ListSinks"}, input, output)
+		if err := req.Send(); err != nil {
+			return err
+		}
+		lastPage := aws.StringValue(output.NextToken) == ""
+		if !fn(output, lastPage) || lastPage {
+			return nil
+		}
+		input = &oamListSinksInput{MaxResults: input.MaxResults, NextToken: output.NextToken}
+	}
+}
+
+func (c *oamClient) ListAttachedLinksPages(input *oamListAttachedLinksInput, fn func(*oamListAttachedLinksOutput, bool) bool) error {
+	for {
+		output := &oamListAttachedLinksOutput{}
+		req := c.NewRequest(&request.Operation{Name: "ListAttachedLinks", HTTPMethod: "POST", HTTPPath: "/ListAttachedLinks"}, input, output)
+		if err := req.Send(); err != nil {
+			return err
+		}
+		lastPage := aws.StringValue(output.NextToken) == ""
+		if !fn(output, lastPage) || lastPage {
+			return nil
+		}
+		input = &oamListAttachedLinksInput{MaxResults: input.MaxResults, NextToken: output.NextToken, SinkIdentifier: input.SinkIdentifier}
+	}
+}
+
+// withLinkedAccounts adds the cross-account parameters, unknown to the AWS SDK, to a ListMetrics request.
+// Metrics of all the linked accounts are listed when accountID is "all", otherwise only the ones of the
+// given account.
+func withLinkedAccounts(accountID string) request.Option {
+	return func(r *request.Request) {
+		r.Handlers.Build.PushBack(func(r *request.Request) {
+			if r.Error != nil || r.Body == nil {
+				return
+			}
+			body, err := ioutil.ReadAll(r.Body)
+			if err != nil {
+				r.Error = err
+				return
+			}
+
+			params := url.Values{}
+			params.Set("IncludeLinkedAccounts", "true")
+			if accountID != allAccounts {
+				params.Set("OwningAccount", accountID)
+			}
+			r.SetBufferBody(append(bytes.TrimSuffix(body, []byte("&")), []byte("&"+params.Encode())...))
+		})
+	}
+}
@@ -34,6 +34,8 @@ type QueryJson struct {
 	RefId             string                 `json:"refId,omitempty"`
 	Region            string                 `json:"region,omitempty"`
 	SqlExpression     string                 `json:"sqlExpression,omitempty"`
+	Sql               *sqlExpression         `json:"sql,omitempty"`
+	AccountId         string                 `json:"accountId,omitempty"`
 	Statistic         *string                `json:"statistic,omitempty"`
 	Statistics        []*string              `json:"statistics,omitempty"`
 	TimezoneUTCOffset string                 `json:"timezoneUTCOffset,omitempty"`
@@ -159,6 +161,9 @@ func migrateAliasToDynamicLabel(queryJson *QueryJson) {
 	queryJson.Label = &fullAliasField
 }
 
+// accountIdPattern matches AWS account IDs.
+var accountIdPattern = regexp.MustCompile(`^\d{12}$`)
+
 func parseRequestQuery(model QueryJson, refId string, startTime time.Time, endTime time.Time) (*cloudWatchQuery, error) {
 	plog.Debug("Parsing request query", "query", model)
 	cloudWatchQuery := cloudWatchQuery{
@@ -177,7 +182,17 @@ func parseRequestQuery(model QueryJson, refId string, startTime time.Time, endTi
 		SqlExpression:     model.SqlExpression,
 		TimezoneUTCOffset: model.TimezoneUTCOffset,
 		Expression:        model.Expression,
+		AccountId:         model.AccountId,
+	}
+	// queries saved by the Metrics Insights builder before the SQL was generated, e.g. through the API
+	if cloudWatchQuery.MetricQueryType == MetricQueryTypeQuery && cloudWatchQuery.SqlExpression == "" && model.Sql != nil {
+		cloudWatchQuery.SqlExpression = model.Sql.toSQL()
 	}
+
+	if model.AccountId != "" && model.AccountId != allAccounts && !accountIdPattern.MatchString(model.AccountId) {
+		return nil, fmt.Errorf("invalid account ID %q, expected 12 digits or %q", model.AccountId, allAccounts)
+	}
+
 	reNumber := regexp.MustCompile(`^\d+$`)
 	dimensions, err := parseDimensions(model.Dimensions)
 	if err != nil {
@@ -362,6 +362,61 @@ func TestRequestParser(t *testing.T) {
 		assert.Equal(t, "some alias", res.Alias) // alias is unmodified
 		assert.Equal(t, "some label", res.Label)
 	})
+
+	t.Run("parseRequestQuery sets account id", func(t *testing.T) {
+		query := getBaseJsonQuery()
+		query.AccountId = "123456789012"
+
+		res, err := parseRequestQuery(query, "ref1", time.Now().Add(-2*time.Hour), time.Now().Add(-time.Hour))
+
+		require.NoError(t, err)
+		assert.Equal(t, "123456789012", res.AccountId)
+	})
+
+	t.Run("parseRequestQuery rejects invalid account ids", func(t *testing.T) {
+		for _, accountId := range []string{"12345", "12345678901a", `123456789012" OR :aws.AccountId="1`} {
+			query := getBaseJsonQuery()
+			query.AccountId = accountId
+
+			_, err := parseRequestQuery(query, "ref1", time.Now().Add(-2*time.Hour), time.Now().Add(-time.Hour))
+			require.Error(t, err)
+		}
+
+		query := getBaseJsonQuery()
+		query.AccountId = "all"
+		res, err := parseRequestQuery(query, "ref1", time.Now().Add(-2*time.Hour), time.Now().Add(-time.Hour))
+		require.NoError(t, err)
+		assert.Equal(t, "all", res.AccountId)
+	})
+
+	t.Run("sql expression is generated from the query builder when not provided", func(t *testing.T) {
+		query := getBaseJsonQuery()
+		query.MetricQueryType = MetricQueryTypeQuery
+		query.Sql = &sqlExpression{
+			Select: &queryEditorExpression{Type: queryEditorExpressionTypeFunction, Name: "AVG", Parameters: []queryEditorExpression{{Type: "functionParameter", Name: "CPUUtilization"}}},
+			From:   &queryEditorExpression{Type: "property", Property: queryEditorProperty{Type: "string", Name: "AWS/EC2"}},
+		}
+
+		res, err := parseRequestQuery(query, "ref1", time.Now().Add(-2*time.Hour), time.Now().Add(-time.Hour))
+
+		require.NoError(t, err)
+		assert.Equal(t, `SELECT AVG(CPUUtilization) FROM "AWS/EC2"`, res.SqlExpression)
+	})
+
+	t.Run("sql expression provided by the frontend is preferred over the query builder", func(t *testing.T) {
+		query := getBaseJsonQuery()
+		query.MetricQueryType = MetricQueryTypeQuery
+		query.SqlExpression = `SELECT MAX(CPUUtilization) FROM "AWS/EC2"`
+		query.Sql = &sqlExpression{
+			Select: &queryEditorExpression{Type: queryEditorExpressionTypeFunction, Name: "AVG", Parameters: []queryEditorExpression{{Type: "functionParameter", Name: "CPUUtilization"}}},
+			From:   &queryEditorExpression{Type: "property", Property: queryEditorProperty{Type: "string", Name: "AWS/EC2"}},
+		}
+
+		res, err := parseRequestQuery(query, "ref1", time.Now().Add(-2*time.Hour), time.Now().Add(-time.Hour))
+
+		require.NoError(t, err)
+		assert.Equal(t, `SELECT MAX(CPUUtilization) FROM "AWS/EC2"`, res.SqlExpression)
+	})
 }
 
 func getBaseJsonQuery() QueryJson {
@@ -0,0 +1,160 @@
+package cloudwatch
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// The types below mirror the query editor expressions stored by the Metrics Insights builder in the `sql`
+// property of a query, see public/app/plugins/datasource/cloudwatch/expressions.ts.
+
+type queryEditorExpressionType string
+
+const (
+	queryEditorExpressionTypeOperator queryEditorExpressionType = "operator"
+	queryEditorExpressionTypeOr       queryEditorExpressionType = "or"
+	queryEditorExpressionTypeAnd      queryEditorExpressionType = "and"
+	queryEditorExpressionTypeGroupBy  queryEditorExpressionType = "groupBy"
+	queryEditorExpressionTypeFunction queryEditorExpressionType = "function"
+)
+
+type queryEditorProperty struct {
+	Type string `json:"type"`
+	Name string `json:"name,omitempty"`
+}
+
+type queryEditorOperator struct {
+	Name  string      `json:"name,omitempty"`
+	Value interface{} `json:"value,omitempty"`
+}
+
+// queryEditorExpression is any of the expressions of the builder, the fields in use depend on the type.
+type queryEditorExpression struct {
+	Type queryEditorExpressionType `json:"type"`
+	// function and function parameter
+	Name       string                  `json:"name,omitempty"`
+	Parameters []queryEditorExpression `json:"parameters,omitempty"`
+	// property, operator and group by
+	Property queryEditorProperty `json:"property,omitempty"`
+	Operator queryEditorOperator `json:"operator,omitempty"`
+	// and, or
+	Expressions []queryEditorExpression `json:"expressions,omitempty"`
+}
+
+// sqlExpression is a Metrics Insights query built with the query editor.
+type sqlExpression struct {
+	Select           *queryEditorExpression `json:"select,omitempty"`
+	From             *queryEditorExpression `json:"from,omitempty"`
+	Where            *queryEditorExpression `json:"where,omitempty"`
+	GroupBy          *queryEditorExpression `json:"groupBy,omitempty"`
+	OrderBy          *queryEditorExpression `json:"orderBy,omitempty"`
+	OrderByDirection string                 `json:"orderByDirection,omitempty"`
+	Limit            int                    `json:"limit,omitempty"`
+}
+
+// toSQL returns the Metrics Insights query, the same way the frontend does. An empty string is returned
+// when the expression has no select function or from clause yet.
+func (e *sqlExpression) toSQL() string {
+	if e.From == nil || e.Select == nil || e.Select.Name == "" || len(e.Select.Parameters) == 0 {
+		return ""
+	}
+
+	parts := []string{"SELECT", sqlFunction(e.Select), "FROM"}
+	if e.From.Type == queryEditorExpressionTypeFunction {
+		parts = append(parts, sqlFunction(e.From))
+	} else {
+		parts = append(parts, sqlName(e.From.Property.Name))
+	}
+
+	if e.Where != nil {
+		if where := sqlFilter(*e.Where, true, len(e.Where.Expressions)); where != "" {
+			parts = append(parts, "WHERE", where)
+		}
+	}
+
+	if e.GroupBy != nil {
+		var groupBy []string
+		for _, expression := range e.GroupBy.Expressions {
+			if expression.Type != queryEditorExpressionTypeGroupBy || expression.Property.Name == "" {
+				continue
+			}
+			groupBy = append(groupBy, sqlName(expression.Property.Name))
+		}
+		if len(groupBy) > 0 {
+			parts = append(parts, "GROUP BY "+strings.Join(groupBy, ", "))
+		}
+	}
+
+	if e.OrderBy != nil && e.OrderBy.Name != "" {
+		direction := e.OrderByDirection
+		if direction == "" {
+			direction = "ASC"
+		}
+		parts = append(parts, "ORDER BY", sqlFunction(e.OrderBy), direction)
+	}
+
+	if e.Limit > 0 {
+		parts = append(parts, "LIMIT "+strconv.Itoa(e.Limit))
+	}
+
+	return strings.Join(parts, " ")
+}
+
+func sqlFilter(filter queryEditorExpression, isTopLevel bool, topLevelCount int) string {
+	switch filter.Type {
+	case queryEditorExpressionTypeAnd, queryEditorExpressionTypeOr:
+		var parts []string
+		for _, expression := range filter.Expressions {
+			if part := sqlFilter(expression, false, topLevelCount); part != "" {
+				parts = append(parts, part)
+			}
+		}
+		if len(parts) == 0 {
+			return ""
+		}
+		combined := strings.Join(parts, " "+strings.ToUpper(string(filter.Type))+" ")
+		if !isTopLevel && topLevelCount > 1 && len(parts) > 1 {
+			return "(" + combined + ")"
+		}
+		return combined
+	case queryEditorExpressionTypeOperator:
+		value := filter.Operator.Value
+		if filter.Property.Name == "" || !sqlComparisonOperators[filter.Operator.Name] || value == nil || value == "" || value == false {
+			return ""
+		}
+		return fmt.Sprintf("%s %s %s", sqlName(filter.Property.Name), filter.Operator.Name, sqlString(value))
+	default:
+		return ""
+	}
+}
+
+func sqlFunction(function *queryEditorExpression) string {
+	var params []string
+	for _, param := range function.Parameters {
+		if param.Name != "" {
+			params = append(params, sqlName(param.Name))
+		}
+	}
+	return fmt.Sprintf("%s(%s)", function.Name, strings.Join(params, ", "))
+}
+
+// sqlComparisonOperators are the operators filters can use, like in the query editor.
+var sqlComparisonOperators = map[string]bool{"=": true, "!=": true}
+
+// sqlString quotes value as a string literal, backslashes and single quotes in it are escaped.
+func sqlString(value interface{}) string {
+	escaped := strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(fmt.Sprint(value))
+	return "'" + escaped + "'"
+}
+
+// slash, space, dot or dash
+var sqlSpecialCharacters = regexp.MustCompile(`[/\s.-]`)
+
+func sqlName(name string) string {
+	if sqlSpecialCharacters.MatchString(name) {
+		return `"` + name + `"`
+	}
+	return name
+}
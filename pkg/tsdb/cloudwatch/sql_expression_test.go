@@ -0,0 +1,106 @@
+package cloudwatch
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSQLExpression(t *testing.T) {
+	avg := &queryEditorExpression{Type: queryEditorExpressionTypeFunction, Name: "AVG", Parameters: []queryEditorExpression{{Type: "functionParameter", Name: "CPUUtilization"}}}
+	ec2 := &queryEditorExpression{Type: "property", Property: queryEditorProperty{Type: "string", Name: "AWS/EC2"}}
+	filter := func(name, operator string, value interface{}) queryEditorExpression {
+		return queryEditorExpression{
+			Type:     queryEditorExpressionTypeOperator,
+			Property: queryEditorProperty{Type: "string", Name: name},
+			Operator: queryEditorOperator{Name: operator, Value: value},
+		}
+	}
+
+	testCases := map[string]struct {
+		expression sqlExpression
+		expected   string
+	}{
+		"empty when select is missing": {
+			expression: sqlExpression{From: ec2},
+			expected:   "",
+		},
+		"empty when from is missing": {
+			expression: sqlExpression{Select: avg},
+			expected:   "",
+		},
+		"select and from": {
+			expression: sqlExpression{Select: avg, From: ec2},
+			expected:   `SELECT AVG(CPUUtilization) FROM "AWS/EC2"`,
+		},
+		"from schema function": {
+			expression: sqlExpression{Select: avg, From: &queryEditorExpression{
+				Type:       queryEditorExpressionTypeFunction,
+				Name:       "SCHEMA",
+				Parameters: []queryEditorExpression{{Type: "functionParameter", Name: "AWS/EC2"}, {Type: "functionParameter", Name: "InstanceId"}},
+			}},
+			expected: `SELECT AVG(CPUUtilization) FROM SCHEMA("AWS/EC2", InstanceId)`,
+		},
+		"incomplete filters are ignored": {
+			expression: sqlExpression{Select: avg, From: ec2, Where: &queryEditorExpression{
+				Type:        queryEditorExpressionTypeAnd,
+				Expressions: []queryEditorExpression{filter("InstanceId", "=", ""), filter("", "=", "i-123")},
+			}},
+			expected: `SELECT AVG(CPUUtilization) FROM "AWS/EC2"`,
+		},
+		"nested filters are wrapped in parentheses": {
+			expression: sqlExpression{Select: avg, From: ec2, Where: &queryEditorExpression{
+				Type: queryEditorExpressionTypeAnd,
+				Expressions: []queryEditorExpression{
+					filter("InstanceType", "!=", "t2.micro"),
+					{
+						Type:        queryEditorExpressionTypeOr,
+						Expressions: []queryEditorExpression{filter("InstanceId", "=", "i-123"), filter("InstanceId", "=", "i-456")},
+					},
+				},
+			}},
+			expected: `SELECT AVG(CPUUtilization) FROM "AWS/EC2" WHERE InstanceType != 't2.micro' AND (InstanceId = 'i-123' OR InstanceId = 'i-456')`,
+		},
+		"quotes in filter values are escaped": {
+			expression: sqlExpression{Select: avg, From: ec2, Where: &queryEditorExpression{
+				Type:        queryEditorExpressionTypeAnd,
+				Expressions: []queryEditorExpression{filter("InstanceId", "=", `i-1' OR InstanceId != '\`)},
+			}},
+			expected: `SELECT AVG(CPUUtilization) FROM "AWS/EC2" WHERE InstanceId = 'i-1\' OR InstanceId != \'\\'`,
+		},
+		"filters with unknown operators are ignored": {
+			expression: sqlExpression{Select: avg, From: ec2, Where: &queryEditorExpression{
+				Type:        queryEditorExpressionTypeAnd,
+				Expressions: []queryEditorExpression{filter("InstanceId", "= 'x' OR 1 =", "i-123")},
+			}},
+			expected: `SELECT AVG(CPUUtilization) FROM "AWS/EC2"`,
+		},
+		"group by, order by and limit": {
+			expression: sqlExpression{
+				Select: avg,
+				From:   ec2,
+				GroupBy: &queryEditorExpression{
+					Type: queryEditorExpressionTypeAnd,
+					Expressions: []queryEditorExpression{
+						{Type: queryEditorExpressionTypeGroupBy, Property: queryEditorProperty{Type: "string", Name: "InstanceId"}},
+						{Type: queryEditorExpressionTypeGroupBy, Property: queryEditorProperty{Type: "string", Name: "Auto Scaling Group"}},
+					},
+				},
+				OrderBy:          &queryEditorExpression{Type: queryEditorExpressionTypeFunction, Name: "MAX"},
+				OrderByDirection: "DESC",
+				Limit:            10,
+			},
+			expected: `SELECT AVG(CPUUtilization) FROM "AWS/EC2" GROUP BY InstanceId, "Auto Scaling Group" ORDER BY MAX() DESC LIMIT 10`,
+		},
+		"order by defaults to ascending": {
+			expression: sqlExpression{Select: avg, From: ec2, OrderBy: &queryEditorExpression{Type: queryEditorExpressionTypeFunction, Name: "SUM"}},
+			expected:   `SELECT AVG(CPUUtilization) FROM "AWS/EC2" ORDER BY SUM() ASC`,
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, tc.expression.toSQL())
+		})
+	}
+}
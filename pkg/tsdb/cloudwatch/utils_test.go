@@ -75,6 +75,7 @@ type fakeCWClient struct {
 	MetricsPerPage int
 
 	callsGetMetricDataWithContext []*cloudwatch.GetMetricDataInput
+	callsListMetricsOptions       [][]request.Option
 }
 
 func (c *fakeCWClient) GetMetricDataWithContext(ctx aws.Context, input *cloudwatch.GetMetricDataInput, opts ...request.Option) (*cloudwatch.GetMetricDataOutput, error) {
@@ -83,7 +84,8 @@ func (c *fakeCWClient) GetMetricDataWithContext(ctx aws.Context, input *cloudwat
 	return &c.GetMetricDataOutput, nil
 }
 
-func (c *fakeCWClient) ListMetricsPages(input *cloudwatch.ListMetricsInput, fn func(*cloudwatch.ListMetricsOutput, bool) bool) error {
+func (c *fakeCWClient) ListMetricsPagesWithContext(ctx aws.Context, input *cloudwatch.ListMetricsInput, fn func(*cloudwatch.ListMetricsOutput, bool) bool, opts ...request.Option) error {
+	c.callsListMetricsOptions = append(c.callsListMetricsOptions, opts)
 	if c.MetricsPerPage == 0 {
 		c.MetricsPerPage = 1000
 	}
@@ -193,7 +195,7 @@ type fakeCheckHealthClient struct {
 		options ...request.Option) (*cloudwatchlogs.DescribeLogGroupsOutput, error)
 }
 
-func (c fakeCheckHealthClient) ListMetricsPages(input *cloudwatch.ListMetricsInput, fn func(*cloudwatch.ListMetricsOutput, bool) bool) error {
+func (c fakeCheckHealthClient) ListMetricsPagesWithContext(ctx aws.Context, input *cloudwatch.ListMetricsInput, fn func(*cloudwatch.ListMetricsOutput, bool) bool, opts ...request.Option) error {
 	if c.listMetricsPages != nil {
 		return c.listMetricsPages(input, fn)
 	}
@@ -243,3 +245,24 @@ func (s *fakeSessionCache) GetSession(c awsds.SessionConfig) (*session.Session,
 		Config: &aws.Config{},
 	}, nil
 }
+
+type fakeOAMClient struct {
+	sinks                  []*oamSink
+	links                  map[string][]*oamAttachedLink
+	listSinksErr           error
+	callsListAttachedLinks []*oamListAttachedLinksInput
+}
+
+func (c *fakeOAMClient) ListSinksPages(input *oamListSinksInput, fn func(*oamListSinksOutput, bool) bool) error {
+	if c.listSinksErr != nil {
+		return c.listSinksErr
+	}
+	fn(&oamListSinksOutput{Items: c.sinks}, true)
+	return nil
+}
+
+func (c *fakeOAMClient) ListAttachedLinksPages(input *oamListAttachedLinksInput, fn func(*oamListAttachedLinksOutput, bool) bool) error {
+	c.callsListAttachedLinks = append(c.callsListAttachedLinks, input)
+	fn(&oamListAttachedLinksOutput{Items: c.links[aws.StringValue(input.SinkIdentifier)]}, true)
+	return nil
+}
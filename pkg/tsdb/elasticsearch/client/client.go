@@ -34,6 +34,15 @@ type DatasourceInfo struct {
 	MaxConcurrentShardRequests int64
 	IncludeFrozen              bool
 	XPack                      bool
+	LogMessageField            string
+	LogLevelField              string
+}
+
+// ConfiguredFields are the fields configured in the data source settings
+type ConfiguredFields struct {
+	TimeField       string
+	LogMessageField string
+	LogLevelField   string
 }
 
 const loggerName = "tsdb.elasticsearch.client"
@@ -50,6 +59,7 @@ var newDatasourceHttpClient = func(httpClientProvider httpclient.Provider, ds *D
 type Client interface {
 	GetVersion() *semver.Version
 	GetTimeField() string
+	GetConfiguredFields() ConfiguredFields
 	GetMinInterval(queryInterval string) (time.Duration, error)
 	ExecuteMultisearch(r *MultiSearchRequest) (*MultiSearchResponse, error)
 	MultiSearch() *MultiSearchRequestBuilder
@@ -100,6 +110,14 @@ func (c *baseClientImpl) GetTimeField() string {
 	return c.timeField
 }
 
+func (c *baseClientImpl) GetConfiguredFields() ConfiguredFields {
+	return ConfiguredFields{
+		TimeField:       c.timeField,
+		LogMessageField: c.ds.LogMessageField,
+		LogLevelField:   c.ds.LogLevelField,
+	}
+}
+
 func (c *baseClientImpl) GetMinInterval(queryInterval string) (time.Duration, error) {
 	timeInterval := c.ds.TimeInterval
 	return intervalv2.GetIntervalFrom(queryInterval, timeInterval, 0, 5*time.Second)
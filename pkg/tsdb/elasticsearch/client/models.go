@@ -38,6 +38,9 @@ type SearchRequest struct {
 	Query       *Query
 	Aggs        AggArray
 	CustomProps map[string]interface{}
+
+	// sortOrder keeps the order sorts were added in, which matters when sorting on several fields
+	sortOrder []string
 }
 
 // MarshalJSON returns the JSON encoding of the request.
@@ -45,8 +48,14 @@ func (r *SearchRequest) MarshalJSON() ([]byte, error) {
 	root := make(map[string]interface{})
 
 	root["size"] = r.Size
-	if len(r.Sort) > 0 {
+	if len(r.Sort) == 1 {
 		root["sort"] = r.Sort
+	} else if len(r.Sort) > 1 {
+		sorts := make([]map[string]interface{}, 0, len(r.Sort))
+		for _, field := range r.sortOrder {
+			sorts = append(sorts, map[string]interface{}{field: r.Sort[field]})
+		}
+		root["sort"] = sorts
 	}
 
 	for key, value := range r.CustomProps {
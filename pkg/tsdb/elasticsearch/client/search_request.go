@@ -14,6 +14,7 @@ type SearchRequestBuilder struct {
 	index        string
 	size         int
 	sort         map[string]interface{}
+	sortOrder    []string
 	queryBuilder *QueryBuilder
 	aggBuilders  []AggBuilder
 	customProps  map[string]interface{}
@@ -39,6 +40,7 @@ func (b *SearchRequestBuilder) Build() (*SearchRequest, error) {
 		Size:        b.size,
 		Sort:        b.sort,
 		CustomProps: b.customProps,
+		sortOrder:   b.sortOrder,
 	}
 
 	if b.queryBuilder != nil {
@@ -80,11 +82,20 @@ func (b *SearchRequestBuilder) SortDesc(field, unmappedType string) *SearchReque
 		props["unmapped_type"] = unmappedType
 	}
 
+	if _, ok := b.sort[field]; !ok {
+		b.sortOrder = append(b.sortOrder, field)
+	}
 	b.sort[field] = props
 
 	return b
 }
 
+// SearchAfter sets the sort values of the last hit of the previous page, to fetch the next page of hits
+func (b *SearchRequestBuilder) SearchAfter(values []interface{}) *SearchRequestBuilder {
+	b.customProps["search_after"] = values
+	return b
+}
+
 // AddDocValueField adds a doc value field to the search request
 func (b *SearchRequestBuilder) AddDocValueField(field string) *SearchRequestBuilder {
 	// fields field not supported on version >= 5
@@ -125,6 +125,16 @@ func newInstanceSettings() datasource.InstanceFactoryFunc {
 			xpack = false
 		}
 
+		logMessageField, ok := jsonData["logMessageField"].(string)
+		if !ok {
+			logMessageField = ""
+		}
+
+		logLevelField, ok := jsonData["logLevelField"].(string)
+		if !ok {
+			logLevelField = ""
+		}
+
 		model := es.DatasourceInfo{
 			ID:                         settings.ID,
 			URL:                        settings.URL,
@@ -137,6 +147,8 @@ func newInstanceSettings() datasource.InstanceFactoryFunc {
 			TimeInterval:               timeInterval,
 			IncludeFrozen:              includeFrozen,
 			XPack:                      xpack,
+			LogMessageField:            logMessageField,
+			LogLevelField:              logLevelField,
 		}
 		return model, nil
 	}
@@ -54,6 +54,7 @@ var metricAggType = map[string]string{
 	"serial_diff":    "Serial Difference",
 	"bucket_script":  "Bucket Script",
 	"raw_document":   "Raw Document",
+	"logs":           "Logs",
 	"rate":           "Rate",
 }
 
@@ -91,6 +92,10 @@ var pipelineAggWithMultipleBucketPathsType = map[string]string{
 	"bucket_script": "bucket_script",
 }
 
+func isLogsQuery(query *Query) bool {
+	return len(query.Metrics) > 0 && query.Metrics[0].Type == logsType
+}
+
 func isPipelineAgg(metricType string) bool {
 	if _, ok := pipelineAggType[metricType]; ok {
 		return true
@@ -1,7 +1,9 @@
 package elasticsearch
 
 import (
+	"encoding/json"
 	"errors"
+	"fmt"
 	"regexp"
 	"sort"
 	"strconv"
@@ -20,25 +22,33 @@ const (
 	percentilesType   = "percentiles"
 	extendedStatsType = "extended_stats"
 	topMetricsType    = "top_metrics"
+	rawDocumentType   = "raw_document"
+	logsType          = "logs"
 	// Bucket types
 	dateHistType    = "date_histogram"
 	histogramType   = "histogram"
 	filtersType     = "filters"
 	termsType       = "terms"
 	geohashGridType = "geohash_grid"
+
+	// defaultSize is the number of documents fetched by raw document and logs queries without a size
+	defaultSize = 500
 )
 
 type responseParser struct {
-	Responses []*es.SearchResponse
-	Targets   []*Query
-	DebugInfo *es.SearchDebugInfo
+	Responses        []*es.SearchResponse
+	Targets          []*Query
+	DebugInfo        *es.SearchDebugInfo
+	ConfiguredFields es.ConfiguredFields
 }
 
-var newResponseParser = func(responses []*es.SearchResponse, targets []*Query, debugInfo *es.SearchDebugInfo) *responseParser {
+var newResponseParser = func(responses []*es.SearchResponse, targets []*Query, debugInfo *es.SearchDebugInfo,
+	configuredFields es.ConfiguredFields) *responseParser {
 	return &responseParser{
-		Responses: responses,
-		Targets:   targets,
-		DebugInfo: debugInfo,
+		Responses:        responses,
+		Targets:          targets,
+		DebugInfo:        debugInfo,
+		ConfiguredFields: configuredFields,
 	}
 }
 
@@ -72,6 +82,13 @@ func (rp *responseParser) getTimeSeries() (*backend.QueryDataResponse, error) {
 			continue
 		}
 
+		if isLogsQuery(target) {
+			result.Responses[target.RefID] = backend.DataResponse{
+				Frames: data.Frames{rp.processLogs(res, target)},
+			}
+			continue
+		}
+
 		queryRes := backend.DataResponse{}
 
 		props := make(map[string]string)
@@ -92,6 +109,175 @@ func (rp *responseParser) getTimeSeries() (*backend.QueryDataResponse, error) {
 	return &result, nil
 }
 
+// processLogs converts the hits of a logs query to a logs frame. The configured time field comes first, followed
+// by the configured message field and the configured level field as level, then all other document fields. The sort
+// values of the last hit are returned in the frame meta as searchAfter, to fetch the next page.
+func (rp *responseParser) processLogs(res *es.SearchResponse, target *Query) *data.Frame {
+	timeField := rp.ConfiguredFields.TimeField
+	messageField := rp.ConfiguredFields.LogMessageField
+	levelField := rp.ConfiguredFields.LogLevelField
+
+	var hits []map[string]interface{}
+	if res.Hits != nil {
+		hits = res.Hits.Hits
+	}
+
+	times := make([]*time.Time, len(hits))
+	docs := make([]map[string]interface{}, len(hits))
+	propNames := map[string]bool{}
+	var searchAfter interface{}
+	for i, hit := range hits {
+		doc := map[string]interface{}{
+			"_id":    hit["_id"],
+			"_index": hit["_index"],
+		}
+		if source, ok := hit["_source"].(map[string]interface{}); ok {
+			flattenDocument("", source, doc)
+		}
+		if levelField != "" {
+			doc["level"] = doc[levelField]
+		}
+
+		sortValues, _ := hit["sort"].([]interface{})
+		times[i] = parseDocumentTime(doc[timeField], sortValues)
+		searchAfter = sortValues
+
+		docs[i] = doc
+		for name := range doc {
+			propNames[name] = true
+		}
+	}
+
+	fields := []*data.Field{data.NewField(timeField, nil, times)}
+	if messageField != "" {
+		fields = append(fields, newLogsStringField(messageField, docs))
+	}
+	if levelField != "" {
+		fields = append(fields, newLogsStringField("level", docs))
+	}
+
+	names := make([]string, 0, len(propNames))
+	for name := range propNames {
+		if name != timeField && name != messageField && name != "level" {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fields = append(fields, newLogsField(name, docs))
+	}
+
+	for _, field := range fields {
+		field.Config = &data.FieldConfig{Filterable: boolPtr(true)}
+	}
+
+	frame := data.NewFrame("", fields...)
+	frame.RefID = target.RefID
+	frame.Meta = &data.FrameMeta{
+		PreferredVisualization: data.VisTypeLogs,
+		Custom:                 map[string]interface{}{"searchAfter": searchAfter},
+	}
+	return frame
+}
+
+// flattenDocument adds the fields of a document to doc, using dotted names for nested objects
+func flattenDocument(prefix string, source map[string]interface{}, doc map[string]interface{}) {
+	for key, value := range source {
+		name := prefix + key
+		if nested, ok := value.(map[string]interface{}); ok {
+			flattenDocument(name+".", nested, doc)
+			continue
+		}
+		doc[name] = value
+	}
+}
+
+// parseDocumentTime reads the time field of a document, falling back to the first sort value which is the
+// time field in epoch milliseconds
+func parseDocumentTime(value interface{}, sort []interface{}) *time.Time {
+	switch v := value.(type) {
+	case string:
+		if t, err := time.Parse(time.RFC3339Nano, v); err == nil {
+			return &t
+		}
+	case float64:
+		t := time.UnixMilli(int64(v)).UTC()
+		return &t
+	}
+
+	if len(sort) > 0 {
+		if ms, ok := sort[0].(float64); ok {
+			t := time.UnixMilli(int64(ms)).UTC()
+			return &t
+		}
+	}
+	return nil
+}
+
+func newLogsStringField(name string, docs []map[string]interface{}) *data.Field {
+	values := make([]*string, len(docs))
+	for i, doc := range docs {
+		if value, ok := doc[name]; ok && value != nil {
+			s := logsValueToString(value)
+			values[i] = &s
+		}
+	}
+	return data.NewField(name, nil, values)
+}
+
+// newLogsField creates a number or boolean field when all values of a document field have that type,
+// and a string field otherwise
+func newLogsField(name string, docs []map[string]interface{}) *data.Field {
+	allNumbers, allBools := true, true
+	for _, doc := range docs {
+		switch doc[name].(type) {
+		case nil:
+		case float64:
+			allBools = false
+		case bool:
+			allNumbers = false
+		default:
+			allNumbers, allBools = false, false
+		}
+	}
+
+	switch {
+	case allNumbers:
+		values := make([]*float64, len(docs))
+		for i, doc := range docs {
+			if v, ok := doc[name].(float64); ok {
+				values[i] = &v
+			}
+		}
+		return data.NewField(name, nil, values)
+	case allBools:
+		values := make([]*bool, len(docs))
+		for i, doc := range docs {
+			if v, ok := doc[name].(bool); ok {
+				values[i] = &v
+			}
+		}
+		return data.NewField(name, nil, values)
+	default:
+		return newLogsStringField(name, docs)
+	}
+}
+
+func logsValueToString(value interface{}) string {
+	if s, ok := value.(string); ok {
+		return s
+	}
+	b, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+	return string(b)
+}
+
+func boolPtr(b bool) *bool {
+	return &b
+}
+
 func (rp *responseParser) processBuckets(aggs map[string]interface{}, target *Query,
 	queryResult *backend.DataResponse, props map[string]string, depth int) error {
 	var err error
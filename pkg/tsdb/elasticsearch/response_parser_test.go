@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
 	es "github.com/grafana/grafana/pkg/tsdb/elasticsearch/client"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -1061,6 +1062,60 @@ func TestResponseParser(t *testing.T) {
 	})
 }
 
+func TestLogsResponseParser(t *testing.T) {
+	targets := map[string]string{
+		"A": `{
+			"timeField": "@timestamp",
+			"metrics": [{ "type": "logs", "id": "1" }]
+		}`,
+	}
+	response := `{
+		"responses": [{
+			"hits": {
+				"hits": [
+					{
+						"_id": "b", "_index": "logs-1", "sort": [1526406660000, 2],
+						"_source": { "@timestamp": "2018-05-15T17:51:00Z", "msg": "timeout", "lvl": "error", "http": { "status": 504 }, "ok": false }
+					},
+					{
+						"_id": "a", "_index": "logs-1", "sort": [1526406600000, 1],
+						"_source": { "msg": "started", "lvl": "info", "http": { "status": "n/a" } }
+					}
+				]
+			}
+		}]
+	}`
+	rp, err := newResponseParserForTest(targets, response)
+	require.NoError(t, err)
+	rp.ConfiguredFields = es.ConfiguredFields{TimeField: "@timestamp", LogMessageField: "msg", LogLevelField: "lvl"}
+	result, err := rp.getTimeSeries()
+	require.NoError(t, err)
+
+	queryRes := result.Responses["A"]
+	require.NoError(t, queryRes.Error)
+	require.Len(t, queryRes.Frames, 1)
+	frame := queryRes.Frames[0]
+	require.Equal(t, "A", frame.RefID)
+	require.Equal(t, data.VisType(data.VisTypeLogs), frame.Meta.PreferredVisualization)
+	require.Equal(t, map[string]interface{}{"searchAfter": []interface{}{1526406600000., 1.}}, frame.Meta.Custom)
+
+	names := make([]string, 0, len(frame.Fields))
+	for _, field := range frame.Fields {
+		names = append(names, field.Name)
+	}
+	require.Equal(t, []string{"@timestamp", "msg", "level", "_id", "_index", "http.status", "lvl", "ok"}, names)
+
+	require.Equal(t, time.Date(2018, 5, 15, 17, 51, 0, 0, time.UTC), *frame.Fields[0].At(0).(*time.Time))
+	// documents without the time field fall back to the sort value
+	require.Equal(t, time.Date(2018, 5, 15, 17, 50, 0, 0, time.UTC), *frame.Fields[0].At(1).(*time.Time))
+	require.Equal(t, "timeout", *frame.Fields[1].At(0).(*string))
+	require.Equal(t, "info", *frame.Fields[2].At(1).(*string))
+	// mixed types become strings
+	require.Equal(t, "504", *frame.Fields[5].At(0).(*string))
+	require.Equal(t, data.FieldTypeNullableBool, frame.Fields[7].Type())
+	require.Nil(t, frame.Fields[7].At(1))
+}
+
 func newResponseParserForTest(tsdbQueries map[string]string, responseBody string) (*responseParser, error) {
 	from := time.Date(2018, 5, 15, 17, 50, 0, 0, time.UTC)
 	to := time.Date(2018, 5, 15, 17, 55, 0, 0, time.UTC)
@@ -1092,5 +1147,5 @@ func newResponseParserForTest(tsdbQueries map[string]string, responseBody string
 		return nil, err
 	}
 
-	return newResponseParser(response.Responses, queries, nil), nil
+	return newResponseParser(response.Responses, queries, nil, es.ConfiguredFields{TimeField: "@timestamp"}), nil
 }
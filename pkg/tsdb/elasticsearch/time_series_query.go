@@ -58,7 +58,7 @@ func (e *timeSeriesQuery) execute() (*backend.QueryDataResponse, error) {
 		return &backend.QueryDataResponse{}, err
 	}
 
-	rp := newResponseParser(res.Responses, queries, res.DebugInfo)
+	rp := newResponseParser(res.Responses, queries, res.DebugInfo, e.client.GetConfiguredFields())
 	return rp.getTimeSeries()
 }
 
@@ -79,17 +79,22 @@ func (e *timeSeriesQuery) processQuery(q *Query, ms *es.MultiSearchRequestBuilde
 		filters.AddQueryStringFilter(q.RawQuery, true)
 	}
 
+	if isLogsQuery(q) {
+		processLogsQuery(q, b, e.client.GetTimeField())
+		return nil
+	}
+
 	if len(q.BucketAggs) == 0 {
-		if len(q.Metrics) == 0 || q.Metrics[0].Type != "raw_document" {
+		if len(q.Metrics) == 0 || q.Metrics[0].Type != rawDocumentType {
 			result.Responses[q.RefID] = backend.DataResponse{
 				Error: fmt.Errorf("invalid query, missing metrics and aggregations"),
 			}
 			return nil
 		}
 		metric := q.Metrics[0]
-		b.Size(metric.Settings.Get("size").MustInt(500))
-		b.SortDesc("@timestamp", "boolean")
-		b.AddDocValueField("@timestamp")
+		b.Size(metric.Settings.Get("size").MustInt(defaultSize))
+		b.SortDesc(e.client.GetTimeField(), "boolean")
+		b.AddDocValueField(e.client.GetTimeField())
 		return nil
 	}
 
@@ -182,6 +187,31 @@ func (e *timeSeriesQuery) processQuery(q *Query, ms *es.MultiSearchRequestBuilde
 	return nil
 }
 
+// processLogsQuery fetches the newest documents in the time range, sorted on the configured time field. The document
+// order breaks ties between documents with the same timestamp, so that the sort values of the last document can be sent
+// back as the searchAfter setting to fetch the next page.
+func processLogsQuery(q *Query, b *es.SearchRequestBuilder, timeField string) {
+	metric := q.Metrics[0]
+	b.Size(intSetting(metric.Settings, "limit", defaultSize))
+	b.SortDesc(timeField, "boolean")
+	b.SortDesc("_doc", "")
+
+	if searchAfter := metric.Settings.Get("searchAfter").MustArray(); len(searchAfter) > 0 {
+		b.SearchAfter(searchAfter)
+	}
+}
+
+// intSetting reads an integer setting that the query editor may have stored as a string
+func intSetting(settings *simplejson.Json, name string, defaultValue int) int {
+	if value, err := settings.Get(name).Int(); err == nil && value > 0 {
+		return value
+	}
+	if value, err := strconv.Atoi(settings.Get(name).MustString()); err == nil && value > 0 {
+		return value
+	}
+	return defaultValue
+}
+
 func setFloatPath(settings *simplejson.Json, path ...string) {
 	if stringValue, err := settings.GetPath(path...).String(); err == nil {
 		if value, err := strconv.ParseFloat(stringValue, 64); err == nil {
@@ -7,6 +7,7 @@ import (
 
 	"github.com/Masterminds/semver"
 	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana/pkg/components/simplejson"
 	es "github.com/grafana/grafana/pkg/tsdb/elasticsearch/client"
 	"github.com/grafana/grafana/pkg/tsdb/intervalv2"
 	"github.com/stretchr/testify/assert"
@@ -377,6 +378,59 @@ func TestExecuteTimeSeriesQuery(t *testing.T) {
 			require.Equal(t, sr.Size, 1337)
 		})
 
+		t.Run("With raw document metric uses the configured time field", func(t *testing.T) {
+			c := newFakeClient("5.0.0")
+			c.timeField = "timestamp"
+			_, err := executeTsdbQuery(c, `{
+				"timeField": "timestamp",
+				"bucketAggs": [],
+				"metrics": [{ "id": "1", "type": "raw_document", "settings": {}	}]
+			}`, from, to, 15*time.Second)
+			require.NoError(t, err)
+			sr := c.multisearchRequests[0].Requests[0]
+
+			require.Contains(t, sr.Sort, "timestamp")
+			require.NotContains(t, sr.Sort, "@timestamp")
+		})
+
+		t.Run("With logs metric", func(t *testing.T) {
+			c := newFakeClient("7.10.0")
+			_, err := executeTsdbQuery(c, `{
+				"timeField": "@timestamp",
+				"query": "level:error",
+				"bucketAggs": [{ "type": "date_histogram", "id": "2", "settings": { "interval": "auto" } }],
+				"metrics": [{ "id": "1", "type": "logs", "settings": { "limit": "100", "searchAfter": [1526406600000, 12] } }]
+			}`, from, to, 15*time.Second)
+			require.NoError(t, err)
+			sr := c.multisearchRequests[0].Requests[0]
+
+			require.Equal(t, 100, sr.Size)
+			require.Empty(t, sr.Aggs)
+			require.Equal(t, []interface{}{json.Number("1526406600000"), json.Number("12")}, sr.CustomProps["search_after"])
+
+			body, err := json.Marshal(sr)
+			require.NoError(t, err)
+			sj, err := simplejson.NewJson(body)
+			require.NoError(t, err)
+			sort := sj.Get("sort").MustArray()
+			require.Len(t, sort, 2)
+			require.Contains(t, sort[0], "@timestamp")
+			require.Contains(t, sort[1], "_doc")
+		})
+
+		t.Run("With logs metric without limit", func(t *testing.T) {
+			c := newFakeClient("7.10.0")
+			_, err := executeTsdbQuery(c, `{
+				"timeField": "@timestamp",
+				"metrics": [{ "id": "1", "type": "logs" }]
+			}`, from, to, 15*time.Second)
+			require.NoError(t, err)
+			sr := c.multisearchRequests[0].Requests[0]
+
+			require.Equal(t, 500, sr.Size)
+			require.NotContains(t, sr.CustomProps, "search_after")
+		})
+
 		t.Run("With date histogram agg", func(t *testing.T) {
 			c := newFakeClient("5.0.0")
 			_, err := executeTsdbQuery(c, `{
@@ -1184,6 +1238,10 @@ func (c *fakeClient) GetTimeField() string {
 	return c.timeField
 }
 
+func (c *fakeClient) GetConfiguredFields() es.ConfiguredFields {
+	return es.ConfiguredFields{TimeField: c.timeField}
+}
+
 func (c *fakeClient) GetMinInterval(queryInterval string) (time.Duration, error) {
 	return 15 * time.Second, nil
 }
@@ -0,0 +1,126 @@
+package graphite
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+)
+
+const eventsQueryType = "events"
+
+type eventsQueryModel struct {
+	QueryType string   `json:"queryType"`
+	Tags      []string `json:"tags"`
+}
+
+// EventDTO is a Graphite event as returned by /events/get_data. Older versions return the tags as a single,
+// space separated string.
+type EventDTO struct {
+	When float64     `json:"when"`
+	What string      `json:"what"`
+	Tags interface{} `json:"tags"`
+	Data string      `json:"data"`
+}
+
+func isEventsQuery(query backend.DataQuery) bool {
+	model := eventsQueryModel{}
+	if err := json.Unmarshal(query.JSON, &model); err != nil {
+		return false
+	}
+	return model.QueryType == eventsQueryType
+}
+
+// queryEvents fetches the Graphite events of the time range having all the tags of the query and returns them
+// as an annotations frame.
+func (s *Service) queryEvents(ctx context.Context, dsInfo *datasourceInfo, query backend.DataQuery) backend.DataResponse {
+	model := eventsQueryModel{}
+	if err := json.Unmarshal(query.JSON, &model); err != nil {
+		return backend.DataResponse{Error: err}
+	}
+
+	from, until := epochMStoGraphiteTime(query.TimeRange)
+	params := url.Values{"from": []string{from}, "until": []string{until}}
+	if len(model.Tags) > 0 {
+		params.Set("tags", strings.Join(model.Tags, " "))
+	}
+
+	u, err := url.Parse(dsInfo.URL)
+	if err != nil {
+		return backend.DataResponse{Error: err}
+	}
+	u.Path = path.Join(u.Path, "events/get_data")
+	u.RawQuery = params.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return backend.DataResponse{Error: fmt.Errorf("failed to create request: %w", err)}
+	}
+
+	res, err := dsInfo.HTTPClient.Do(req)
+	if err != nil {
+		return backend.DataResponse{Error: err}
+	}
+	defer func() {
+		if err := res.Body.Close(); err != nil {
+			s.logger.Warn("Failed to close response body", "err", err)
+		}
+	}()
+
+	body, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return backend.DataResponse{Error: err}
+	}
+	if res.StatusCode/100 != 2 {
+		s.logger.Info("Events request failed", "status", res.Status, "body", string(body))
+		return backend.DataResponse{Error: fmt.Errorf("request failed, status: %s", res.Status)}
+	}
+
+	var events []EventDTO
+	if err := json.Unmarshal(body, &events); err != nil {
+		return backend.DataResponse{Error: err}
+	}
+
+	return backend.DataResponse{Frames: data.Frames{transformEventsToFrame(events, query.RefID)}}
+}
+
+func transformEventsToFrame(events []EventDTO, refID string) *data.Frame {
+	frame := data.NewFrame(refID,
+		data.NewField("time", nil, []time.Time{}),
+		data.NewField("title", nil, []string{}),
+		data.NewField("tags", nil, []string{}),
+		data.NewField("text", nil, []string{}),
+	)
+
+	for _, event := range events {
+		when := time.Unix(0, int64(event.When*float64(time.Second))).UTC()
+		frame.AppendRow(when, event.What, strings.Join(eventTags(event.Tags), ","), event.Data)
+	}
+
+	return frame
+}
+
+func eventTags(tags interface{}) []string {
+	switch tags := tags.(type) {
+	case string:
+		return strings.FieldsFunc(tags, func(r rune) bool { return r == ' ' || r == ',' })
+	case []interface{}:
+		result := make([]string, 0, len(tags))
+		for _, tag := range tags {
+			if tag, ok := tag.(string); ok {
+				result = append(result, tag)
+			}
+		}
+		return result
+	default:
+		return nil
+	}
+}
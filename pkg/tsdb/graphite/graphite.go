@@ -23,6 +23,7 @@ import (
 
 	"github.com/grafana/grafana/pkg/components/simplejson"
 	"github.com/grafana/grafana/pkg/infra/httpclient"
+	"github.com/grafana/grafana/pkg/infra/localcache"
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/infra/tracing"
 	"github.com/grafana/grafana/pkg/setting"
@@ -38,6 +39,9 @@ type Service struct {
 const (
 	TargetFullModelField = "targetFull"
 	TargetModelField     = "target"
+	// SeriesByTagModelField holds the tag expressions of queries built with the tag editor,
+	// used when the query has no target.
+	SeriesByTagModelField = "seriesByTag"
 )
 
 func ProvideService(httpClientProvider httpclient.Provider, tracer tracing.Tracer) *Service {
@@ -52,6 +56,8 @@ type datasourceInfo struct {
 	HTTPClient *http.Client
 	URL        string
 	Id         int64
+	// responses of the resource API, see resourceCacheTTLs
+	cache *localcache.CacheService
 }
 
 func newInstanceSettings(httpClientProvider httpclient.Provider) datasource.InstanceFactoryFunc {
@@ -70,6 +76,7 @@ func newInstanceSettings(httpClientProvider httpclient.Provider) datasource.Inst
 			HTTPClient: client,
 			URL:        settings.URL,
 			Id:         settings.ID,
+			cache:      localcache.New(resourceCacheTTL, 10*time.Minute),
 		}
 
 		return model, nil
@@ -96,8 +103,22 @@ func (s *Service) QueryData(ctx context.Context, req *backend.QueryDataRequest)
 		return nil, err
 	}
 
+	// events are queried on their own, the other queries are combined into a single render request
+	eventResponses := backend.Responses{}
+	var targetQueries []backend.DataQuery
+	for _, query := range req.Queries {
+		if isEventsQuery(query) {
+			eventResponses[query.RefID] = s.queryEvents(ctx, dsInfo, query)
+			continue
+		}
+		targetQueries = append(targetQueries, query)
+	}
+	if len(targetQueries) == 0 {
+		return &backend.QueryDataResponse{Responses: eventResponses}, nil
+	}
+
 	// take the first query in the request list, since all query should share the same timerange
-	q := req.Queries[0]
+	q := targetQueries[0]
 
 	/*
 		graphite doc about from and until, with sdk we are getting absolute instead of relative time
@@ -114,7 +135,7 @@ func (s *Service) QueryData(ctx context.Context, req *backend.QueryDataRequest)
 	// Calculate and get the last target of Graphite Request
 	var target string
 	emptyQueries := make([]string, 0)
-	for _, query := range req.Queries {
+	for _, query := range targetQueries {
 		model, err := simplejson.NewJson(query.JSON)
 		if err != nil {
 			return nil, err
@@ -126,6 +147,9 @@ func (s *Service) QueryData(ctx context.Context, req *backend.QueryDataRequest)
 		} else {
 			currTarget = model.Get(TargetModelField).MustString()
 		}
+		if currTarget == "" {
+			currTarget = buildSeriesByTag(model.Get(SeriesByTagModelField).MustStringArray())
+		}
 		if currTarget == "" {
 			s.logger.Debug("graphite", "empty query target", model)
 			emptyQueries = append(emptyQueries, fmt.Sprintf("Query: %v has no target", model))
@@ -179,6 +203,9 @@ func (s *Service) QueryData(ctx context.Context, req *backend.QueryDataRequest)
 	result.Responses["A"] = backend.DataResponse{
 		Frames: frames,
 	}
+	for refID, res := range eventResponses {
+		result.Responses[refID] = res
+	}
 
 	return &result, nil
 }
@@ -268,6 +295,24 @@ func (s *Service) createRequest(ctx context.Context, dsInfo *datasourceInfo, dat
 	return req, err
 }
 
+// buildSeriesByTag composes a seriesByTag target from tag expressions like `name=cpu` or `host=~web.*`.
+func buildSeriesByTag(expressions []string) string {
+	quoted := make([]string, 0, len(expressions))
+	for _, expression := range expressions {
+		expression = strings.TrimSpace(expression)
+		if expression == "" {
+			continue
+		}
+		expression = strings.ReplaceAll(expression, `\`, `\\`)
+		expression = strings.ReplaceAll(expression, `'`, `\'`)
+		quoted = append(quoted, "'"+expression+"'")
+	}
+	if len(quoted) == 0 {
+		return ""
+	}
+	return "seriesByTag(" + strings.Join(quoted, ", ") + ")"
+}
+
 func fixIntervalFormat(target string) string {
 	rMinute := regexp.MustCompile(`'(\d+)m'`)
 	target = rMinute.ReplaceAllStringFunc(target, func(m string) string {
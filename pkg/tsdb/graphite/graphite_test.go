@@ -112,3 +112,10 @@ func TestFixIntervalFormat(t *testing.T) {
 		}
 	})
 }
+
+func TestBuildSeriesByTag(t *testing.T) {
+	assert.Equal(t, "", buildSeriesByTag(nil))
+	assert.Equal(t, "", buildSeriesByTag([]string{" "}))
+	assert.Equal(t, "seriesByTag('name=cpu', 'host=~web.*')", buildSeriesByTag([]string{"name=cpu", " host=~web.* "}))
+	assert.Equal(t, `seriesByTag('app=it\'s')`, buildSeriesByTag([]string{"app=it's"}))
+}
@@ -0,0 +1,179 @@
+package graphite
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"path"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+)
+
+const (
+	resourceCacheTTL  = time.Minute
+	functionsCacheTTL = time.Hour
+)
+
+// resourceCacheTTLs lists the Graphite endpoints that can be called through the resource API
+// and how long their responses are cached.
+var resourceCacheTTLs = map[string]time.Duration{
+	"metrics/find":             resourceCacheTTL,
+	"tags/autoComplete/tags":   resourceCacheTTL,
+	"tags/autoComplete/values": resourceCacheTTL,
+	"functions":                functionsCacheTTL,
+}
+
+// Headers forwarded to Graphite which may change what the user is allowed to see, so they are
+// part of the cache key.
+var identityHeaders = []string{"Authorization", "X-Id-Token", "Cookie"}
+
+// Graphite 1.1.7 returns `Infinity` as default value of some function parameters, which is not valid JSON.
+// See https://github.com/graphite-project/graphite-web/issues/2609
+var infinityDefault = regexp.MustCompile(`"default": ?Infinity`)
+
+type cachedResource struct {
+	status int
+	body   []byte
+}
+
+func (s *Service) CallResource(ctx context.Context, req *backend.CallResourceRequest, sender backend.CallResourceResponseSender) error {
+	dsInfo, err := s.getDSInfo(req.PluginContext)
+	if err != nil {
+		return err
+	}
+
+	resourcePath := strings.Trim(req.Path, "/")
+	ttl, ok := resourceCacheTTLs[resourcePath]
+	if !ok {
+		return sender.Send(&backend.CallResourceResponse{Status: http.StatusNotFound})
+	}
+
+	query, form, err := resourceParams(req)
+	if err != nil {
+		return sender.Send(&backend.CallResourceResponse{Status: http.StatusBadRequest, Body: []byte(err.Error())})
+	}
+
+	headers := http.Header{}
+	for _, name := range identityHeaders {
+		for _, value := range http.Header(req.Headers).Values(name) {
+			headers.Add(name, value)
+		}
+	}
+
+	key := resourceCacheKey(resourcePath, query, form, headers)
+	if cached, found := dsInfo.cache.Get(key); found {
+		res := cached.(cachedResource)
+		return sender.Send(resourceResponse(res.status, res.body))
+	}
+
+	status, body, err := s.fetchResource(ctx, dsInfo, resourcePath, query, form, headers)
+	if err != nil {
+		return err
+	}
+	if resourcePath == "functions" {
+		body = infinityDefault.ReplaceAll(body, []byte(`"default": 1e9999`))
+	}
+	if status/100 == 2 {
+		dsInfo.cache.Set(key, cachedResource{status: status, body: body}, ttl)
+	}
+
+	return sender.Send(resourceResponse(status, body))
+}
+
+// resourceParams returns the query string and the form encoded body, which the frontend
+// uses to send long metric queries.
+func resourceParams(req *backend.CallResourceRequest) (url.Values, url.Values, error) {
+	u, err := url.Parse(req.URL)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var form url.Values
+	if req.Method == http.MethodPost && len(req.Body) > 0 {
+		form, err = url.ParseQuery(string(req.Body))
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid request body: %w", err)
+		}
+	}
+	return u.Query(), form, nil
+}
+
+// resourceCacheKey identifies a response by the combined parameters, so GET and POST requests
+// share cached responses, and by the identity headers of the user.
+func resourceCacheKey(resourcePath string, query url.Values, form url.Values, headers http.Header) string {
+	params := url.Values{}
+	for name, values := range query {
+		params[name] = append(params[name], values...)
+	}
+	for name, values := range form {
+		params[name] = append(params[name], values...)
+	}
+
+	hash := sha256.New()
+	for _, name := range identityHeaders {
+		for _, value := range headers.Values(name) {
+			_, _ = hash.Write([]byte(name + ":" + value + "\n"))
+		}
+	}
+	return resourcePath + "?" + params.Encode() + "|" + hex.EncodeToString(hash.Sum(nil))
+}
+
+// fetchResource sends the request to Graphite, POST requests keep their parameters in the form encoded body.
+func (s *Service) fetchResource(ctx context.Context, dsInfo *datasourceInfo, resourcePath string, query url.Values, form url.Values, headers http.Header) (int, []byte, error) {
+	u, err := url.Parse(dsInfo.URL)
+	if err != nil {
+		return 0, nil, err
+	}
+	u.Path = path.Join(u.Path, resourcePath)
+	u.RawQuery = query.Encode()
+
+	method := http.MethodGet
+	var body io.Reader
+	if form != nil {
+		method = http.MethodPost
+		body = strings.NewReader(form.Encode())
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), body)
+	if err != nil {
+		return 0, nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	for name, values := range headers {
+		req.Header[name] = values
+	}
+	if form != nil {
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	}
+
+	res, err := dsInfo.HTTPClient.Do(req)
+	if err != nil {
+		return 0, nil, err
+	}
+	defer func() {
+		if err := res.Body.Close(); err != nil {
+			s.logger.Warn("Failed to close response body", "err", err)
+		}
+	}()
+
+	resBody, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return 0, nil, err
+	}
+	return res.StatusCode, resBody, nil
+}
+
+func resourceResponse(status int, body []byte) *backend.CallResourceResponse {
+	return &backend.CallResourceResponse{
+		Status:  status,
+		Headers: map[string][]string{"Content-Type": {"application/json"}},
+		Body:    body,
+	}
+}
@@ -0,0 +1,145 @@
+package graphite
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana/pkg/infra/httpclient"
+	"github.com/grafana/grafana/pkg/infra/tracing"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeSender struct {
+	res *backend.CallResourceResponse
+}
+
+func (s *fakeSender) Send(res *backend.CallResourceResponse) error {
+	s.res = res
+	return nil
+}
+
+func newTestService(t *testing.T, handler http.HandlerFunc) (*Service, backend.PluginContext) {
+	t.Helper()
+
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+
+	pluginCtx := backend.PluginContext{
+		DataSourceInstanceSettings: &backend.DataSourceInstanceSettings{URL: srv.URL},
+	}
+	return ProvideService(httpclient.NewProvider(), tracing.InitializeTracerForTest()), pluginCtx
+}
+
+func TestCallResource(t *testing.T) {
+	requests := 0
+	var lastRequest *http.Request
+	service, pluginCtx := newTestService(t, func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		lastRequest = r
+		switch r.URL.Path {
+		case "/metrics/find":
+			require.Equal(t, "servers.*", r.FormValue("query"))
+			require.Equal(t, "-1h", r.URL.Query().Get("from"))
+			_, _ = w.Write([]byte(`[{"text": "web-1", "expandable": 1}]`))
+		case "/functions":
+			_, _ = w.Write([]byte(`{"movingWindow": {"params": [{"name": "n", "default": Infinity}]}}`))
+		default:
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	})
+
+	call := func(req *backend.CallResourceRequest) *backend.CallResourceResponse {
+		req.PluginContext = pluginCtx
+		sender := &fakeSender{}
+		require.NoError(t, service.CallResource(context.Background(), req, sender))
+		return sender.res
+	}
+
+	t.Run("metric find requests are sent to Graphite and cached", func(t *testing.T) {
+		req := &backend.CallResourceRequest{Method: http.MethodPost, Path: "metrics/find", URL: "metrics/find?from=-1h", Body: []byte("query=servers.*")}
+		res := call(req)
+		require.Equal(t, http.StatusOK, res.Status)
+		require.JSONEq(t, `[{"text": "web-1", "expandable": 1}]`, string(res.Body))
+		require.Equal(t, 1, requests)
+		// the body is forwarded as is, long queries would not fit into the query string
+		require.Equal(t, http.MethodPost, lastRequest.Method)
+		require.Equal(t, "servers.*", lastRequest.PostForm.Get("query"))
+		require.Empty(t, lastRequest.URL.Query().Get("query"))
+
+		call(&backend.CallResourceRequest{Method: http.MethodGet, Path: "metrics/find", URL: "metrics/find?from=-1h&query=servers.*"})
+		require.Equal(t, 1, requests)
+	})
+
+	t.Run("responses are cached per user identity", func(t *testing.T) {
+		before := requests
+		headers := map[string][]string{"Authorization": {"Bearer user-1"}}
+		call(&backend.CallResourceRequest{Method: http.MethodGet, Path: "metrics/find", URL: "metrics/find?from=-1h&query=servers.*", Headers: headers})
+		require.Equal(t, before+1, requests)
+		require.Equal(t, "Bearer user-1", lastRequest.Header.Get("Authorization"))
+
+		call(&backend.CallResourceRequest{Method: http.MethodGet, Path: "metrics/find", URL: "metrics/find?from=-1h&query=servers.*", Headers: headers})
+		require.Equal(t, before+1, requests)
+
+		call(&backend.CallResourceRequest{Method: http.MethodGet, Path: "metrics/find", URL: "metrics/find?from=-1h&query=servers.*", Headers: map[string][]string{"Authorization": {"Bearer user-2"}}})
+		require.Equal(t, before+2, requests)
+	})
+
+	t.Run("function definitions are fixed up", func(t *testing.T) {
+		res := call(&backend.CallResourceRequest{Method: http.MethodGet, Path: "functions", URL: "functions"})
+		require.Equal(t, `{"movingWindow": {"params": [{"name": "n", "default": 1e9999}]}}`, string(res.Body))
+	})
+
+	t.Run("errors are not cached", func(t *testing.T) {
+		before := requests
+		res := call(&backend.CallResourceRequest{Method: http.MethodGet, Path: "tags/autoComplete/tags", URL: "tags/autoComplete/tags?expr=name%3Dcpu"})
+		require.Equal(t, http.StatusInternalServerError, res.Status)
+		call(&backend.CallResourceRequest{Method: http.MethodGet, Path: "tags/autoComplete/tags", URL: "tags/autoComplete/tags?expr=name%3Dcpu"})
+		require.Equal(t, before+2, requests)
+	})
+
+	t.Run("other paths are not allowed", func(t *testing.T) {
+		before := requests
+		res := call(&backend.CallResourceRequest{Method: http.MethodGet, Path: "render", URL: "render?target=x"})
+		require.Equal(t, http.StatusNotFound, res.Status)
+		require.Equal(t, before, requests)
+	})
+}
+
+func TestEventsQuery(t *testing.T) {
+	service, pluginCtx := newTestService(t, func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/events/get_data", r.URL.Path)
+		require.Equal(t, "deploy prod", r.URL.Query().Get("tags"))
+		require.Equal(t, "1647338400", r.URL.Query().Get("from"))
+		_, _ = w.Write([]byte(`[
+			{"when": 1647339000, "what": "deploy api", "tags": ["deploy", "prod"], "data": "v1.2.3"},
+			{"when": 1647339600.5, "what": "deploy db", "tags": "deploy prod", "data": ""}
+		]`))
+	})
+
+	from := time.Date(2022, 3, 15, 10, 0, 0, 0, time.UTC)
+	resp, err := service.QueryData(context.Background(), &backend.QueryDataRequest{
+		PluginContext: pluginCtx,
+		Queries: []backend.DataQuery{{
+			RefID:     "Anno",
+			TimeRange: backend.TimeRange{From: from, To: from.Add(time.Hour)},
+			JSON:      []byte(`{"queryType": "events", "tags": ["deploy", "prod"]}`),
+		}},
+	})
+	require.NoError(t, err)
+
+	res := resp.Responses["Anno"]
+	require.NoError(t, res.Error)
+	require.Len(t, res.Frames, 1)
+	frame := res.Frames[0]
+	require.Equal(t, 2, frame.Rows())
+	require.Equal(t, from.Add(10*time.Minute), frame.Fields[0].At(0))
+	require.Equal(t, from.Add(20*time.Minute+500*time.Millisecond), frame.Fields[0].At(1))
+	require.Equal(t, "deploy api", frame.Fields[1].At(0))
+	require.Equal(t, "deploy,prod", frame.Fields[2].At(0))
+	require.Equal(t, "deploy,prod", frame.Fields[2].At(1))
+	require.Equal(t, "v1.2.3", frame.Fields[3].At(0))
+}
@@ -36,7 +36,15 @@ func Query(ctx context.Context, dsInfo *models.DatasourceInfo, tsdbQuery backend
 
 		// If the default changes also update labels/placeholder in config page.
 		maxSeries := dsInfo.MaxSeries
-		res := executeQuery(ctx, *qm, r, maxSeries)
+		var res backend.DataResponse
+		switch query.QueryType {
+		case models.QueryTypeMetricFind:
+			res = executeMetricFindQuery(ctx, *qm, r, query.RefID)
+		case models.QueryTypeAnnotation:
+			res = executeAnnotationQuery(ctx, *qm, r, query.RefID)
+		default:
+			res = executeQuery(ctx, *qm, r, maxSeries)
+		}
 
 		tRes.Responses[query.RefID] = res
 	}
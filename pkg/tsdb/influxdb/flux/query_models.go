@@ -21,6 +21,9 @@ type queryModel struct {
 	RawQuery string       `json:"query"`
 	Options  queryOptions `json:"options"`
 
+	// used by annotation queries
+	models.AnnotationColumns
+
 	// Not from JSON
 	TimeRange     backend.TimeRange `json:"-"`
 	MaxDataPoints int64             `json:"-"`
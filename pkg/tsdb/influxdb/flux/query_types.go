@@ -0,0 +1,81 @@
+package flux
+
+import (
+	"context"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/grafana/grafana/pkg/tsdb/influxdb/models"
+)
+
+// columns added by Flux to every table, they are never used for annotations
+var fluxSystemColumns = map[string]bool{
+	"result": true,
+	"table":  true,
+	"_start": true,
+	"_stop":  true,
+}
+
+// executeMetricFindQuery returns the distinct `_value`s of all tables, as returned by
+// functions like schema.tagValues() or schema.measurements().
+func executeMetricFindQuery(ctx context.Context, query queryModel, runner queryRunner, refID string) backend.DataResponse {
+	flux := interpolate(query)
+	glog.Debug("Executing Flux metric find query", "flux", flux)
+
+	result, err := runner.runQuery(ctx, flux)
+	if err != nil {
+		return backend.DataResponse{Error: err}
+	}
+
+	var values []string
+	for result.Next() {
+		values = append(values, models.ToString(result.Record().Value()))
+	}
+	if result.Err() != nil {
+		return backend.DataResponse{Error: result.Err()}
+	}
+
+	return backend.DataResponse{Frames: data.Frames{models.MetricFindFrame(refID, values)}}
+}
+
+// executeAnnotationQuery returns every record as an annotation event. The text is taken
+// from `_value` unless another column is configured.
+func executeAnnotationQuery(ctx context.Context, query queryModel, runner queryRunner, refID string) backend.DataResponse {
+	flux := interpolate(query)
+	glog.Debug("Executing Flux annotation query", "flux", flux)
+
+	result, err := runner.runQuery(ctx, flux)
+	if err != nil {
+		return backend.DataResponse{Error: err}
+	}
+
+	annotation := query.AnnotationColumns
+	if annotation.TextColumn == "" {
+		annotation.TextColumn = "_value"
+	}
+
+	var events []models.AnnotationEvent
+	var columns []string
+	for result.Next() {
+		if result.TableChanged() || columns == nil {
+			columns = columns[:0]
+			for _, col := range result.TableMetadata().Columns() {
+				if !fluxSystemColumns[col.Name()] {
+					columns = append(columns, col.Name())
+				}
+			}
+		}
+
+		record := result.Record()
+		values := make([]interface{}, len(columns))
+		for i, column := range columns {
+			values[i] = record.ValueByKey(column)
+		}
+		events = append(events, annotation.Event(columns, values, "_time"))
+	}
+	if result.Err() != nil {
+		return backend.DataResponse{Error: result.Err()}
+	}
+
+	return backend.DataResponse{Frames: data.Frames{models.AnnotationsToFrame(refID, events)}}
+}
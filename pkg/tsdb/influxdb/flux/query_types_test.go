@@ -0,0 +1,43 @@
+package flux
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestExecuteMetricFindQuery(t *testing.T) {
+	dr := executeMetricFindQuery(context.Background(), queryModel{}, &MockRunner{testDataPath: "tag_values.csv"}, "A")
+	require.NoError(t, dr.Error)
+	require.Len(t, dr.Frames, 1)
+
+	frame := dr.Frames[0]
+	require.Equal(t, "A", frame.Name)
+	require.Len(t, frame.Fields, 1)
+	require.Equal(t, "text", frame.Fields[0].Name)
+	require.Equal(t, 3, frame.Rows())
+	require.Equal(t, []interface{}{"host1", "host2", "host3"}, []interface{}{
+		frame.Fields[0].At(0), frame.Fields[0].At(1), frame.Fields[0].At(2),
+	})
+}
+
+func TestExecuteAnnotationQuery(t *testing.T) {
+	query := queryModel{}
+	query.TitleColumn = "_measurement"
+	query.TagsColumn = "a,b"
+
+	dr := executeAnnotationQuery(context.Background(), query, &MockRunner{testDataPath: "simple.csv"}, "A")
+	require.NoError(t, dr.Error)
+	require.Len(t, dr.Frames, 1)
+
+	frame := dr.Frames[0]
+	require.Greater(t, frame.Rows(), 0)
+	row := frame.RowCopy(0)
+	require.Equal(t, time.Date(2020, 2, 18, 10, 34, 8, 135814545, time.UTC), row[0])
+	require.Nil(t, row[1])
+	require.Equal(t, "test", row[2])
+	require.Equal(t, "1.4", row[3])
+	require.Equal(t, "1,adsfasdf", row[4])
+}
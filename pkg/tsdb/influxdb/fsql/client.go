@@ -0,0 +1,185 @@
+package fsql
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/url"
+	"time"
+
+	"github.com/apache/arrow/go/arrow/flight"
+	sdkhttpclient "github.com/grafana/grafana-plugin-sdk-go/backend/httpclient"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/grafana/grafana/pkg/tsdb/influxdb/models"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/backoff"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+)
+
+// type of the FlightSQL command used to run a statement, see arrow/format/FlightSql.proto
+const commandStatementQueryType = "type.googleapis.com/arrow.flight.protocol.sql.CommandStatementQuery"
+
+// client runs SQL statements against the FlightSQL endpoint of InfluxDB 3.
+type client struct {
+	flight   flight.FlightServiceClient
+	token    string
+	database string
+}
+
+// defaultDialTimeout is used when the datasource has no HTTP timeout options.
+const defaultDialTimeout = 30 * time.Second
+
+// NewConn creates the gRPC connection to the FlightSQL endpoint at rawURL. TLS settings and the dial
+// timeout are taken from the HTTP options of the datasource, proxies are read from the environment
+// like for HTTP requests. The connection is established lazily, so it can be kept for the lifetime
+// of the datasource instance.
+func NewConn(rawURL string, opts sdkhttpclient.Options, tlsConfig *tls.Config) (*grpc.ClientConn, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid URL in datasource configuration: %w", err)
+	}
+	if u.Host == "" {
+		return nil, fmt.Errorf("missing URL from datasource configuration")
+	}
+
+	creds := insecure.NewCredentials()
+	port := "80"
+	if u.Scheme == "https" {
+		if tlsConfig == nil {
+			tlsConfig = &tls.Config{}
+		}
+		if tlsConfig.MinVersion == 0 {
+			tlsConfig.MinVersion = tls.VersionTLS12
+		}
+		creds = credentials.NewTLS(tlsConfig)
+		port = "443"
+	}
+	addr := u.Host
+	if u.Port() == "" {
+		addr = net.JoinHostPort(u.Hostname(), port)
+	}
+
+	dialTimeout := defaultDialTimeout
+	if opts.Timeouts != nil && opts.Timeouts.DialTimeout > 0 {
+		dialTimeout = opts.Timeouts.DialTimeout
+	}
+
+	return grpc.Dial(addr,
+		grpc.WithTransportCredentials(creds),
+		grpc.WithConnectParams(grpc.ConnectParams{Backoff: backoff.DefaultConfig, MinConnectTimeout: dialTimeout}),
+	)
+}
+
+func newClient(dsInfo *models.DatasourceInfo) (*client, error) {
+	if dsInfo.FlightSQLConn == nil {
+		return nil, fmt.Errorf("missing FlightSQL connection, check the URL in datasource configuration")
+	}
+
+	database := dsInfo.Database
+	if database == "" {
+		database = dsInfo.DefaultBucket
+	}
+	if database == "" {
+		return nil, fmt.Errorf("missing database in datasource configuration")
+	}
+
+	return &client{
+		flight:   flight.NewFlightServiceClient(dsInfo.FlightSQLConn),
+		token:    dsInfo.Token,
+		database: database,
+	}, nil
+}
+
+// query runs the statement and returns all the record batches of the result as a single frame.
+func (c *client) query(ctx context.Context, sql string) (*data.Frame, error) {
+	ctx = metadata.AppendToOutgoingContext(ctx, "database", c.database)
+	if c.token != "" {
+		ctx = metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer "+c.token)
+	}
+
+	cmd, err := statementCommand(sql)
+	if err != nil {
+		return nil, err
+	}
+
+	info, err := c.flight.GetFlightInfo(ctx, &flight.FlightDescriptor{
+		Type: flight.FlightDescriptor_CMD,
+		Cmd:  cmd,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var frame *data.Frame
+	for _, endpoint := range info.Endpoint {
+		stream, err := c.flight.DoGet(ctx, endpoint.Ticket)
+		if err != nil {
+			return nil, err
+		}
+		frame, err = readRecords(stream, frame)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if frame == nil {
+		frame = data.NewFrame("")
+	}
+	return frame, nil
+}
+
+// statementCommand encodes the statement as a CommandStatementQuery wrapped in an Any message,
+// which is what FlightSQL servers expect in the descriptor of GetFlightInfo.
+func statementCommand(sql string) ([]byte, error) {
+	statement := protowire.AppendTag(nil, 1, protowire.BytesType)
+	statement = protowire.AppendString(statement, sql)
+
+	return proto.Marshal(&anypb.Any{
+		TypeUrl: commandStatementQueryType,
+		Value:   statement,
+	})
+}
+
+// readRecords appends the rows of all the record batches of the stream to frame, which is
+// created from the first batch when nil.
+func readRecords(stream flight.DataStreamReader, frame *data.Frame) (*data.Frame, error) {
+	reader, err := flight.NewRecordReader(stream)
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Release()
+
+	for reader.Next() {
+		batch, err := data.FromArrowRecord(reader.Record())
+		if err != nil {
+			return nil, err
+		}
+		frame, err = appendFrame(frame, batch)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if err := reader.Err(); err != nil {
+		return nil, err
+	}
+	return frame, nil
+}
+
+func appendFrame(frame *data.Frame, batch *data.Frame) (*data.Frame, error) {
+	if frame == nil {
+		return batch, nil
+	}
+	if len(frame.Fields) != len(batch.Fields) {
+		return nil, fmt.Errorf("record batches with different schemas in the same result")
+	}
+	for i := 0; i < batch.Rows(); i++ {
+		frame.AppendRow(batch.RowCopy(i)...)
+	}
+	return frame, nil
+}
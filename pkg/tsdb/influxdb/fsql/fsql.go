@@ -0,0 +1,142 @@
+package fsql
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/tsdb/influxdb/models"
+)
+
+var (
+	glog = log.New("tsdb.influx_fsql")
+)
+
+// results are converted to time series unless the query asks for a table
+const formatTimeSeries = "time_series"
+
+// queryModel represents a SQL query.
+type queryModel struct {
+	RawQuery string `json:"query"`
+	// time_series (the default) or table
+	Format string `json:"resultFormat"`
+
+	// used by annotation queries
+	models.AnnotationColumns
+
+	// Not from JSON
+	TimeRange backend.TimeRange `json:"-"`
+	Interval  time.Duration     `json:"-"`
+}
+
+// queryRunner runs an interpolated SQL statement, it is an interface to help testing.
+type queryRunner interface {
+	query(ctx context.Context, sql string) (*data.Frame, error)
+}
+
+// Query runs the SQL queries against the FlightSQL endpoint of InfluxDB 3 and returns the results.
+func Query(ctx context.Context, dsInfo *models.DatasourceInfo, req backend.QueryDataRequest) (
+	*backend.QueryDataResponse, error) {
+	c, err := newClient(dsInfo)
+	if err != nil {
+		return &backend.QueryDataResponse{}, err
+	}
+
+	return runQueries(ctx, c, req), nil
+}
+
+func runQueries(ctx context.Context, runner queryRunner, req backend.QueryDataRequest) *backend.QueryDataResponse {
+	tRes := backend.NewQueryDataResponse()
+	for _, query := range req.Queries {
+		qm, err := getQueryModel(query)
+		if err != nil {
+			tRes.Responses[query.RefID] = backend.DataResponse{Error: err}
+			continue
+		}
+		tRes.Responses[query.RefID] = executeQuery(ctx, *qm, runner, query.QueryType, query.RefID)
+	}
+	return tRes
+}
+
+func getQueryModel(query backend.DataQuery) (*queryModel, error) {
+	model := &queryModel{}
+	if err := json.Unmarshal(query.JSON, model); err != nil {
+		return nil, fmt.Errorf("error reading query: %w", err)
+	}
+	if model.Format == "" {
+		model.Format = formatTimeSeries
+	}
+	model.TimeRange = query.TimeRange
+	model.Interval = query.Interval
+	if model.Interval.Milliseconds() == 0 {
+		model.Interval = time.Millisecond
+	}
+	return model, nil
+}
+
+func executeQuery(ctx context.Context, query queryModel, runner queryRunner, queryType string, refID string) backend.DataResponse {
+	sql, err := interpolate(query)
+	if err != nil {
+		return backend.DataResponse{Error: err}
+	}
+
+	glog.Debug("Executing SQL query", "sql", sql)
+	frame, err := runner.query(ctx, sql)
+	if err != nil {
+		glog.Warn("SQL query failed", "err", err, "query", sql)
+		return backend.DataResponse{Error: err}
+	}
+
+	switch queryType {
+	case models.QueryTypeMetricFind:
+		frame = metricFindFrame(refID, frame)
+	case models.QueryTypeAnnotation:
+		frame = annotationFrame(refID, frame, query.AnnotationColumns)
+	default:
+		frame.RefID = refID
+		if query.Format == formatTimeSeries && frame.TimeSeriesSchema().Type == data.TimeSeriesTypeLong {
+			frame, err = data.LongToWide(frame, nil)
+			if err != nil {
+				return backend.DataResponse{Error: fmt.Errorf("failed to convert the result to time series, make sure it is ordered by time: %w", err)}
+			}
+		}
+	}
+
+	frame.SetMeta(&data.FrameMeta{ExecutedQueryString: sql})
+	return backend.DataResponse{Frames: data.Frames{frame}}
+}
+
+// metricFindFrame returns the values of the first column.
+func metricFindFrame(refID string, frame *data.Frame) *data.Frame {
+	var values []string
+	if len(frame.Fields) > 0 {
+		field := frame.Fields[0]
+		for i := 0; i < field.Len(); i++ {
+			value, _ := field.ConcreteAt(i)
+			values = append(values, models.ToString(value))
+		}
+	}
+	return models.MetricFindFrame(refID, values)
+}
+
+// annotationFrame returns every row as an annotation event, the time being taken from the "time" column.
+func annotationFrame(refID string, frame *data.Frame, annotation models.AnnotationColumns) *data.Frame {
+	columns := make([]string, len(frame.Fields))
+	for i, field := range frame.Fields {
+		columns[i] = field.Name
+	}
+
+	events := make([]models.AnnotationEvent, 0, frame.Rows())
+	for row := 0; row < frame.Rows(); row++ {
+		values := make([]interface{}, len(frame.Fields))
+		for i, field := range frame.Fields {
+			values[i], _ = field.ConcreteAt(row)
+		}
+		events = append(events, annotation.Event(columns, values, "time"))
+	}
+	return models.AnnotationsToFrame(refID, events)
+}
@@ -0,0 +1,113 @@
+package fsql
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	sdkhttpclient "github.com/grafana/grafana-plugin-sdk-go/backend/httpclient"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/grafana/grafana/pkg/tsdb/influxdb/models"
+	"github.com/stretchr/testify/require"
+	"github.com/xorcare/pointer"
+)
+
+type fakeRunner struct {
+	frame *data.Frame
+	sql   string
+}
+
+func (r *fakeRunner) query(ctx context.Context, sql string) (*data.Frame, error) {
+	r.sql = sql
+	return r.frame, nil
+}
+
+func longFrame() *data.Frame {
+	t1 := time.Date(2021, 9, 22, 10, 0, 0, 0, time.UTC)
+	t2 := t1.Add(time.Minute)
+	return data.NewFrame("",
+		data.NewField("time", nil, []time.Time{t1, t1, t2, t2}),
+		data.NewField("host", nil, []*string{pointer.String("a"), pointer.String("b"), pointer.String("a"), pointer.String("b")}),
+		data.NewField("usage", nil, []*float64{pointer.Float64(1), pointer.Float64(2), pointer.Float64(3), nil}),
+	)
+}
+
+func runQuery(t *testing.T, runner queryRunner, queryType string, json string) backend.DataResponse {
+	t.Helper()
+	res := runQueries(context.Background(), runner, backend.QueryDataRequest{
+		Queries: []backend.DataQuery{{
+			RefID:     "A",
+			QueryType: queryType,
+			JSON:      []byte(json),
+			Interval:  time.Minute,
+			TimeRange: backend.TimeRange{From: time.Unix(0, 0), To: time.Unix(60, 0)},
+		}},
+	})
+	return res.Responses["A"]
+}
+
+func TestQuery(t *testing.T) {
+	t.Run("long results are converted to time series", func(t *testing.T) {
+		runner := &fakeRunner{frame: longFrame()}
+		dr := runQuery(t, runner, "", `{"query": "SELECT * FROM cpu WHERE $__timeFilter(time) ORDER BY time"}`)
+		require.NoError(t, dr.Error)
+		require.Len(t, dr.Frames, 1)
+
+		frame := dr.Frames[0]
+		require.Equal(t, data.TimeSeriesTypeWide, frame.TimeSeriesSchema().Type)
+		require.Len(t, frame.Fields, 3)
+		require.Equal(t, data.Labels{"host": "a"}, frame.Fields[1].Labels)
+		require.Equal(t, data.Labels{"host": "b"}, frame.Fields[2].Labels)
+		require.Equal(t, runner.sql, frame.Meta.ExecutedQueryString)
+		require.Equal(t, "SELECT * FROM cpu WHERE time >= '1970-01-01T00:00:00Z' AND time <= '1970-01-01T00:01:00Z' ORDER BY time", runner.sql)
+	})
+
+	t.Run("table results are kept as is", func(t *testing.T) {
+		dr := runQuery(t, &fakeRunner{frame: longFrame()}, "", `{"query": "SELECT * FROM cpu", "resultFormat": "table"}`)
+		require.NoError(t, dr.Error)
+		require.Equal(t, 4, dr.Frames[0].Rows())
+		require.Equal(t, "A", dr.Frames[0].RefID)
+	})
+
+	t.Run("metric find returns the distinct values of the first column", func(t *testing.T) {
+		frame := data.NewFrame("", data.NewField("host", nil, []*string{pointer.String("a"), pointer.String("b"), pointer.String("a"), nil}))
+		dr := runQuery(t, &fakeRunner{frame: frame}, models.QueryTypeMetricFind, `{"query": "SELECT DISTINCT host FROM cpu"}`)
+		require.NoError(t, dr.Error)
+
+		field := dr.Frames[0].Fields[0]
+		require.Equal(t, "text", field.Name)
+		require.Equal(t, 3, field.Len())
+		require.Equal(t, []interface{}{"a", "b", ""}, []interface{}{field.At(0), field.At(1), field.At(2)})
+	})
+
+	t.Run("annotation rows are returned as events", func(t *testing.T) {
+		dr := runQuery(t, &fakeRunner{frame: longFrame()}, models.QueryTypeAnnotation, `{"query": "SELECT * FROM cpu", "textColumn": "usage", "tagsColumn": "host"}`)
+		require.NoError(t, dr.Error)
+
+		frame := dr.Frames[0]
+		require.Equal(t, 4, frame.Rows())
+		row := frame.RowCopy(0)
+		require.Equal(t, time.Date(2021, 9, 22, 10, 0, 0, 0, time.UTC), row[0])
+		require.Equal(t, "1", row[3])
+		require.Equal(t, "a", row[4])
+	})
+}
+
+func TestNewConn(t *testing.T) {
+	conn, err := NewConn("https://influx.example.com", sdkhttpclient.Options{Timeouts: &sdkhttpclient.TimeoutOptions{DialTimeout: time.Second}}, nil)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = conn.Close() })
+	require.Equal(t, "influx.example.com:443", conn.Target())
+
+	conn, err = NewConn("http://influx.example.com:8086", sdkhttpclient.Options{}, nil)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = conn.Close() })
+	require.Equal(t, "influx.example.com:8086", conn.Target())
+
+	_, err = NewConn("influx.example.com", sdkhttpclient.Options{}, nil)
+	require.Error(t, err)
+
+	_, err = newClient(&models.DatasourceInfo{Database: "db"})
+	require.Error(t, err)
+}
@@ -0,0 +1,59 @@
+package fsql
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// $__timeFilter(column) filters the column on the time range of the query
+// $__timeFrom and $__timeTo are the bounds of the time range, as quoted RFC3339 timestamps
+// $__dateBin(column) groups the column in buckets of the query interval
+// $__interval_ms is the query interval in milliseconds
+
+var macroExp = regexp.MustCompile(`\$__(\w+)(?:\(([^)]*)\))?`)
+
+func interpolate(query queryModel) (string, error) {
+	from := quoteTime(query.TimeRange.From)
+	to := quoteTime(query.TimeRange.To)
+	intervalMs := query.Interval.Milliseconds()
+
+	var err error
+	sql := macroExp.ReplaceAllStringFunc(query.RawQuery, func(match string) string {
+		groups := macroExp.FindStringSubmatch(match)
+		name, arg := groups[1], strings.TrimSpace(groups[2])
+
+		switch name {
+		case "timeFilter":
+			if arg == "" {
+				err = fmt.Errorf("missing column argument for macro $__timeFilter")
+				return match
+			}
+			return fmt.Sprintf("%s >= %s AND %s <= %s", arg, from, arg, to)
+		case "timeFrom":
+			return from
+		case "timeTo":
+			return to
+		case "dateBin":
+			if arg == "" {
+				err = fmt.Errorf("missing column argument for macro $__dateBin")
+				return match
+			}
+			return fmt.Sprintf("date_bin(interval '%d millisecond', %s)", intervalMs, arg)
+		case "interval_ms":
+			return strconv.FormatInt(intervalMs, 10)
+		default:
+			return match
+		}
+	})
+	if err != nil {
+		return "", err
+	}
+	return sql, nil
+}
+
+func quoteTime(t time.Time) string {
+	return "'" + t.UTC().Format(time.RFC3339Nano) + "'"
+}
@@ -0,0 +1,59 @@
+package fsql
+
+import (
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInterpolate(t *testing.T) {
+	timeRange := backend.TimeRange{
+		From: time.Unix(1632305571, 310985041),
+		To:   time.Unix(1632309171, 310985042),
+	}
+
+	tests := []struct {
+		name   string
+		before string
+		after  string
+	}{
+		{
+			name:   "time filter",
+			before: `SELECT * FROM cpu WHERE $__timeFilter(time)`,
+			after:  `SELECT * FROM cpu WHERE time >= '2021-09-22T10:12:51.310985041Z' AND time <= '2021-09-22T11:12:51.310985042Z'`,
+		},
+		{
+			name:   "time range bounds",
+			before: `time > $__timeFrom AND time < $__timeTo`,
+			after:  `time > '2021-09-22T10:12:51.310985041Z' AND time < '2021-09-22T11:12:51.310985042Z'`,
+		},
+		{
+			name:   "date bin and interval",
+			before: `SELECT $__dateBin(time) AS time, $__interval_ms AS ms`,
+			after:  `SELECT date_bin(interval '61258 millisecond', time) AS time, 61258 AS ms`,
+		},
+		{
+			name:   "unknown macros are kept",
+			before: `SELECT $__unknown(time)`,
+			after:  `SELECT $__unknown(time)`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sql, err := interpolate(queryModel{
+				RawQuery:  tt.before,
+				TimeRange: timeRange,
+				Interval:  time.Duration(61258) * time.Millisecond,
+			})
+			require.NoError(t, err)
+			require.Equal(t, tt.after, sql)
+		})
+	}
+
+	t.Run("time filter without column", func(t *testing.T) {
+		_, err := interpolate(queryModel{RawQuery: `WHERE $__timeFilter()`, TimeRange: timeRange})
+		require.Error(t, err)
+	})
+}
@@ -8,6 +8,7 @@ import (
 
 	"github.com/grafana/grafana-plugin-sdk-go/backend"
 	"github.com/grafana/grafana/pkg/tsdb/influxdb/flux"
+	"github.com/grafana/grafana/pkg/tsdb/influxdb/fsql"
 	"github.com/grafana/grafana/pkg/tsdb/influxdb/models"
 )
 
@@ -31,6 +32,8 @@ func (s *Service) CheckHealth(ctx context.Context, req *backend.CheckHealthReque
 		return CheckFluxHealth(ctx, dsInfo, s, req)
 	case influxVersionInfluxQL:
 		return CheckInfluxQLHealth(ctx, dsInfo, s)
+	case influxVersionSQL:
+		return CheckSQLHealth(ctx, dsInfo, s, req)
 	default:
 		return getHealthCheckMessage(s, "", errors.New("unknown influx version"))
 	}
@@ -70,6 +73,35 @@ func CheckFluxHealth(ctx context.Context, dsInfo *models.DatasourceInfo, s *Serv
 	return getHealthCheckMessage(s, "", errors.New("error getting flux query buckets"))
 }
 
+func CheckSQLHealth(ctx context.Context, dsInfo *models.DatasourceInfo, s *Service,
+	req *backend.CheckHealthRequest) (*backend.CheckHealthResult, error) {
+	ds, err := fsql.Query(ctx, dsInfo, backend.QueryDataRequest{
+		PluginContext: req.PluginContext,
+		Queries: []backend.DataQuery{
+			{
+				RefID:     refID,
+				JSON:      []byte(`{ "query": "SELECT table_name FROM information_schema.tables WHERE table_schema = 'iox'", "resultFormat": "table" }`),
+				Interval:  1 * time.Minute,
+				TimeRange: backend.TimeRange{From: time.Now().AddDate(0, 0, -1), To: time.Now()},
+			},
+		},
+	})
+
+	if err != nil {
+		return getHealthCheckMessage(s, "error performing sql query", err)
+	}
+	if res, ok := ds.Responses[refID]; ok {
+		if res.Error != nil {
+			return getHealthCheckMessage(s, "error reading tables", res.Error)
+		}
+		if len(res.Frames) > 0 {
+			return getHealthCheckMessage(s, fmt.Sprintf("%d tables found", res.Frames[0].Rows()), nil)
+		}
+	}
+
+	return getHealthCheckMessage(s, "", errors.New("error getting sql query tables"))
+}
+
 func CheckInfluxQLHealth(ctx context.Context, dsInfo *models.DatasourceInfo, s *Service) (*backend.CheckHealthResult, error) {
 	queryString := "SHOW measurements"
 	hcRequest, err := s.createRequest(ctx, dsInfo, queryString)
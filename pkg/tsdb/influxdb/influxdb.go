@@ -17,6 +17,7 @@ import (
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/setting"
 	"github.com/grafana/grafana/pkg/tsdb/influxdb/flux"
+	"github.com/grafana/grafana/pkg/tsdb/influxdb/fsql"
 	"github.com/grafana/grafana/pkg/tsdb/influxdb/models"
 )
 
@@ -80,6 +81,16 @@ func newInstanceSettings(httpClientProvider httpclient.Provider) datasource.Inst
 			MaxSeries:     maxSeries,
 			Token:         settings.DecryptedSecureJSONData["token"],
 		}
+		if version == influxVersionSQL {
+			tlsConfig, err := httpClientProvider.GetTLSConfig(opts)
+			if err != nil {
+				return nil, err
+			}
+			model.FlightSQLConn, err = fsql.NewConn(settings.URL, opts, tlsConfig)
+			if err != nil {
+				return nil, err
+			}
+		}
 		return model, nil
 	}
 }
@@ -91,9 +102,11 @@ func (s *Service) QueryData(ctx context.Context, req *backend.QueryDataRequest)
 	if err != nil {
 		return nil, err
 	}
-	version := dsInfo.Version
-	if version == "Flux" {
+	switch dsInfo.Version {
+	case influxVersionFlux:
 		return flux.Query(ctx, dsInfo, *req)
+	case influxVersionSQL:
+		return fsql.Query(ctx, dsInfo, *req)
 	}
 
 	s.glog.Debug("Making a non-Flux type query")
@@ -7,6 +7,7 @@ import (
 
 	"github.com/grafana/grafana-plugin-sdk-go/backend"
 	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/tsdb/influxdb/models"
 )
 
 type InfluxdbQueryParser struct{}
@@ -28,6 +29,17 @@ func (qp *InfluxdbQueryParser) Parse(query backend.DataQuery) (*Query, error) {
 
 	measurement := model.Get("measurement").MustString("")
 
+	// variable and annotation queries are always written by hand
+	if query.QueryType == models.QueryTypeMetricFind || query.QueryType == models.QueryTypeAnnotation {
+		useRawQuery = true
+	}
+	annotation := models.AnnotationColumns{
+		TitleColumn:   model.Get("titleColumn").MustString(""),
+		TextColumn:    model.Get("textColumn").MustString(""),
+		TagsColumn:    model.Get("tagsColumn").MustString(""),
+		TimeEndColumn: model.Get("timeEndColumn").MustString(""),
+	}
+
 	tags, err := qp.parseTags(model)
 	if err != nil {
 		return nil, err
@@ -66,6 +78,8 @@ func (qp *InfluxdbQueryParser) Parse(query backend.DataQuery) (*Query, error) {
 		Limit:       limit,
 		Slimit:      slimit,
 		OrderByTime: orderByTime,
+		QueryType:   query.QueryType,
+		Annotation:  annotation,
 	}, nil
 }
 
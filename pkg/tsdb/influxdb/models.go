@@ -1,6 +1,10 @@
 package influxdb
 
-import "time"
+import (
+	"time"
+
+	"github.com/grafana/grafana/pkg/tsdb/influxdb/models"
+)
 
 type Query struct {
 	Measurement string
@@ -17,6 +21,9 @@ type Query struct {
 	Slimit      string
 	OrderByTime string
 	RefID       string
+	// QueryType is empty for metric queries, or one of models.QueryTypeMetricFind and models.QueryTypeAnnotation
+	QueryType  string
+	Annotation models.AnnotationColumns
 }
 
 type Tag struct {
@@ -2,12 +2,17 @@ package models
 
 import (
 	"net/http"
+
+	"google.golang.org/grpc"
 )
 
 type DatasourceInfo struct {
 	HTTPClient *http.Client
 	Token      string
 	URL        string
+	// FlightSQLConn is the connection used by the SQL query language, it is kept for the lifetime of
+	// the instance and nil for the other query languages.
+	FlightSQLConn *grpc.ClientConn
 
 	Database      string `json:"database"`
 	Version       string `json:"version"`
@@ -17,3 +22,10 @@ type DatasourceInfo struct {
 	Organization  string `json:"organization"`
 	MaxSeries     int    `json:"maxSeries"`
 }
+
+// Dispose closes the FlightSQL connection when the instance is replaced.
+func (d *DatasourceInfo) Dispose() {
+	if d.FlightSQLConn != nil {
+		_ = d.FlightSQLConn.Close()
+	}
+}
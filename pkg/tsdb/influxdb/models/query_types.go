@@ -0,0 +1,168 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+)
+
+// Query types handled by the backend besides the regular metric queries. They are
+// set on the query's queryType and work with every query language.
+const (
+	// QueryTypeMetricFind returns the distinct values of the query as a single "text" field,
+	// used for template variables.
+	QueryTypeMetricFind = "metricFind"
+	// QueryTypeAnnotation returns the rows of the query as annotation events.
+	QueryTypeAnnotation = "annotation"
+)
+
+// AnnotationColumns tells which columns of an annotation query hold the parts of the events.
+type AnnotationColumns struct {
+	TitleColumn   string `json:"titleColumn"`
+	TextColumn    string `json:"textColumn"`
+	TagsColumn    string `json:"tagsColumn"`
+	TimeEndColumn string `json:"timeEndColumn"`
+}
+
+func (c AnnotationColumns) isTagsColumn(column string) bool {
+	for _, name := range strings.Split(strings.ReplaceAll(c.TagsColumn, " ", ""), ",") {
+		if name != "" && name == column {
+			return true
+		}
+	}
+	return false
+}
+
+// AnnotationEvent is a single row of an annotation query.
+type AnnotationEvent struct {
+	Time    time.Time
+	TimeEnd *time.Time
+	Title   string
+	Text    string
+	Tags    []string
+}
+
+// Event maps a row to an annotation event. Without a title column, the first column that is
+// not configured for anything else is used as title, like the frontend does.
+func (c AnnotationColumns) Event(columns []string, values []interface{}, timeColumn string) AnnotationEvent {
+	event := AnnotationEvent{}
+	titleFound := false
+
+	for i, column := range columns {
+		if i >= len(values) {
+			break
+		}
+		value := values[i]
+
+		switch {
+		case column == timeColumn:
+			if t, ok := toTime(value); ok {
+				event.Time = t
+			}
+		case column == "sequence_number":
+		case column == c.TitleColumn:
+			event.Title = ToString(value)
+			titleFound = true
+		case c.isTagsColumn(column):
+			for _, tag := range strings.Split(ToString(value), ",") {
+				if tag != "" {
+					event.Tags = append(event.Tags, tag)
+				}
+			}
+		case column == c.TextColumn:
+			event.Text = ToString(value)
+		case column == c.TimeEndColumn:
+			if t, ok := toTime(value); ok {
+				event.TimeEnd = &t
+			}
+		case !titleFound && c.TitleColumn == "":
+			event.Title = ToString(value)
+			titleFound = true
+		}
+	}
+
+	return event
+}
+
+// AnnotationsToFrame returns the events in the frame layout used by backend annotation queries.
+func AnnotationsToFrame(refID string, events []AnnotationEvent) *data.Frame {
+	frame := data.NewFrame(refID,
+		data.NewField("time", nil, []time.Time{}),
+		data.NewField("timeEnd", nil, []*time.Time{}),
+		data.NewField("title", nil, []string{}),
+		data.NewField("text", nil, []string{}),
+		data.NewField("tags", nil, []string{}),
+	)
+	for _, event := range events {
+		frame.AppendRow(event.Time, event.TimeEnd, event.Title, event.Text, strings.Join(event.Tags, ","))
+	}
+	return frame
+}
+
+// MetricFindFrame returns the distinct values, in order of appearance, as a frame with a single "text" field.
+func MetricFindFrame(refID string, values []string) *data.Frame {
+	seen := make(map[string]bool, len(values))
+	texts := make([]string, 0, len(values))
+	for _, value := range values {
+		if seen[value] {
+			continue
+		}
+		seen[value] = true
+		texts = append(texts, value)
+	}
+	return data.NewFrame(refID, data.NewField("text", nil, texts))
+}
+
+// ToString converts a value of a query result to its text representation, nil becoming an empty string.
+func ToString(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case *string:
+		if v == nil {
+			return ""
+		}
+		return *v
+	case time.Time:
+		return v.Format(time.RFC3339Nano)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+func toTime(value interface{}) (time.Time, bool) {
+	switch v := value.(type) {
+	case time.Time:
+		return v, true
+	case *time.Time:
+		if v == nil {
+			return time.Time{}, false
+		}
+		return *v, true
+	case json.Number:
+		// InfluxQL is queried with epoch=ms
+		ms, err := v.Int64()
+		if err != nil {
+			f, err := v.Float64()
+			if err != nil {
+				return time.Time{}, false
+			}
+			ms = int64(f)
+		}
+		return time.UnixMilli(ms).UTC(), true
+	case float64:
+		return time.UnixMilli(int64(v)).UTC(), true
+	case int64:
+		return time.UnixMilli(v).UTC(), true
+	case string:
+		t, err := time.Parse(time.RFC3339Nano, v)
+		return t, err == nil
+	default:
+		return time.Time{}, false
+	}
+}
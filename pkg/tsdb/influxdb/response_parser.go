@@ -11,6 +11,7 @@ import (
 
 	"github.com/grafana/grafana-plugin-sdk-go/backend"
 	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/grafana/grafana/pkg/tsdb/influxdb/models"
 )
 
 type ResponseParser struct{}
@@ -36,6 +37,10 @@ func (rp *ResponseParser) Parse(buf io.ReadCloser, queries []Query) *backend.Que
 	for i, result := range response.Results {
 		if result.Error != "" {
 			resp.Responses[queries[i].RefID] = backend.DataResponse{Error: fmt.Errorf(result.Error)}
+		} else if queries[i].QueryType == models.QueryTypeMetricFind {
+			resp.Responses[queries[i].RefID] = backend.DataResponse{Frames: data.Frames{transformMetricFindRows(result.Series, queries[i])}}
+		} else if queries[i].QueryType == models.QueryTypeAnnotation {
+			resp.Responses[queries[i].RefID] = backend.DataResponse{Frames: data.Frames{transformAnnotationRows(result.Series, queries[i])}}
 		} else {
 			resp.Responses[queries[i].RefID] = backend.DataResponse{Frames: transformRows(result.Series, queries[i])}
 		}
@@ -44,6 +49,61 @@ func (rp *ResponseParser) Parse(buf io.ReadCloser, queries []Query) *backend.Que
 	return resp
 }
 
+// transformMetricFindRows returns the values of all series in a single frame. SHOW TAG VALUES returns
+// the key and the value of the tag, every other statement has the values in the first column.
+func transformMetricFindRows(rows []Row, query Query) *data.Frame {
+	isTagValues := isShowTagValues(query.RawQuery)
+
+	var values []string
+	for _, row := range rows {
+		column := 0
+		if isTagValues {
+			column = 1
+		}
+		for _, valuePair := range row.Values {
+			if len(valuePair) > column {
+				values = append(values, models.ToString(valuePair[column]))
+			}
+		}
+	}
+	return models.MetricFindFrame(query.RefID, values)
+}
+
+// isShowTagValues returns true if the statement is a SHOW TAG VALUES one, leading comments are skipped.
+func isShowTagValues(statement string) bool {
+	for {
+		statement = strings.TrimSpace(statement)
+		switch {
+		case strings.HasPrefix(statement, "--"):
+			end := strings.IndexByte(statement, '\n')
+			if end < 0 {
+				return false
+			}
+			statement = statement[end+1:]
+		case strings.HasPrefix(statement, "/*"):
+			end := strings.Index(statement, "*/")
+			if end < 0 {
+				return false
+			}
+			statement = statement[end+2:]
+		default:
+			keywords := strings.Fields(statement)
+			return len(keywords) >= 3 && strings.EqualFold(keywords[0], "SHOW") &&
+				strings.EqualFold(keywords[1], "TAG") && strings.EqualFold(keywords[2], "VALUES")
+		}
+	}
+}
+
+func transformAnnotationRows(rows []Row, query Query) *data.Frame {
+	var events []models.AnnotationEvent
+	for _, row := range rows {
+		for _, values := range row.Values {
+			events = append(events, query.Annotation.Event(row.Columns, values, "time"))
+		}
+	}
+	return models.AnnotationsToFrame(query.RefID, events)
+}
+
 func parseJSON(buf io.ReadCloser) (Response, error) {
 	var response Response
 	dec := json.NewDecoder(buf)
@@ -11,6 +11,7 @@ import (
 	"github.com/google/go-cmp/cmp"
 	"github.com/grafana/grafana-plugin-sdk-go/backend"
 	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/grafana/grafana/pkg/tsdb/influxdb/models"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"github.com/xorcare/pointer"
@@ -729,6 +730,80 @@ func TestInfluxdbResponseParser(t *testing.T) {
 		_, err := parseTimestamp("hello")
 		require.Error(t, err)
 	})
+
+	t.Run("Influxdb response parser should parse metric find queries", func(t *testing.T) {
+		parser := &ResponseParser{}
+
+		response := `
+		{
+			"results": [
+				{
+					"series": [
+						{"name": "cpu", "columns": ["key","value"], "values": [["host","server1"],["host","server2"]]},
+						{"name": "mem", "columns": ["key","value"], "values": [["host","server1"],["host","server3"]]}
+					]
+				}
+			]
+		}
+		`
+
+		query := Query{RefID: "A", RawQuery: `SHOW TAG VALUES WITH KEY = "host"`, QueryType: models.QueryTypeMetricFind}
+		result := parser.Parse(prepare(response), []Query{query})
+
+		require.NoError(t, result.Responses["A"].Error)
+		frame := result.Responses["A"].Frames[0]
+		require.Equal(t, "text", frame.Fields[0].Name)
+		require.Equal(t, 3, frame.Rows())
+		require.Equal(t, "server3", frame.Fields[0].At(2))
+	})
+
+	t.Run("Influxdb response parser should detect SHOW TAG VALUES statements", func(t *testing.T) {
+		require.True(t, isShowTagValues(`show tag values with key = "host"`))
+		require.True(t, isShowTagValues("-- hosts\n /* of the region */ SHOW\tTAG  VALUES WITH KEY = \"host\""))
+		require.False(t, isShowTagValues(`SELECT "value" FROM "show tag values"`))
+		require.False(t, isShowTagValues(`SHOW TAG KEYS`))
+		require.False(t, isShowTagValues(`/* SHOW TAG VALUES */ SHOW MEASUREMENTS`))
+	})
+
+	t.Run("Influxdb response parser should parse annotation queries", func(t *testing.T) {
+		parser := &ResponseParser{}
+
+		response := `
+		{
+			"results": [
+				{
+					"series": [
+						{
+							"name": "events",
+							"columns": ["time","title","description","host","region"],
+							"values": [[1609556645000,"deploy","version 2","server1","eu"]]
+						}
+					]
+				}
+			]
+		}
+		`
+
+		query := Query{
+			RefID:     "A",
+			RawQuery:  "SELECT * FROM events",
+			QueryType: models.QueryTypeAnnotation,
+			Annotation: models.AnnotationColumns{
+				TextColumn: "description",
+				TagsColumn: "host, region",
+			},
+		}
+		result := parser.Parse(prepare(response), []Query{query})
+
+		require.NoError(t, result.Responses["A"].Error)
+		frame := result.Responses["A"].Frames[0]
+		require.Equal(t, 1, frame.Rows())
+		row := frame.RowCopy(0)
+		require.Equal(t, time.Date(2021, 1, 2, 3, 4, 5, 0, time.UTC), row[0])
+		require.Equal(t, "deploy", row[2])
+		require.Equal(t, "version 2", row[3])
+		require.Equal(t, "server1,eu", row[4])
+	})
 }
 
 func TestResponseParser_Parse(t *testing.T) {
@@ -54,7 +54,7 @@ func makeDataRequest(ctx context.Context, lokiDsUrl string, query lokiQuery, hea
 	}
 
 	switch query.QueryType {
-	case QueryTypeRange:
+	case QueryTypeRange, QueryTypeVolume:
 		{
 			qs.Set("start", strconv.FormatInt(query.Start.UnixNano(), 10))
 			qs.Set("end", strconv.FormatInt(query.End.UnixNano(), 10))
@@ -0,0 +1,106 @@
+package loki
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"sort"
+)
+
+const (
+	labelStatsResource = "label_stats"
+	// number of most common values returned per label
+	labelStatsTopValues = 5
+)
+
+type labelValueStat struct {
+	Value   string `json:"value"`
+	Streams int    `json:"streams"`
+}
+
+type labelStat struct {
+	Name       string           `json:"name"`
+	ValueCount int              `json:"valueCount"`
+	Streams    int              `json:"streams"`
+	TopValues  []labelValueStat `json:"topValues"`
+}
+
+type labelStatsResult struct {
+	Streams int         `json:"streams"`
+	Labels  []labelStat `json:"labels"`
+}
+
+type seriesResponse struct {
+	Status string              `json:"status"`
+	Data   []map[string]string `json:"data"`
+}
+
+// labelStats fetches the streams matching the `query` selector between `start` and `end` and counts,
+// per label name, the number of streams having it and its distinct values.
+func labelStats(ctx context.Context, api *LokiAPI, params url.Values) ([]byte, error) {
+	query := params.Get("query")
+	if query == "" {
+		return nil, fmt.Errorf("missing query parameter")
+	}
+
+	qs := url.Values{}
+	qs.Set("match[]", query)
+	for _, name := range []string{"start", "end"} {
+		if value := params.Get(name); value != "" {
+			qs.Set(name, value)
+		}
+	}
+
+	body, err := api.RawQuery(ctx, "/loki/api/v1/series?"+qs.Encode())
+	if err != nil {
+		return nil, err
+	}
+
+	var series seriesResponse
+	if err := json.Unmarshal(body, &series); err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(computeLabelStats(series.Data))
+}
+
+func computeLabelStats(streams []map[string]string) labelStatsResult {
+	values := map[string]map[string]int{}
+	for _, labels := range streams {
+		for name, value := range labels {
+			if values[name] == nil {
+				values[name] = map[string]int{}
+			}
+			values[name][value]++
+		}
+	}
+
+	result := labelStatsResult{Streams: len(streams), Labels: make([]labelStat, 0, len(values))}
+	for name, counts := range values {
+		stat := labelStat{Name: name, ValueCount: len(counts)}
+		for value, count := range counts {
+			stat.Streams += count
+			stat.TopValues = append(stat.TopValues, labelValueStat{Value: value, Streams: count})
+		}
+		sort.Slice(stat.TopValues, func(i, j int) bool {
+			if stat.TopValues[i].Streams != stat.TopValues[j].Streams {
+				return stat.TopValues[i].Streams > stat.TopValues[j].Streams
+			}
+			return stat.TopValues[i].Value < stat.TopValues[j].Value
+		})
+		if len(stat.TopValues) > labelStatsTopValues {
+			stat.TopValues = stat.TopValues[:labelStatsTopValues]
+		}
+		result.Labels = append(result.Labels, stat)
+	}
+
+	// labels with the most values first, those are the ones driving the number of streams
+	sort.Slice(result.Labels, func(i, j int) bool {
+		if result.Labels[i].ValueCount != result.Labels[j].ValueCount {
+			return result.Labels[i].ValueCount > result.Labels[j].ValueCount
+		}
+		return result.Labels[i].Name < result.Labels[j].Name
+	})
+	return result
+}
@@ -0,0 +1,42 @@
+package loki
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLabelStats(t *testing.T) {
+	response := []byte(`{"status":"success","data":[
+		{"job":"api","level":"error","pod":"api-1"},
+		{"job":"api","level":"info","pod":"api-2"},
+		{"job":"db","level":"info","pod":"db-1"}]}`)
+
+	dsInfo := makeMockedDsInfoForOauth(response, func(req *http.Request) {
+		require.Equal(t, "/loki/api/v1/series", req.URL.Path)
+		require.Equal(t, `{job=~".+"}`, req.URL.Query().Get("match[]"))
+		require.Equal(t, "1000", req.URL.Query().Get("start"))
+	})
+
+	sender := &mockedCallResourceResponseSenderForOauth{}
+	err := callResource(context.Background(), &backend.CallResourceRequest{
+		Method: "GET",
+		URL:    `label_stats?query=%7Bjob%3D~%22.%2B%22%7D&start=1000&end=2000`,
+	}, sender, &dsInfo, log.New("test"))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, sender.Response.Status)
+
+	var result labelStatsResult
+	require.NoError(t, json.Unmarshal(sender.Response.Body, &result))
+	require.Equal(t, 3, result.Streams)
+	require.Equal(t, []labelStat{
+		{Name: "pod", ValueCount: 3, Streams: 3, TopValues: []labelValueStat{{"api-1", 1}, {"api-2", 1}, {"db-1", 1}}},
+		{Name: "job", ValueCount: 2, Streams: 3, TopValues: []labelValueStat{{"api", 2}, {"db", 1}}},
+		{Name: "level", ValueCount: 2, Streams: 3, TopValues: []labelValueStat{{"info", 2}, {"error", 1}}},
+	}, result.Labels)
+}
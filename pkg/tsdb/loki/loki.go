@@ -5,9 +5,11 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	neturl "net/url"
 	"regexp"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/grafana/grafana-plugin-sdk-go/backend"
 	"github.com/grafana/grafana-plugin-sdk-go/backend/datasource"
@@ -17,6 +19,7 @@ import (
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/infra/tracing"
 	"github.com/grafana/grafana/pkg/services/featuremgmt"
+	"github.com/grafana/grafana/pkg/tsdb/intervalv2"
 	"go.opentelemetry.io/otel/attribute"
 )
 
@@ -50,11 +53,18 @@ type datasourceInfo struct {
 	HTTPClient *http.Client
 	URL        string
 
+	// range queries longer than this are split into shards of this duration, zero disables sharding
+	shardDuration time.Duration
+
 	// open streams
 	streams   map[string]data.FrameJSONCache
 	streamsMu sync.RWMutex
 }
 
+type datasourceJSONModel struct {
+	QueryShardDuration string `json:"queryShardDuration"`
+}
+
 type QueryJSONModel struct {
 	QueryType    string `json:"queryType"`
 	Expr         string `json:"expr"`
@@ -85,10 +95,26 @@ func newInstanceSettings(httpClientProvider httpclient.Provider) datasource.Inst
 			return nil, err
 		}
 
+		jsonData := datasourceJSONModel{}
+		if len(settings.JSONData) > 0 {
+			if err := json.Unmarshal(settings.JSONData, &jsonData); err != nil {
+				return nil, fmt.Errorf("error reading settings: %w", err)
+			}
+		}
+
+		var shardDuration time.Duration
+		if jsonData.QueryShardDuration != "" {
+			shardDuration, err = intervalv2.ParseIntervalStringToTimeDuration(jsonData.QueryShardDuration)
+			if err != nil {
+				return nil, fmt.Errorf("invalid query shard duration: %w", err)
+			}
+		}
+
 		model := &datasourceInfo{
-			HTTPClient: client,
-			URL:        settings.URL,
-			streams:    make(map[string]data.FrameJSONCache),
+			HTTPClient:    client,
+			URL:           settings.URL,
+			shardDuration: shardDuration,
+			streams:       make(map[string]data.FrameJSONCache),
 		}
 		return model, nil
 	}
@@ -140,6 +166,22 @@ func callResource(ctx context.Context, req *backend.CallResourceRequest, sender
 	if req.Method != "GET" {
 		return fmt.Errorf("invalid resource method: %s", req.Method)
 	}
+
+	api := newLokiAPI(dsInfo.HTTPClient, dsInfo.URL, plog, getAuthHeadersForCallResource(req.Headers))
+
+	// label statistics are computed here, everything else is passed through to Loki
+	if strings.HasPrefix(url, labelStatsResource+"?") {
+		parsed, err := neturl.Parse(url)
+		if err != nil {
+			return err
+		}
+		bytes, err := labelStats(ctx, api, parsed.Query())
+		if err != nil {
+			return err
+		}
+		return sendJSON(sender, bytes)
+	}
+
 	if (!strings.HasPrefix(url, "labels?")) &&
 		(!strings.HasPrefix(url, "label/")) && // the `/label/$label_name/values` form
 		(!strings.HasPrefix(url, "series?")) {
@@ -147,13 +189,16 @@ func callResource(ctx context.Context, req *backend.CallResourceRequest, sender
 	}
 	lokiURL := fmt.Sprintf("/loki/api/v1/%s", url)
 
-	api := newLokiAPI(dsInfo.HTTPClient, dsInfo.URL, plog, getAuthHeadersForCallResource(req.Headers))
 	bytes, err := api.RawQuery(ctx, lokiURL)
 
 	if err != nil {
 		return err
 	}
 
+	return sendJSON(sender, bytes)
+}
+
+func sendJSON(sender backend.CallResourceResponseSender, bytes []byte) error {
 	return sender.Send(&backend.CallResourceResponse{
 		Status: http.StatusOK,
 		Headers: map[string][]string{
@@ -205,7 +250,7 @@ func queryData(ctx context.Context, req *backend.QueryDataRequest, dsInfo *datas
 		span.SetAttributes("stop_unixnano", query.End, attribute.Key("stop_unixnano").Int64(query.End.UnixNano()))
 		defer span.End()
 
-		frames, err := runQuery(ctx, api, query)
+		frames, err := runQuery(ctx, api, query, dsInfo.shardDuration)
 
 		queryRes := backend.DataResponse{}
 
@@ -221,12 +266,16 @@ func queryData(ctx context.Context, req *backend.QueryDataRequest, dsInfo *datas
 }
 
 // we extracted this part of the functionality to make it easy to unit-test it
-func runQuery(ctx context.Context, api *LokiAPI, query *lokiQuery) (data.Frames, error) {
-	frames, err := api.DataQuery(ctx, *query)
+func runQuery(ctx context.Context, api *LokiAPI, query *lokiQuery, shardDuration time.Duration) (data.Frames, error) {
+	frames, err := fetchFrames(ctx, api, query, shardDuration)
 	if err != nil {
 		return data.Frames{}, err
 	}
 
+	if query.QueryType == QueryTypeVolume {
+		setVolumeLevels(frames)
+	}
+
 	for _, frame := range frames {
 		if err = adjustFrame(frame, query); err != nil {
 			return data.Frames{}, err
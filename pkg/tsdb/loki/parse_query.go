@@ -58,6 +58,8 @@ func parseQueryType(jsonValue string) (QueryType, error) {
 		return QueryTypeInstant, nil
 	case "range":
 		return QueryTypeRange, nil
+	case "volume":
+		return QueryTypeVolume, nil
 	case "":
 		// there are older queries stored in alerting that did not have queryType,
 		// those were range-queries
@@ -115,19 +117,34 @@ func parseQuery(queryContext *backend.QueryDataRequest) ([]*lokiQuery, error) {
 			return nil, err
 		}
 
+		legendFormat := model.LegendFormat
+		volumeQuery := model.VolumeQuery
+		if queryType == QueryTypeVolume {
+			expr = makeVolumeExpr(expr, step)
+			if legendFormat == "" {
+				legendFormat = "{{" + volumeLevelLabel + "}}"
+			}
+			volumeQuery = true
+		}
+
 		qs = append(qs, &lokiQuery{
 			Expr:         expr,
 			QueryType:    queryType,
 			Direction:    direction,
 			Step:         step,
 			MaxLines:     model.MaxLines,
-			LegendFormat: model.LegendFormat,
+			LegendFormat: legendFormat,
 			Start:        start,
 			End:          end,
 			RefID:        query.RefID,
-			VolumeQuery:  model.VolumeQuery,
+			VolumeQuery:  volumeQuery,
 		})
 	}
 
 	return qs, nil
 }
+
+// makeVolumeExpr turns a log query into a metric query counting its lines per level over each step.
+func makeVolumeExpr(expr string, step time.Duration) string {
+	return fmt.Sprintf("sum by (%s) (count_over_time(%s [%dms]))", volumeLevelLabel, expr, step.Milliseconds())
+}
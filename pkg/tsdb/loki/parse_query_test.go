@@ -34,6 +34,26 @@ func TestParseQuery(t *testing.T) {
 		require.Equal(t, time.Second*15, models[0].Step)
 		require.Equal(t, "go_goroutines 15s 15000 3000s 3000 3000000", models[0].Expr)
 	})
+	t.Run("parsing volume query model", func(t *testing.T) {
+		queryContext := &backend.QueryDataRequest{
+			Queries: []backend.DataQuery{
+				{
+					JSON: []byte(`{"expr": "{job=\"api\"} |= \"error\"", "queryType": "volume", "refId": "A"}`),
+					TimeRange: backend.TimeRange{
+						From: time.Now().Add(-3000 * time.Second),
+						To:   time.Now(),
+					},
+					Interval: time.Second * 15,
+				},
+			},
+		}
+		models, err := parseQuery(queryContext)
+		require.NoError(t, err)
+		require.Equal(t, QueryTypeVolume, models[0].QueryType)
+		require.Equal(t, `sum by (level) (count_over_time({job="api"} |= "error" [15000ms]))`, models[0].Expr)
+		require.Equal(t, "{{level}}", models[0].LegendFormat)
+		require.True(t, models[0].VolumeQuery)
+	})
 	t.Run("interpolate variables, range between 1s and 0.5s", func(t *testing.T) {
 		expr := "go_goroutines $__interval $__interval_ms $__range $__range_s $__range_ms"
 
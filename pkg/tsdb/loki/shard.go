@@ -0,0 +1,202 @@
+package loki
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+)
+
+// maxConcurrentShards limits how many shards of a single query are sent to Loki at the same time.
+const maxConcurrentShards = 4
+
+// errShardSchemaMismatch is returned when frames of different shards have different fields and can't be merged.
+var errShardSchemaMismatch = errors.New("frames of query shards have different fields")
+
+// splitQuery splits a range query into consecutive shards of about shardDuration. The shards are aligned to the
+// step of the query so the evaluation timestamps of metric queries stay the same as for the unsplit query.
+func splitQuery(query *lokiQuery, shardDuration time.Duration) []lokiQuery {
+	if shardDuration <= 0 || query.QueryType == QueryTypeInstant || query.End.Sub(query.Start) <= shardDuration {
+		return []lokiQuery{*query}
+	}
+
+	size := shardDuration
+	if query.Step > 0 {
+		size = time.Duration((int64(shardDuration) + int64(query.Step) - 1) / int64(query.Step) * int64(query.Step))
+	}
+
+	var shards []lokiQuery
+	for start := query.Start; start.Before(query.End); start = start.Add(size) {
+		shard := *query
+		shard.Start = start
+		shard.End = start.Add(size)
+		if shard.End.After(query.End) {
+			shard.End = query.End
+		}
+		shards = append(shards, shard)
+	}
+	return shards
+}
+
+// fetchFrames runs the query, split into shards when it covers more than shardDuration, and merges the results.
+func fetchFrames(ctx context.Context, api *LokiAPI, query *lokiQuery, shardDuration time.Duration) (data.Frames, error) {
+	shards := splitQuery(query, shardDuration)
+	if len(shards) == 1 {
+		return api.DataQuery(ctx, *query)
+	}
+
+	results := make([]data.Frames, len(shards))
+	errs := make([]error, len(shards))
+
+	sem := make(chan struct{}, maxConcurrentShards)
+	var wg sync.WaitGroup
+	for i := range shards {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			results[i], errs[i] = api.DataQuery(ctx, shards[i])
+		}(i)
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	merged, err := mergeShardFrames(results, query)
+	if errors.Is(err, errShardSchemaMismatch) {
+		// rows of a shard would be lost, the whole range is queried at once instead
+		return api.DataQuery(ctx, *query)
+	}
+	return merged, err
+}
+
+// mergeShardFrames combines the frames returned for the shards of a query, which are ordered by time.
+// Metric series are joined by name and labels, log lines are concatenated in the direction of the query
+// and limited to its maximum number of lines. errShardSchemaMismatch is returned when frames to merge have different fields.
+func mergeShardFrames(shards []data.Frames, query *lokiQuery) (data.Frames, error) {
+	isLogs := false
+	for _, frames := range shards {
+		for _, frame := range frames {
+			if len(frame.Fields) > 0 && frame.Fields[0].Type() == data.FieldTypeJSON {
+				isLogs = true
+			}
+		}
+	}
+
+	if isLogs {
+		if query.Direction == DirectionBackward {
+			reversed := make([]data.Frames, len(shards))
+			for i, frames := range shards {
+				reversed[len(shards)-1-i] = frames
+			}
+			shards = reversed
+		}
+		return mergeLogsFrames(shards, query.MaxLines)
+	}
+	return mergeMetricFrames(shards)
+}
+
+func mergeLogsFrames(shards []data.Frames, maxLines int) (data.Frames, error) {
+	var merged *data.Frame
+	for _, frames := range shards {
+		for _, frame := range frames {
+			if merged == nil {
+				merged = frame
+				continue
+			}
+			if err := appendRows(merged, frame, func(int) bool { return true }); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if merged == nil {
+		return data.Frames{}, nil
+	}
+
+	if maxLines > 0 {
+		for merged.Rows() > maxLines {
+			merged.DeleteRow(merged.Rows() - 1)
+		}
+	}
+	return data.Frames{merged}, nil
+}
+
+// mergeMetricFrames joins frames of the same series. Frames without a time field first are not series and are
+// kept as they are.
+func mergeMetricFrames(shards []data.Frames) (data.Frames, error) {
+	merged := data.Frames{}
+	byKey := map[string]*data.Frame{}
+
+	for _, frames := range shards {
+		for _, frame := range frames {
+			if len(frame.Fields) == 0 || frame.Fields[0].Type() != data.FieldTypeTime {
+				merged = append(merged, frame)
+				continue
+			}
+
+			key := frameKey(frame)
+			existing, ok := byKey[key]
+			if !ok {
+				byKey[key] = frame
+				merged = append(merged, frame)
+				continue
+			}
+
+			// consecutive shards share their boundary, so the samples up to the last one we have are skipped
+			timeField := existing.Fields[0]
+			include := func(int) bool { return true }
+			if timeField.Len() > 0 {
+				last := timeField.At(timeField.Len() - 1).(time.Time)
+				include = func(i int) bool {
+					return frame.Fields[0].At(i).(time.Time).After(last)
+				}
+			}
+			if err := appendRows(existing, frame, include); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return merged, nil
+}
+
+func appendRows(dst *data.Frame, src *data.Frame, include func(i int) bool) error {
+	if len(dst.Fields) != len(src.Fields) {
+		return errShardSchemaMismatch
+	}
+	for j, field := range dst.Fields {
+		if field.Type() != src.Fields[j].Type() {
+			return errShardSchemaMismatch
+		}
+	}
+	for i := 0; i < src.Rows(); i++ {
+		if !include(i) {
+			continue
+		}
+		for j, field := range dst.Fields {
+			field.Append(src.Fields[j].At(i))
+		}
+	}
+	return nil
+}
+
+func frameKey(frame *data.Frame) string {
+	parts := []string{frame.Name}
+	for _, field := range frame.Fields {
+		labels := make([]string, 0, len(field.Labels))
+		for k, v := range field.Labels {
+			labels = append(labels, k+"="+v)
+		}
+		sort.Strings(labels)
+		parts = append(parts, field.Name+"{"+strings.Join(labels, ",")+"}")
+	}
+	return strings.Join(parts, "|")
+}
@@ -0,0 +1,166 @@
+package loki
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSplitQuery(t *testing.T) {
+	start := time.Date(2022, 6, 1, 0, 0, 0, 0, time.UTC)
+	query := &lokiQuery{QueryType: QueryTypeRange, Start: start, End: start.Add(50 * time.Hour), Step: 7 * time.Hour}
+
+	t.Run("short or instant queries are not split", func(t *testing.T) {
+		require.Len(t, splitQuery(query, 0), 1)
+		require.Len(t, splitQuery(query, 100*time.Hour), 1)
+		require.Len(t, splitQuery(&lokiQuery{QueryType: QueryTypeInstant, Start: start, End: start.Add(50 * time.Hour)}, time.Hour), 1)
+	})
+
+	t.Run("shards are aligned to the step", func(t *testing.T) {
+		shards := splitQuery(query, 24*time.Hour)
+		require.Len(t, shards, 2)
+		require.Equal(t, start, shards[0].Start)
+		require.Equal(t, start.Add(28*time.Hour), shards[0].End)
+		require.Equal(t, start.Add(28*time.Hour), shards[1].Start)
+		require.Equal(t, query.End, shards[1].End)
+	})
+}
+
+func TestShardedQuery(t *testing.T) {
+	start := time.Date(2022, 6, 1, 0, 0, 0, 0, time.UTC)
+	step := time.Hour
+
+	var mu sync.Mutex
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		requests++
+		mu.Unlock()
+
+		from, err := strconv.ParseInt(r.URL.Query().Get("start"), 10, 64)
+		require.NoError(t, err)
+		to, err := strconv.ParseInt(r.URL.Query().Get("end"), 10, 64)
+		require.NoError(t, err)
+
+		// one sample per step, both ends included, as Loki does for metric queries
+		values := ""
+		for ts := time.Unix(0, from); !ts.After(time.Unix(0, to)); ts = ts.Add(step) {
+			if values != "" {
+				values += ","
+			}
+			values += fmt.Sprintf(`[%d, "1"]`, ts.Unix())
+		}
+		_, _ = w.Write([]byte(`{"status":"success","data":{"resultType":"matrix","result":[{"metric":{"level":"error"},"values":[` + values + `]}]}}`))
+	}))
+	defer server.Close()
+
+	api := newLokiAPI(server.Client(), server.URL, log.New("test"), nil)
+	query := &lokiQuery{Expr: "x", QueryType: QueryTypeRange, Direction: DirectionBackward, Start: start, End: start.Add(72 * time.Hour), Step: step}
+
+	frames, err := runQuery(context.Background(), api, query, 24*time.Hour)
+	require.NoError(t, err)
+	require.Equal(t, 3, requests)
+	require.Len(t, frames, 1)
+
+	timeField := frames[0].Fields[0]
+	require.Equal(t, 73, timeField.Len())
+	for i := 0; i < timeField.Len(); i++ {
+		require.Equal(t, start.Add(time.Duration(i)*step), timeField.At(i).(time.Time).UTC())
+	}
+}
+
+func TestMergeLogsFrames(t *testing.T) {
+	makeFrame := func(lines ...string) *data.Frame {
+		labels := make([]json.RawMessage, len(lines))
+		times := make([]time.Time, len(lines))
+		tsNs := make([]string, len(lines))
+		for i := range lines {
+			labels[i] = json.RawMessage(`{}`)
+			tsNs[i] = lines[i]
+		}
+		return data.NewFrame("",
+			data.NewField("", nil, labels),
+			data.NewField("", nil, times),
+			data.NewField("", nil, lines),
+			data.NewField("", nil, tsNs),
+		)
+	}
+
+	older := data.Frames{makeFrame("b2", "b1")}
+	newer := data.Frames{makeFrame("a2", "a1")}
+
+	merged, err := mergeShardFrames([]data.Frames{older, newer}, &lokiQuery{Direction: DirectionBackward, MaxLines: 3})
+	require.NoError(t, err)
+	require.Len(t, merged, 1)
+	require.Equal(t, 3, merged[0].Rows())
+	require.Equal(t, []interface{}{"a2", "a1", "b2"}, []interface{}{merged[0].Fields[2].At(0), merged[0].Fields[2].At(1), merged[0].Fields[2].At(2)})
+}
+
+func TestMergeShardFramesSchemaMismatch(t *testing.T) {
+	t.Run("log frames with different fields are not merged", func(t *testing.T) {
+		full := data.NewFrame("",
+			data.NewField("", nil, []json.RawMessage{json.RawMessage(`{}`)}),
+			data.NewField("", nil, []time.Time{{}}),
+			data.NewField("", nil, []string{"a"}),
+			data.NewField("", nil, []string{"1"}),
+		)
+		partial := data.NewFrame("",
+			data.NewField("", nil, []json.RawMessage{json.RawMessage(`{}`)}),
+			data.NewField("", nil, []time.Time{{}}),
+			data.NewField("", nil, []string{"b"}),
+		)
+		_, err := mergeShardFrames([]data.Frames{{full}, {partial}}, &lokiQuery{Direction: DirectionForward})
+		require.ErrorIs(t, err, errShardSchemaMismatch)
+	})
+
+	t.Run("metric frames without a time field are kept as they are", func(t *testing.T) {
+		frame := func(v float64) *data.Frame {
+			return data.NewFrame("x", data.NewField("value", nil, []float64{v}))
+		}
+		merged, err := mergeShardFrames([]data.Frames{{frame(1)}, {frame(2)}}, &lokiQuery{})
+		require.NoError(t, err)
+		require.Len(t, merged, 2)
+	})
+}
+
+func TestShardedQueryFallsBackOnSchemaMismatch(t *testing.T) {
+	start := time.Date(2022, 6, 1, 0, 0, 0, 0, time.UTC)
+	end := start.Add(48 * time.Hour)
+
+	var mu sync.Mutex
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		requests++
+		mu.Unlock()
+
+		// the first shard returns frames with other fields than the rest
+		if r.URL.Query().Get("end") == strconv.FormatInt(start.Add(24*time.Hour).UnixNano(), 10) {
+			_, _ = w.Write([]byte(`{"status":"success","data":{"resultType":"matrix","result":[{"metric":{"level":"error"},"values":[[` + strconv.FormatInt(start.Unix(), 10) + `,"1"]]}]}}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"status":"success","data":{"resultType":"streams","result":[
+			{"stream":{"level":"error"},"values":[["` + strconv.FormatInt(start.Add(30*time.Hour).UnixNano(), 10) + `","b"]]}]}}`))
+	}))
+	defer server.Close()
+
+	api := newLokiAPI(server.Client(), server.URL, log.New("test"), nil)
+	query := &lokiQuery{Expr: "x", QueryType: QueryTypeRange, Direction: DirectionForward, Start: start, End: end}
+
+	frames, err := fetchFrames(context.Background(), api, query, 24*time.Hour)
+	require.NoError(t, err)
+	// two shards and the unsharded query
+	require.Equal(t, 3, requests)
+	require.Len(t, frames, 1)
+	require.Equal(t, data.FieldTypeJSON, frames[0].Fields[0].Type())
+}
@@ -7,6 +7,8 @@ type QueryType string
 const (
 	QueryTypeRange   QueryType = "range"
 	QueryTypeInstant QueryType = "instant"
+	// QueryTypeVolume is a range query counting the log lines of the selector, grouped by level.
+	QueryTypeVolume QueryType = "volume"
 )
 
 type Direction string
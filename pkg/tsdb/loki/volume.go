@@ -0,0 +1,26 @@
+package loki
+
+import "github.com/grafana/grafana-plugin-sdk-go/data"
+
+const (
+	volumeLevelLabel = "level"
+	// used for the lines of streams without a level label, same as the log volume histogram in the frontend
+	volumeUnknownLevel = "unknown"
+)
+
+// setVolumeLevels makes sure every series of a volume query has a level, so it can be named and colored.
+func setVolumeLevels(frames data.Frames) {
+	for _, frame := range frames {
+		for _, field := range frame.Fields {
+			if field.Type() != data.FieldTypeFloat64 {
+				continue
+			}
+			if field.Labels == nil {
+				field.Labels = data.Labels{}
+			}
+			if field.Labels[volumeLevelLabel] == "" {
+				field.Labels[volumeLevelLabel] = volumeUnknownLevel
+			}
+		}
+	}
+}
@@ -2,6 +2,7 @@ package mysql
 
 import (
 	"context"
+	"database/sql"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -105,6 +106,11 @@ func newInstanceSettings(cfg *setting.Cfg, httpClientProvider httpclient.Provide
 			cnnstr += fmt.Sprintf("&time_zone='%s'", url.QueryEscape(dsInfo.JsonData.Timezone))
 		}
 
+		// several statements per query are opt-in, they let a query run statements after the one it was written for
+		if dsInfo.JsonData.MultiStatements {
+			cnnstr += "&multiStatements=true"
+		}
+
 		if cfg.Env == setting.Dev {
 			logger.Debug("getEngine", "connection", cnnstr)
 		}
@@ -116,6 +122,8 @@ func newInstanceSettings(cfg *setting.Cfg, httpClientProvider httpclient.Provide
 			TimeColumnNames:   []string{"time", "time_sec"},
 			MetricColumnTypes: []string{"CHAR", "VARCHAR", "TINYTEXT", "TEXT", "MEDIUMTEXT", "LONGTEXT"},
 			RowLimit:          cfg.DataProxyRowLimit,
+			Placeholder:       sqleng.QuestionPlaceholder,
+			BindSyntax:        sqleng.MySQLBindSyntax,
 		}
 
 		rowTransformer := mysqlQueryResultTransformer{
@@ -143,6 +151,14 @@ func (s *Service) QueryData(ctx context.Context, req *backend.QueryDataRequest)
 	return dsHandler.QueryData(ctx, req)
 }
 
+func (s *Service) CheckHealth(ctx context.Context, req *backend.CheckHealthRequest) (*backend.CheckHealthResult, error) {
+	dsHandler, err := s.getDataSourceHandler(req.PluginContext)
+	if err != nil {
+		return nil, err
+	}
+	return dsHandler.CheckHealth(ctx, req)
+}
+
 type mysqlQueryResultTransformer struct {
 	log log.Logger
 }
@@ -162,6 +178,65 @@ func (t *mysqlQueryResultTransformer) TransformQueryError(err error) error {
 
 var errQueryFailed = errors.New("query failed - please inspect Grafana server log for details")
 
+func (t *mysqlQueryResultTransformer) EstimateQueryCost(ctx context.Context, db *sql.DB, query string, args []interface{}) (float64, error) {
+	var plan string
+	if err := db.QueryRowContext(ctx, "EXPLAIN FORMAT=JSON "+query, args...).Scan(&plan); err != nil {
+		return 0, err
+	}
+	return parseExplainCost(plan)
+}
+
+// parseExplainCost returns the query cost of an EXPLAIN FORMAT=JSON result. Plans without a cost for
+// the whole query, like the ones of unions, are estimated with the sum of their query blocks.
+func parseExplainCost(plan string) (float64, error) {
+	var explain interface{}
+	if err := json.Unmarshal([]byte(plan), &explain); err != nil {
+		return 0, err
+	}
+	cost, ok, err := sumQueryCosts(explain)
+	if err != nil {
+		return 0, err
+	}
+	if !ok {
+		return 0, errors.New("query plan has no query cost")
+	}
+	return cost, nil
+}
+
+// sumQueryCosts sums the query costs of the outermost query blocks of a plan, the cost of a block
+// includes the blocks nested in it.
+func sumQueryCosts(plan interface{}) (float64, bool, error) {
+	var children []interface{}
+	switch v := plan.(type) {
+	case map[string]interface{}:
+		if info, ok := v["cost_info"].(map[string]interface{}); ok {
+			if queryCost, ok := info["query_cost"].(string); ok {
+				cost, err := strconv.ParseFloat(queryCost, 64)
+				return cost, true, err
+			}
+		}
+		for _, child := range v {
+			children = append(children, child)
+		}
+	case []interface{}:
+		children = v
+	}
+
+	var total float64
+	found := false
+	for _, child := range children {
+		cost, ok, err := sumQueryCosts(child)
+		if err != nil {
+			return 0, false, err
+		}
+		if ok {
+			total += cost
+			found = true
+		}
+	}
+	return total, found, nil
+}
+
 func (t *mysqlQueryResultTransformer) GetConverterList() []sqlutil.StringConverter {
 	// For the MySQL driver , we have these possible data types:
 	// https://www.w3schools.com/sql/sql_datatypes.asp#:~:text=In%20MySQL%20there%20are%20three,numeric%2C%20and%20date%20and%20time.
@@ -1262,3 +1262,19 @@ func genTimeRangeByInterval(from time.Time, duration time.Duration, interval tim
 
 	return timeRange
 }
+
+func TestParseExplainCost(t *testing.T) {
+	cost, err := parseExplainCost(`{"query_block": {"select_id": 1, "cost_info": {"query_cost": "1254.20"}, "table": {"table_name": "metric"}}}`)
+	require.NoError(t, err)
+	require.Equal(t, 1254.2, cost)
+
+	cost, err = parseExplainCost(`{"query_block": {"union_result": {"query_specifications": [
+		{"query_block": {"select_id": 1, "cost_info": {"query_cost": "10.50"}, "table": {"cost_info": {"read_cost": "5.00"}}}},
+		{"query_block": {"select_id": 2, "cost_info": {"query_cost": "20.25"}, "table": {"materialized_from_subquery": {"query_block": {"cost_info": {"query_cost": "99.00"}}}}}}
+	]}}}`)
+	require.NoError(t, err)
+	require.Equal(t, 30.75, cost)
+
+	_, err = parseExplainCost(`{"query_block": {"union_result": {}}}`)
+	require.Error(t, err)
+}
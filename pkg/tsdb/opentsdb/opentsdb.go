@@ -8,6 +8,7 @@ import (
 	"net/http"
 	"net/url"
 	"path"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
@@ -38,10 +39,20 @@ func ProvideService(httpClientProvider httpclient.Provider) *Service {
 type datasourceInfo struct {
 	HTTPClient *http.Client
 	URL        string
+
+	// 1 for second and 2 for millisecond resolution
+	TSDBResolution int
+	// maximum number of results of the lookups
+	LookupLimit int
 }
 
 type DsAccess string
 
+const (
+	tsdbResolutionMillisecond = 2
+	defaultLookupLimit        = 1000
+)
+
 func newInstanceSettings(httpClientProvider httpclient.Provider) datasource.InstanceFactoryFunc {
 	return func(settings backend.DataSourceInstanceSettings) (instancemgmt.Instance, error) {
 		opts, err := settings.HTTPClientOptions()
@@ -54,51 +65,83 @@ func newInstanceSettings(httpClientProvider httpclient.Provider) datasource.Inst
 			return nil, err
 		}
 
+		jsonData, err := simplejson.NewJson(settings.JSONData)
+		if err != nil {
+			jsonData = simplejson.New()
+		}
+
 		model := &datasourceInfo{
-			HTTPClient: client,
-			URL:        settings.URL,
+			HTTPClient:     client,
+			URL:            settings.URL,
+			TSDBResolution: intSetting(jsonData, "tsdbResolution", 1),
+			LookupLimit:    intSetting(jsonData, "lookupLimit", defaultLookupLimit),
 		}
 
 		return model, nil
 	}
 }
 
-func (s *Service) QueryData(ctx context.Context, req *backend.QueryDataRequest) (*backend.QueryDataResponse, error) {
-	var tsdbQuery OpenTsdbQuery
-
-	q := req.Queries[0]
-
-	tsdbQuery.Start = q.TimeRange.From.UnixNano() / int64(time.Millisecond)
-	tsdbQuery.End = q.TimeRange.To.UnixNano() / int64(time.Millisecond)
-
-	for _, query := range req.Queries {
-		metric := s.buildMetric(query)
-		tsdbQuery.Queries = append(tsdbQuery.Queries, metric)
+// intSetting reads a numeric setting, which older versions of the config editor saved as a string
+func intSetting(jsonData *simplejson.Json, key string, defaultValue int) int {
+	value := jsonData.Get(key)
+	if i, err := value.Int(); err == nil && i > 0 {
+		return i
 	}
-
-	// TODO: Don't use global variable
-	if setting.Env == setting.Dev {
-		s.logger.Debug("OpenTsdb request", "params", tsdbQuery)
+	if str, err := value.String(); err == nil {
+		if i, err := strconv.Atoi(str); err == nil && i > 0 {
+			return i
+		}
 	}
+	return defaultValue
+}
 
+// QueryData sends one request to OpenTSDB per query, so the returned series can be matched with their query.
+func (s *Service) QueryData(ctx context.Context, req *backend.QueryDataRequest) (*backend.QueryDataResponse, error) {
 	dsInfo, err := s.getDSInfo(req.PluginContext)
 	if err != nil {
 		return nil, err
 	}
 
-	request, err := s.createRequest(ctx, dsInfo, tsdbQuery)
-	if err != nil {
-		return &backend.QueryDataResponse{}, err
-	}
+	result := backend.NewQueryDataResponse()
+	for _, query := range req.Queries {
+		metric, err := s.buildMetric(query)
+		if err != nil {
+			result.Responses[query.RefID] = backend.DataResponse{Error: err}
+			continue
+		}
+		if metric["metric"] == "" {
+			continue
+		}
 
-	res, err := dsInfo.HTTPClient.Do(request)
-	if err != nil {
-		return &backend.QueryDataResponse{}, err
-	}
+		tsdbQuery := OpenTsdbQuery{
+			Start:        query.TimeRange.From.UnixNano() / int64(time.Millisecond),
+			End:          query.TimeRange.To.UnixNano() / int64(time.Millisecond),
+			Queries:      []map[string]interface{}{metric},
+			MsResolution: dsInfo.TSDBResolution == tsdbResolutionMillisecond,
+		}
 
-	result, err := s.parseResponse(res)
-	if err != nil {
-		return &backend.QueryDataResponse{}, err
+		// TODO: Don't use global variable
+		if setting.Env == setting.Dev {
+			s.logger.Debug("OpenTsdb request", "params", tsdbQuery)
+		}
+
+		request, err := s.createRequest(ctx, dsInfo, tsdbQuery)
+		if err != nil {
+			return &backend.QueryDataResponse{}, err
+		}
+
+		res, err := dsInfo.HTTPClient.Do(request)
+		if err != nil {
+			result.Responses[query.RefID] = backend.DataResponse{Error: err}
+			continue
+		}
+
+		queryRes, err := s.parseResponse(query.RefID, res, tsdbQuery.MsResolution)
+		if err != nil {
+			result.Responses[query.RefID] = backend.DataResponse{Error: err}
+			continue
+		}
+		result.Responses[query.RefID] = queryRes.Responses[query.RefID]
 	}
 
 	return result, nil
@@ -127,7 +170,7 @@ func (s *Service) createRequest(ctx context.Context, dsInfo *datasourceInfo, dat
 	return req, nil
 }
 
-func (s *Service) parseResponse(res *http.Response) (*backend.QueryDataResponse, error) {
+func (s *Service) parseResponse(refID string, res *http.Response, msResolution bool) (*backend.QueryDataResponse, error) {
 	resp := backend.NewQueryDataResponse()
 
 	body, err := ioutil.ReadAll(res.Body)
@@ -154,52 +197,66 @@ func (s *Service) parseResponse(res *http.Response) (*backend.QueryDataResponse,
 
 	frames := data.Frames{}
 	for _, val := range responseData {
-		timeVector := make([]time.Time, 0, len(val.DataPoints))
-		values := make([]float64, 0, len(val.DataPoints))
-		name := val.Metric
-		tags := val.Tags
-
-		for timeString, value := range val.DataPoints {
+		timestamps := make([]int64, 0, len(val.DataPoints))
+		for timeString := range val.DataPoints {
 			timestamp, err := strconv.ParseInt(timeString, 10, 64)
 			if err != nil {
 				s.logger.Info("Failed to unmarshal opentsdb timestamp", "timestamp", timeString)
 				return nil, err
 			}
-			timeVector = append(timeVector, time.Unix(timestamp, 0).UTC())
-			values = append(values, value)
+			timestamps = append(timestamps, timestamp)
+		}
+		// data points are returned as an object, so their order is lost
+		sort.Slice(timestamps, func(i, j int) bool { return timestamps[i] < timestamps[j] })
+
+		timeVector := make([]time.Time, 0, len(timestamps))
+		values := make([]float64, 0, len(timestamps))
+		for _, timestamp := range timestamps {
+			if msResolution {
+				timeVector = append(timeVector, time.UnixMilli(timestamp).UTC())
+			} else {
+				timeVector = append(timeVector, time.Unix(timestamp, 0).UTC())
+			}
+			values = append(values, val.DataPoints[strconv.FormatInt(timestamp, 10)])
 		}
-		frames = append(frames, data.NewFrame(name,
+
+		frame := data.NewFrame(val.Metric,
 			data.NewField("time", nil, timeVector),
-			data.NewField("value", tags, values)))
+			data.NewField("value", val.Tags, values))
+		frame.RefID = refID
+		frames = append(frames, frame)
 	}
-	result := resp.Responses["A"]
+	result := resp.Responses[refID]
 	result.Frames = frames
-	resp.Responses["A"] = result
+	resp.Responses[refID] = result
 	return resp, nil
 }
 
-func (s *Service) buildMetric(query backend.DataQuery) map[string]interface{} {
+// buildMetric converts a query to a sub query of the OpenTSDB query API.
+// See http://opentsdb.net/docs/build/html/api_http/query/index.html
+func (s *Service) buildMetric(query backend.DataQuery) (map[string]interface{}, error) {
 	metric := make(map[string]interface{})
 
 	model, err := simplejson.NewJson(query.JSON)
 	if err != nil {
-		return nil
+		return nil, fmt.Errorf("failed to parse query: %w", err)
 	}
 
 	// Setting metric and aggregator
 	metric["metric"] = model.Get("metric").MustString()
-	metric["aggregator"] = model.Get("aggregator").MustString()
+	metric["aggregator"] = model.Get("aggregator").MustString("sum")
 
 	// Setting downsampling options
 	disableDownsampling := model.Get("disableDownsampling").MustBool()
 	if !disableDownsampling {
 		downsampleInterval := model.Get("downsampleInterval").MustString()
 		if downsampleInterval == "" {
-			downsampleInterval = "1m" // default value for blank
+			downsampleInterval = formatInterval(query.Interval)
 		}
-		downsample := downsampleInterval + "-" + model.Get("downsampleAggregator").MustString()
-		if model.Get("downsampleFillPolicy").MustString() != "none" {
-			metric["downsample"] = downsample + "-" + model.Get("downsampleFillPolicy").MustString()
+		downsample := downsampleInterval + "-" + model.Get("downsampleAggregator").MustString("avg")
+		fillPolicy := model.Get("downsampleFillPolicy").MustString()
+		if fillPolicy != "" && fillPolicy != "none" {
+			metric["downsample"] = downsample + "-" + fillPolicy
 		} else {
 			metric["downsample"] = downsample
 		}
@@ -211,17 +268,23 @@ func (s *Service) buildMetric(query backend.DataQuery) map[string]interface{} {
 		rateOptions := make(map[string]interface{})
 		rateOptions["counter"] = model.Get("isCounter").MustBool()
 
-		counterMax, counterMaxCheck := model.CheckGet("counterMax")
+		counterMax, counterMaxCheck, err := numberOption(model, "counterMax")
+		if err != nil {
+			return nil, err
+		}
 		if counterMaxCheck {
-			rateOptions["counterMax"] = counterMax.MustFloat64()
+			rateOptions["counterMax"] = counterMax
 		}
 
-		resetValue, resetValueCheck := model.CheckGet("counterResetValue")
+		resetValue, resetValueCheck, err := numberOption(model, "counterResetValue")
+		if err != nil {
+			return nil, err
+		}
 		if resetValueCheck {
-			rateOptions["resetValue"] = resetValue.MustFloat64()
+			rateOptions["resetValue"] = resetValue
 		}
 
-		if !counterMaxCheck && (!resetValueCheck || resetValue.MustFloat64() == 0) {
+		if !counterMaxCheck && (!resetValueCheck || resetValue == 0) {
 			rateOptions["dropResets"] = true
 		}
 
@@ -235,12 +298,85 @@ func (s *Service) buildMetric(query backend.DataQuery) map[string]interface{} {
 	}
 
 	// Setting filters
-	filters, filtersCheck := model.CheckGet("filters")
-	if filtersCheck && len(filters.MustArray()) > 0 {
-		metric["filters"] = filters.MustArray()
+	filters, err := parseFilters(model)
+	if err != nil {
+		return nil, err
+	}
+	if len(filters) > 0 {
+		metric["filters"] = filters
+	}
+
+	// Only return the series having exactly the tags of the query, available since OpenTSDB 2.3
+	if model.Get("explicitTags").MustBool() {
+		metric["explicitTags"] = true
+	}
+
+	return metric, nil
+}
+
+func parseFilters(model *simplejson.Json) ([]Filter, error) {
+	raw, ok := model.CheckGet("filters")
+	if !ok {
+		return nil, nil
+	}
+
+	encoded, err := raw.Encode()
+	if err != nil {
+		return nil, err
+	}
+
+	var filters []Filter
+	if err := json.Unmarshal(encoded, &filters); err != nil {
+		return nil, fmt.Errorf("invalid filters: %w", err)
+	}
+
+	for _, filter := range filters {
+		if filter.Type == "" || filter.Tagk == "" {
+			return nil, fmt.Errorf("invalid filter %q: type and tag key are required", filter.Filter)
+		}
 	}
+	return filters, nil
+}
 
-	return metric
+// numberOption reads an optional number, which the query editor saves as a string
+func numberOption(model *simplejson.Json, key string) (float64, bool, error) {
+	value, ok := model.CheckGet(key)
+	if !ok {
+		return 0, false, nil
+	}
+
+	if f, err := value.Float64(); err == nil {
+		return f, true, nil
+	}
+
+	str := strings.TrimSpace(value.MustString())
+	if str == "" {
+		return 0, false, nil
+	}
+	f, err := strconv.ParseFloat(str, 64)
+	if err != nil {
+		return 0, false, fmt.Errorf("invalid %s %q: %w", key, str, err)
+	}
+	return f, true, nil
+}
+
+// formatInterval formats the query interval as an OpenTSDB downsample interval, 1m being used when the
+// interval is not known.
+func formatInterval(interval time.Duration) string {
+	switch {
+	case interval <= 0:
+		return "1m"
+	case interval%(24*time.Hour) == 0:
+		return fmt.Sprintf("%dd", interval/(24*time.Hour))
+	case interval%time.Hour == 0:
+		return fmt.Sprintf("%dh", interval/time.Hour)
+	case interval%time.Minute == 0:
+		return fmt.Sprintf("%dm", interval/time.Minute)
+	case interval%time.Second == 0:
+		return fmt.Sprintf("%ds", interval/time.Second)
+	default:
+		return fmt.Sprintf("%dms", interval.Milliseconds())
+	}
 }
 
 func (s *Service) getDSInfo(pluginCtx backend.PluginContext) (*datasourceInfo, error) {
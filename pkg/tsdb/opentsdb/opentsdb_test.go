@@ -4,6 +4,7 @@ import (
 	"context"
 	"io/ioutil"
 	"net/http"
+	"net/http/httptest"
 	"strings"
 	"testing"
 	"time"
@@ -37,7 +38,7 @@ func TestOpenTsdbExecutor(t *testing.T) {
 	t.Run("Parse response should handle invalid JSON", func(t *testing.T) {
 		response := `{ invalid }`
 
-		result, err := service.parseResponse(&http.Response{Body: ioutil.NopCloser(strings.NewReader(response))})
+		result, err := service.parseResponse("A", &http.Response{Body: ioutil.NopCloser(strings.NewReader(response))}, false)
 		require.Nil(t, result)
 		require.Error(t, err)
 	})
@@ -64,10 +65,11 @@ func TestOpenTsdbExecutor(t *testing.T) {
 			data.NewField("value", map[string]string{"env": "prod", "app": "grafana"}, []float64{
 				50}),
 		)
+		testFrame.RefID = "A"
 
 		resp := http.Response{Body: ioutil.NopCloser(strings.NewReader(response))}
 		resp.StatusCode = 200
-		result, err := service.parseResponse(&resp)
+		result, err := service.parseResponse("A", &resp, false)
 		require.NoError(t, err)
 
 		frame := result.Responses["A"]
@@ -91,7 +93,8 @@ func TestOpenTsdbExecutor(t *testing.T) {
 			),
 		}
 
-		metric := service.buildMetric(query)
+		metric, err := service.buildMetric(query)
+		require.NoError(t, err)
 
 		require.Len(t, metric, 3)
 		require.Equal(t, "cpu.average.percent", metric["metric"])
@@ -113,7 +116,8 @@ func TestOpenTsdbExecutor(t *testing.T) {
 			),
 		}
 
-		metric := service.buildMetric(query)
+		metric, err := service.buildMetric(query)
+		require.NoError(t, err)
 
 		require.Len(t, metric, 2)
 		require.Equal(t, "cpu.average.percent", metric["metric"])
@@ -134,7 +138,8 @@ func TestOpenTsdbExecutor(t *testing.T) {
 			),
 		}
 
-		metric := service.buildMetric(query)
+		metric, err := service.buildMetric(query)
+		require.NoError(t, err)
 
 		require.Len(t, metric, 3)
 		require.Equal(t, "cpu.average.percent", metric["metric"])
@@ -160,7 +165,8 @@ func TestOpenTsdbExecutor(t *testing.T) {
 			),
 		}
 
-		metric := service.buildMetric(query)
+		metric, err := service.buildMetric(query)
+		require.NoError(t, err)
 
 		require.Len(t, metric, 3)
 		require.Equal(t, "cpu.average.percent", metric["metric"])
@@ -191,7 +197,8 @@ func TestOpenTsdbExecutor(t *testing.T) {
 			),
 		}
 
-		metric := service.buildMetric(query)
+		metric, err := service.buildMetric(query)
+		require.NoError(t, err)
 
 		require.Len(t, metric, 5)
 		require.Equal(t, "cpu.average.percent", metric["metric"])
@@ -226,7 +233,8 @@ func TestOpenTsdbExecutor(t *testing.T) {
 			),
 		}
 
-		metric := service.buildMetric(query)
+		metric, err := service.buildMetric(query)
+		require.NoError(t, err)
 
 		require.Len(t, metric, 5)
 		require.Equal(t, "cpu.average.percent", metric["metric"])
@@ -246,3 +254,203 @@ func TestOpenTsdbExecutor(t *testing.T) {
 		require.Equal(t, float64(60), metricRateOptions["resetValue"])
 	})
 }
+
+func TestBuildMetric(t *testing.T) {
+	service := &Service{
+		logger: log.New("test"),
+	}
+
+	t.Run("filters are passed with their group by flag", func(t *testing.T) {
+		query := backend.DataQuery{
+			JSON: []byte(`
+					{
+						"metric": "sys.cpu.user",
+						"aggregator": "sum",
+						"disableDownsampling": true,
+						"filters": [
+							{"type": "literal_or", "tagk": "host", "filter": "web01|web02", "groupBy": true},
+							{"type": "wildcard", "tagk": "dc", "filter": "l*", "groupBy": false},
+							{"type": "regexp", "tagk": "rack", "filter": "r[0-9]+"}
+						]
+					}`,
+			),
+		}
+
+		metric, err := service.buildMetric(query)
+		require.NoError(t, err)
+
+		require.Equal(t, []Filter{
+			{Type: "literal_or", Tagk: "host", Filter: "web01|web02", GroupBy: true},
+			{Type: "wildcard", Tagk: "dc", Filter: "l*"},
+			{Type: "regexp", Tagk: "rack", Filter: "r[0-9]+"},
+		}, metric["filters"])
+	})
+
+	t.Run("filters without type or tag key are rejected", func(t *testing.T) {
+		query := backend.DataQuery{
+			JSON: []byte(`{"metric": "sys.cpu.user", "filters": [{"type": "literal_or", "filter": "web01"}]}`),
+		}
+
+		_, err := service.buildMetric(query)
+		require.EqualError(t, err, `invalid filter "web01": type and tag key are required`)
+	})
+
+	t.Run("explicit tags", func(t *testing.T) {
+		query := backend.DataQuery{
+			JSON: []byte(`{"metric": "sys.cpu.user", "disableDownsampling": true, "explicitTags": true, "tags": {"host": "web01"}}`),
+		}
+
+		metric, err := service.buildMetric(query)
+		require.NoError(t, err)
+
+		require.Equal(t, true, metric["explicitTags"])
+		require.Equal(t, map[string]interface{}{"host": "web01"}, metric["tags"])
+	})
+
+	t.Run("rate options entered as text", func(t *testing.T) {
+		query := backend.DataQuery{
+			JSON: []byte(`
+					{
+						"metric": "net.bytes",
+						"disableDownsampling": true,
+						"shouldComputeRate": true,
+						"isCounter": true,
+						"counterMax": "65535",
+						"counterResetValue": ""
+					}`,
+			),
+		}
+
+		metric, err := service.buildMetric(query)
+		require.NoError(t, err)
+
+		require.Equal(t, map[string]interface{}{"counter": true, "counterMax": float64(65535)}, metric["rateOptions"])
+	})
+
+	t.Run("resets are dropped without counter max and reset value", func(t *testing.T) {
+		query := backend.DataQuery{
+			JSON: []byte(`{"metric": "net.bytes", "disableDownsampling": true, "shouldComputeRate": true, "isCounter": true, "counterResetValue": "0"}`),
+		}
+
+		metric, err := service.buildMetric(query)
+		require.NoError(t, err)
+
+		require.Equal(t, map[string]interface{}{"counter": true, "resetValue": float64(0), "dropResets": true}, metric["rateOptions"])
+	})
+
+	t.Run("invalid rate options are rejected", func(t *testing.T) {
+		query := backend.DataQuery{
+			JSON: []byte(`{"metric": "net.bytes", "shouldComputeRate": true, "counterMax": "max"}`),
+		}
+
+		_, err := service.buildMetric(query)
+		require.Error(t, err)
+	})
+
+	t.Run("downsampling defaults to the query interval", func(t *testing.T) {
+		for interval, expected := range map[time.Duration]string{
+			0:                      "1m-avg",
+			500 * time.Millisecond: "500ms-avg",
+			30 * time.Second:       "30s-avg",
+			5 * time.Minute:        "5m-avg",
+			2 * time.Hour:          "2h-avg",
+			24 * time.Hour:         "1d-avg",
+		} {
+			metric, err := service.buildMetric(backend.DataQuery{Interval: interval, JSON: []byte(`{"metric": "sys.cpu.user"}`)})
+			require.NoError(t, err)
+			require.Equal(t, expected, metric["downsample"])
+			require.Equal(t, "sum", metric["aggregator"])
+		}
+	})
+}
+
+func TestQueryData(t *testing.T) {
+	var requests []*http.Request
+	var bodies []string
+	srv := newRecordedServer(t, map[string]string{"/api/query": "query_response.json"}, &requests)
+	service, pluginCtx := newTestService(srv, `{"tsdbVersion": 3}`)
+
+	from := time.Date(2014, 7, 16, 20, 0, 0, 0, time.UTC)
+	to := from.Add(time.Hour)
+	resp, err := service.QueryData(context.Background(), &backend.QueryDataRequest{
+		PluginContext: pluginCtx,
+		Queries: []backend.DataQuery{
+			{RefID: "A", TimeRange: backend.TimeRange{From: from, To: to}, JSON: []byte(`{"metric": "sys.cpu.user", "downsampleInterval": "1m", "filters": [{"type": "wildcard", "tagk": "host", "filter": "web*", "groupBy": true}]}`)},
+			{RefID: "B", TimeRange: backend.TimeRange{From: from, To: to}, JSON: []byte(`{"metric": ""}`)},
+			{RefID: "C", TimeRange: backend.TimeRange{From: from, To: to}, JSON: []byte(`{"metric": "sys.cpu.user", "filters": [{"filter": "web*"}]}`)},
+		},
+	})
+	require.NoError(t, err)
+	for _, r := range requests {
+		body, err := ioutil.ReadAll(r.Body)
+		require.NoError(t, err)
+		bodies = append(bodies, string(body))
+	}
+
+	t.Run("one request is sent per query", func(t *testing.T) {
+		require.Len(t, requests, 1)
+		assert.JSONEq(t, `{
+			"start": 1405540800000,
+			"end": 1405544400000,
+			"queries": [{
+				"metric": "sys.cpu.user",
+				"aggregator": "sum",
+				"downsample": "1m-avg",
+				"filters": [{"type": "wildcard", "tagk": "host", "filter": "web*", "groupBy": true}]
+			}]
+		}`, bodies[0])
+	})
+
+	t.Run("series are returned for the refId of their query, sorted by time", func(t *testing.T) {
+		res := resp.Responses["A"]
+		require.NoError(t, res.Error)
+		require.Len(t, res.Frames, 2)
+
+		expected := data.NewFrame("sys.cpu.user",
+			data.NewField("time", nil, []time.Time{
+				time.Unix(1405544146, 0).UTC(),
+				time.Unix(1405544206, 0).UTC(),
+				time.Unix(1405544266, 0).UTC(),
+			}),
+			data.NewField("value", data.Labels{"host": "web01", "dc": "lga"}, []float64{40, 42.5, 45.25}),
+		)
+		expected.RefID = "A"
+		if diff := cmp.Diff(expected, res.Frames[0], data.FrameTestCompareOptions()...); diff != "" {
+			t.Errorf("Result mismatch (-want +got):\n%s", diff)
+		}
+		assert.Equal(t, data.Labels{"host": "web02", "dc": "lga"}, res.Frames[1].Fields[1].Labels)
+	})
+
+	t.Run("queries without metric are skipped", func(t *testing.T) {
+		_, ok := resp.Responses["B"]
+		assert.False(t, ok)
+	})
+
+	t.Run("invalid queries return an error", func(t *testing.T) {
+		assert.Error(t, resp.Responses["C"].Error)
+	})
+}
+
+func TestQueryDataMillisecondResolution(t *testing.T) {
+	var bodies []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := ioutil.ReadAll(r.Body)
+		require.NoError(t, err)
+		bodies = append(bodies, string(body))
+		_, _ = w.Write([]byte(`[{"metric": "sys.cpu.user", "tags": {}, "dps": {"1405544146500": 1}}]`))
+	}))
+	t.Cleanup(srv.Close)
+	service, pluginCtx := newTestService(srv, `{"tsdbResolution": 2}`)
+
+	resp, err := service.QueryData(context.Background(), &backend.QueryDataRequest{
+		PluginContext: pluginCtx,
+		Queries:       []backend.DataQuery{{RefID: "A", JSON: []byte(`{"metric": "sys.cpu.user"}`)}},
+	})
+	require.NoError(t, err)
+
+	require.Len(t, bodies, 1)
+	assert.Contains(t, bodies[0], `"msResolution":true`)
+
+	frame := resp.Responses["A"].Frames[0]
+	assert.Equal(t, time.UnixMilli(1405544146500).UTC(), frame.Fields[0].At(0))
+}
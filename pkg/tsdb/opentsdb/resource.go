@@ -0,0 +1,240 @@
+package opentsdb
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+)
+
+// suggestTypes are the types of the /api/suggest endpoint
+var suggestTypes = map[string]bool{
+	"metrics": true,
+	"tagk":    true,
+	"tagv":    true,
+}
+
+// errorResponse is returned by the resource handlers for invalid requests and OpenTSDB errors
+type errorResponse struct {
+	status  int
+	message string
+}
+
+func (e *errorResponse) Error() string {
+	return e.message
+}
+
+func (s *Service) CallResource(ctx context.Context, req *backend.CallResourceRequest, sender backend.CallResourceResponseSender) error {
+	dsInfo, err := s.getDSInfo(req.PluginContext)
+	if err != nil {
+		return err
+	}
+
+	u, err := url.Parse(req.URL)
+	if err != nil {
+		return sender.Send(&backend.CallResourceResponse{Status: http.StatusBadRequest, Body: []byte(err.Error())})
+	}
+	params := u.Query()
+
+	var result []string
+	switch strings.Trim(req.Path, "/") {
+	case "suggest":
+		result, err = s.handleSuggest(ctx, dsInfo, params)
+	case "tag-keys":
+		result, err = s.handleTagKeys(ctx, dsInfo, params)
+	case "tag-values":
+		result, err = s.handleTagValues(ctx, dsInfo, params)
+	case "aggregators":
+		result, err = s.handleAggregators(ctx, dsInfo)
+	case "filter-types":
+		result, err = s.handleFilterTypes(ctx, dsInfo)
+	default:
+		return sender.Send(&backend.CallResourceResponse{Status: http.StatusNotFound})
+	}
+
+	var errRes *errorResponse
+	if err != nil {
+		if !errors.As(err, &errRes) {
+			return err
+		}
+		body, _ := json.Marshal(map[string]string{"message": errRes.message})
+		return sender.Send(resourceResponse(errRes.status, body))
+	}
+
+	body, err := json.Marshal(result)
+	if err != nil {
+		return err
+	}
+	return sender.Send(resourceResponse(http.StatusOK, body))
+}
+
+// handleSuggest returns the metric names, tag keys or tag values starting with the `q` parameter.
+func (s *Service) handleSuggest(ctx context.Context, dsInfo *datasourceInfo, params url.Values) ([]string, error) {
+	suggestType := params.Get("type")
+	if !suggestTypes[suggestType] {
+		return nil, &errorResponse{status: http.StatusBadRequest, message: fmt.Sprintf("invalid suggest type %q", suggestType)}
+	}
+
+	max := dsInfo.LookupLimit
+	if value, err := strconv.Atoi(params.Get("max")); err == nil && value > 0 && value < max {
+		max = value
+	}
+
+	result := []string{}
+	err := s.fetchJSON(ctx, dsInfo, "api/suggest", url.Values{
+		"type": []string{suggestType},
+		"q":    []string{params.Get("q")},
+		"max":  []string{strconv.Itoa(max)},
+	}, &result)
+	return result, err
+}
+
+// handleTagKeys returns the tag keys of the series of a metric.
+func (s *Service) handleTagKeys(ctx context.Context, dsInfo *datasourceInfo, params url.Values) ([]string, error) {
+	metric := params.Get("metric")
+	if metric == "" {
+		return []string{}, nil
+	}
+
+	lookup, err := s.lookup(ctx, dsInfo, metric)
+	if err != nil {
+		return nil, err
+	}
+
+	keys := map[string]bool{}
+	for _, r := range lookup.Results {
+		for key := range r.Tags {
+			keys[key] = true
+		}
+	}
+	return sortedKeys(keys), nil
+}
+
+// handleTagValues returns the values of the first tag key of the `keys` parameter, the other keys can be
+// used to filter the series, e.g. `host,env=prod`.
+func (s *Service) handleTagValues(ctx context.Context, dsInfo *datasourceInfo, params url.Values) ([]string, error) {
+	metric := params.Get("metric")
+	keys := strings.Split(params.Get("keys"), ",")
+	for i := range keys {
+		keys[i] = strings.TrimSpace(keys[i])
+	}
+	if metric == "" || keys[0] == "" {
+		return []string{}, nil
+	}
+
+	query := keys[0] + "=*"
+	if len(keys) > 1 {
+		query += "," + strings.Join(keys[1:], ",")
+	}
+
+	lookup, err := s.lookup(ctx, dsInfo, metric+"{"+query+"}")
+	if err != nil {
+		return nil, err
+	}
+
+	values := map[string]bool{}
+	for _, r := range lookup.Results {
+		if value, ok := r.Tags[keys[0]]; ok {
+			values[value] = true
+		}
+	}
+	return sortedKeys(values), nil
+}
+
+// handleAggregators returns the aggregation functions supported by OpenTSDB.
+func (s *Service) handleAggregators(ctx context.Context, dsInfo *datasourceInfo) ([]string, error) {
+	result := []string{}
+	if err := s.fetchJSON(ctx, dsInfo, "api/aggregators", nil, &result); err != nil {
+		return nil, err
+	}
+	sort.Strings(result)
+	return result, nil
+}
+
+// handleFilterTypes returns the tag filters supported by OpenTSDB, e.g. literal_or, wildcard or regexp.
+func (s *Service) handleFilterTypes(ctx context.Context, dsInfo *datasourceInfo) ([]string, error) {
+	filters := map[string]json.RawMessage{}
+	if err := s.fetchJSON(ctx, dsInfo, "api/config/filters", nil, &filters); err != nil {
+		return nil, err
+	}
+
+	types := make(map[string]bool, len(filters))
+	for name := range filters {
+		types[name] = true
+	}
+	return sortedKeys(types), nil
+}
+
+func (s *Service) lookup(ctx context.Context, dsInfo *datasourceInfo, query string) (*LookupResponse, error) {
+	lookup := &LookupResponse{}
+	err := s.fetchJSON(ctx, dsInfo, "api/search/lookup", url.Values{
+		"m":     []string{query},
+		"limit": []string{strconv.Itoa(dsInfo.LookupLimit)},
+	}, lookup)
+	return lookup, err
+}
+
+func (s *Service) fetchJSON(ctx context.Context, dsInfo *datasourceInfo, resourcePath string, params url.Values, result interface{}) error {
+	u, err := url.Parse(dsInfo.URL)
+	if err != nil {
+		return err
+	}
+	u.Path = path.Join(u.Path, resourcePath)
+	u.RawQuery = params.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	res, err := dsInfo.HTTPClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := res.Body.Close(); err != nil {
+			s.logger.Warn("Failed to close response body", "err", err)
+		}
+	}()
+
+	body, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return err
+	}
+
+	if res.StatusCode/100 != 2 {
+		s.logger.Info("Request failed", "path", resourcePath, "status", res.Status, "body", string(body))
+		return &errorResponse{status: res.StatusCode, message: fmt.Sprintf("request failed, status: %s", res.Status)}
+	}
+
+	if err := json.Unmarshal(body, result); err != nil {
+		return fmt.Errorf("failed to parse the response of %s: %w", resourcePath, err)
+	}
+	return nil
+}
+
+func sortedKeys(set map[string]bool) []string {
+	keys := make([]string, 0, len(set))
+	for key := range set {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func resourceResponse(status int, body []byte) *backend.CallResourceResponse {
+	return &backend.CallResourceResponse{
+		Status:  status,
+		Headers: map[string][]string{"Content-Type": {"application/json"}},
+		Body:    body,
+	}
+}
@@ -0,0 +1,176 @@
+package opentsdb
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana/pkg/infra/httpclient"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeSender struct {
+	res *backend.CallResourceResponse
+}
+
+func (s *fakeSender) Send(res *backend.CallResourceResponse) error {
+	s.res = res
+	return nil
+}
+
+// newRecordedServer serves the recorded OpenTSDB responses of the testdata folder, keyed by request path.
+func newRecordedServer(t *testing.T, files map[string]string, requests *[]*http.Request) *httptest.Server {
+	t.Helper()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if requests != nil {
+			// the body is buffered since it is closed once the handler returns
+			body, err := ioutil.ReadAll(r.Body)
+			require.NoError(t, err)
+			r.Body = ioutil.NopCloser(bytes.NewReader(body))
+			*requests = append(*requests, r)
+		}
+		file, ok := files[r.URL.Path]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"error": {"code": 404, "message": "Endpoint not found"}}`))
+			return
+		}
+		body, err := os.ReadFile(filepath.Join("testdata", file))
+		require.NoError(t, err)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write(body)
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func newTestService(srv *httptest.Server, jsonData string) (*Service, backend.PluginContext) {
+	pluginCtx := backend.PluginContext{
+		DataSourceInstanceSettings: &backend.DataSourceInstanceSettings{URL: srv.URL, JSONData: []byte(jsonData)},
+	}
+	return ProvideService(httpclient.NewProvider()), pluginCtx
+}
+
+func TestCallResource(t *testing.T) {
+	var requests []*http.Request
+	srv := newRecordedServer(t, map[string]string{
+		"/api/suggest":        "suggest_metrics.json",
+		"/api/search/lookup":  "search_lookup.json",
+		"/api/aggregators":    "aggregators.json",
+		"/api/config/filters": "config_filters.json",
+	}, &requests)
+	service, pluginCtx := newTestService(srv, `{"lookupLimit": "500"}`)
+
+	call := func(path string, url string) *backend.CallResourceResponse {
+		sender := &fakeSender{}
+		err := service.CallResource(context.Background(), &backend.CallResourceRequest{
+			PluginContext: pluginCtx,
+			Method:        http.MethodGet,
+			Path:          path,
+			URL:           url,
+		}, sender)
+		require.NoError(t, err)
+		return sender.res
+	}
+
+	t.Run("suggest metrics", func(t *testing.T) {
+		requests = nil
+		res := call("suggest", "suggest?type=metrics&q=sys.cpu")
+		require.Equal(t, http.StatusOK, res.Status)
+		require.JSONEq(t, `["sys.cpu.nice", "sys.cpu.system", "sys.cpu.user"]`, string(res.Body))
+
+		require.Len(t, requests, 1)
+		query := requests[0].URL.Query()
+		require.Equal(t, "metrics", query.Get("type"))
+		require.Equal(t, "sys.cpu", query.Get("q"))
+		require.Equal(t, "500", query.Get("max"))
+	})
+
+	t.Run("suggest max is capped by the lookup limit", func(t *testing.T) {
+		requests = nil
+		call("suggest", "suggest?type=tagk&q=h&max=10")
+		call("suggest", "suggest?type=tagv&q=w&max=10000")
+
+		require.Len(t, requests, 2)
+		require.Equal(t, "10", requests[0].URL.Query().Get("max"))
+		require.Equal(t, "500", requests[1].URL.Query().Get("max"))
+	})
+
+	t.Run("suggest rejects unknown types", func(t *testing.T) {
+		requests = nil
+		res := call("suggest", "suggest?type=users&q=a")
+		require.Equal(t, http.StatusBadRequest, res.Status)
+		require.JSONEq(t, `{"message": "invalid suggest type \"users\""}`, string(res.Body))
+		require.Empty(t, requests)
+	})
+
+	t.Run("tag keys of a metric", func(t *testing.T) {
+		requests = nil
+		res := call("tag-keys", "tag-keys?metric=sys.cpu.user")
+		require.Equal(t, http.StatusOK, res.Status)
+		require.JSONEq(t, `["dc", "host", "rack"]`, string(res.Body))
+
+		require.Len(t, requests, 1)
+		require.Equal(t, "sys.cpu.user", requests[0].URL.Query().Get("m"))
+		require.Equal(t, "500", requests[0].URL.Query().Get("limit"))
+	})
+
+	t.Run("tag values of a metric", func(t *testing.T) {
+		requests = nil
+		res := call("tag-values", "tag-values?metric=sys.cpu.user&keys=host,%20dc%3Dlga")
+		require.Equal(t, http.StatusOK, res.Status)
+		require.JSONEq(t, `["web01", "web02", "web03"]`, string(res.Body))
+
+		require.Len(t, requests, 1)
+		require.Equal(t, "sys.cpu.user{host=*,dc=lga}", requests[0].URL.Query().Get("m"))
+	})
+
+	t.Run("tag values without key", func(t *testing.T) {
+		requests = nil
+		res := call("tag-values", "tag-values?metric=sys.cpu.user")
+		require.Equal(t, http.StatusOK, res.Status)
+		require.JSONEq(t, `[]`, string(res.Body))
+		require.Empty(t, requests)
+	})
+
+	t.Run("aggregators are sorted", func(t *testing.T) {
+		res := call("aggregators", "aggregators")
+		require.Equal(t, http.StatusOK, res.Status)
+		require.JSONEq(t, `["avg", "count", "dev", "max", "mimmin", "min", "p99", "sum", "zimsum"]`, string(res.Body))
+	})
+
+	t.Run("filter types", func(t *testing.T) {
+		res := call("filter-types", "filter-types")
+		require.Equal(t, http.StatusOK, res.Status)
+		require.JSONEq(t, `["iliteral_or", "literal_or", "regexp", "wildcard"]`, string(res.Body))
+	})
+
+	t.Run("unknown resource", func(t *testing.T) {
+		res := call("metrics", "metrics")
+		require.Equal(t, http.StatusNotFound, res.Status)
+	})
+}
+
+func TestCallResourceErrors(t *testing.T) {
+	srv := newRecordedServer(t, map[string]string{}, nil)
+	service, pluginCtx := newTestService(srv, `{}`)
+
+	sender := &fakeSender{}
+	err := service.CallResource(context.Background(), &backend.CallResourceRequest{
+		PluginContext: pluginCtx,
+		Method:        http.MethodGet,
+		Path:          "aggregators",
+		URL:           "aggregators",
+	}, sender)
+	require.NoError(t, err)
+
+	require.Equal(t, http.StatusNotFound, sender.res.Status)
+	require.JSONEq(t, `{"message": "request failed, status: 404 Not Found"}`, string(sender.res.Body))
+}
@@ -1,9 +1,10 @@
 package opentsdb
 
 type OpenTsdbQuery struct {
-	Start   int64                    `json:"start"`
-	End     int64                    `json:"end"`
-	Queries []map[string]interface{} `json:"queries"`
+	Start        int64                    `json:"start"`
+	End          int64                    `json:"end"`
+	Queries      []map[string]interface{} `json:"queries"`
+	MsResolution bool                     `json:"msResolution,omitempty"`
 }
 
 type OpenTsdbResponse struct {
@@ -11,3 +12,20 @@ type OpenTsdbResponse struct {
 	Tags       map[string]string  `json:"tags"`
 	DataPoints map[string]float64 `json:"dps"`
 }
+
+// Filter is a tag filter of a query, available since OpenTSDB 2.2.
+// See http://opentsdb.net/docs/build/html/user_guide/query/filters.html
+type Filter struct {
+	Type    string `json:"type"`
+	Tagk    string `json:"tagk"`
+	Filter  string `json:"filter"`
+	GroupBy bool   `json:"groupBy"`
+}
+
+// LookupResponse is the response of the /api/search/lookup endpoint
+type LookupResponse struct {
+	Results []struct {
+		Metric string            `json:"metric"`
+		Tags   map[string]string `json:"tags"`
+	} `json:"results"`
+}
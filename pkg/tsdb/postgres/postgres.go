@@ -2,7 +2,9 @@ package postgres
 
 import (
 	"context"
+	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"reflect"
 	"strconv"
@@ -50,6 +52,14 @@ func (s *Service) QueryData(ctx context.Context, req *backend.QueryDataRequest)
 	return dsInfo.QueryData(ctx, req)
 }
 
+func (s *Service) CheckHealth(ctx context.Context, req *backend.CheckHealthRequest) (*backend.CheckHealthResult, error) {
+	dsInfo, err := s.getDSInfo(req.PluginContext)
+	if err != nil {
+		return nil, err
+	}
+	return dsInfo.CheckHealth(ctx, req)
+}
+
 func (s *Service) newInstanceSettings(cfg *setting.Cfg) datasource.InstanceFactoryFunc {
 	return func(settings backend.DataSourceInstanceSettings) (instancemgmt.Instance, error) {
 		logger.Debug("Creating Postgres query endpoint")
@@ -91,6 +101,8 @@ func (s *Service) newInstanceSettings(cfg *setting.Cfg) datasource.InstanceFacto
 			DSInfo:            dsInfo,
 			MetricColumnTypes: []string{"UNKNOWN", "TEXT", "VARCHAR", "CHAR"},
 			RowLimit:          cfg.DataProxyRowLimit,
+			Placeholder:       sqleng.DollarPlaceholder,
+			BindSyntax:        sqleng.PostgresBindSyntax,
 		}
 
 		queryResultTransformer := postgresQueryResultTransformer{
@@ -193,6 +205,38 @@ func (t *postgresQueryResultTransformer) TransformQueryError(err error) error {
 	return err
 }
 
+func (t *postgresQueryResultTransformer) EstimateQueryCost(ctx context.Context, db *sql.DB, query string, args []interface{}) (float64, error) {
+	var plan string
+	if err := db.QueryRowContext(ctx, "EXPLAIN (FORMAT JSON) "+query, args...).Scan(&plan); err != nil {
+		return 0, err
+	}
+	return parseExplainCost(plan)
+}
+
+// parseExplainCost returns the total cost of the top plan nodes of an EXPLAIN (FORMAT JSON) result,
+// which include the costs of their subplans.
+func parseExplainCost(plan string) (float64, error) {
+	var explain []struct {
+		Plan struct {
+			TotalCost *float64 `json:"Total Cost"`
+		} `json:"Plan"`
+	}
+	if err := json.Unmarshal([]byte(plan), &explain); err != nil {
+		return 0, err
+	}
+	if len(explain) == 0 {
+		return 0, errors.New("query plan has no total cost")
+	}
+	var total float64
+	for _, e := range explain {
+		if e.Plan.TotalCost == nil {
+			return 0, errors.New("query plan has no total cost")
+		}
+		total += *e.Plan.TotalCost
+	}
+	return total, nil
+}
+
 func (t *postgresQueryResultTransformer) GetConverterList() []sqlutil.StringConverter {
 	return []sqlutil.StringConverter{
 		{
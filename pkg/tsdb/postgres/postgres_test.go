@@ -1370,3 +1370,19 @@ type tlsTestManager struct {
 func (m *tlsTestManager) getTLSSettings(dsInfo sqleng.DataSourceInfo) (tlsSettings, error) {
 	return m.settings, nil
 }
+
+func TestParseExplainCost(t *testing.T) {
+	cost, err := parseExplainCost(`[{"Plan": {"Node Type": "Seq Scan", "Startup Cost": 0.00, "Total Cost": 35.50, "Plan Rows": 2550}}]`)
+	require.NoError(t, err)
+	require.Equal(t, 35.5, cost)
+
+	cost, err = parseExplainCost(`[{"Plan": {"Total Cost": 10.25}}, {"Plan": {"Total Cost": 5.25}}]`)
+	require.NoError(t, err)
+	require.Equal(t, 15.5, cost)
+
+	_, err = parseExplainCost(`[]`)
+	require.Error(t, err)
+
+	_, err = parseExplainCost(`[{"Plan": {"Total Cost": 10.25}}, {"Plan": {}}]`)
+	require.Error(t, err)
+}
@@ -88,6 +88,61 @@ func (c *Client) QueryExemplars(ctx context.Context, q *models.Query, headers ht
 	return c.doer.Do(req)
 }
 
+// QueryTSDBStatus fetches the cardinality statistics of the head block. Not every Prometheus compatible backend
+// implements this endpoint, so callers should be ready to handle a non 2xx status.
+func (c *Client) QueryTSDBStatus(ctx context.Context, limit int, headers http.Header) (*http.Response, error) {
+	u, err := c.createUrl("api/v1/status/tsdb", map[string]string{"limit": strconv.Itoa(limit)})
+	if err != nil {
+		return nil, err
+	}
+	req, err := createRequest(ctx, http.MethodGet, u, nil, headers)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.doer.Do(req)
+}
+
+// QuerySeries fetches the series matching match. A positive limit is passed on to backends that support limiting
+// the number of returned series, older backends ignore it and callers should not rely on it being respected.
+func (c *Client) QuerySeries(ctx context.Context, match string, start, end time.Time, limit int, headers http.Header) (*http.Response, error) {
+	qs := map[string]string{
+		"match[]": match,
+		"start":   formatTime(start),
+		"end":     formatTime(end),
+	}
+	if limit > 0 {
+		qs["limit"] = strconv.Itoa(limit)
+	}
+	u, err := c.createUrl("api/v1/series", qs)
+	if err != nil {
+		return nil, err
+	}
+	req, err := createRequest(ctx, http.MethodGet, u, nil, headers)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.doer.Do(req)
+}
+
+func (c *Client) QueryMetadata(ctx context.Context, metric string, headers http.Header) (*http.Response, error) {
+	qs := map[string]string{}
+	if metric != "" {
+		qs["metric"] = metric
+	}
+	u, err := c.createUrl("api/v1/metadata", qs)
+	if err != nil {
+		return nil, err
+	}
+	req, err := createRequest(ctx, http.MethodGet, u, nil, headers)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.doer.Do(req)
+}
+
 func (c *Client) QueryResource(ctx context.Context, req *backend.CallResourceRequest) (*http.Response, error) {
 	// The way URL is represented in CallResourceRequest and what we need for the fetch function is different
 	// so here we have to do a bit of parsing, so we can then compose it with the base url in correct way.
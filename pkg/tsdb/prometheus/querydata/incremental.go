@@ -0,0 +1,183 @@
+package querydata
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"math"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana/pkg/infra/localcache"
+	"github.com/grafana/grafana/pkg/tsdb/prometheus/client"
+	"github.com/grafana/grafana/pkg/tsdb/prometheus/models"
+)
+
+const (
+	// chunkDuration is the length of the chunks long range queries are split into
+	chunkDuration = 24 * time.Hour
+	// defaultOverlapWindow is how far back from now samples may still change, e.g. because of late samples or
+	// recording rule evaluations. Chunks ending within the window are not cached.
+	defaultOverlapWindow = 10 * time.Minute
+	chunkCacheTTL        = time.Hour
+	// defaultChunkCacheSizeMB bounds the estimated size of the cached chunks of a data source, chunks are not
+	// cached when the cache is full until entries expire.
+	defaultChunkCacheSizeMB = 64
+	maxConcurrentChunks     = 4
+)
+
+// Headers that may change what the user is allowed to see and therefore have to be part of the chunk cache key.
+var chunkCacheKeyHeaders = []string{"Authorization", "X-Id-Token"}
+
+type cachedChunk struct {
+	res  *backend.DataResponse
+	size int64
+}
+
+type queryChunk struct {
+	query *models.Query
+	// cacheable is set for chunks that ended before the overlap window, their samples will not change anymore
+	cacheable bool
+}
+
+// splitQuery splits the time range of a range query into chunks aligned to chunkDuration. The chunk boundaries
+// don't depend on the query start, so that chunks can be reused by later queries with a moving time range. The
+// first chunk may start before the query, samples outside the query time range are dropped when merging.
+func splitQuery(q *models.Query, mutableAfter time.Time) []queryChunk {
+	tr := q.TimeRange()
+	// chunks are a multiple of the step, so chunk boundaries stay aligned to the step
+	chunkLen := time.Duration(math.Ceil(float64(chunkDuration)/float64(tr.Step))) * tr.Step
+
+	var chunks []queryChunk
+	for start := alignTime(tr.Start, chunkLen, q.UtcOffsetSec); !start.After(tr.End); start = start.Add(chunkLen) {
+		end := start.Add(chunkLen - tr.Step)
+		if end.After(tr.End) {
+			end = tr.End
+		}
+
+		chunk := *q
+		chunk.Start = start
+		chunk.End = end
+		chunks = append(chunks, queryChunk{query: &chunk, cacheable: end.Before(mutableAfter)})
+	}
+	return chunks
+}
+
+func alignTime(t time.Time, d time.Duration, offset int64) time.Time {
+	seconds := d.Seconds()
+	return time.Unix(int64(math.Floor(float64(t.Unix()+offset)/seconds)*seconds)-offset, 0)
+}
+
+// incrementalRangeQuery runs a range query chunk by chunk, taking chunks that can no longer change from the cache,
+// and stitches the chunk responses together.
+func (s *QueryData) incrementalRangeQuery(ctx context.Context, c *client.Client, q *models.Query, headers map[string]string) (*backend.DataResponse, error) {
+	chunks := splitQuery(q, time.Now().Add(-s.overlapWindow))
+	responses := make([]*backend.DataResponse, len(chunks))
+	errs := make([]error, len(chunks))
+
+	sem := make(chan struct{}, maxConcurrentChunks)
+	var wg sync.WaitGroup
+	for i, chunk := range chunks {
+		key := chunkCacheKey(chunk.query, headers)
+		if chunk.cacheable {
+			if cached, ok := s.chunkCache.Get(key); ok {
+				responses[i] = cached.(cachedChunk).res
+				continue
+			}
+		}
+
+		wg.Add(1)
+		go func(i int, chunk queryChunk) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			res, err := c.QueryRange(ctx, chunk.query, sdkHeaderToHttpHeader(headers))
+			if err != nil {
+				errs[i] = err
+				return
+			}
+			r, err := s.parseResponse(ctx, chunk.query, res)
+			if err != nil {
+				errs[i] = err
+				return
+			}
+			if chunk.cacheable && r.Error == nil {
+				s.cacheChunk(key, r)
+			}
+			responses[i] = r
+		}(i, chunk)
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	s.log.Debug("Ran incremental range query", "query", q.Expr, "chunks", len(chunks))
+	return mergeChunkResponses(q, responses), nil
+}
+
+// newChunkCache creates the chunk cache, keeping track of the estimated size of the cached chunks.
+func (s *QueryData) newChunkCache() *localcache.CacheService {
+	cache := localcache.New(chunkCacheTTL, 2*chunkCacheTTL)
+	cache.OnEvicted(func(_ string, v interface{}) {
+		atomic.AddInt64(&s.chunkCacheSize, -v.(cachedChunk).size)
+	})
+	return cache
+}
+
+// cacheChunk caches the response of a chunk unless the cache is full.
+func (s *QueryData) cacheChunk(key string, r *backend.DataResponse) {
+	size := responseSize(r)
+
+	s.chunkCacheMu.Lock()
+	defer s.chunkCacheMu.Unlock()
+
+	// chunks queried concurrently by several requests replace each other
+	s.chunkCache.Delete(key)
+	if atomic.LoadInt64(&s.chunkCacheSize)+size > s.chunkCacheMaxSize {
+		s.chunkCache.DeleteExpired()
+		if atomic.LoadInt64(&s.chunkCacheSize)+size > s.chunkCacheMaxSize {
+			return
+		}
+	}
+	atomic.AddInt64(&s.chunkCacheSize, size)
+	s.chunkCache.Set(key, cachedChunk{res: r, size: size}, chunkCacheTTL)
+}
+
+// responseSize estimates the memory used by a response. Prometheus responses have a time and a float64 field,
+// nullable values take 16 bytes.
+func responseSize(r *backend.DataResponse) int64 {
+	var size int64
+	for _, frame := range r.Frames {
+		size += int64(len(frame.Name))
+		for _, field := range frame.Fields {
+			size += int64(len(field.Name)) + 16*int64(field.Len())
+			for name, value := range field.Labels {
+				size += int64(len(name) + len(value))
+			}
+		}
+	}
+	return size
+}
+
+// chunkCacheKey identifies a chunk by everything its response depends on. Identity headers, like forwarded OAuth
+// tokens, are part of the key as they may change the result.
+func chunkCacheKey(q *models.Query, headers map[string]string) string {
+	h := sha256.New()
+	for _, name := range chunkCacheKeyHeaders {
+		for key, value := range headers {
+			if strings.EqualFold(key, name) {
+				_, _ = fmt.Fprintf(h, "%s=%s\n", name, value)
+			}
+		}
+	}
+
+	return fmt.Sprintf("%s\x00%s\x00%s\x00%d\x00%d\x00%x", q.Expr, q.LegendFormat, q.Step, q.Start.Unix(), q.End.Unix(), h.Sum(nil))
+}
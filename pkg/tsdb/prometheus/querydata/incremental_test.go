@@ -0,0 +1,185 @@
+package querydata
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/tracing"
+	"github.com/grafana/grafana/pkg/tsdb/prometheus/models"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeFeatures struct{}
+
+func (fakeFeatures) IsEnabled(string) bool { return false }
+
+func TestSplitQuery(t *testing.T) {
+	from := time.Date(2022, 3, 10, 6, 0, 0, 0, time.UTC)
+	q := &models.Query{Expr: "up", Step: time.Hour, Start: from, End: from.Add(50 * time.Hour)}
+
+	chunks := splitQuery(q, from.Add(40*time.Hour))
+	require.Len(t, chunks, 3)
+
+	require.Equal(t, time.Date(2022, 3, 10, 0, 0, 0, 0, time.UTC), chunks[0].query.Start.UTC())
+	require.Equal(t, time.Date(2022, 3, 10, 23, 0, 0, 0, time.UTC), chunks[0].query.End.UTC())
+	require.True(t, chunks[0].cacheable)
+
+	require.Equal(t, time.Date(2022, 3, 11, 0, 0, 0, 0, time.UTC), chunks[1].query.Start.UTC())
+	require.False(t, chunks[1].cacheable, "chunks ending within the overlap window are not cached")
+
+	require.Equal(t, time.Date(2022, 3, 12, 0, 0, 0, 0, time.UTC), chunks[2].query.Start.UTC())
+	require.Equal(t, time.Date(2022, 3, 12, 8, 0, 0, 0, time.UTC), chunks[2].query.End.UTC())
+}
+
+func TestMergeChunkResponses(t *testing.T) {
+	at := func(hour int) time.Time { return time.Date(2022, 3, 10, hour, 0, 0, 0, time.UTC) }
+	newFrame := func(labels data.Labels, times []time.Time, values []float64) *data.Frame {
+		return data.NewFrame("", data.NewField("Time", nil, times), data.NewField("Value", labels, values))
+	}
+	a := data.Labels{"job": "a"}
+	b := data.Labels{"job": "b"}
+
+	warning := data.Notice{Severity: data.NoticeSeverityWarning, Text: "too many samples"}
+	last := newFrame(a, []time.Time{at(4), at(5)}, []float64{4, 5})
+	last.Meta = &data.FrameMeta{ExecutedQueryString: "chunk", Notices: []data.Notice{warning}}
+
+	q := &models.Query{Expr: "up", Step: time.Hour, Start: at(2), End: at(6)}
+	merged := mergeChunkResponses(q, []*backend.DataResponse{
+		{Frames: data.Frames{
+			newFrame(a, []time.Time{at(0), at(1), at(2), at(3)}, []float64{0, 1, 2, 3}),
+			newFrame(b, []time.Time{at(0)}, []float64{0}),
+		}},
+		{Frames: data.Frames{last}},
+	})
+
+	require.NoError(t, merged.Error)
+	require.Len(t, merged.Frames, 1, "series without samples in the time range are dropped")
+	require.Equal(t, 4, merged.Frames[0].Rows())
+	require.Equal(t, at(2), merged.Frames[0].Fields[0].At(0))
+	require.Equal(t, 5.0, merged.Frames[0].Fields[1].At(3))
+
+	// notices of later chunks are kept, the chunk responses are not changed as they may be cached
+	require.Equal(t, executedQueryString(q), merged.Frames[0].Meta.ExecutedQueryString)
+	require.Equal(t, []data.Notice{warning}, merged.Frames[0].Meta.Notices)
+	require.Equal(t, "chunk", last.Meta.ExecutedQueryString)
+}
+
+func TestIncrementalRangeQuery(t *testing.T) {
+	var requests int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		start, _ := strconv.ParseFloat(r.FormValue("start"), 64)
+		end, _ := strconv.ParseFloat(r.FormValue("end"), 64)
+		step, _ := strconv.ParseFloat(r.FormValue("step"), 64)
+
+		values := []string{}
+		for ts := start; ts <= end; ts += step {
+			values = append(values, fmt.Sprintf(`[%v, "%v"]`, ts, ts))
+		}
+		_, _ = fmt.Fprintf(w, `{"status": "success", "data": {"resultType": "matrix", "result": [{"metric": {"job": "a"}, "values": [%s]}]}}`,
+			strings.Join(values, ","))
+	}))
+	t.Cleanup(srv.Close)
+
+	qd, err := New(srv.Client(), fakeFeatures{}, tracing.InitializeTracerForTest(), backend.DataSourceInstanceSettings{
+		URL:      srv.URL,
+		JSONData: json.RawMessage(`{"incrementalQuerying": true}`),
+	}, log.New("test"))
+	require.NoError(t, err)
+
+	query := func(from time.Time) data.Frames {
+		res, err := qd.Execute(context.Background(), &backend.QueryDataRequest{
+			Queries: []backend.DataQuery{{
+				RefID:     "A",
+				JSON:      []byte(`{"expr": "up", "range": true, "interval": "1h"}`),
+				TimeRange: backend.TimeRange{From: from, To: from.Add(72 * time.Hour)},
+			}},
+		})
+		require.NoError(t, err)
+		require.NoError(t, res.Responses["A"].Error)
+		return res.Responses["A"].Frames
+	}
+
+	from := time.Date(2022, 3, 10, 6, 30, 0, 0, time.UTC)
+	frames := query(from)
+	require.Equal(t, int32(4), atomic.LoadInt32(&requests))
+	require.Len(t, frames, 1)
+
+	times := frames[0].Fields[0]
+	require.Equal(t, time.Date(2022, 3, 10, 6, 0, 0, 0, time.UTC), times.At(0).(time.Time).UTC())
+	require.Equal(t, time.Date(2022, 3, 13, 6, 0, 0, 0, time.UTC), times.At(times.Len()-1).(time.Time).UTC())
+	for i := 1; i < times.Len(); i++ {
+		require.Equal(t, time.Hour, times.At(i).(time.Time).Sub(times.At(i-1).(time.Time)))
+	}
+
+	// moving the time range only fetches the changed head
+	frames = query(from.Add(time.Hour))
+	require.Equal(t, int32(5), atomic.LoadInt32(&requests))
+	require.Equal(t, 73, frames[0].Rows())
+}
+
+func TestChunkCacheKey(t *testing.T) {
+	q := &models.Query{Expr: "up", Step: time.Hour, Start: time.Unix(0, 0), End: time.Unix(3600, 0)}
+
+	key := chunkCacheKey(q, map[string]string{"Authorization": "Bearer a", "X-Request-Id": "1"})
+	require.Equal(t, key, chunkCacheKey(q, map[string]string{"Authorization": "Bearer a", "X-Request-Id": "2"}))
+	require.NotEqual(t, key, chunkCacheKey(q, map[string]string{"Authorization": "Bearer b", "X-Request-Id": "1"}))
+	require.NotEqual(t, key, chunkCacheKey(q, map[string]string{"Authorization": "Bearer a", "X-ID-Token": "t"}))
+}
+
+func TestChunkCacheIsBounded(t *testing.T) {
+	qd, err := New(http.DefaultClient, fakeFeatures{}, tracing.InitializeTracerForTest(), backend.DataSourceInstanceSettings{
+		JSONData: json.RawMessage(`{"incrementalQuerying": true}`),
+	}, log.New("test"))
+	require.NoError(t, err)
+
+	res := &backend.DataResponse{Frames: data.Frames{data.NewFrame("",
+		data.NewField("Time", nil, make([]time.Time, 1024)),
+		data.NewField("Value", nil, make([]float64, 1024)),
+	)}}
+	size := responseSize(res)
+	require.Equal(t, int64(defaultChunkCacheSizeMB<<20), qd.chunkCacheMaxSize)
+	qd.chunkCacheMaxSize = 10 * size
+
+	for i := 0; i < 20; i++ {
+		qd.cacheChunk(strconv.Itoa(i), res)
+	}
+	require.Equal(t, 10, qd.chunkCache.ItemCount())
+	require.Equal(t, 10*size, qd.chunkCacheSize)
+
+	// replacing a chunk does not count it twice, removed chunks free their size
+	qd.cacheChunk("0", res)
+	require.Equal(t, 10*size, qd.chunkCacheSize)
+	qd.chunkCache.Delete("0")
+	require.Equal(t, 9*size, qd.chunkCacheSize)
+}
+
+func TestChunkCacheSizeSetting(t *testing.T) {
+	for jsonData, expected := range map[string]int64{
+		`{"incrementalQueryCacheSizeMB": 10}`:    10 << 20,
+		`{"incrementalQueryCacheSizeMB": "0.5"}`: 1 << 19,
+		`{"incrementalQueryCacheSizeMB": ""}`:    defaultChunkCacheSizeMB << 20,
+	} {
+		qd, err := New(http.DefaultClient, fakeFeatures{}, tracing.InitializeTracerForTest(), backend.DataSourceInstanceSettings{
+			JSONData: json.RawMessage(jsonData),
+		}, log.New("test"))
+		require.NoError(t, err, jsonData)
+		require.Equal(t, expected, qd.chunkCacheMaxSize, jsonData)
+	}
+
+	_, err := New(http.DefaultClient, fakeFeatures{}, tracing.InitializeTracerForTest(), backend.DataSourceInstanceSettings{
+		JSONData: json.RawMessage(`{"incrementalQueryCacheSizeMB": "a lot"}`),
+	}, log.New("test"))
+	require.Error(t, err)
+}
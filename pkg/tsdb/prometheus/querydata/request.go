@@ -2,12 +2,16 @@ package querydata
 
 import (
 	"context"
+	"fmt"
 	"net/http"
 	"regexp"
+	"strconv"
+	"sync"
 	"time"
 
 	"github.com/grafana/grafana-plugin-sdk-go/backend"
 	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/grafana/grafana/pkg/infra/localcache"
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/infra/tracing"
 	"github.com/grafana/grafana/pkg/services/featuremgmt"
@@ -40,6 +44,14 @@ type QueryData struct {
 	URL                string
 	TimeInterval       string
 	enableWideSeries   bool
+
+	// incrementalQuerying splits long range queries into chunks and caches the chunks that can no longer change
+	incrementalQuerying bool
+	overlapWindow       time.Duration
+	chunkCache          *localcache.CacheService
+	chunkCacheMu        sync.Mutex
+	chunkCacheSize      int64 // estimated size of the cached chunks, updated atomically
+	chunkCacheMaxSize   int64
 }
 
 func New(
@@ -60,9 +72,43 @@ func New(
 		return nil, err
 	}
 
+	incrementalQuerying, err := maputil.GetBoolOptional(jsonData, "incrementalQuerying")
+	if err != nil {
+		return nil, err
+	}
+
+	overlapWindow := defaultOverlapWindow
+	if window, err := maputil.GetStringOptional(jsonData, "incrementalQueryOverlapWindow"); err != nil {
+		return nil, err
+	} else if window != "" {
+		overlapWindow, err = intervalv2.ParseIntervalStringToTimeDuration(window)
+		if err != nil {
+			return nil, fmt.Errorf("invalid incremental query overlap window: %w", err)
+		}
+	}
+
+	cacheSizeMB := float64(defaultChunkCacheSizeMB)
+	switch v := jsonData["incrementalQueryCacheSizeMB"].(type) {
+	case nil:
+	case float64:
+		cacheSizeMB = v
+	case string:
+		if v != "" {
+			cacheSizeMB, err = strconv.ParseFloat(v, 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid incremental query cache size: %w", err)
+			}
+		}
+	default:
+		return nil, fmt.Errorf("invalid incremental query cache size: %v", v)
+	}
+	if cacheSizeMB < 0 {
+		return nil, fmt.Errorf("invalid incremental query cache size: %v", cacheSizeMB)
+	}
+
 	promClient := client.NewClient(httpClient, httpMethod, settings.URL)
 
-	return &QueryData{
+	qd := &QueryData{
 		intervalCalculator: intervalv2.NewCalculator(),
 		tracer:             tracer,
 		log:                plog,
@@ -71,7 +117,13 @@ func New(
 		ID:                 settings.ID,
 		URL:                settings.URL,
 		enableWideSeries:   features.IsEnabled(featuremgmt.FlagPrometheusWideSeries),
-	}, nil
+
+		incrementalQuerying: incrementalQuerying,
+		overlapWindow:       overlapWindow,
+		chunkCacheMaxSize:   int64(cacheSizeMB * (1 << 20)),
+	}
+	qd.chunkCache = qd.newChunkCache()
+	return qd, nil
 }
 
 func (s *QueryData) Execute(ctx context.Context, req *backend.QueryDataRequest) (*backend.QueryDataResponse, error) {
@@ -142,6 +194,11 @@ func (s *QueryData) fetch(ctx context.Context, client *client.Client, q *models.
 }
 
 func (s *QueryData) rangeQuery(ctx context.Context, c *client.Client, q *models.Query, headers map[string]string) (*backend.DataResponse, error) {
+	// wide frames hold all series in one frame and are not split
+	if s.incrementalQuerying && !s.enableWideSeries && q.End.Sub(q.Start) > chunkDuration {
+		return s.incrementalRangeQuery(ctx, c, q, headers)
+	}
+
 	res, err := c.QueryRange(ctx, q, sdkHeaderToHttpHeader(headers))
 	if err != nil {
 		return nil, err
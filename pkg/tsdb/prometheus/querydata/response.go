@@ -6,6 +6,7 @@ import (
 	"net/http"
 	"sort"
 	"strings"
+	"time"
 
 	"github.com/grafana/grafana-plugin-sdk-go/backend"
 	"github.com/grafana/grafana-plugin-sdk-go/data"
@@ -42,6 +43,98 @@ func (s *QueryData) parseResponse(ctx context.Context, q *models.Query, res *htt
 	return r, nil
 }
 
+// mergeChunkResponses stitches the responses of the consecutive chunks of a range query together. Frames of the
+// same series are joined and samples outside of the query time range are dropped.
+func mergeChunkResponses(q *models.Query, responses []*backend.DataResponse) *backend.DataResponse {
+	tr := q.TimeRange()
+	merged := &backend.DataResponse{Frames: data.Frames{}}
+	series := map[string]*data.Frame{}
+	var notices []data.Notice
+
+	for _, res := range responses {
+		if res.Error != nil {
+			return res
+		}
+
+		for _, frame := range res.Frames {
+			if frame.Meta != nil {
+				notices = appendNewNotices(notices, frame.Meta.Notices)
+			}
+			if len(frame.Fields) < 2 {
+				continue
+			}
+
+			key := frame.Name + frame.Fields[1].Labels.String()
+			target, exists := series[key]
+			if !exists {
+				target = frame.EmptyCopy()
+				target.Meta = frame.Meta
+				for i, field := range frame.Fields {
+					target.Fields[i].Config = field.Config
+				}
+				series[key] = target
+				merged.Frames = append(merged.Frames, target)
+			}
+
+			for row := 0; row < frame.Rows(); row++ {
+				t, ok := frame.Fields[0].ConcreteAt(row)
+				if !ok {
+					continue
+				}
+				if ts := t.(time.Time); ts.Before(tr.Start) || ts.After(tr.End) {
+					continue
+				}
+				for i, field := range frame.Fields {
+					target.Fields[i].Append(field.At(row))
+				}
+			}
+		}
+	}
+
+	// drop series that only had samples before the query time range
+	frames := merged.Frames[:0]
+	for _, frame := range merged.Frames {
+		if frame.Rows() > 0 {
+			frames = append(frames, frame)
+		}
+	}
+	merged.Frames = frames
+
+	// without any samples, the most recent chunk still carries the response metadata
+	if len(merged.Frames) == 0 && len(responses) > 0 {
+		return responses[len(responses)-1]
+	}
+
+	// the metadata is recomputed for the whole query, notices of all chunks are kept
+	for _, frame := range merged.Frames {
+		meta := data.FrameMeta{}
+		if frame.Meta != nil {
+			meta = *frame.Meta
+		}
+		meta.ExecutedQueryString = executedQueryString(q)
+		meta.Notices = notices
+		frame.Meta = &meta
+	}
+	return merged
+}
+
+// appendNewNotices appends the notices which are not in the list yet
+func appendNewNotices(notices []data.Notice, newNotices []data.Notice) []data.Notice {
+	for _, notice := range newNotices {
+		exists := false
+		for _, n := range notices {
+			if n.Severity == notice.Severity && n.Text == notice.Text {
+				exists = true
+				break
+			}
+		}
+		if !exists {
+			notices = append(notices, notice)
+		}
+	}
+	return notices
+}
+
 func addMetadataToMultiFrame(q *models.Query, frame *data.Frame) {
 	if frame.Meta == nil {
 		frame.Meta = &data.FrameMeta{}
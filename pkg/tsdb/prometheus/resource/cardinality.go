@@ -0,0 +1,416 @@
+package resource
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+)
+
+const (
+	// Paths handled by the backend itself instead of being proxied to Prometheus.
+	cardinalityPath = "cardinality"
+	metadataPath    = "metadata"
+
+	defaultCardinalityLimit = 10
+	maxCardinalityLimit     = 1000
+	// Window of series looked at when the TSDB status endpoint is not available.
+	seriesSampleWindow = 5 * time.Minute
+	defaultSeriesMatch = `{__name__=~".+"}`
+	// Maximum number of series read when sampling, the rest of the response is not read so a cardinality
+	// explosion can't exhaust Grafana's memory.
+	maxSeriesSample = 50000
+
+	resourceCacheTTL = 5 * time.Minute
+)
+
+// Headers that may change what the user is allowed to see and therefore have to be part of the cache key.
+var cacheKeyHeaders = []string{"Authorization", "X-Id-Token"}
+
+type promResponse struct {
+	Status    string          `json:"status"`
+	Data      json.RawMessage `json:"data"`
+	ErrorType string          `json:"errorType"`
+	Error     string          `json:"error"`
+}
+
+type tsdbStatus struct {
+	HeadStats struct {
+		NumSeries uint64 `json:"numSeries"`
+	} `json:"headStats"`
+	SeriesCountByMetricName     []CardinalityStat `json:"seriesCountByMetricName"`
+	LabelValueCountByLabelName  []CardinalityStat `json:"labelValueCountByLabelName"`
+	SeriesCountByLabelValuePair []CardinalityStat `json:"seriesCountByLabelValuePair"`
+}
+
+type CardinalityStat struct {
+	Name  string `json:"name"`
+	Value uint64 `json:"value"`
+}
+
+// CardinalityResult is the body returned by the cardinality resource. Source is either "tsdb" when the statistics
+// come from the TSDB status endpoint or "series" when they were computed by sampling recent series. Truncated is
+// set when more series matched than were sampled.
+type CardinalityResult struct {
+	Source                      string            `json:"source"`
+	Truncated                   bool              `json:"truncated"`
+	SeriesCount                 uint64            `json:"seriesCount"`
+	SeriesCountByMetricName     []CardinalityStat `json:"seriesCountByMetricName"`
+	LabelValueCountByLabelName  []CardinalityStat `json:"labelValueCountByLabelName"`
+	SeriesCountByLabelValuePair []CardinalityStat `json:"seriesCountByLabelValuePair"`
+}
+
+type MetricMetadata struct {
+	Metric string `json:"metric"`
+	Type   string `json:"type"`
+	Help   string `json:"help"`
+	Unit   string `json:"unit"`
+}
+
+type metadataResult struct {
+	Metrics []MetricMetadata `json:"metrics"`
+}
+
+// executeCached serves the backend handled resources, caching successful responses per data source instance.
+func (r *Resource) executeCached(ctx context.Context, req *backend.CallResourceRequest, path string) (*backend.CallResourceResponse, error) {
+	params, err := resourceParams(req)
+	if err != nil {
+		return badRequest(err), nil
+	}
+
+	key := resourceCacheKey(path, params, req.Headers)
+	if cached, ok := r.cache.Get(key); ok {
+		return jsonResponse(cached.([]byte)), nil
+	}
+
+	var result interface{}
+	switch path {
+	case cardinalityPath:
+		limit, err := cardinalityLimit(params)
+		if err != nil {
+			return badRequest(err), nil
+		}
+		result, err = r.cardinality(ctx, limit, params.Get("match[]"), req.Headers)
+		if err != nil {
+			return nil, err
+		}
+	case metadataPath:
+		result, err = r.metadata(ctx, params.Get("metric"), req.Headers)
+		if err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("unknown resource %q", path)
+	}
+
+	body, err := json.Marshal(result)
+	if err != nil {
+		return nil, err
+	}
+	r.cache.Set(key, body, resourceCacheTTL)
+	return jsonResponse(body), nil
+}
+
+// cardinality returns the series count per metric name, the number of values per label name and the series count
+// per label value pair. The TSDB status endpoint is preferred, sampling the series of the last few minutes is the
+// fallback for backends that do not implement it.
+func (r *Resource) cardinality(ctx context.Context, limit int, match string, headers http.Header) (*CardinalityResult, error) {
+	if match == "" {
+		status, err := r.tsdbStatus(ctx, limit, headers)
+		if err == nil {
+			return &CardinalityResult{
+				Source:                      "tsdb",
+				SeriesCount:                 status.HeadStats.NumSeries,
+				SeriesCountByMetricName:     topStats(status.SeriesCountByMetricName, limit),
+				LabelValueCountByLabelName:  topStats(status.LabelValueCountByLabelName, limit),
+				SeriesCountByLabelValuePair: topStats(status.SeriesCountByLabelValuePair, limit),
+			}, nil
+		}
+		r.log.Debug("TSDB status not available, falling back to series sampling", "err", err)
+		match = defaultSeriesMatch
+	}
+
+	end := time.Now()
+	resp, err := r.promClient.QuerySeries(ctx, match, end.Add(-seriesSampleWindow), end, maxSeriesSample+1, headers)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			r.log.Warn("Failed to close response body", "err", err)
+		}
+	}()
+
+	counter := newSeriesCounter()
+	if err := decodeSeries(resp, maxSeriesSample, counter.add); err != nil {
+		return nil, err
+	}
+	return counter.result(limit), nil
+}
+
+func (r *Resource) tsdbStatus(ctx context.Context, limit int, headers http.Header) (*tsdbStatus, error) {
+	var status tsdbStatus
+	if err := r.query(ctx, func() (*http.Response, error) {
+		return r.promClient.QueryTSDBStatus(ctx, limit, headers)
+	}, &status); err != nil {
+		return nil, err
+	}
+	return &status, nil
+}
+
+func (r *Resource) metadata(ctx context.Context, metric string, headers http.Header) (*metadataResult, error) {
+	var metadata map[string][]struct {
+		Type string `json:"type"`
+		Help string `json:"help"`
+		Unit string `json:"unit"`
+	}
+	if err := r.query(ctx, func() (*http.Response, error) {
+		return r.promClient.QueryMetadata(ctx, metric, headers)
+	}, &metadata); err != nil {
+		return nil, err
+	}
+
+	result := &metadataResult{Metrics: make([]MetricMetadata, 0, len(metadata))}
+	for name, entries := range metadata {
+		if len(entries) == 0 {
+			continue
+		}
+		// Prometheus returns one entry per distinct metadata across targets, the first one is good enough here.
+		result.Metrics = append(result.Metrics, MetricMetadata{
+			Metric: name,
+			Type:   entries[0].Type,
+			Help:   entries[0].Help,
+			Unit:   entries[0].Unit,
+		})
+	}
+	sort.Slice(result.Metrics, func(i, j int) bool {
+		return result.Metrics[i].Metric < result.Metrics[j].Metric
+	})
+	return result, nil
+}
+
+// query runs the request and decodes the data of a Prometheus API response into v.
+func (r *Resource) query(ctx context.Context, do func() (*http.Response, error), v interface{}) error {
+	resp, err := do()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			r.log.Warn("Failed to close response body", "err", err)
+		}
+	}()
+
+	var promResp promResponse
+	if err := json.NewDecoder(resp.Body).Decode(&promResp); err != nil {
+		return fmt.Errorf("unexpected response with status %d: %w", resp.StatusCode, err)
+	}
+	if resp.StatusCode/100 != 2 || promResp.Status != "success" {
+		return fmt.Errorf("request failed with status %d: %s", resp.StatusCode, promResp.Error)
+	}
+	return json.Unmarshal(promResp.Data, v)
+}
+
+// decodeSeries streams the label sets of a series API response to fn, reading at most max series. When the
+// response holds more, the remaining series are left unread and fn is called with nil once.
+func decodeSeries(resp *http.Response, max int, fn func(labels map[string]string)) error {
+	if resp.StatusCode/100 != 2 {
+		var promResp promResponse
+		if err := json.NewDecoder(resp.Body).Decode(&promResp); err != nil {
+			return fmt.Errorf("unexpected response with status %d: %w", resp.StatusCode, err)
+		}
+		return fmt.Errorf("request failed with status %d: %s", resp.StatusCode, promResp.Error)
+	}
+
+	dec := json.NewDecoder(resp.Body)
+	if err := expectDelim(dec, '{'); err != nil {
+		return err
+	}
+	var status, errMsg string
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return err
+		}
+		switch tok {
+		case "status":
+			err = dec.Decode(&status)
+		case "error":
+			err = dec.Decode(&errMsg)
+		case "data":
+			if err := expectDelim(dec, '['); err != nil {
+				return err
+			}
+			for read := 0; dec.More(); read++ {
+				if read == max {
+					fn(nil)
+					return nil
+				}
+				var labels map[string]string
+				if err := dec.Decode(&labels); err != nil {
+					return err
+				}
+				fn(labels)
+			}
+			err = expectDelim(dec, ']')
+		default:
+			var skip json.RawMessage
+			err = dec.Decode(&skip)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	if status != "success" {
+		return fmt.Errorf("request failed with status %d: %s", resp.StatusCode, errMsg)
+	}
+	return nil
+}
+
+func expectDelim(dec *json.Decoder, delim json.Delim) error {
+	tok, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	if tok != delim {
+		return fmt.Errorf("unexpected token %v, expected %v", tok, delim)
+	}
+	return nil
+}
+
+// seriesCounter computes cardinality statistics from sampled series without keeping the series themselves.
+type seriesCounter struct {
+	series      uint64
+	truncated   bool
+	byMetric    map[string]uint64
+	byPair      map[string]uint64
+	labelValues map[string]map[string]struct{}
+}
+
+func newSeriesCounter() *seriesCounter {
+	return &seriesCounter{
+		byMetric:    map[string]uint64{},
+		byPair:      map[string]uint64{},
+		labelValues: map[string]map[string]struct{}{},
+	}
+}
+
+// add counts a series, a nil label set marks the sample as truncated.
+func (c *seriesCounter) add(labels map[string]string) {
+	if labels == nil {
+		c.truncated = true
+		return
+	}
+	c.series++
+	c.byMetric[labels["__name__"]]++
+	for name, value := range labels {
+		if name == "__name__" {
+			continue
+		}
+		c.byPair[name+"="+value]++
+		if c.labelValues[name] == nil {
+			c.labelValues[name] = map[string]struct{}{}
+		}
+		c.labelValues[name][value] = struct{}{}
+	}
+}
+
+func (c *seriesCounter) result(limit int) *CardinalityResult {
+	byLabelName := make(map[string]uint64, len(c.labelValues))
+	for name, values := range c.labelValues {
+		byLabelName[name] = uint64(len(values))
+	}
+
+	return &CardinalityResult{
+		Source:                      "series",
+		Truncated:                   c.truncated,
+		SeriesCount:                 c.series,
+		SeriesCountByMetricName:     topStats(toStats(c.byMetric), limit),
+		LabelValueCountByLabelName:  topStats(toStats(byLabelName), limit),
+		SeriesCountByLabelValuePair: topStats(toStats(c.byPair), limit),
+	}
+}
+
+func toStats(counts map[string]uint64) []CardinalityStat {
+	stats := make([]CardinalityStat, 0, len(counts))
+	for name, value := range counts {
+		stats = append(stats, CardinalityStat{Name: name, Value: value})
+	}
+	return stats
+}
+
+// topStats sorts by value descending, then by name, and keeps the first limit entries.
+func topStats(stats []CardinalityStat, limit int) []CardinalityStat {
+	if stats == nil {
+		stats = []CardinalityStat{}
+	}
+	sort.SliceStable(stats, func(i, j int) bool {
+		if stats[i].Value != stats[j].Value {
+			return stats[i].Value > stats[j].Value
+		}
+		return stats[i].Name < stats[j].Name
+	})
+	if len(stats) > limit {
+		stats = stats[:limit]
+	}
+	return stats
+}
+
+func resourceParams(req *backend.CallResourceRequest) (url.Values, error) {
+	u, err := url.Parse(req.URL)
+	if err != nil {
+		return nil, err
+	}
+	return u.Query(), nil
+}
+
+func cardinalityLimit(params url.Values) (int, error) {
+	raw := params.Get("limit")
+	if raw == "" {
+		return defaultCardinalityLimit, nil
+	}
+	limit, err := strconv.Atoi(raw)
+	if err != nil || limit <= 0 {
+		return 0, fmt.Errorf("invalid limit %q", raw)
+	}
+	if limit > maxCardinalityLimit {
+		limit = maxCardinalityLimit
+	}
+	return limit, nil
+}
+
+func resourceCacheKey(path string, params url.Values, headers http.Header) string {
+	hash := sha256.New()
+	for _, name := range cacheKeyHeaders {
+		for _, value := range headers[http.CanonicalHeaderKey(name)] {
+			_, _ = hash.Write([]byte(name + ":" + value + "\n"))
+		}
+	}
+	return strings.Join([]string{path, params.Encode(), hex.EncodeToString(hash.Sum(nil))}, "|")
+}
+
+func jsonResponse(body []byte) *backend.CallResourceResponse {
+	return &backend.CallResourceResponse{
+		Status:  http.StatusOK,
+		Headers: map[string][]string{"Content-Type": {"application/json"}},
+		Body:    body,
+	}
+}
+
+func badRequest(err error) *backend.CallResourceResponse {
+	body, _ := json.Marshal(map[string]string{"message": err.Error()})
+	return &backend.CallResourceResponse{
+		Status:  http.StatusBadRequest,
+		Headers: map[string][]string{"Content-Type": {"application/json"}},
+		Body:    body,
+	}
+}
@@ -0,0 +1,164 @@
+package resource
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestResource(t *testing.T, handler http.HandlerFunc) (*Resource, *int) {
+	t.Helper()
+
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		handler(w, r)
+	}))
+	t.Cleanup(server.Close)
+
+	r, err := New(server.Client(), backend.DataSourceInstanceSettings{URL: server.URL, JSONData: []byte(`{}`)}, log.New("test"))
+	require.NoError(t, err)
+	return r, &calls
+}
+
+func callResource(t *testing.T, r *Resource, url string, headers map[string][]string) *backend.CallResourceResponse {
+	t.Helper()
+
+	path := strings.SplitN(url, "?", 2)[0]
+	resp, err := r.Execute(context.Background(), &backend.CallResourceRequest{
+		Method:  http.MethodGet,
+		Path:    path,
+		URL:     url,
+		Headers: headers,
+	})
+	require.NoError(t, err)
+	return resp
+}
+
+func TestCardinality(t *testing.T) {
+	t.Run("uses the TSDB status endpoint and caches the result", func(t *testing.T) {
+		r, calls := newTestResource(t, func(w http.ResponseWriter, req *http.Request) {
+			require.Equal(t, "/api/v1/status/tsdb", req.URL.Path)
+			require.Equal(t, "2", req.URL.Query().Get("limit"))
+			_, _ = w.Write([]byte(`{"status":"success","data":{"headStats":{"numSeries":42},
+				"seriesCountByMetricName":[{"name":"up","value":10},{"name":"http_requests_total","value":30},{"name":"go_goroutines","value":2}],
+				"labelValueCountByLabelName":[{"name":"instance","value":5}],
+				"seriesCountByLabelValuePair":[{"name":"job=api","value":12}]}}`))
+		})
+
+		resp := callResource(t, r, "cardinality?limit=2", nil)
+		require.Equal(t, http.StatusOK, resp.Status)
+
+		var result CardinalityResult
+		require.NoError(t, json.Unmarshal(resp.Body, &result))
+		require.Equal(t, "tsdb", result.Source)
+		require.Equal(t, uint64(42), result.SeriesCount)
+		require.Equal(t, []CardinalityStat{{Name: "http_requests_total", Value: 30}, {Name: "up", Value: 10}}, result.SeriesCountByMetricName)
+		require.Equal(t, []CardinalityStat{{Name: "instance", Value: 5}}, result.LabelValueCountByLabelName)
+
+		callResource(t, r, "cardinality?limit=2", nil)
+		require.Equal(t, 1, *calls)
+
+		callResource(t, r, "cardinality?limit=2", map[string][]string{"Authorization": {"Bearer other"}})
+		require.Equal(t, 2, *calls)
+	})
+
+	t.Run("falls back to series sampling", func(t *testing.T) {
+		r, _ := newTestResource(t, func(w http.ResponseWriter, req *http.Request) {
+			switch req.URL.Path {
+			case "/api/v1/status/tsdb":
+				w.WriteHeader(http.StatusNotFound)
+				_, _ = w.Write([]byte(`404 page not found`))
+			case "/api/v1/series":
+				require.Equal(t, defaultSeriesMatch, req.URL.Query().Get("match[]"))
+				require.Equal(t, strconv.Itoa(maxSeriesSample+1), req.URL.Query().Get("limit"))
+				_, _ = w.Write([]byte(`{"status":"success","data":[
+					{"__name__":"up","job":"api","instance":"a"},
+					{"__name__":"up","job":"api","instance":"b"},
+					{"__name__":"errors_total","job":"db","instance":"a"}]}`))
+			default:
+				t.Fatalf("unexpected request to %s", req.URL.Path)
+			}
+		})
+
+		resp := callResource(t, r, "/cardinality", nil)
+		require.Equal(t, http.StatusOK, resp.Status)
+
+		var result CardinalityResult
+		require.NoError(t, json.Unmarshal(resp.Body, &result))
+		require.Equal(t, "series", result.Source)
+		require.False(t, result.Truncated)
+		require.Equal(t, uint64(3), result.SeriesCount)
+		require.Equal(t, []CardinalityStat{{Name: "up", Value: 2}, {Name: "errors_total", Value: 1}}, result.SeriesCountByMetricName)
+		require.Equal(t, []CardinalityStat{{Name: "instance", Value: 2}, {Name: "job", Value: 2}}, result.LabelValueCountByLabelName)
+		require.Equal(t, []CardinalityStat{{Name: "instance=a", Value: 2}, {Name: "job=api", Value: 2}}, result.SeriesCountByLabelValuePair[:2])
+	})
+
+	t.Run("rejects an invalid limit", func(t *testing.T) {
+		r, calls := newTestResource(t, func(w http.ResponseWriter, req *http.Request) {})
+		resp := callResource(t, r, "cardinality?limit=abc", nil)
+		require.Equal(t, http.StatusBadRequest, resp.Status)
+		require.Equal(t, 0, *calls)
+	})
+}
+
+func TestDecodeSeries(t *testing.T) {
+	decode := func(status int, body string, max int) (*seriesCounter, error) {
+		counter := newSeriesCounter()
+		err := decodeSeries(&http.Response{StatusCode: status, Body: io.NopCloser(strings.NewReader(body))}, max, counter.add)
+		return counter, err
+	}
+
+	t.Run("stops reading after the maximum number of series", func(t *testing.T) {
+		counter, err := decode(http.StatusOK, `{"status":"success","data":[
+			{"__name__":"up","job":"a"},{"__name__":"up","job":"b"},{"__name__":"up","job":"c"},not json`, 2)
+		require.NoError(t, err)
+		result := counter.result(10)
+		require.True(t, result.Truncated)
+		require.Equal(t, uint64(2), result.SeriesCount)
+	})
+
+	t.Run("reads all series below the maximum", func(t *testing.T) {
+		counter, err := decode(http.StatusOK, `{"status":"success","data":[{"__name__":"up","job":"a"}],"warnings":["w"]}`, 2)
+		require.NoError(t, err)
+		result := counter.result(10)
+		require.False(t, result.Truncated)
+		require.Equal(t, uint64(1), result.SeriesCount)
+	})
+
+	t.Run("returns the error of a failed request", func(t *testing.T) {
+		_, err := decode(http.StatusBadRequest, `{"status":"error","errorType":"bad_data","error":"invalid match"}`, 2)
+		require.ErrorContains(t, err, "invalid match")
+
+		_, err = decode(http.StatusOK, `{"status":"error","error":"too many series"}`, 2)
+		require.ErrorContains(t, err, "too many series")
+	})
+}
+
+func TestMetadata(t *testing.T) {
+	r, _ := newTestResource(t, func(w http.ResponseWriter, req *http.Request) {
+		require.Equal(t, "/api/v1/metadata", req.URL.Path)
+		_, _ = w.Write([]byte(`{"status":"success","data":{
+			"up":[{"type":"gauge","help":"Target is up","unit":""}],
+			"http_request_duration_seconds":[{"type":"histogram","help":"Request latency","unit":"seconds"}]}}`))
+	})
+
+	resp := callResource(t, r, "metadata", nil)
+	require.Equal(t, http.StatusOK, resp.Status)
+
+	var result metadataResult
+	require.NoError(t, json.Unmarshal(resp.Body, &result))
+	require.Equal(t, []MetricMetadata{
+		{Metric: "http_request_duration_seconds", Type: "histogram", Help: "Request latency", Unit: "seconds"},
+		{Metric: "up", Type: "gauge", Help: "Target is up"},
+	}, result.Metrics)
+}
@@ -5,8 +5,10 @@ import (
 	"context"
 	"fmt"
 	"net/http"
+	"strings"
 
 	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana/pkg/infra/localcache"
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/tsdb/prometheus/client"
 	"github.com/grafana/grafana/pkg/tsdb/prometheus/utils"
@@ -16,6 +18,7 @@ import (
 type Resource struct {
 	promClient *client.Client
 	log        log.Logger
+	cache      *localcache.CacheService
 }
 
 // Hop-by-hop headers. These are removed when sent to the backend.
@@ -63,6 +66,7 @@ func New(
 	return &Resource{
 		log:        plog,
 		promClient: client.NewClient(httpClient, httpMethod, settings.URL),
+		cache:      localcache.New(resourceCacheTTL, 2*resourceCacheTTL),
 	}, nil
 }
 
@@ -70,6 +74,11 @@ func (r *Resource) Execute(ctx context.Context, req *backend.CallResourceRequest
 	delHopHeaders(req.Headers)
 	delStopHeaders(req.Headers)
 
+	switch path := strings.Trim(req.Path, "/"); path {
+	case cardinalityPath, metadataPath:
+		return r.executeCached(ctx, req, path)
+	}
+
 	r.log.Debug("Sending resource query", "URL", req.URL)
 	resp, err := r.promClient.QueryResource(ctx, req)
 	if err != nil {
@@ -0,0 +1,132 @@
+package sqleng
+
+import (
+	"strconv"
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	queryDurationHistogram = promauto.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace: "grafana",
+			Name:      "sql_datasource_query_duration_seconds",
+			Help:      "histogram of durations of queries run by SQL data sources",
+			Buckets:   []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10, 25, 50, 100},
+		}, []string{"org_id", "datasource", "datasource_type", "status"},
+	)
+
+	queryStatuses = []string{"ok", "error"}
+
+	poolStats = newPoolStatsCollector()
+)
+
+func init() {
+	prometheus.MustRegister(poolStats)
+}
+
+// poolStatsCollector exposes the connection pool statistics of the SQL data source instances
+// currently in use, labelled by organization, data source UID and driver.
+type poolStatsCollector struct {
+	mu       sync.Mutex
+	handlers map[poolStatsKey]*DataSourceHandler
+
+	maxOpenConnections *prometheus.Desc
+	openConnections    *prometheus.Desc
+	inUse              *prometheus.Desc
+	idle               *prometheus.Desc
+	waitCount          *prometheus.Desc
+	waitDuration       *prometheus.Desc
+}
+
+// poolStatsKey identifies a data source, UIDs are only unique within an organization.
+type poolStatsKey struct {
+	orgID int64
+	uid   string
+}
+
+func newPoolStatsCollector() *poolStatsCollector {
+	labels := []string{"org_id", "datasource", "datasource_type"}
+	desc := func(name string, help string) *prometheus.Desc {
+		return prometheus.NewDesc(prometheus.BuildFQName("grafana", "sql_datasource", name), help, labels, nil)
+	}
+
+	return &poolStatsCollector{
+		handlers:           make(map[poolStatsKey]*DataSourceHandler),
+		maxOpenConnections: desc("max_open_connections", "Maximum number of open connections to the database"),
+		openConnections:    desc("open_connections", "The number of established connections both in use and idle"),
+		inUse:              desc("in_use_connections", "The number of connections currently in use"),
+		idle:               desc("idle_connections", "The number of idle connections"),
+		waitCount:          desc("wait_count_total", "The total number of connections waited for"),
+		waitDuration:       desc("wait_duration_seconds_total", "The total time blocked waiting for a new connection"),
+	}
+}
+
+// register tracks the handler under its organization and data source UID, replacing the instance it
+// was created to replace. Instance settings don't carry the organization, so handlers are registered
+// by the requests they serve.
+func (c *poolStatsCollector) register(handler *DataSourceHandler, orgID int64) {
+	if handler.dsInfo.UID == "" {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if handler.disposed {
+		return
+	}
+	c.handlers[poolStatsKey{orgID: orgID, uid: handler.dsInfo.UID}] = handler
+}
+
+// unregister stops tracking the handler and returns the data sources it was registered for, those
+// which were taken over by a replacing instance are not returned.
+func (c *poolStatsCollector) unregister(handler *DataSourceHandler) []poolStatsKey {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	handler.disposed = true
+	var removed []poolStatsKey
+	for key, h := range c.handlers {
+		if h == handler {
+			delete(c.handlers, key)
+			removed = append(removed, key)
+		}
+	}
+	return removed
+}
+
+// deleteQueryDurations removes the query duration series of a data source.
+func deleteQueryDurations(key poolStatsKey, driverName string) {
+	for _, status := range queryStatuses {
+		queryDurationHistogram.DeleteLabelValues(strconv.FormatInt(key.orgID, 10), key.uid, driverName, status)
+	}
+}
+
+// Describe implements prometheus.Collector.
+func (c *poolStatsCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.maxOpenConnections
+	ch <- c.openConnections
+	ch <- c.inUse
+	ch <- c.idle
+	ch <- c.waitCount
+	ch <- c.waitDuration
+}
+
+// Collect implements prometheus.Collector.
+func (c *poolStatsCollector) Collect(ch chan<- prometheus.Metric) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for key, handler := range c.handlers {
+		stats := handler.engine.DB().Stats()
+		org, uid, driver := strconv.FormatInt(key.orgID, 10), key.uid, handler.driverName
+
+		ch <- prometheus.MustNewConstMetric(c.maxOpenConnections, prometheus.GaugeValue, float64(stats.MaxOpenConnections), org, uid, driver)
+		ch <- prometheus.MustNewConstMetric(c.openConnections, prometheus.GaugeValue, float64(stats.OpenConnections), org, uid, driver)
+		ch <- prometheus.MustNewConstMetric(c.inUse, prometheus.GaugeValue, float64(stats.InUse), org, uid, driver)
+		ch <- prometheus.MustNewConstMetric(c.idle, prometheus.GaugeValue, float64(stats.Idle), org, uid, driver)
+		ch <- prometheus.MustNewConstMetric(c.waitCount, prometheus.CounterValue, float64(stats.WaitCount), org, uid, driver)
+		ch <- prometheus.MustNewConstMetric(c.waitDuration, prometheus.CounterValue, stats.WaitDuration.Seconds(), org, uid, driver)
+	}
+}
@@ -0,0 +1,64 @@
+package sqleng
+
+import (
+	"testing"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+	"xorm.io/xorm"
+)
+
+func TestPoolStatsCollector(t *testing.T) {
+	newHandler := func(uid string) *DataSourceHandler {
+		engine, err := xorm.NewEngine("sqlite3", ":memory:")
+		require.NoError(t, err)
+		t.Cleanup(func() { _ = engine.Close() })
+		return &DataSourceHandler{engine: engine, driverName: "sqlite3", dsInfo: DataSourceInfo{UID: uid}}
+	}
+	collector := newPoolStatsCollector()
+
+	// data sources of different organizations can share a UID
+	a, b := newHandler("db"), newHandler("db")
+	collector.register(a, 1)
+	collector.register(b, 2)
+	require.Equal(t, 2, testutil.CollectAndCount(collector, "grafana_sql_datasource_open_connections"))
+
+	// a new instance of a data source replaces the previous one
+	replacement := newHandler("db")
+	collector.register(replacement, 1)
+	require.Equal(t, 2, testutil.CollectAndCount(collector, "grafana_sql_datasource_open_connections"))
+	collector.unregister(a)
+	require.Equal(t, 2, testutil.CollectAndCount(collector, "grafana_sql_datasource_open_connections"))
+
+	// disposed instances are not registered again by queries still in flight
+	collector.unregister(b)
+	collector.register(b, 2)
+	require.Equal(t, 1, testutil.CollectAndCount(collector, "grafana_sql_datasource_open_connections"))
+}
+
+func TestDisposeDeletesQueryDurations(t *testing.T) {
+	newHandler := func() *DataSourceHandler {
+		engine, err := xorm.NewEngine("sqlite3", ":memory:")
+		require.NoError(t, err)
+		return &DataSourceHandler{engine: engine, driverName: "sqlite3", dsInfo: DataSourceInfo{UID: "dispose-test"}, log: log.New("test")}
+	}
+	count := func() int {
+		return testutil.CollectAndCount(queryDurationHistogram, "grafana_sql_datasource_query_duration_seconds")
+	}
+	before := count()
+
+	old, replacement := newHandler(), newHandler()
+	poolStats.register(old, 7)
+	queryDurationHistogram.WithLabelValues("7", "dispose-test", "sqlite3", "ok").Observe(1)
+	require.Equal(t, before+1, count())
+
+	// the series stay while a replacing instance serves the data source
+	poolStats.register(replacement, 7)
+	old.Dispose()
+	require.Equal(t, before+1, count())
+
+	replacement.Dispose()
+	require.Equal(t, before, count())
+}
@@ -0,0 +1,268 @@
+package sqleng
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// QueryVariable is a template variable sent along with the query to be bound as a parameter
+// instead of being spliced into the SQL text. Multi-value variables bind one parameter per value.
+type QueryVariable struct {
+	Name   string   `json:"name"`
+	Values []string `json:"values"`
+}
+
+// PlaceholderFunc returns the driver placeholder for the bind parameter at the given 1-based position.
+type PlaceholderFunc func(position int) string
+
+// QuestionPlaceholder is the placeholder style of MySQL and SQLite.
+func QuestionPlaceholder(int) string {
+	return "?"
+}
+
+// DollarPlaceholder is the placeholder style of PostgreSQL.
+func DollarPlaceholder(position int) string {
+	return "$" + strconv.Itoa(position)
+}
+
+// AtPPlaceholder is the placeholder style of Microsoft SQL Server.
+func AtPPlaceholder(position int) string {
+	return "@p" + strconv.Itoa(position)
+}
+
+// variableRefRegExp matches the $name, ${name}, ${name:format} and [[name]] template variable syntaxes at the start of the input.
+var variableRefRegExp = regexp.MustCompile(`^(?:\$(\w+)|\$\{(\w+)(?::[^}]*)?\}|\[\[(\w+)(?::[^\]]*)?\]\])`)
+
+func matchVariableRef(s string) (name string, length int) {
+	m := variableRefRegExp.FindStringSubmatch(s)
+	if m == nil {
+		return "", 0
+	}
+	for _, group := range m[1:] {
+		if group != "" {
+			return group, len(m[0])
+		}
+	}
+	return "", 0
+}
+
+// BindSyntax describes how a SQL dialect writes string literals, quoted identifiers and comments, so
+// that template variable references are only bound where a value can appear. The zero value is
+// standard SQL, as spoken by PostgreSQL, SQLite and Microsoft SQL Server.
+type BindSyntax struct {
+	// BackslashEscapes is set when a backslash escapes the next character of a string literal.
+	BackslashEscapes bool
+	// DoubleQuotedStrings is set when double quotes delimit string literals instead of identifiers.
+	DoubleQuotedStrings bool
+	// HashComments is set when # starts a comment running to the end of the line.
+	HashComments bool
+	// DollarQuotedStrings is set when $$...$$ and $tag$...$tag$ delimit string literals.
+	DollarQuotedStrings bool
+}
+
+// MySQLBindSyntax is the syntax of MySQL with its default SQL mode.
+var MySQLBindSyntax = BindSyntax{BackslashEscapes: true, DoubleQuotedStrings: true, HashComments: true}
+
+// PostgresBindSyntax is the syntax of PostgreSQL.
+var PostgresBindSyntax = BindSyntax{DollarQuotedStrings: true}
+
+// bindVariables replaces references to the given variables with driver placeholders and returns the
+// rewritten SQL together with the values to bind. A reference that makes up a whole string literal,
+// like '$host', is replaced including its quotes. References to bound variables inside a longer string
+// literal or a quoted identifier are rejected, since a placeholder cannot be part of either. Comments
+// are left as they are.
+func bindVariables(sql string, variables []QueryVariable, placeholder PlaceholderFunc, syntax BindSyntax) (string, []interface{}, error) {
+	if len(variables) == 0 {
+		return sql, nil, nil
+	}
+
+	values := make(map[string][]string, len(variables))
+	for _, v := range variables {
+		values[v.Name] = v.Values
+	}
+	boundRef := func(s string) (string, bool) {
+		for j := 0; j < len(s); j++ {
+			if name, n := matchVariableRef(s[j:]); n > 0 {
+				if _, ok := values[name]; ok {
+					return name, true
+				}
+			}
+		}
+		return "", false
+	}
+
+	var sb strings.Builder
+	var args []interface{}
+	bind := func(name string) error {
+		vals := values[name]
+		if len(vals) == 0 {
+			return fmt.Errorf("template variable %q has no value", name)
+		}
+		for i, v := range vals {
+			if i > 0 {
+				sb.WriteString(", ")
+			}
+			args = append(args, v)
+			sb.WriteString(placeholder(len(args)))
+		}
+		return nil
+	}
+
+	for i := 0; i < len(sql); {
+		c := sql[i]
+		switch {
+		case c == '\'' || (c == '"' && syntax.DoubleQuotedStrings):
+			end := quotedEnd(sql, i, syntax.BackslashEscapes)
+			literal := sql[i:end]
+			content := strings.TrimSuffix(literal[1:], string(c))
+			if name, n := matchVariableRef(content); n > 0 && n == len(content) {
+				if _, ok := values[name]; ok {
+					if err := bind(name); err != nil {
+						return "", nil, err
+					}
+					i = end
+					continue
+				}
+			}
+			if name, ok := boundRef(content); ok {
+				return "", nil, fmt.Errorf("template variable %q is part of a string literal and cannot be bound, use string concatenation instead", name)
+			}
+			sb.WriteString(literal)
+			i = end
+		case syntax.DollarQuotedStrings && dollarQuoteEnd(sql, i) > i:
+			end := dollarQuoteEnd(sql, i)
+			if name, ok := boundRef(sql[i:end]); ok {
+				return "", nil, fmt.Errorf("template variable %q is part of a dollar-quoted string and cannot be bound, use string concatenation instead", name)
+			}
+			sb.WriteString(sql[i:end])
+			i = end
+		case c == '"' || c == '`':
+			end := quotedEnd(sql, i, false)
+			if name, ok := boundRef(sql[i+1 : end]); ok {
+				return "", nil, fmt.Errorf("template variable %q is part of a quoted identifier and cannot be bound, use ${%s:raw} instead", name, name)
+			}
+			sb.WriteString(sql[i:end])
+			i = end
+		case commentEnd(sql, i, syntax) > i:
+			end := commentEnd(sql, i, syntax)
+			sb.WriteString(sql[i:end])
+			i = end
+		case c == '$' || c == '[':
+			if name, n := matchVariableRef(sql[i:]); n > 0 {
+				if _, ok := values[name]; ok {
+					if err := bind(name); err != nil {
+						return "", nil, err
+					}
+					i += n
+					continue
+				}
+			}
+			sb.WriteByte(c)
+			i++
+		default:
+			sb.WriteByte(c)
+			i++
+		}
+	}
+
+	return sb.String(), args, nil
+}
+
+// quotedEnd returns the index just past the string literal or quoted identifier starting at start,
+// taking doubled quotes and, if backslashEscapes is set, backslash escapes into account. An
+// unterminated literal ends with the input.
+func quotedEnd(sql string, start int, backslashEscapes bool) int {
+	quote := sql[start]
+	for i := start + 1; i < len(sql); i++ {
+		if backslashEscapes && sql[i] == '\\' {
+			i++
+			continue
+		}
+		if sql[i] != quote {
+			continue
+		}
+		if i+1 < len(sql) && sql[i+1] == quote {
+			i++
+			continue
+		}
+		return i + 1
+	}
+	return len(sql)
+}
+
+// dollarTagRegExp matches the opening delimiter of a PostgreSQL dollar-quoted string, $$ or $tag$.
+var dollarTagRegExp = regexp.MustCompile(`^\$(?:[A-Za-z_\x80-\xff][A-Za-z0-9_\x80-\xff]*)?\$`)
+
+// dollarQuoteEnd returns the index just past the dollar-quoted string starting at start, or start if
+// there is none. A dollar sign that is part of an identifier doesn't start one. An unterminated
+// string ends with the input.
+func dollarQuoteEnd(sql string, start int) int {
+	if start > 0 && isIdentifierChar(sql[start-1]) {
+		return start
+	}
+	tag := dollarTagRegExp.FindString(sql[start:])
+	if tag == "" {
+		return start
+	}
+	if n := strings.Index(sql[start+len(tag):], tag); n >= 0 {
+		return start + len(tag) + n + len(tag)
+	}
+	return len(sql)
+}
+
+func isIdentifierChar(c byte) bool {
+	return c == '_' || c == '$' || c >= 0x80 || ('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z') || ('0' <= c && c <= '9')
+}
+
+// commentEnd returns the index just past the comment starting at start, or start if there is no
+// comment. An unterminated comment ends with the input.
+func commentEnd(sql string, start int, syntax BindSyntax) int {
+	switch {
+	case strings.HasPrefix(sql[start:], "--") || (sql[start] == '#' && syntax.HashComments):
+		if n := strings.IndexByte(sql[start:], '\n'); n >= 0 {
+			return start + n + 1
+		}
+		return len(sql)
+	case strings.HasPrefix(sql[start:], "/*"):
+		if n := strings.Index(sql[start+2:], "*/"); n >= 0 {
+			return start + 2 + n + 2
+		}
+		return len(sql)
+	default:
+		return start
+	}
+}
+
+// hasMultipleStatements reports whether sql holds more than one statement, that is a semicolon outside
+// of string literals, quoted identifiers and comments followed by anything other than whitespace or
+// comments.
+func hasMultipleStatements(sql string, syntax BindSyntax) bool {
+	terminated := false
+	for i := 0; i < len(sql); {
+		c := sql[i]
+		switch {
+		case commentEnd(sql, i, syntax) > i:
+			i = commentEnd(sql, i, syntax)
+			continue
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+			continue
+		case terminated:
+			return true
+		case c == ';':
+			terminated = true
+			i++
+		case c == '\'' || (c == '"' && syntax.DoubleQuotedStrings):
+			i = quotedEnd(sql, i, syntax.BackslashEscapes)
+		case syntax.DollarQuotedStrings && dollarQuoteEnd(sql, i) > i:
+			i = dollarQuoteEnd(sql, i)
+		case c == '"' || c == '`':
+			i = quotedEnd(sql, i, false)
+		default:
+			i++
+		}
+	}
+	return false
+}
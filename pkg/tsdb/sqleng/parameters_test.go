@@ -0,0 +1,117 @@
+package sqleng
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBindVariables(t *testing.T) {
+	variables := []QueryVariable{
+		{Name: "host", Values: []string{"web-1"}},
+		{Name: "region", Values: []string{"eu", "us"}},
+		{Name: "empty"},
+	}
+
+	t.Run("without variables the query is unchanged", func(t *testing.T) {
+		sql, args, err := bindVariables("SELECT '$host' FROM t", nil, QuestionPlaceholder, BindSyntax{})
+		require.NoError(t, err)
+		require.Equal(t, "SELECT '$host' FROM t", sql)
+		require.Empty(t, args)
+	})
+
+	t.Run("binds all variable syntaxes in order", func(t *testing.T) {
+		sql, args, err := bindVariables("SELECT * FROM t WHERE host = $host AND region IN (${region:csv}) AND h2 = [[host]]", variables, QuestionPlaceholder, BindSyntax{})
+		require.NoError(t, err)
+		require.Equal(t, "SELECT * FROM t WHERE host = ? AND region IN (?, ?) AND h2 = ?", sql)
+		require.Equal(t, []interface{}{"web-1", "eu", "us", "web-1"}, args)
+	})
+
+	t.Run("replaces quoted references including the quotes", func(t *testing.T) {
+		sql, args, err := bindVariables("SELECT * FROM t WHERE host = '$host' AND note = 'it''s'", variables, DollarPlaceholder, BindSyntax{})
+		require.NoError(t, err)
+		require.Equal(t, "SELECT * FROM t WHERE host = $1 AND note = 'it''s'", sql)
+		require.Equal(t, []interface{}{"web-1"}, args)
+	})
+
+	t.Run("numbers placeholders per dialect", func(t *testing.T) {
+		sql, _, err := bindVariables("WHERE region IN ($region) AND host = $host", variables, AtPPlaceholder, BindSyntax{})
+		require.NoError(t, err)
+		require.Equal(t, "WHERE region IN (@p1, @p2) AND host = @p3", sql)
+	})
+
+	t.Run("leaves unknown references and macros alone", func(t *testing.T) {
+		sql, args, err := bindVariables("SELECT $other, $__interval, '$other-x' FROM t WHERE a[1] = $host", variables, QuestionPlaceholder, BindSyntax{})
+		require.NoError(t, err)
+		require.Equal(t, "SELECT $other, $__interval, '$other-x' FROM t WHERE a[1] = ?", sql)
+		require.Equal(t, []interface{}{"web-1"}, args)
+	})
+
+	t.Run("rejects references inside longer string literals", func(t *testing.T) {
+		_, _, err := bindVariables("SELECT * FROM t WHERE host LIKE '$host%'", variables, QuestionPlaceholder, BindSyntax{})
+		require.Error(t, err)
+	})
+
+	t.Run("rejects variables without values", func(t *testing.T) {
+		_, _, err := bindVariables("SELECT * FROM t WHERE x = $empty", variables, QuestionPlaceholder, BindSyntax{})
+		require.Error(t, err)
+	})
+
+	t.Run("leaves references in comments alone", func(t *testing.T) {
+		sql, args, err := bindVariables("SELECT 1 -- $host\nFROM t /* '$host */ WHERE x = $host", variables, QuestionPlaceholder, BindSyntax{})
+		require.NoError(t, err)
+		require.Equal(t, "SELECT 1 -- $host\nFROM t /* '$host */ WHERE x = ?", sql)
+		require.Equal(t, []interface{}{"web-1"}, args)
+
+		sql, _, err = bindVariables("SELECT 1 # it's $host\nFROM t WHERE x = $host", variables, QuestionPlaceholder, MySQLBindSyntax)
+		require.NoError(t, err)
+		require.Equal(t, "SELECT 1 # it's $host\nFROM t WHERE x = ?", sql)
+	})
+
+	t.Run("rejects references inside quoted identifiers", func(t *testing.T) {
+		_, _, err := bindVariables(`SELECT * FROM "$host"`, variables, DollarPlaceholder, BindSyntax{})
+		require.Error(t, err)
+		_, _, err = bindVariables("SELECT * FROM `$host`", variables, QuestionPlaceholder, MySQLBindSyntax)
+		require.Error(t, err)
+	})
+
+	t.Run("handles MySQL backslash escapes and double quoted strings", func(t *testing.T) {
+		sql, args, err := bindVariables(`SELECT * FROM t WHERE a = 'it\'s' AND b = $host AND c = "$host" AND d = "\""`, variables, QuestionPlaceholder, MySQLBindSyntax)
+		require.NoError(t, err)
+		require.Equal(t, `SELECT * FROM t WHERE a = 'it\'s' AND b = ? AND c = ? AND d = "\""`, sql)
+		require.Equal(t, []interface{}{"web-1", "web-1"}, args)
+
+		_, _, err = bindVariables(`SELECT * FROM t WHERE a = 'x\' $host'`, variables, QuestionPlaceholder, MySQLBindSyntax)
+		require.Error(t, err)
+	})
+
+	t.Run("backslashes don't escape quotes in standard SQL", func(t *testing.T) {
+		sql, args, err := bindVariables(`SELECT * FROM t WHERE path = 'C:\' AND host = $host`, variables, QuestionPlaceholder, BindSyntax{})
+		require.NoError(t, err)
+		require.Equal(t, `SELECT * FROM t WHERE path = 'C:\' AND host = ?`, sql)
+		require.Equal(t, []interface{}{"web-1"}, args)
+	})
+
+	t.Run("leaves PostgreSQL dollar-quoted strings alone", func(t *testing.T) {
+		sql, args, err := bindVariables("SELECT $$it's $1$$, $fn$ 'x' $fn$, a$b$c FROM t WHERE host = $host", variables, DollarPlaceholder, PostgresBindSyntax)
+		require.NoError(t, err)
+		require.Equal(t, "SELECT $$it's $1$$, $fn$ 'x' $fn$, a$b$c FROM t WHERE host = $1", sql)
+		require.Equal(t, []interface{}{"web-1"}, args)
+
+		_, _, err = bindVariables("SELECT $q$ $host $q$", variables, DollarPlaceholder, PostgresBindSyntax)
+		require.Error(t, err)
+	})
+}
+
+func TestHasMultipleStatements(t *testing.T) {
+	require.False(t, hasMultipleStatements("SELECT 1", BindSyntax{}))
+	require.False(t, hasMultipleStatements("SELECT 1; -- done\n /* really */ ", BindSyntax{}))
+	require.False(t, hasMultipleStatements(`SELECT ';', "a;b" FROM t /* ; DROP TABLE t */`, BindSyntax{}))
+	require.False(t, hasMultipleStatements(`SELECT 'it\'s; fine' # ; DROP TABLE t`, MySQLBindSyntax))
+	require.True(t, hasMultipleStatements("SELECT 1; DROP TABLE t", BindSyntax{}))
+	require.True(t, hasMultipleStatements("SELECT 1;/**/DROP TABLE t", BindSyntax{}))
+	require.True(t, hasMultipleStatements(`SELECT 'it\'; DROP TABLE t; --'`, BindSyntax{}))
+	require.True(t, hasMultipleStatements("SELECT 1 # comment only in MySQL\n; DROP TABLE t", MySQLBindSyntax))
+	require.False(t, hasMultipleStatements("SELECT $body$ a; b $body$", PostgresBindSyntax))
+	require.True(t, hasMultipleStatements("SELECT a$b$; DROP TABLE t; SELECT $b$", PostgresBindSyntax))
+}
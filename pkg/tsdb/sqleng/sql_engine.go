@@ -40,6 +40,20 @@ type SqlQueryResultTransformer interface {
 	GetConverterList() []sqlutil.StringConverter
 }
 
+// SqlQueryCostEstimator can be implemented by a SqlQueryResultTransformer for databases that estimate the
+// cost of a query with EXPLAIN. Queries estimated above the maxQueryCost data source setting are rejected.
+type SqlQueryCostEstimator interface {
+	EstimateQueryCost(ctx context.Context, db *sql.DB, query string, args []interface{}) (float64, error)
+}
+
+// SqlFrameTransformer can be implemented by a SqlQueryResultTransformer whose driver only knows the
+// column types once rows have been read. Its converters are used instead of GetConverterList, and
+// TransformFrame is called with the frame built from the rows before time and value columns are handled.
+type SqlFrameTransformer interface {
+	GetConverters() []sqlutil.Converter
+	TransformFrame(frame *data.Frame, columnTypes []*sql.ColumnType) error
+}
+
 var sqlIntervalCalculator = intervalv2.NewCalculator()
 
 // NewXormEngine is an xorm.Engine factory, that can be stubbed by tests.
@@ -49,20 +63,24 @@ var NewXormEngine = func(driverName string, connectionString string) (*xorm.Engi
 }
 
 type JsonData struct {
-	MaxOpenConns        int    `json:"maxOpenConns"`
-	MaxIdleConns        int    `json:"maxIdleConns"`
-	ConnMaxLifetime     int    `json:"connMaxLifetime"`
-	Timescaledb         bool   `json:"timescaledb"`
-	Mode                string `json:"sslmode"`
-	ConfigurationMethod string `json:"tlsConfigurationMethod"`
-	TlsSkipVerify       bool   `json:"tlsSkipVerify"`
-	RootCertFile        string `json:"sslRootCertFile"`
-	CertFile            string `json:"sslCertFile"`
-	CertKeyFile         string `json:"sslKeyFile"`
-	Timezone            string `json:"timezone"`
-	Encrypt             string `json:"encrypt"`
-	Servername          string `json:"servername"`
-	TimeInterval        string `json:"timeInterval"`
+	MaxOpenConns        int     `json:"maxOpenConns"`
+	MaxIdleConns        int     `json:"maxIdleConns"`
+	ConnMaxLifetime     int     `json:"connMaxLifetime"`
+	Timescaledb         bool    `json:"timescaledb"`
+	Mode                string  `json:"sslmode"`
+	ConfigurationMethod string  `json:"tlsConfigurationMethod"`
+	TlsSkipVerify       bool    `json:"tlsSkipVerify"`
+	RootCertFile        string  `json:"sslRootCertFile"`
+	CertFile            string  `json:"sslCertFile"`
+	CertKeyFile         string  `json:"sslKeyFile"`
+	Timezone            string  `json:"timezone"`
+	Encrypt             string  `json:"encrypt"`
+	Servername          string  `json:"servername"`
+	TimeInterval        string  `json:"timeInterval"`
+	QueryTimeout        int     `json:"queryTimeout"`
+	MultiStatements     bool    `json:"multiStatements"`
+	MaxRows             int64   `json:"maxRows"`
+	MaxQueryCost        float64 `json:"maxQueryCost"`
 }
 
 type DataSourceInfo struct {
@@ -83,24 +101,36 @@ type DataPluginConfiguration struct {
 	TimeColumnNames   []string
 	MetricColumnTypes []string
 	RowLimit          int64
+	// Placeholder is the driver placeholder style used to bind template variables, defaults to QuestionPlaceholder
+	Placeholder PlaceholderFunc
+	// BindSyntax is the SQL syntax template variables are bound in, defaults to standard SQL
+	BindSyntax BindSyntax
 }
 type DataSourceHandler struct {
 	macroEngine            SQLMacroEngine
 	queryResultTransformer SqlQueryResultTransformer
 	engine                 *xorm.Engine
+	driverName             string
 	timeColumnNames        []string
 	metricColumnTypes      []string
 	log                    log.Logger
 	dsInfo                 DataSourceInfo
 	rowLimit               int64
+	placeholder            PlaceholderFunc
+	bindSyntax             BindSyntax
+	queryTimeout           time.Duration
+	maxQueryCost           float64
+	// disposed is set once the handler is disposed, guarded by the pool stats collector
+	disposed bool
 }
 type QueryJson struct {
-	RawSql       string  `json:"rawSql"`
-	Fill         bool    `json:"fill"`
-	FillInterval float64 `json:"fillInterval"`
-	FillMode     string  `json:"fillMode"`
-	FillValue    float64 `json:"fillValue"`
-	Format       string  `json:"format"`
+	RawSql       string          `json:"rawSql"`
+	Fill         bool            `json:"fill"`
+	FillInterval float64         `json:"fillInterval"`
+	FillMode     string          `json:"fillMode"`
+	FillValue    float64         `json:"fillValue"`
+	Format       string          `json:"format"`
+	Variables    []QueryVariable `json:"variables"`
 }
 
 func (e *DataSourceHandler) transformQueryError(err error) error {
@@ -117,6 +147,15 @@ func (e *DataSourceHandler) transformQueryError(err error) error {
 	return e.queryResultTransformer.TransformQueryError(err)
 }
 
+// queryError reports queries cancelled because they ran longer than the queryTimeout setting
+// as such, other errors are transformed by transformQueryError.
+func (e *DataSourceHandler) queryError(queryContext context.Context, err error) error {
+	if e.queryTimeout > 0 && errors.Is(queryContext.Err(), context.DeadlineExceeded) {
+		return fmt.Errorf("query exceeded the maximum duration of %s", e.queryTimeout)
+	}
+	return e.transformQueryError(err)
+}
+
 func NewQueryDataHandler(config DataPluginConfiguration, queryResultTransformer SqlQueryResultTransformer,
 	macroEngine SQLMacroEngine, log log.Logger) (*DataSourceHandler, error) {
 	log.Debug("Creating engine...")
@@ -131,6 +170,19 @@ func NewQueryDataHandler(config DataPluginConfiguration, queryResultTransformer
 		log:                    log,
 		dsInfo:                 config.DSInfo,
 		rowLimit:               config.RowLimit,
+		placeholder:            QuestionPlaceholder,
+		bindSyntax:             config.BindSyntax,
+		queryTimeout:           time.Duration(config.DSInfo.JsonData.QueryTimeout) * time.Second,
+		maxQueryCost:           config.DSInfo.JsonData.MaxQueryCost,
+	}
+
+	// the data source can only lower the row limit configured for Grafana
+	if maxRows := config.DSInfo.JsonData.MaxRows; maxRows > 0 && (queryDataHandler.rowLimit < 0 || maxRows < queryDataHandler.rowLimit) {
+		queryDataHandler.rowLimit = maxRows
+	}
+
+	if config.Placeholder != nil {
+		queryDataHandler.placeholder = config.Placeholder
 	}
 
 	if len(config.TimeColumnNames) > 0 {
@@ -151,6 +203,7 @@ func NewQueryDataHandler(config DataPluginConfiguration, queryResultTransformer
 	engine.SetConnMaxLifetime(time.Duration(config.DSInfo.JsonData.ConnMaxLifetime) * time.Second)
 
 	queryDataHandler.engine = engine
+	queryDataHandler.driverName = config.DriverName
 	return &queryDataHandler, nil
 }
 
@@ -162,6 +215,9 @@ type DBDataResponse struct {
 func (e *DataSourceHandler) Dispose() {
 	e.log.Debug("Disposing engine...")
 	if e.engine != nil {
+		for _, key := range poolStats.unregister(e) {
+			deleteQueryDurations(key, e.driverName)
+		}
 		if err := e.engine.Close(); err != nil {
 			e.log.Error("Failed to dispose engine", "error", err)
 		}
@@ -169,7 +225,44 @@ func (e *DataSourceHandler) Dispose() {
 	e.log.Debug("Engine disposed")
 }
 
+// CheckHealth pings the database and reports the connection pool statistics of the data source in the result details.
+func (e *DataSourceHandler) CheckHealth(ctx context.Context, req *backend.CheckHealthRequest) (*backend.CheckHealthResult, error) {
+	poolStats.register(e, req.PluginContext.OrgID)
+
+	stats := e.engine.DB().Stats()
+	details, err := json.Marshal(map[string]interface{}{
+		"pool": map[string]interface{}{
+			"maxOpenConnections": stats.MaxOpenConnections,
+			"openConnections":    stats.OpenConnections,
+			"inUse":              stats.InUse,
+			"idle":               stats.Idle,
+			"waitCount":          stats.WaitCount,
+			"waitDurationMs":     stats.WaitDuration.Milliseconds(),
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := e.engine.DB().PingContext(ctx); err != nil {
+		e.log.Warn("Health check failed", "err", err)
+		return &backend.CheckHealthResult{
+			Status:      backend.HealthStatusError,
+			Message:     e.transformQueryError(err).Error(),
+			JSONDetails: details,
+		}, nil
+	}
+
+	return &backend.CheckHealthResult{
+		Status:      backend.HealthStatusOk,
+		Message:     "Database Connection OK",
+		JSONDetails: details,
+	}, nil
+}
+
 func (e *DataSourceHandler) QueryData(ctx context.Context, req *backend.QueryDataRequest) (*backend.QueryDataResponse, error) {
+	poolStats.register(e, req.PluginContext.OrgID)
+
 	result := backend.NewQueryDataResponse()
 	ch := make(chan DBDataResponse, len(req.Queries))
 	var wg sync.WaitGroup
@@ -188,7 +281,7 @@ func (e *DataSourceHandler) QueryData(ctx context.Context, req *backend.QueryDat
 		}
 
 		wg.Add(1)
-		go e.executeQuery(query, &wg, ctx, ch, queryjson)
+		go e.executeQuery(query, &wg, ctx, ch, queryjson, req.PluginContext.OrgID)
 	}
 
 	wg.Wait()
@@ -204,7 +297,7 @@ func (e *DataSourceHandler) QueryData(ctx context.Context, req *backend.QueryDat
 }
 
 func (e *DataSourceHandler) executeQuery(query backend.DataQuery, wg *sync.WaitGroup, queryContext context.Context,
-	ch chan DBDataResponse, queryJson QueryJson) {
+	ch chan DBDataResponse, queryJson QueryJson, orgID int64) {
 	defer wg.Done()
 	queryResult := DBDataResponse{
 		dataResponse: backend.DataResponse{},
@@ -255,13 +348,50 @@ func (e *DataSourceHandler) executeQuery(query backend.DataQuery, wg *sync.WaitG
 		return
 	}
 
+	// template variables sent along with the query are bound as parameters
+	interpolatedQuery, args, err := bindVariables(interpolatedQuery, queryJson.Variables, e.placeholder, e.bindSyntax)
+	if err != nil {
+		errAppendDebug("binding variables failed", err, interpolatedQuery)
+		return
+	}
+
+	if e.queryTimeout > 0 {
+		var cancel context.CancelFunc
+		queryContext, cancel = context.WithTimeout(queryContext, e.queryTimeout)
+		defer cancel()
+	}
+
 	session := e.engine.NewSession()
 	defer session.Close()
 	db := session.DB()
 
-	rows, err := db.QueryContext(queryContext, interpolatedQuery)
+	if estimator, ok := e.queryResultTransformer.(SqlQueryCostEstimator); ok && e.maxQueryCost > 0 {
+		// the statement is prefixed with EXPLAIN, any following statement would run unguarded
+		if hasMultipleStatements(interpolatedQuery, e.bindSyntax) {
+			errAppendDebug("query rejected", errors.New("queries with several statements cannot be cost estimated"), interpolatedQuery)
+			return
+		}
+		// statements that cannot be explained, like stored procedure calls, are rejected too
+		cost, err := estimator.EstimateQueryCost(queryContext, db.DB, interpolatedQuery, args)
+		if err != nil {
+			errAppendDebug("query rejected", fmt.Errorf("failed to estimate query cost: %w", e.queryError(queryContext, err)), interpolatedQuery)
+			return
+		}
+		if cost > e.maxQueryCost {
+			errAppendDebug("query rejected", fmt.Errorf("estimated query cost %.0f exceeds the maximum of %.0f", cost, e.maxQueryCost), interpolatedQuery)
+			return
+		}
+	}
+
+	start := time.Now()
+	status := "error"
+	defer func() {
+		queryDurationHistogram.WithLabelValues(strconv.FormatInt(orgID, 10), e.dsInfo.UID, e.driverName, status).Observe(time.Since(start).Seconds())
+	}()
+
+	rows, err := db.QueryContext(queryContext, interpolatedQuery, args...)
 	if err != nil {
-		errAppendDebug("db query error", e.transformQueryError(err), interpolatedQuery)
+		errAppendDebug("db query error", e.queryError(queryContext, err), interpolatedQuery)
 		return
 	}
 	defer func() {
@@ -270,18 +400,59 @@ func (e *DataSourceHandler) executeQuery(query backend.DataQuery, wg *sync.WaitG
 		}
 	}()
 
+	// Statements and stored procedures can return several result sets, each one becomes a frame
+	var frames data.Frames
+	for {
+		frame, ok := e.resultSetToFrame(query, queryContext, rows, interpolatedQuery, errAppendDebug)
+		if !ok {
+			return
+		}
+		frames = append(frames, frame)
+
+		if !rows.NextResultSet() {
+			break
+		}
+	}
+	if err := rows.Err(); err != nil {
+		errAppendDebug("db query error", e.queryError(queryContext, err), interpolatedQuery)
+		return
+	}
+
+	status = "ok"
+	queryResult.dataResponse.Frames = frames
+	ch <- queryResult
+}
+
+// resultSetToFrame converts the current result set of rows to a frame. On failure the error is
+// reported through errAppendDebug and false is returned.
+func (e *DataSourceHandler) resultSetToFrame(query backend.DataQuery, queryContext context.Context, rows *core.Rows,
+	interpolatedQuery string, errAppendDebug func(frameErr string, err error, query string)) (*data.Frame, bool) {
 	qm, err := e.newProcessCfg(query, queryContext, rows, interpolatedQuery)
 	if err != nil {
 		errAppendDebug("failed to get configurations", err, interpolatedQuery)
-		return
+		return nil, false
 	}
 
 	// Convert row.Rows to dataframe
-	stringConverters := e.queryResultTransformer.GetConverterList()
-	frame, err := sqlutil.FrameFromRows(rows.Rows, e.rowLimit, sqlutil.ToConverters(stringConverters...)...)
+	frameTransformer, isFrameTransformer := e.queryResultTransformer.(SqlFrameTransformer)
+	converters := sqlutil.ToConverters(e.queryResultTransformer.GetConverterList()...)
+	if isFrameTransformer {
+		converters = frameTransformer.GetConverters()
+	}
+	frame, err := sqlutil.FrameFromRows(rows.Rows, e.rowLimit, converters...)
 	if err != nil {
+		if queryContext.Err() != nil {
+			err = e.queryError(queryContext, err)
+		}
 		errAppendDebug("convert frame from rows error", err, interpolatedQuery)
-		return
+		return nil, false
+	}
+
+	if isFrameTransformer {
+		if err := frameTransformer.TransformFrame(frame, qm.columnTypes); err != nil {
+			errAppendDebug("transform frame error", err, interpolatedQuery)
+			return nil, false
+		}
 	}
 
 	if frame.Meta == nil {
@@ -292,21 +463,19 @@ func (e *DataSourceHandler) executeQuery(query backend.DataQuery, wg *sync.WaitG
 
 	// If no rows were returned, no point checking anything else.
 	if frame.Rows() == 0 {
-		queryResult.dataResponse.Frames = data.Frames{frame}
-		ch <- queryResult
-		return
+		return frame, true
 	}
 
 	if err := convertSQLTimeColumnsToEpochMS(frame, qm); err != nil {
 		errAppendDebug("converting time columns failed", err, interpolatedQuery)
-		return
+		return nil, false
 	}
 
 	if qm.Format == dataQueryFormatSeries {
 		// time series has to have time column
 		if qm.timeIndex == -1 {
 			errAppendDebug("db has no time column", errors.New("no time column found"), interpolatedQuery)
-			return
+			return nil, false
 		}
 
 		// Make sure to name the time field 'Time' to be backward compatible with Grafana pre-v8.
@@ -324,7 +493,7 @@ func (e *DataSourceHandler) executeQuery(query backend.DataQuery, wg *sync.WaitG
 			var err error
 			if frame, err = convertSQLValueColumnToFloat(frame, i); err != nil {
 				errAppendDebug("convert value to float failed", err, interpolatedQuery)
-				return
+				return nil, false
 			}
 		}
 
@@ -335,7 +504,7 @@ func (e *DataSourceHandler) executeQuery(query backend.DataQuery, wg *sync.WaitG
 			frame, err = data.LongToWide(frame, qm.FillMissing)
 			if err != nil {
 				errAppendDebug("failed to convert long to wide series when converting from dataframe", err, interpolatedQuery)
-				return
+				return nil, false
 			}
 
 			// Before 8x, a special metric column was used to name time series. The LongToWide transforms that into a metric label on the value field.
@@ -363,8 +532,7 @@ func (e *DataSourceHandler) executeQuery(query backend.DataQuery, wg *sync.WaitG
 		}
 	}
 
-	queryResult.dataResponse.Frames = data.Frames{frame}
-	ch <- queryResult
+	return frame, true
 }
 
 // Interpolate provides global macros/substitutions for all sql datasources.
@@ -0,0 +1,458 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/datasource"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/instancemgmt"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/grafana/grafana-plugin-sdk-go/data/sqlutil"
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/events"
+	"github.com/grafana/grafana/pkg/infra/filestorage"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/store"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/tsdb/sqleng"
+	"github.com/mattn/go-sqlite3"
+	"xorm.io/core"
+)
+
+// driverName is the SQLite driver with connections restricted to the data source database
+const driverName = "sqlite3_datasource"
+
+func init() {
+	sql.Register(driverName, &sqlite3.SQLiteDriver{ConnectHook: restrictConnection})
+	core.RegisterDriver(driverName, core.QueryDriver("sqlite3"))
+}
+
+// restrictConnection prevents queries from reaching files other than the data source database,
+// including through statements hidden from the macro engine checks by comments.
+func restrictConnection(conn *sqlite3.SQLiteConn) error {
+	conn.SetLimit(sqlite3.SQLITE_LIMIT_ATTACHED, 0)
+	conn.RegisterAuthorizer(func(op int, _ string, arg2 string, _ string) int {
+		switch {
+		case op == sqlite3.SQLITE_ATTACH, op == sqlite3.SQLITE_DETACH:
+			return sqlite3.SQLITE_DENY
+		case op == sqlite3.SQLITE_FUNCTION && strings.EqualFold(arg2, "load_extension"):
+			return sqlite3.SQLITE_DENY
+		}
+		return sqlite3.SQLITE_OK
+	})
+	return nil
+}
+
+// storagePathPrefix marks a data source path that points to a file in the storage service
+const storagePathPrefix = "storage://"
+
+var logger = log.New("tsdb.sqlite")
+
+var errPathNotAllowed = errors.New("database file is outside of the directories allowed for SQLite data sources")
+
+// fileReader is the part of the storage service used to fetch database files
+type fileReader interface {
+	Read(ctx context.Context, user *models.SignedInUser, path string) (*filestorage.File, error)
+}
+
+type Service struct {
+	im  instancemgmt.InstanceManager
+	cfg *setting.Cfg
+}
+
+func ProvideService(cfg *setting.Cfg, storage store.StorageService, bus bus.Bus) *Service {
+	s := &Service{
+		im:  datasource.NewInstanceManager(newInstanceSettings(cfg, storage)),
+		cfg: cfg,
+	}
+	bus.AddEventListener(s.handleDataSourceDeleted)
+	return s
+}
+
+// handleDataSourceDeleted removes the cached copy of the database file of a deleted data source.
+func (s *Service) handleDataSourceDeleted(_ context.Context, event *events.DataSourceDeleted) error {
+	path, err := storageCachePath(s.cfg, event.OrgID, event.UID)
+	if err != nil {
+		return nil
+	}
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		logger.Warn("Failed to remove cached database file", "path", path, "error", err)
+	}
+	return nil
+}
+
+func newInstanceSettings(cfg *setting.Cfg, files fileReader) datasource.InstanceFactoryFunc {
+	return func(settings backend.DataSourceInstanceSettings) (instancemgmt.Instance, error) {
+		jsonData := sqleng.JsonData{
+			MaxOpenConns:    0,
+			MaxIdleConns:    2,
+			ConnMaxLifetime: 14400,
+		}
+
+		err := json.Unmarshal(settings.JSONData, &jsonData)
+		if err != nil {
+			return nil, fmt.Errorf("error reading settings: %w", err)
+		}
+		dsInfo := sqleng.DataSourceInfo{
+			JsonData:                jsonData,
+			URL:                     settings.URL,
+			User:                    settings.User,
+			Database:                settings.Database,
+			ID:                      settings.ID,
+			Updated:                 settings.Updated,
+			UID:                     settings.UID,
+			DecryptedSecureJSONData: settings.DecryptedSecureJSONData,
+		}
+
+		return &dataSourceInstance{
+			create: func(orgID int64) (*sqleng.DataSourceHandler, string, error) {
+				var dbPath, cacheFile string
+				var err error
+				if strings.HasPrefix(dsInfo.Database, storagePathPrefix) {
+					dbPath, err = fetchStorageFile(cfg, files, orgID, dsInfo.UID, strings.TrimPrefix(dsInfo.Database, storagePathPrefix))
+					cacheFile = dbPath
+				} else {
+					dbPath, err = resolveLocalPath(cfg.SQLiteDataSourcePaths, dsInfo.Database)
+				}
+				if err != nil {
+					return nil, "", err
+				}
+
+				config := sqleng.DataPluginConfiguration{
+					DriverName:        driverName,
+					ConnectionString:  readOnlyConnectionString(dbPath),
+					DSInfo:            dsInfo,
+					TimeColumnNames:   []string{"time", "time_sec"},
+					MetricColumnTypes: []string{"TEXT", "text", "VARCHAR", "varchar", "CHAR", "char"},
+					RowLimit:          cfg.DataProxyRowLimit,
+					Placeholder:       sqleng.QuestionPlaceholder,
+				}
+
+				rowTransformer := sqliteQueryResultTransformer{
+					log: logger,
+				}
+
+				handler, err := sqleng.NewQueryDataHandler(config, &rowTransformer, newSQLiteMacroEngine(logger), logger)
+				if err != nil {
+					if cacheFile != "" {
+						_ = os.Remove(cacheFile)
+					}
+					return nil, "", err
+				}
+				return handler, cacheFile, nil
+			},
+		}, nil
+	}
+}
+
+// dataSourceInstance opens the database on first use. Files in the storage service belong to an org,
+// which is only known from the plugin context of a request. A database file fetched from the storage
+// service is removed when the instance is disposed.
+type dataSourceInstance struct {
+	mu        sync.Mutex
+	handler   *sqleng.DataSourceHandler
+	cacheFile string
+	create    func(orgID int64) (*sqleng.DataSourceHandler, string, error)
+}
+
+func (i *dataSourceInstance) getHandler(orgID int64) (*sqleng.DataSourceHandler, error) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	if i.handler == nil {
+		handler, cacheFile, err := i.create(orgID)
+		if err != nil {
+			return nil, err
+		}
+		i.handler = handler
+		i.cacheFile = cacheFile
+	}
+	return i.handler, nil
+}
+
+func (i *dataSourceInstance) Dispose() {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	if i.handler != nil {
+		i.handler.Dispose()
+		i.handler = nil
+	}
+	if i.cacheFile != "" {
+		if err := os.Remove(i.cacheFile); err != nil && !os.IsNotExist(err) {
+			logger.Warn("Failed to remove cached database file", "path", i.cacheFile, "error", err)
+		}
+		i.cacheFile = ""
+	}
+}
+
+// readOnlyConnectionString opens the database file read-only and additionally
+// makes SQLite reject any statement that would modify it.
+func readOnlyConnectionString(path string) string {
+	u := url.URL{Scheme: "file", Opaque: (&url.URL{Path: path}).EscapedPath()}
+	q := url.Values{}
+	q.Set("mode", "ro")
+	q.Set("_query_only", "true")
+	u.RawQuery = q.Encode()
+	return u.String()
+}
+
+// resolveLocalPath makes the database path absolute, relative paths being resolved
+// against the first allowed directory, and checks it is inside an allowed directory.
+func resolveLocalPath(allowedPaths []string, path string) (string, error) {
+	if path == "" {
+		return "", errors.New("missing database file path")
+	}
+	if len(allowedPaths) == 0 {
+		return "", errPathNotAllowed
+	}
+
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(allowedPaths[0], path)
+	}
+	path, err := filepath.EvalSymlinks(filepath.Clean(path))
+	if err != nil {
+		return "", fmt.Errorf("error opening database file: %w", err)
+	}
+
+	for _, dir := range allowedPaths {
+		dir, err := filepath.EvalSymlinks(filepath.Clean(dir))
+		if err != nil {
+			continue
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil || rel == "." || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			continue
+		}
+		return path, nil
+	}
+
+	return "", errPathNotAllowed
+}
+
+// storageCachePath returns the path of the local copy of a storage database file of a data source.
+func storageCachePath(cfg *setting.Cfg, orgID int64, uid string) (string, error) {
+	if uid == "" || uid == "." || uid == ".." || strings.ContainsAny(uid, `/\`) {
+		return "", fmt.Errorf("invalid data source uid %q", uid)
+	}
+	return filepath.Join(cfg.DataPath, "storage", "cache", "sqlite", strconv.FormatInt(orgID, 10), uid+".db"), nil
+}
+
+// fetchStorageFile copies a database file from the resources storage of the org into a local cache
+// file owned by the data source. The copy is refreshed every time the instance is recreated.
+func fetchStorageFile(cfg *setting.Cfg, files fileReader, orgID int64, uid string, path string) (string, error) {
+	if files == nil {
+		return "", errors.New("storage service is not available")
+	}
+
+	file, err := files.Read(context.Background(), store.DatasourceFileReader(orgID), path)
+	if err != nil {
+		return "", fmt.Errorf("error reading database file from storage: %w", err)
+	}
+	if file == nil || file.IsFolder() {
+		return "", fmt.Errorf("database file %q not found in storage", path)
+	}
+
+	dbPath, err := storageCachePath(cfg, orgID, uid)
+	if err != nil {
+		return "", err
+	}
+	cacheDir := filepath.Dir(dbPath)
+	if err := os.MkdirAll(cacheDir, 0750); err != nil {
+		return "", err
+	}
+
+	tmp, err := os.CreateTemp(cacheDir, uid+"-*.tmp")
+	if err != nil {
+		return "", err
+	}
+	if _, err := tmp.Write(file.Contents); err != nil {
+		_ = tmp.Close()
+		_ = os.Remove(tmp.Name())
+		return "", err
+	}
+	if err := tmp.Close(); err != nil {
+		_ = os.Remove(tmp.Name())
+		return "", err
+	}
+	if err := os.Rename(tmp.Name(), dbPath); err != nil {
+		_ = os.Remove(tmp.Name())
+		return "", err
+	}
+
+	return dbPath, nil
+}
+
+func (s *Service) getDataSourceHandler(pluginCtx backend.PluginContext) (*sqleng.DataSourceHandler, error) {
+	i, err := s.im.Get(pluginCtx)
+	if err != nil {
+		return nil, err
+	}
+	instance := i.(*dataSourceInstance)
+	return instance.getHandler(pluginCtx.OrgID)
+}
+
+func (s *Service) QueryData(ctx context.Context, req *backend.QueryDataRequest) (*backend.QueryDataResponse, error) {
+	dsHandler, err := s.getDataSourceHandler(req.PluginContext)
+	if err != nil {
+		return nil, err
+	}
+	return dsHandler.QueryData(ctx, req)
+}
+
+func (s *Service) CheckHealth(ctx context.Context, req *backend.CheckHealthRequest) (*backend.CheckHealthResult, error) {
+	dsHandler, err := s.getDataSourceHandler(req.PluginContext)
+	if err != nil {
+		return nil, err
+	}
+	return dsHandler.CheckHealth(ctx, req)
+}
+
+type sqliteQueryResultTransformer struct {
+	log log.Logger
+}
+
+func (t *sqliteQueryResultTransformer) TransformQueryError(err error) error {
+	return err
+}
+
+func (t *sqliteQueryResultTransformer) GetConverterList() []sqlutil.StringConverter {
+	return nil
+}
+
+// GetConverters scans every column as a nullable string. SQLite values are dynamically typed and the
+// driver cannot report a scan type before reading the first row, so fields get their final type in TransformFrame.
+func (t *sqliteQueryResultTransformer) GetConverters() []sqlutil.Converter {
+	return []sqlutil.Converter{
+		{
+			Name:           "handle all columns",
+			InputScanType:  reflect.TypeOf(sql.NullString{}),
+			InputTypeRegex: regexp.MustCompile(".*"),
+			FrameConverter: sqlutil.NullStringConverter.FrameConverter,
+		},
+	}
+}
+
+// TransformFrame converts the string fields to the type implied by the declared column type, following
+// SQLite's type affinity rules. Columns without a declared type, like expressions, get the narrowest type
+// all their values can be parsed as.
+func (t *sqliteQueryResultTransformer) TransformFrame(frame *data.Frame, columnTypes []*sql.ColumnType) error {
+	for i, field := range frame.Fields {
+		if field.Type() != data.FieldTypeNullableString || i >= len(columnTypes) {
+			continue
+		}
+
+		declType := strings.ToUpper(columnTypes[i].DatabaseTypeName())
+		var converted *data.Field
+		switch {
+		case strings.Contains(declType, "DATE") || strings.Contains(declType, "TIME"):
+			converted = convertField(field, data.FieldTypeNullableTime, parseTime)
+		case strings.Contains(declType, "INT"):
+			converted = convertField(field, data.FieldTypeNullableInt64, parseInt)
+		case strings.Contains(declType, "CHAR") || strings.Contains(declType, "CLOB") || strings.Contains(declType, "TEXT"),
+			strings.Contains(declType, "BLOB"):
+			continue
+		case strings.Contains(declType, "REAL") || strings.Contains(declType, "FLOA") || strings.Contains(declType, "DOUB"):
+			converted = convertField(field, data.FieldTypeNullableFloat64, parseFloat)
+		}
+
+		if converted == nil {
+			converted = inferField(field)
+		}
+		if converted != nil {
+			frame.Fields[i] = converted
+		}
+	}
+
+	return nil
+}
+
+// inferField returns the field converted to the narrowest of integer, float and time that fits all of
+// its values, or nil if the values are not all of one of those types.
+func inferField(field *data.Field) *data.Field {
+	if f := convertField(field, data.FieldTypeNullableInt64, parseInt); f != nil {
+		return f
+	}
+	if f := convertField(field, data.FieldTypeNullableFloat64, parseFloat); f != nil {
+		return f
+	}
+	return convertField(field, data.FieldTypeNullableTime, parseTime)
+}
+
+// convertField returns a copy of a nullable string field with every value parsed by parse, or nil if any value fails to parse.
+func convertField(field *data.Field, fieldType data.FieldType, parse func(string) (interface{}, error)) *data.Field {
+	converted := data.NewFieldFromFieldType(fieldType, field.Len())
+	converted.Name = field.Name
+	converted.Labels = field.Labels
+	converted.Config = field.Config
+
+	for i := 0; i < field.Len(); i++ {
+		s, ok := field.At(i).(*string)
+		if !ok || s == nil {
+			continue
+		}
+		v, err := parse(*s)
+		if err != nil {
+			return nil
+		}
+		converted.Set(i, v)
+	}
+
+	return converted
+}
+
+func parseInt(s string) (interface{}, error) {
+	v, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return nil, err
+	}
+	return &v, nil
+}
+
+func parseFloat(s string) (interface{}, error) {
+	v, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return nil, err
+	}
+	return &v, nil
+}
+
+// timeFormats are the formats the driver and SQLite's date functions produce for date and time values
+var timeFormats = []string{
+	time.RFC3339Nano,
+	"2006-01-02 15:04:05.999999999-07:00",
+	"2006-01-02T15:04:05.999999999-07:00",
+	"2006-01-02 15:04:05.999999999",
+	"2006-01-02T15:04:05.999999999",
+	"2006-01-02 15:04",
+	"2006-01-02T15:04",
+	"2006-01-02",
+}
+
+func parseTime(s string) (interface{}, error) {
+	for _, format := range timeFormats {
+		if v, err := time.ParseInLocation(format, s, time.UTC); err == nil {
+			return &v, nil
+		}
+	}
+	// Unix timestamps in seconds
+	if v, err := strconv.ParseInt(s, 10, 64); err == nil {
+		t := time.Unix(v, 0).UTC()
+		return &t, nil
+	}
+	return nil, fmt.Errorf("%q is not a date or time", s)
+}
@@ -0,0 +1,288 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/datasource"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/grafana/grafana/pkg/events"
+	"github.com/grafana/grafana/pkg/infra/filestorage"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeFileReader struct {
+	files map[string][]byte
+	orgID int64
+}
+
+func (f *fakeFileReader) Read(_ context.Context, user *models.SignedInUser, path string) (*filestorage.File, error) {
+	f.orgID = user.OrgId
+	contents, ok := f.files[path]
+	if !ok {
+		return nil, nil
+	}
+	return &filestorage.File{Contents: contents}, nil
+}
+
+func createTestDB(t *testing.T, dir string) string {
+	t.Helper()
+
+	path := filepath.Join(dir, "ops.db")
+	db, err := sql.Open("sqlite3", path)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = db.Close() })
+
+	for _, stmt := range []string{
+		"CREATE TABLE metrics (ts DATETIME, host TEXT, value REAL, count INTEGER)",
+		"INSERT INTO metrics VALUES ('2018-03-15 13:00:00', 'a', 1.5, 1)",
+		"INSERT INTO metrics VALUES ('2018-03-15 13:05:00', 'a', 2.5, 2)",
+		"INSERT INTO metrics VALUES ('2018-03-15 13:05:00', 'b', 3.5, 3)",
+		"INSERT INTO metrics VALUES ('2018-03-15 14:00:00', 'b', 4.5, 4)",
+	} {
+		_, err := db.Exec(stmt)
+		require.NoError(t, err)
+	}
+	return path
+}
+
+func newTestService(cfg *setting.Cfg, files fileReader) *Service {
+	return &Service{im: datasource.NewInstanceManager(newInstanceSettings(cfg, files)), cfg: cfg}
+}
+
+func queryRequest(database string, rawSQL string, format string) *backend.QueryDataRequest {
+	from := time.Date(2018, 3, 15, 13, 0, 0, 0, time.UTC)
+	return &backend.QueryDataRequest{
+		PluginContext: backend.PluginContext{
+			OrgID: 2,
+			DataSourceInstanceSettings: &backend.DataSourceInstanceSettings{
+				UID:      "sqlite-test",
+				Database: database,
+				JSONData: []byte(`{}`),
+			},
+		},
+		Queries: []backend.DataQuery{
+			{
+				RefID:     "A",
+				JSON:      []byte(`{"rawSql": "` + rawSQL + `", "format": "` + format + `"}`),
+				TimeRange: backend.TimeRange{From: from, To: from.Add(30 * time.Minute)},
+			},
+		},
+	}
+}
+
+func TestSQLite(t *testing.T) {
+	dir := t.TempDir()
+	createTestDB(t, dir)
+	cfg := &setting.Cfg{SQLiteDataSourcePaths: []string{dir}, DataProxyRowLimit: 1000}
+	svc := newTestService(cfg, nil)
+
+	t.Run("time series query with macros", func(t *testing.T) {
+		resp, err := svc.QueryData(context.Background(), queryRequest("ops.db",
+			"SELECT $__timeGroupAlias(ts, '5m'), host AS metric, avg(value) AS value FROM metrics WHERE $__timeFilter(ts) GROUP BY 1, 2 ORDER BY 1",
+			"time_series"))
+		require.NoError(t, err)
+		res := resp.Responses["A"]
+		require.NoError(t, res.Error)
+		require.Len(t, res.Frames, 1)
+
+		frame := res.Frames[0]
+		require.Len(t, frame.Fields, 3)
+		require.Equal(t, data.FieldTypeTime, frame.Fields[0].Type())
+		require.Equal(t, 2, frame.Fields[0].Len())
+		require.Equal(t, time.Date(2018, 3, 15, 13, 0, 0, 0, time.UTC), frame.Fields[0].At(0).(time.Time).UTC())
+		require.Equal(t, "a", frame.Fields[1].Name)
+		require.Equal(t, 2.5, *frame.Fields[1].At(1).(*float64))
+		require.Equal(t, "b", frame.Fields[2].Name)
+		require.Equal(t, 3.5, *frame.Fields[2].At(1).(*float64))
+	})
+
+	t.Run("table query keeps declared column types", func(t *testing.T) {
+		resp, err := svc.QueryData(context.Background(), queryRequest("ops.db",
+			"SELECT ts, host, value, count, count * 2 AS doubled, host || '-x' AS label FROM metrics ORDER BY ts LIMIT 1",
+			"table"))
+		require.NoError(t, err)
+		res := resp.Responses["A"]
+		require.NoError(t, res.Error)
+
+		frame := res.Frames[0]
+		require.Equal(t, data.FieldTypeNullableTime, frame.Fields[0].Type())
+		require.Equal(t, time.Date(2018, 3, 15, 13, 0, 0, 0, time.UTC), *frame.Fields[0].At(0).(*time.Time))
+		require.Equal(t, data.FieldTypeNullableString, frame.Fields[1].Type())
+		require.Equal(t, data.FieldTypeNullableFloat64, frame.Fields[2].Type())
+		require.Equal(t, data.FieldTypeNullableInt64, frame.Fields[3].Type())
+		require.Equal(t, int64(2), *frame.Fields[4].At(0).(*int64))
+		require.Equal(t, "a-x", *frame.Fields[5].At(0).(*string))
+	})
+
+	t.Run("template variables are bound as parameters", func(t *testing.T) {
+		req := queryRequest("ops.db", "SELECT count(*) AS n FROM metrics WHERE host IN ($host)", "table")
+		req.Queries[0].JSON = []byte(`{"rawSql": "SELECT count(*) AS n FROM metrics WHERE host IN ($host)", "format": "table",
+			"variables": [{"name": "host", "values": ["b", "x') OR ('1' = '1"]}]}`)
+		resp, err := svc.QueryData(context.Background(), req)
+		require.NoError(t, err)
+		res := resp.Responses["A"]
+		require.NoError(t, res.Error)
+		require.Equal(t, "SELECT count(*) AS n FROM metrics WHERE host IN (?, ?)", res.Frames[0].Meta.ExecutedQueryString)
+		require.Equal(t, int64(2), *res.Frames[0].Fields[0].At(0).(*int64))
+	})
+
+	t.Run("writes are rejected", func(t *testing.T) {
+		resp, err := svc.QueryData(context.Background(), queryRequest("ops.db", "DELETE FROM metrics", "table"))
+		require.NoError(t, err)
+		require.ErrorContains(t, resp.Responses["A"].Error, "readonly database")
+
+		resp, err = svc.QueryData(context.Background(), queryRequest("ops.db", "SELECT count(*) AS n FROM metrics", "table"))
+		require.NoError(t, err)
+		require.NoError(t, resp.Responses["A"].Error)
+		require.Equal(t, int64(4), *resp.Responses["A"].Frames[0].Fields[0].At(0).(*int64))
+	})
+
+	t.Run("statements hidden by comments can't reach other files", func(t *testing.T) {
+		other := filepath.Join(t.TempDir(), "other.db")
+		for _, rawSQL := range []string{
+			"ATTACH/**/DATABASE '" + other + "' AS other",
+			"SELECT 1;/**/DETACH/**/main",
+			"VACUUM/**/INTO '" + other + "'",
+			"SELECT load_extension/**/('" + other + "')",
+		} {
+			resp, err := svc.QueryData(context.Background(), queryRequest("ops.db", rawSQL, "table"))
+			require.NoError(t, err)
+			require.Error(t, resp.Responses["A"].Error, rawSQL)
+		}
+		require.NoFileExists(t, other)
+	})
+}
+
+func TestSQLiteGuardrails(t *testing.T) {
+	dir := t.TempDir()
+	createTestDB(t, dir)
+	cfg := &setting.Cfg{SQLiteDataSourcePaths: []string{dir}, DataProxyRowLimit: 1000}
+
+	t.Run("rows above maxRows are truncated with a notice", func(t *testing.T) {
+		req := queryRequest("ops.db", "SELECT host FROM metrics", "table")
+		req.PluginContext.DataSourceInstanceSettings.JSONData = []byte(`{"maxRows": 2}`)
+		resp, err := newTestService(cfg, nil).QueryData(context.Background(), req)
+		require.NoError(t, err)
+		res := resp.Responses["A"]
+		require.NoError(t, res.Error)
+		require.Equal(t, 2, res.Frames[0].Rows())
+		require.Len(t, res.Frames[0].Meta.Notices, 1)
+	})
+
+	t.Run("queries running longer than queryTimeout are cancelled", func(t *testing.T) {
+		req := queryRequest("ops.db", "WITH RECURSIVE c(x) AS (SELECT 1 UNION ALL SELECT x + 1 FROM c) SELECT count(*) AS n FROM c", "table")
+		req.PluginContext.DataSourceInstanceSettings.JSONData = []byte(`{"queryTimeout": 1}`)
+		resp, err := newTestService(cfg, nil).QueryData(context.Background(), req)
+		require.NoError(t, err)
+		require.ErrorContains(t, resp.Responses["A"].Error, "maximum duration of 1s")
+	})
+}
+
+func TestSQLiteCheckHealth(t *testing.T) {
+	dir := t.TempDir()
+	createTestDB(t, dir)
+	cfg := &setting.Cfg{SQLiteDataSourcePaths: []string{dir}, DataProxyRowLimit: 1000}
+	svc := newTestService(cfg, nil)
+
+	pluginCtx := queryRequest("ops.db", "SELECT 1", "table").PluginContext
+	pluginCtx.DataSourceInstanceSettings.UID = "sqlite-health"
+	res, err := svc.CheckHealth(context.Background(), &backend.CheckHealthRequest{PluginContext: pluginCtx})
+	require.NoError(t, err)
+	require.Equal(t, backend.HealthStatusOk, res.Status)
+
+	var details struct {
+		Pool map[string]int64 `json:"pool"`
+	}
+	require.NoError(t, json.Unmarshal(res.JSONDetails, &details))
+	require.Contains(t, details.Pool, "openConnections")
+	require.Contains(t, details.Pool, "waitDurationMs")
+
+	families, err := prometheus.DefaultGatherer.Gather()
+	require.NoError(t, err)
+	found := false
+	for _, family := range families {
+		if family.GetName() != "grafana_sql_datasource_open_connections" {
+			continue
+		}
+		for _, metric := range family.GetMetric() {
+			labels := map[string]string{}
+			for _, label := range metric.GetLabel() {
+				labels[label.GetName()] = label.GetValue()
+			}
+			if labels["datasource"] == "sqlite-health" && labels["org_id"] == "2" {
+				found = true
+			}
+		}
+	}
+	require.True(t, found, "pool metrics of the data source should be exposed")
+}
+
+func TestResolveLocalPath(t *testing.T) {
+	allowed := t.TempDir()
+	other := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(allowed, "ops.db"), nil, 0600))
+	require.NoError(t, os.WriteFile(filepath.Join(other, "grafana.db"), nil, 0600))
+
+	path, err := resolveLocalPath([]string{allowed}, "ops.db")
+	require.NoError(t, err)
+	require.Equal(t, "ops.db", filepath.Base(path))
+
+	_, err = resolveLocalPath([]string{allowed}, filepath.Join(other, "grafana.db"))
+	require.ErrorIs(t, err, errPathNotAllowed)
+
+	_, err = resolveLocalPath([]string{allowed}, filepath.Join("..", filepath.Base(other), "grafana.db"))
+	require.ErrorIs(t, err, errPathNotAllowed)
+
+	require.NoError(t, os.Symlink(filepath.Join(other, "grafana.db"), filepath.Join(allowed, "link.db")))
+	_, err = resolveLocalPath([]string{allowed}, "link.db")
+	require.ErrorIs(t, err, errPathNotAllowed)
+}
+
+func TestStorageFile(t *testing.T) {
+	contents, err := os.ReadFile(createTestDB(t, t.TempDir()))
+	require.NoError(t, err)
+
+	files := &fakeFileReader{files: map[string][]byte{"resources/ops.db": contents}}
+	cfg := &setting.Cfg{DataPath: t.TempDir(), DataProxyRowLimit: 1000}
+	svc := newTestService(cfg, files)
+
+	resp, err := svc.QueryData(context.Background(), queryRequest("storage://resources/ops.db", "SELECT count(*) AS n FROM metrics", "table"))
+	require.NoError(t, err)
+	require.NoError(t, resp.Responses["A"].Error)
+	require.Equal(t, int64(4), *resp.Responses["A"].Frames[0].Fields[0].At(0).(*int64))
+	require.Equal(t, int64(2), files.orgID)
+
+	// the copy is kept per org and removed when the instance is disposed
+	cacheFile := filepath.Join(cfg.DataPath, "storage", "cache", "sqlite", "2", "sqlite-test.db")
+	require.FileExists(t, cacheFile)
+	req := queryRequest("storage://resources/ops.db", "SELECT 1", "table")
+	req.PluginContext.DataSourceInstanceSettings.Updated = time.Now()
+	_, err = svc.QueryData(context.Background(), req)
+	require.NoError(t, err)
+	require.FileExists(t, cacheFile)
+	i, err := svc.im.Get(req.PluginContext)
+	require.NoError(t, err)
+	i.(*dataSourceInstance).Dispose()
+	require.NoFileExists(t, cacheFile)
+
+	// and when the data source is deleted
+	_, err = svc.QueryData(context.Background(), req)
+	require.NoError(t, err)
+	require.FileExists(t, cacheFile)
+	require.NoError(t, svc.handleDataSourceDeleted(context.Background(), &events.DataSourceDeleted{OrgID: 2, UID: "sqlite-test"}))
+	require.NoFileExists(t, cacheFile)
+
+	svc = newTestService(cfg, files)
+	_, err = svc.QueryData(context.Background(), queryRequest("storage://resources/missing.db", "SELECT 1", "table"))
+	require.Error(t, err)
+}
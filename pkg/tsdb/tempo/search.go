@@ -0,0 +1,111 @@
+package tempo
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+)
+
+const defaultSearchLimit = 20
+
+type searchResponse struct {
+	Traces []*traceSearchMetadata `json:"traces"`
+}
+
+type traceSearchMetadata struct {
+	TraceID           string `json:"traceID"`
+	RootServiceName   string `json:"rootServiceName"`
+	RootTraceName     string `json:"rootTraceName"`
+	StartTimeUnixNano string `json:"startTimeUnixNano"`
+	DurationMs        uint32 `json:"durationMs"`
+}
+
+// querySearch finds traces through the Tempo search API and returns them as a table, most recent trace first.
+func (s *Service) querySearch(ctx context.Context, dsInfo *datasourceInfo, model *QueryModel, timeRange backend.TimeRange) (data.Frames, error) {
+	params, err := searchParams(model, timeRange)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp searchResponse
+	if err := s.getJSON(ctx, dsInfo, "/api/search", params, &resp); err != nil {
+		return nil, err
+	}
+
+	return data.Frames{searchResponseToFrame(resp.Traces)}, nil
+}
+
+func searchParams(model *QueryModel, timeRange backend.TimeRange) (url.Values, error) {
+	tags := []string{}
+	if model.Search != "" {
+		tags = append(tags, model.Search)
+	}
+	if model.ServiceName != "" {
+		tags = append(tags, fmt.Sprintf("service.name=%q", model.ServiceName))
+	}
+	if model.SpanName != "" {
+		tags = append(tags, fmt.Sprintf("name=%q", model.SpanName))
+	}
+
+	params := url.Values{}
+	params.Set("tags", strings.Join(tags, " "))
+
+	for name, value := range map[string]string{"minDuration": model.MinDuration, "maxDuration": model.MaxDuration} {
+		if value == "" {
+			continue
+		}
+		value = strings.ReplaceAll(value, " ", "")
+		if _, err := time.ParseDuration(value); err != nil {
+			return nil, fmt.Errorf("invalid %s %q: %w", name, value, err)
+		}
+		params.Set(name, value)
+	}
+
+	limit := model.Limit
+	if limit == 0 {
+		limit = defaultSearchLimit
+	}
+	if limit < 0 {
+		return nil, fmt.Errorf("invalid limit %d", limit)
+	}
+	params.Set("limit", strconv.FormatInt(limit, 10))
+
+	params.Set("start", strconv.FormatInt(timeRange.From.Unix(), 10))
+	params.Set("end", strconv.FormatInt(timeRange.To.Unix(), 10))
+	return params, nil
+}
+
+// searchResponseToFrame builds a table with a single numeric duration field so that
+// search results can be used as a number set by server side expressions and alerting.
+func searchResponseToFrame(traces []*traceSearchMetadata) *data.Frame {
+	frame := data.NewFrame("Traces",
+		data.NewField("traceID", nil, []string{}).SetConfig(&data.FieldConfig{DisplayNameFromDS: "Trace ID"}),
+		data.NewField("traceName", nil, []string{}).SetConfig(&data.FieldConfig{DisplayNameFromDS: "Trace name"}),
+		data.NewField("startTime", nil, []string{}).SetConfig(&data.FieldConfig{DisplayNameFromDS: "Start time"}),
+		data.NewField("duration", nil, []float64{}).SetConfig(&data.FieldConfig{DisplayNameFromDS: "Duration", Unit: "ms"}),
+	)
+	frame.Meta = &data.FrameMeta{PreferredVisualization: data.VisTypeTable}
+
+	startTimes := make(map[*traceSearchMetadata]time.Time, len(traces))
+	for _, trace := range traces {
+		nanos, _ := strconv.ParseInt(trace.StartTimeUnixNano, 10, 64)
+		startTimes[trace] = time.Unix(0, nanos).UTC()
+	}
+	sort.SliceStable(traces, func(i, j int) bool {
+		return startTimes[traces[i]].After(startTimes[traces[j]])
+	})
+
+	for _, trace := range traces {
+		traceName := strings.TrimSpace(trace.RootServiceName + " " + trace.RootTraceName)
+		frame.AppendRow(trace.TraceID, traceName, startTimes[trace].Format(time.RFC3339), float64(trace.DurationMs))
+	}
+
+	return frame
+}
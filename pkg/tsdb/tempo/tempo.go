@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"io/ioutil"
 	"net/http"
+	"net/url"
 
 	"github.com/grafana/grafana-plugin-sdk-go/backend"
 	"github.com/grafana/grafana-plugin-sdk-go/backend/datasource"
@@ -33,8 +34,24 @@ type datasourceInfo struct {
 	URL        string
 }
 
+const (
+	queryTypeTraceID = "traceId"
+	queryTypeSearch  = "nativeSearch"
+	queryTypeMetrics = "traceqlMetrics"
+)
+
 type QueryModel struct {
-	TraceID string `json:"query"`
+	QueryType string `json:"queryType"`
+	// Query is the trace ID of traceId queries and the TraceQL expression of traceqlMetrics queries
+	Query string `json:"query"`
+
+	// Search filters of nativeSearch queries
+	Search      string `json:"search"`
+	ServiceName string `json:"serviceName"`
+	SpanName    string `json:"spanName"`
+	MinDuration string `json:"minDuration"`
+	MaxDuration string `json:"maxDuration"`
+	Limit       int64  `json:"limit"`
 }
 
 func newInstanceSettings(httpClientProvider httpclient.Provider) datasource.InstanceFactoryFunc {
@@ -59,28 +76,53 @@ func newInstanceSettings(httpClientProvider httpclient.Provider) datasource.Inst
 
 func (s *Service) QueryData(ctx context.Context, req *backend.QueryDataRequest) (*backend.QueryDataResponse, error) {
 	result := backend.NewQueryDataResponse()
-	queryRes := backend.DataResponse{}
-	refID := req.Queries[0].RefID
-
-	model := &QueryModel{}
-	err := json.Unmarshal(req.Queries[0].JSON, model)
-	if err != nil {
-		return result, err
-	}
 
 	dsInfo, err := s.getDSInfo(req.PluginContext)
 	if err != nil {
 		return nil, err
 	}
 
-	request, err := s.createRequest(ctx, dsInfo, model.TraceID)
+	for _, query := range req.Queries {
+		model := &QueryModel{}
+		if err := json.Unmarshal(query.JSON, model); err != nil {
+			result.Responses[query.RefID] = backend.DataResponse{Error: fmt.Errorf("failed to parse query: %w", err)}
+			continue
+		}
+
+		var frames data.Frames
+		switch model.QueryType {
+		case "", queryTypeTraceID:
+			frames, err = s.queryTrace(ctx, dsInfo, model)
+		case queryTypeSearch:
+			frames, err = s.querySearch(ctx, dsInfo, model, query.TimeRange)
+		case queryTypeMetrics:
+			frames, err = s.queryMetrics(ctx, dsInfo, model, query)
+		default:
+			err = fmt.Errorf("unsupported query type: %s", model.QueryType)
+		}
+		if err != nil {
+			result.Responses[query.RefID] = backend.DataResponse{Error: err}
+			continue
+		}
+
+		for _, frame := range frames {
+			frame.RefID = query.RefID
+		}
+		result.Responses[query.RefID] = backend.DataResponse{Frames: frames}
+	}
+
+	return result, nil
+}
+
+func (s *Service) queryTrace(ctx context.Context, dsInfo *datasourceInfo, model *QueryModel) (data.Frames, error) {
+	request, err := s.createRequest(ctx, dsInfo, model.Query)
 	if err != nil {
-		return result, err
+		return nil, err
 	}
 
 	resp, err := dsInfo.HTTPClient.Do(request)
 	if err != nil {
-		return result, fmt.Errorf("failed get to tempo: %w", err)
+		return nil, fmt.Errorf("failed get to tempo: %w", err)
 	}
 
 	defer func() {
@@ -91,30 +133,59 @@ func (s *Service) QueryData(ctx context.Context, req *backend.QueryDataRequest)
 
 	body, err := ioutil.ReadAll(resp.Body)
 	if err != nil {
-		return &backend.QueryDataResponse{}, err
+		return nil, err
 	}
 
 	if resp.StatusCode != http.StatusOK {
-		queryRes.Error = fmt.Errorf("failed to get trace with id: %s Status: %s Body: %s", model.TraceID, resp.Status, string(body))
-		result.Responses[refID] = queryRes
-		return result, nil
+		return nil, fmt.Errorf("failed to get trace with id: %s Status: %s Body: %s", model.Query, resp.Status, string(body))
 	}
 
 	otTrace, err := otlp.NewProtobufTracesUnmarshaler().UnmarshalTraces(body)
 
 	if err != nil {
-		return &backend.QueryDataResponse{}, fmt.Errorf("failed to convert tempo response to Otlp: %w", err)
+		return nil, fmt.Errorf("failed to convert tempo response to Otlp: %w", err)
 	}
 
 	frame, err := TraceToFrame(otTrace)
 	if err != nil {
-		return &backend.QueryDataResponse{}, fmt.Errorf("failed to transform trace %v to data frame: %w", model.TraceID, err)
+		return nil, fmt.Errorf("failed to transform trace %v to data frame: %w", model.Query, err)
 	}
-	frame.RefID = refID
-	frames := []*data.Frame{frame}
-	queryRes.Frames = frames
-	result.Responses[refID] = queryRes
-	return result, nil
+	if frame == nil {
+		return nil, nil
+	}
+	return data.Frames{frame}, nil
+}
+
+// getJSON runs a GET request against the Tempo API and decodes the JSON response into v.
+func (s *Service) getJSON(ctx context.Context, dsInfo *datasourceInfo, path string, params url.Values, v interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, "GET", dsInfo.URL+path+"?"+params.Encode(), nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	s.tlog.Debug("Tempo request", "url", req.URL.String())
+
+	resp, err := dsInfo.HTTPClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed get to tempo: %w", err)
+	}
+
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			s.tlog.Warn("failed to close response body", "err", err)
+		}
+	}()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("request to %s failed. Status: %s Body: %s", path, resp.Status, string(body))
+	}
+
+	return json.Unmarshal(body, v)
 }
 
 func (s *Service) createRequest(ctx context.Context, dsInfo *datasourceInfo, traceID string) (*http.Request, error) {
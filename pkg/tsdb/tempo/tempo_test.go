@@ -2,8 +2,14 @@ package tempo
 
 import (
 	"context"
+	"net/http"
+	"net/http/httptest"
 	"testing"
+	"time"
 
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/grafana/grafana/pkg/infra/httpclient"
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -17,3 +23,102 @@ func TestTempo(t *testing.T) {
 		assert.Equal(t, 1, len(req.Header))
 	})
 }
+
+func TestQueryData(t *testing.T) {
+	var searchQuery, metricsQuery map[string][]string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/search":
+			searchQuery = r.URL.Query()
+			_, _ = w.Write([]byte(`{"traces": [
+				{"traceID": "a1", "rootServiceName": "api", "rootTraceName": "GET /", "startTimeUnixNano": "1647339600000000000", "durationMs": 12},
+				{"traceID": "b2", "rootServiceName": "db", "startTimeUnixNano": "1647339660000000000", "durationMs": 40}
+			]}`))
+		case "/api/metrics/query_range":
+			metricsQuery = r.URL.Query()
+			_, _ = w.Write([]byte(`{"series": [{
+				"labels": [{"key": "resource.service.name", "value": {"stringValue": "api"}}],
+				"samples": [{"timestampMs": "1647339600000", "value": 2}, {"timestampMs": "1647339660000", "value": 5}]
+			}]}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte("trace not found"))
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	from := time.Date(2022, 3, 15, 10, 0, 0, 0, time.UTC)
+	timeRange := backend.TimeRange{From: from, To: from.Add(time.Hour)}
+	service := ProvideService(httpclient.NewProvider())
+	resp, err := service.QueryData(context.Background(), &backend.QueryDataRequest{
+		PluginContext: backend.PluginContext{
+			DataSourceInstanceSettings: &backend.DataSourceInstanceSettings{URL: srv.URL},
+		},
+		Queries: []backend.DataQuery{
+			{
+				RefID:     "A",
+				TimeRange: timeRange,
+				JSON:      []byte(`{"queryType": "nativeSearch", "serviceName": "api", "search": "http.status_code=500", "minDuration": "10 ms"}`),
+			},
+			{
+				RefID:     "B",
+				TimeRange: timeRange,
+				Interval:  time.Minute,
+				JSON:      []byte(`{"queryType": "traceqlMetrics", "query": "{status=error} | rate()"}`),
+			},
+			{
+				RefID:     "C",
+				TimeRange: timeRange,
+				JSON:      []byte(`{"queryType": "traceId", "query": "missing"}`),
+			},
+		},
+	})
+	require.NoError(t, err)
+	require.Len(t, resp.Responses, 3)
+
+	t.Run("search query returns the most recent traces first", func(t *testing.T) {
+		res := resp.Responses["A"]
+		require.NoError(t, res.Error)
+		assert.Equal(t, `http.status_code=500 service.name="api"`, searchQuery["tags"][0])
+		assert.Equal(t, "10ms", searchQuery["minDuration"][0])
+		assert.Equal(t, "20", searchQuery["limit"][0])
+		assert.Equal(t, "1647338400", searchQuery["start"][0])
+
+		require.Len(t, res.Frames, 1)
+		frame := res.Frames[0]
+		assert.Equal(t, "A", frame.RefID)
+		assert.Equal(t, "b2", frame.Fields[0].At(0))
+		assert.Equal(t, "api GET /", frame.Fields[1].At(1))
+		assert.Equal(t, "2022-03-15T10:20:00Z", frame.Fields[2].At(1))
+		assert.Equal(t, 40.0, frame.Fields[3].At(0))
+	})
+
+	t.Run("metrics query returns time series", func(t *testing.T) {
+		res := resp.Responses["B"]
+		require.NoError(t, res.Error)
+		assert.Equal(t, "{status=error} | rate()", metricsQuery["q"][0])
+		assert.Equal(t, "1m0s", metricsQuery["step"][0])
+
+		require.Len(t, res.Frames, 1)
+		frame := res.Frames[0]
+		assert.Equal(t, data.FrameType(data.FrameTypeTimeSeriesMany), frame.Meta.Type)
+		assert.Equal(t, time.UnixMilli(1647339660000).UTC(), frame.Fields[0].At(1))
+		assert.Equal(t, 5.0, frame.Fields[1].At(1))
+		assert.Equal(t, data.Labels{"resource.service.name": "api"}, frame.Fields[1].Labels)
+	})
+
+	t.Run("failed queries do not fail the request", func(t *testing.T) {
+		require.Error(t, resp.Responses["C"].Error)
+		assert.Contains(t, resp.Responses["C"].Error.Error(), "trace not found")
+	})
+}
+
+func TestSearchParams(t *testing.T) {
+	_, err := searchParams(&QueryModel{MaxDuration: "10 parsecs"}, backend.TimeRange{})
+	require.Error(t, err)
+
+	params, err := searchParams(&QueryModel{SpanName: "GET /", Limit: 5}, backend.TimeRange{})
+	require.NoError(t, err)
+	assert.Equal(t, `name="GET /"`, params.Get("tags"))
+	assert.Equal(t, "5", params.Get("limit"))
+}
@@ -0,0 +1,97 @@
+package tempo
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+)
+
+type metricsResponse struct {
+	Series []*metricsSeries `json:"series"`
+}
+
+type metricsSeries struct {
+	Labels  []*metricsLabel  `json:"labels"`
+	Samples []*metricsSample `json:"samples"`
+}
+
+type metricsLabel struct {
+	Key   string                 `json:"key"`
+	Value map[string]interface{} `json:"value"`
+}
+
+type metricsSample struct {
+	TimestampMs json.Number `json:"timestampMs"`
+	Value       float64     `json:"value"`
+}
+
+// queryMetrics runs a TraceQL metrics query, like {status=error} | rate(), over the query time range
+// and returns one time series per series in the response.
+func (s *Service) queryMetrics(ctx context.Context, dsInfo *datasourceInfo, model *QueryModel, query backend.DataQuery) (data.Frames, error) {
+	if model.Query == "" {
+		return nil, fmt.Errorf("TraceQL metrics query is empty")
+	}
+
+	params := url.Values{}
+	params.Set("q", model.Query)
+	params.Set("start", strconv.FormatInt(query.TimeRange.From.Unix(), 10))
+	params.Set("end", strconv.FormatInt(query.TimeRange.To.Unix(), 10))
+	if query.Interval > 0 {
+		params.Set("step", query.Interval.String())
+	}
+
+	var resp metricsResponse
+	if err := s.getJSON(ctx, dsInfo, "/api/metrics/query_range", params, &resp); err != nil {
+		return nil, err
+	}
+
+	return metricsResponseToFrames(resp.Series)
+}
+
+func metricsResponseToFrames(series []*metricsSeries) (data.Frames, error) {
+	frames := make(data.Frames, 0, len(series))
+	for _, s := range series {
+		labels := data.Labels{}
+		for _, l := range s.Labels {
+			labels[l.Key] = labelValue(l.Value)
+		}
+
+		times := make([]time.Time, 0, len(s.Samples))
+		values := make([]float64, 0, len(s.Samples))
+		for _, sample := range s.Samples {
+			ms, err := sample.TimestampMs.Int64()
+			if err != nil {
+				return nil, fmt.Errorf("invalid sample timestamp %q: %w", sample.TimestampMs, err)
+			}
+			times = append(times, time.UnixMilli(ms).UTC())
+			values = append(values, sample.Value)
+		}
+
+		frame := data.NewFrame("",
+			data.NewField(data.TimeSeriesTimeFieldName, nil, times),
+			data.NewField(data.TimeSeriesValueFieldName, labels, values),
+		)
+		frame.Meta = &data.FrameMeta{Type: data.FrameTypeTimeSeriesMany}
+		frames = append(frames, frame)
+	}
+	return frames, nil
+}
+
+// labelValue formats an OTLP AnyValue, like {"stringValue": "api"}, as a label value.
+func labelValue(value map[string]interface{}) string {
+	for _, v := range value {
+		switch v := v.(type) {
+		case string:
+			return v
+		default:
+			return fmt.Sprint(v)
+		}
+	}
+	return ""
+}
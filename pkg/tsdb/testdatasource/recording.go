@@ -0,0 +1,189 @@
+package testdatasource
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/grafana/grafana/pkg/infra/filestorage"
+)
+
+// RecordingsFolder is the folder of the resources storage root holding the recorded query responses
+const RecordingsFolder = "testdata/recordings"
+
+var validRecordingName = regexp.MustCompile(`^[\w-]+$`)
+
+// ResourceReader reads files of the resources storage root on behalf of the data source of an org.
+type ResourceReader interface {
+	ReadResource(ctx context.Context, orgID int64, path string) (*filestorage.File, error)
+}
+
+// Recording holds the frames returned by a data source query, to be replayed by the recorded scenario.
+type Recording struct {
+	// Time range of the recorded query
+	From time.Time `json:"from"`
+	To   time.Time `json:"to"`
+
+	// Frames returned for each query of the request, by refId
+	Frames map[string]data.Frames `json:"frames"`
+}
+
+// NewRecording captures the frames of a query response. An error is returned if any of the queries failed,
+// recordings are fixtures and should not silently miss data.
+func NewRecording(from time.Time, to time.Time, resp *backend.QueryDataResponse) (*Recording, error) {
+	recording := &Recording{
+		From:   from.UTC(),
+		To:     to.UTC(),
+		Frames: make(map[string]data.Frames, len(resp.Responses)),
+	}
+	for refID, res := range resp.Responses {
+		if res.Error != nil {
+			return nil, fmt.Errorf("query %s returned an error: %w", refID, res.Error)
+		}
+		recording.Frames[refID] = res.Frames
+	}
+	return recording, nil
+}
+
+// RecordingPath returns the path of a recording relative to the resources storage root.
+func RecordingPath(name string) (string, error) {
+	if !validRecordingName.MatchString(name) {
+		return "", fmt.Errorf("invalid recording name %q, only letters, digits, dashes and underscores are allowed", name)
+	}
+	return RecordingsFolder + "/" + name + ".json", nil
+}
+
+type recordingQueryWrapper struct {
+	Recording recordingQuery `json:"recording"`
+}
+
+type recordingQuery struct {
+	Name string `json:"name"`
+	// refId of the recorded query, defaults to the refId of the query or to the only recorded query
+	RefID string `json:"refId"`
+	// Shift the recorded times so the end of the recording matches the end of the query time range
+	TimeShift bool `json:"timeShift"`
+}
+
+func (s *Service) handleRecordedScenario(ctx context.Context, req *backend.QueryDataRequest) (*backend.QueryDataResponse, error) {
+	resp := backend.NewQueryDataResponse()
+
+	for _, q := range req.Queries {
+		wrapper := &recordingQueryWrapper{}
+		if err := json.Unmarshal(q.JSON, wrapper); err != nil {
+			return nil, fmt.Errorf("failed to parse query json: %v", err)
+		}
+
+		if wrapper.Recording.Name == "" {
+			continue
+		}
+
+		frames, err := s.replayRecording(ctx, req.PluginContext.OrgID, q, wrapper.Recording)
+		if err != nil {
+			resp.Responses[q.RefID] = backend.DataResponse{Error: err}
+			continue
+		}
+		resp.Responses[q.RefID] = backend.DataResponse{Frames: frames}
+	}
+
+	return resp, nil
+}
+
+func (s *Service) replayRecording(ctx context.Context, orgID int64, q backend.DataQuery, query recordingQuery) (data.Frames, error) {
+	recording, err := s.loadRecording(ctx, orgID, query.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	frames, err := recording.framesFor(query.RefID, q.RefID)
+	if err != nil {
+		return nil, err
+	}
+
+	offset := time.Duration(0)
+	if query.TimeShift {
+		offset = q.TimeRange.To.Sub(recording.To)
+	}
+
+	for _, frame := range frames {
+		frame.RefID = q.RefID
+		if offset != 0 {
+			shiftFrameTimes(frame, offset)
+		}
+	}
+	return frames, nil
+}
+
+func (s *Service) loadRecording(ctx context.Context, orgID int64, name string) (*Recording, error) {
+	if s.resources == nil {
+		return nil, fmt.Errorf("storage service is not available")
+	}
+
+	path, err := RecordingPath(name)
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := s.resources.ReadResource(ctx, orgID, path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read recording %q: %w", name, err)
+	}
+	if file == nil || file.IsFolder() {
+		return nil, fmt.Errorf("recording %q not found", name)
+	}
+
+	recording := &Recording{}
+	if err := json.Unmarshal(file.Contents, recording); err != nil {
+		return nil, fmt.Errorf("failed to parse recording %q: %w", name, err)
+	}
+	return recording, nil
+}
+
+func (r *Recording) framesFor(refID string, queryRefID string) (data.Frames, error) {
+	if refID != "" {
+		if frames, ok := r.Frames[refID]; ok {
+			return frames, nil
+		}
+	} else {
+		if frames, ok := r.Frames[queryRefID]; ok {
+			return frames, nil
+		}
+		if len(r.Frames) == 1 {
+			for _, frames := range r.Frames {
+				return frames, nil
+			}
+		}
+		refID = queryRefID
+	}
+
+	refIDs := make([]string, 0, len(r.Frames))
+	for id := range r.Frames {
+		refIDs = append(refIDs, id)
+	}
+	sort.Strings(refIDs)
+	return nil, fmt.Errorf("query %s not found in the recording, recorded queries: %s", refID, strings.Join(refIDs, ", "))
+}
+
+func shiftFrameTimes(frame *data.Frame, offset time.Duration) {
+	for _, field := range frame.Fields {
+		switch field.Type() {
+		case data.FieldTypeTime:
+			for i := 0; i < field.Len(); i++ {
+				field.Set(i, field.At(i).(time.Time).Add(offset))
+			}
+		case data.FieldTypeNullableTime:
+			for i := 0; i < field.Len(); i++ {
+				if t, ok := field.At(i).(*time.Time); ok && t != nil {
+					shifted := t.Add(offset)
+					field.Set(i, &shifted)
+				}
+			}
+		}
+	}
+}
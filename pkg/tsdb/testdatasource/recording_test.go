@@ -0,0 +1,154 @@
+package testdatasource
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/grafana/grafana/pkg/infra/filestorage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeResourceReader struct {
+	files  map[string][]byte
+	orgIDs []int64
+}
+
+func (r *fakeResourceReader) ReadResource(ctx context.Context, orgID int64, path string) (*filestorage.File, error) {
+	r.orgIDs = append(r.orgIDs, orgID)
+	contents, ok := r.files[path]
+	if !ok {
+		return nil, nil
+	}
+	return &filestorage.File{Contents: contents, FileMetadata: filestorage.FileMetadata{Name: path, MimeType: "application/json"}}, nil
+}
+
+func TestRecordedScenario(t *testing.T) {
+	recordedFrom := time.Date(2022, time.January, 10, 10, 0, 0, 0, time.UTC)
+	recordedTo := recordedFrom.Add(time.Hour)
+
+	recording, err := NewRecording(recordedFrom, recordedTo, &backend.QueryDataResponse{
+		Responses: backend.Responses{
+			"A": {Frames: data.Frames{data.NewFrame("cpu",
+				data.NewField("time", nil, []time.Time{recordedFrom, recordedTo}),
+				data.NewField("value", data.Labels{"host": "a"}, []float64{1, 2}),
+			)}},
+			"B": {Frames: data.Frames{data.NewFrame("events",
+				data.NewField("time", nil, []*time.Time{&recordedTo, nil}),
+				data.NewField("text", nil, []string{"deploy", "unknown"}),
+			)}},
+		},
+	})
+	require.NoError(t, err)
+	contents, err := json.Marshal(recording)
+	require.NoError(t, err)
+
+	resources := &fakeResourceReader{files: map[string][]byte{RecordingsFolder + "/node-cpu.json": contents}}
+	s := &Service{resources: resources}
+
+	query := func(refID string, model string) *backend.QueryDataRequest {
+		return &backend.QueryDataRequest{
+			PluginContext: backend.PluginContext{OrgID: 3},
+			Queries: []backend.DataQuery{{
+				RefID:     refID,
+				TimeRange: backend.TimeRange{From: time.Date(2022, time.March, 1, 0, 0, 0, 0, time.UTC), To: time.Date(2022, time.March, 1, 1, 0, 0, 0, time.UTC)},
+				JSON:      []byte(model),
+			}},
+		}
+	}
+
+	t.Run("replays the frames of the query with the same refId", func(t *testing.T) {
+		resp, err := s.handleRecordedScenario(context.Background(), query("A", `{"recording": {"name": "node-cpu"}}`))
+		require.NoError(t, err)
+
+		res := resp.Responses["A"]
+		require.NoError(t, res.Error)
+		require.Len(t, res.Frames, 1)
+		frame := res.Frames[0]
+		assert.Equal(t, "cpu", frame.Name)
+		assert.Equal(t, "A", frame.RefID)
+		assert.Equal(t, recordedFrom, frame.Fields[0].At(0))
+		assert.Equal(t, data.Labels{"host": "a"}, frame.Fields[1].Labels)
+		assert.Equal(t, 2.0, frame.Fields[1].At(1))
+
+		require.NotEmpty(t, resources.orgIDs)
+		assert.Equal(t, int64(3), resources.orgIDs[len(resources.orgIDs)-1])
+	})
+
+	t.Run("replays the frames of the selected refId", func(t *testing.T) {
+		resp, err := s.handleRecordedScenario(context.Background(), query("C", `{"recording": {"name": "node-cpu", "refId": "B"}}`))
+		require.NoError(t, err)
+
+		res := resp.Responses["C"]
+		require.NoError(t, res.Error)
+		require.Len(t, res.Frames, 1)
+		assert.Equal(t, "events", res.Frames[0].Name)
+		assert.Equal(t, "C", res.Frames[0].RefID)
+	})
+
+	t.Run("shifts the recorded times to the end of the query time range", func(t *testing.T) {
+		resp, err := s.handleRecordedScenario(context.Background(), query("B", `{"recording": {"name": "node-cpu", "timeShift": true}}`))
+		require.NoError(t, err)
+
+		res := resp.Responses["B"]
+		require.NoError(t, res.Error)
+		field := res.Frames[0].Fields[0]
+		end := time.Date(2022, time.March, 1, 1, 0, 0, 0, time.UTC)
+		assert.Equal(t, end, *field.At(0).(*time.Time))
+		assert.Nil(t, field.At(1))
+	})
+
+	t.Run("returns an error when the refId is not recorded", func(t *testing.T) {
+		resp, err := s.handleRecordedScenario(context.Background(), query("C", `{"recording": {"name": "node-cpu"}}`))
+		require.NoError(t, err)
+
+		assert.EqualError(t, resp.Responses["C"].Error, "query C not found in the recording, recorded queries: A, B")
+	})
+
+	t.Run("returns an error when the recording does not exist", func(t *testing.T) {
+		resp, err := s.handleRecordedScenario(context.Background(), query("A", `{"recording": {"name": "missing"}}`))
+		require.NoError(t, err)
+
+		assert.EqualError(t, resp.Responses["A"].Error, `recording "missing" not found`)
+	})
+
+	t.Run("returns an error for invalid recording names", func(t *testing.T) {
+		resp, err := s.handleRecordedScenario(context.Background(), query("A", `{"recording": {"name": "../secrets"}}`))
+		require.NoError(t, err)
+
+		assert.Error(t, resp.Responses["A"].Error)
+	})
+
+	t.Run("returns no response when no recording is selected", func(t *testing.T) {
+		resp, err := s.handleRecordedScenario(context.Background(), query("A", `{}`))
+		require.NoError(t, err)
+
+		assert.Empty(t, resp.Responses)
+	})
+}
+
+func TestNewRecording(t *testing.T) {
+	t.Run("fails when a query returned an error", func(t *testing.T) {
+		_, err := NewRecording(time.Now(), time.Now(), &backend.QueryDataResponse{
+			Responses: backend.Responses{"A": {Error: errors.New("timeout")}},
+		})
+
+		assert.EqualError(t, err, "query A returned an error: timeout")
+	})
+}
+
+func TestRecordingPath(t *testing.T) {
+	path, err := RecordingPath("node_cpu-1")
+	require.NoError(t, err)
+	assert.Equal(t, "testdata/recordings/node_cpu-1.json", path)
+
+	for _, name := range []string{"", "../etc/passwd", "a/b", "a.json"} {
+		_, err := RecordingPath(name)
+		assert.Error(t, err, name)
+	}
+}
@@ -42,6 +42,7 @@ const (
 	rawFrameQuery                     queryType = "raw_frame"
 	csvFileQueryType                  queryType = "csv_file"
 	csvContentQueryType               queryType = "csv_content"
+	recordedQuery                     queryType = "recorded"
 )
 
 type queryType string
@@ -212,6 +213,13 @@ Timestamps will line up evenly on timeStepSeconds (For example, 60 seconds means
 		handler: s.handleCsvContentScenario,
 	})
 
+	s.registerScenario(&Scenario{
+		ID:          string(recordedQuery),
+		Name:        "Recorded",
+		handler:     s.handleRecordedScenario,
+		Description: "Replays the frames of a data source query recorded with the /api/admin/testdata/recordings endpoint",
+	})
+
 	s.queryMux.HandleFunc("", s.handleFallbackScenario)
 }
 
@@ -15,7 +15,7 @@ import (
 	"github.com/grafana/grafana/pkg/tsdb/testdatasource/sims"
 )
 
-func ProvideService(cfg *setting.Cfg, features featuremgmt.FeatureToggles) *Service {
+func ProvideService(cfg *setting.Cfg, features featuremgmt.FeatureToggles, resources ResourceReader) *Service {
 	s := &Service{
 		features:  features,
 		queryMux:  datasource.NewQueryTypeMux(),
@@ -31,8 +31,9 @@ func ProvideService(cfg *setting.Cfg, features featuremgmt.FeatureToggles) *Serv
 			data.NewField("Time", nil, make([]time.Time, 1)),
 			data.NewField("Value", nil, make([]float64, 1)),
 		),
-		logger: log.New("tsdb.testdata"),
-		cfg:    cfg,
+		logger:    log.New("tsdb.testdata"),
+		cfg:       cfg,
+		resources: resources,
 	}
 
 	var err error
@@ -57,6 +58,7 @@ type Service struct {
 	resourceHandler backend.CallResourceHandler
 	features        featuremgmt.FeatureToggles
 	sims            *sims.SimulationEngine
+	resources       ResourceReader
 }
 
 func (s *Service) QueryData(ctx context.Context, req *backend.QueryDataRequest) (*backend.QueryDataResponse, error) {
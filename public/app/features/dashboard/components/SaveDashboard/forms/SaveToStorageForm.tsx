@@ -44,6 +44,8 @@ export function SaveToStorageForm(props: Props) {
         return 'Create PR';
       case WorkflowID.Push:
         return 'Push';
+      case WorkflowID.Branch:
+        return 'Save as branch';
     }
     console.log('???', workflow);
     return 'Save';
@@ -68,7 +70,7 @@ export function SaveToStorageForm(props: Props) {
       <div>
         {response.url && (
           <div>
-            <h2>View pull request</h2>
+            <h2>{workflow === WorkflowID.PR ? 'View pull request' : 'View branch'}</h2>
             <a href={response.url}>{response.url}</a>
           </div>
         )}
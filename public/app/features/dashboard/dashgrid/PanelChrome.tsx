@@ -323,7 +323,8 @@ export class PanelChrome extends PureComponent<Props, State> {
         dashboard.getTimezone(),
         timeData,
         width,
-        dashboard.meta.publicDashboardAccessToken
+        dashboard.meta.publicDashboardAccessToken,
+        dashboard.uid
       );
     } else {
       // The panel should render on refresh as well if it doesn't have a query, like clock panel
@@ -66,11 +66,34 @@ export interface KeepFieldsFrameProcessorConfig {
 export interface DropFieldsFrameProcessorConfig {
   fieldNames: string[];
 }
+export interface ScriptFrameProcessorConfig {
+  script: string;
+}
+export interface FieldUpdate {
+  fieldName: string;
+  name?: string;
+  unit?: string;
+}
+export interface UpdateFieldsFrameProcessorConfig {
+  fields: FieldUpdate[];
+}
+export interface RateFrameProcessorConfig {
+  fieldNames?: string[];
+}
+export interface AggregateFrameProcessorConfig {
+  windowSeconds: number;
+  functions?: string[];
+  fieldNames?: string[];
+}
 export interface FrameProcessorConfig {
   type: Omit<keyof FrameProcessorConfig, 'type'>;
   dropFields?: DropFieldsFrameProcessorConfig;
   keepFields?: KeepFieldsFrameProcessorConfig;
   multiple?: MultipleFrameProcessorConfig;
+  aggregate?: AggregateFrameProcessorConfig;
+  rate?: RateFrameProcessorConfig;
+  updateFields?: UpdateFieldsFrameProcessorConfig;
+  script?: ScriptFrameProcessorConfig;
 }
 export interface JsonFrameConverterConfig {}
 export interface AutoInfluxConverterConfig {
@@ -118,6 +141,30 @@ export interface SubscriberConfig {
   type: Omit<keyof SubscriberConfig, 'type'>;
   multiple?: MultipleSubscriberConfig;
 }
+export interface NATSSourceConfig {
+  url: string;
+  subject: string;
+  queue?: string;
+}
+export interface MQTTSourceConfig {
+  broker: string;
+  topic: string;
+  qos?: number;
+  clientId?: string;
+  sharedGroup?: string;
+  username?: string;
+}
+export interface KafkaSourceConfig {
+  brokers: string[];
+  topic: string;
+  groupId?: string;
+}
+export interface SourceConfig {
+  type: Omit<keyof SourceConfig, 'type'>;
+  kafka?: KafkaSourceConfig;
+  mqtt?: MQTTSourceConfig;
+  nats?: NATSSourceConfig;
+}
 export interface ChannelRuleSettings {
   auth?: ChannelAuthConfig;
   subscribers?: SubscriberConfig[];
@@ -125,6 +172,7 @@ export interface ChannelRuleSettings {
   converter?: ConverterConfig;
   frameProcessors?: FrameProcessorConfig[];
   frameOutputs?: FrameOutputterConfig[];
+  sources?: SourceConfig[];
 }
 export interface ChannelRule {
   pattern: string;
@@ -25,12 +25,14 @@ import { getTimeSrv } from 'app/features/dashboard/services/TimeSrv';
 import { VariableWithMultiSupport } from '../../../variables/types';
 import { getSearchFilterScopedVar, SearchFilterOptions } from '../../../variables/utils';
 import { MACRO_NAMES } from '../constants';
-import { DB, SQLQuery, SQLOptions, ResponseParser, SqlQueryModel, QueryFormat } from '../types';
+import { DB, SQLQuery, SQLOptions, ResponseParser, SqlQueryModel, QueryFormat, SQLVariable } from '../types';
+import { bindTemplateVariables } from '../utils/templateVariables';
 
 export abstract class SqlDatasource extends DataSourceWithBackend<SQLQuery, SQLOptions> {
   id: number;
   name: string;
   interval: string;
+  bindVariables: boolean;
   db: DB;
   annotations = {};
 
@@ -43,6 +45,7 @@ export abstract class SqlDatasource extends DataSourceWithBackend<SQLQuery, SQLO
     this.id = instanceSettings.id;
     const settingsData = instanceSettings.jsonData || {};
     this.interval = settingsData.timeInterval || '1m';
+    this.bindVariables = settingsData.bindVariables ?? false;
     this.db = this.getDB();
   }
 
@@ -97,14 +100,20 @@ export abstract class SqlDatasource extends DataSourceWithBackend<SQLQuery, SQLO
   applyTemplateVariables(
     target: SQLQuery,
     scopedVars: ScopedVars
-  ): Record<string, string | DataSourceRef | SQLQuery['format']> {
-    const queryModel = this.getQueryModel(target, this.templateSrv, scopedVars);
-    const rawSql = this.clean(queryModel.interpolate());
+  ): Record<string, string | DataSourceRef | SQLQuery['format'] | SQLVariable[]> {
+    const { rawSql, variables } = bindTemplateVariables(
+      target.rawSql ?? '',
+      this.templateSrv,
+      scopedVars,
+      (sql) => this.clean(this.getQueryModel({ ...target, rawSql: sql }, this.templateSrv, scopedVars).interpolate()),
+      this.bindVariables
+    );
     return {
       refId: target.refId,
       datasource: this.getRef(),
       rawSql,
       format: target.format,
+      variables,
     };
   }
 
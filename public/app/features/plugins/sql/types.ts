@@ -27,6 +27,14 @@ export interface SqlQueryForInterpolation {
   hide?: boolean;
 }
 
+/**
+ * Template variable sent with a query and bound as a query parameter by the backend.
+ */
+export interface SQLVariable {
+  name: string;
+  values: string[];
+}
+
 export interface SQLConnectionLimits {
   maxOpenConns: number;
   maxIdleConns: number;
@@ -42,6 +50,7 @@ export interface SQLOptions extends SQLConnectionLimits, DataSourceJsonData {
   database: string;
   url: string;
   timeInterval: string;
+  bindVariables?: boolean;
 }
 
 export enum QueryFormat {
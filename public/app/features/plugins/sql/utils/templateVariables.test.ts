@@ -0,0 +1,54 @@
+import { TemplateSrv } from 'app/features/templating/template_srv';
+
+import { bindTemplateVariables } from './templateVariables';
+
+describe('bindTemplateVariables', () => {
+  const variables = [
+    { type: 'query', name: 'host', multi: true, current: { value: ['a', 'b'] } },
+    { type: 'custom', name: 'table', current: { value: 'metrics' } },
+    { type: 'interval', name: 'summarize', current: { value: '1m' } },
+  ];
+  const templateSrv = new TemplateSrv({
+    getVariables: () => variables as any,
+    getVariableWithName: jest.fn(),
+    getFilteredVariables: jest.fn(),
+  });
+  templateSrv.init(variables);
+
+  const bind = (sql: string, scopedVars = {}) =>
+    bindTemplateVariables(sql, templateSrv, scopedVars, (masked) => templateSrv.replace(masked, scopedVars));
+
+  it('keeps references to dashboard variables and returns their values', () => {
+    const result = bind("SELECT * FROM t WHERE host IN ($host) AND h = '[[host]]' AND h2 = ${host:csv}");
+
+    expect(result.rawSql).toBe("SELECT * FROM t WHERE host IN ($host) AND h = '[[host]]' AND h2 = ${host:csv}");
+    expect(result.variables).toEqual([{ name: 'host', values: ['a', 'b'] }]);
+  });
+
+  it('interpolates raw references, macro arguments and other variable types', () => {
+    const result = bind('SELECT $__timeGroup(time, $summarize), $__unixEpochFilter(${table}.time) FROM ${table:raw}');
+
+    expect(result.rawSql).toBe('SELECT $__timeGroup(time, 1m), $__unixEpochFilter(metrics.time) FROM metrics');
+    expect(result.variables).toEqual([]);
+  });
+
+  it('takes values from scoped variables', () => {
+    const result = bind('SELECT * FROM t WHERE host = $host', { host: { text: 'c', value: 'c' } });
+
+    expect(result.rawSql).toBe('SELECT * FROM t WHERE host = $host');
+    expect(result.variables).toEqual([{ name: 'host', values: ['c'] }]);
+  });
+
+  it('interpolates all references when binding is disabled', () => {
+    const result = bindTemplateVariables(
+      'SELECT * FROM $table WHERE host = $host LIMIT $host',
+      templateSrv,
+      {},
+      (sql) => templateSrv.replace(sql, {}),
+      false
+    );
+
+    expect(result.rawSql).toBe('SELECT * FROM metrics WHERE host = {a,b} LIMIT {a,b}');
+    expect(result.variables).toEqual([]);
+  });
+});
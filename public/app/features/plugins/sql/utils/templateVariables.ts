@@ -0,0 +1,72 @@
+import { ScopedVars } from '@grafana/data';
+import { TemplateSrv } from '@grafana/runtime';
+
+import { SQLVariable } from '../types';
+
+// Same syntaxes as the variable references the backend binds: $name, ${name:format} and [[name:format]].
+const variableRefRegExp = /\$(\w+)|\$\{(\w+)(?::([^}]*))?\}|\[\[(\w+)(?::([^\]]*))?\]\]/g;
+const macroCallRegExp = /\$__\w+\([^)]*\)/g;
+const tokenRegExp = /\u0000(\d+)\u0000/g;
+
+// Variables of other types, like intervals, are interpolated into the SQL.
+const boundVariableTypes = ['query', 'custom', 'textbox', 'constant'];
+
+/**
+ * Keeps references to dashboard variables in the SQL and returns their values, so that the backend binds
+ * them as query parameters instead of splicing values into the SQL. Other references are interpolated with
+ * the interpolate function. References inside macro arguments and ones with the raw format, like
+ * ${table:raw}, are interpolated too, since macros are expanded before parameters are bound and a
+ * parameter can't be used as an identifier. Binding is enabled per data source with the bindVariables
+ * option, without it every reference is interpolated.
+ */
+export function bindTemplateVariables(
+  sql: string,
+  templateSrv: TemplateSrv,
+  scopedVars: ScopedVars,
+  interpolate: (sql: string) => string,
+  enabled = true
+): { rawSql: string; variables: SQLVariable[] } {
+  if (!enabled) {
+    return { rawSql: interpolate(sql), variables: [] };
+  }
+
+  const bindable = new Set(
+    templateSrv
+      .getVariables()
+      .filter((v) => boundVariableTypes.includes(v.type))
+      .map((v) => v.name)
+  );
+  const macroCalls = Array.from(sql.matchAll(macroCallRegExp), (m) => [m.index!, m.index! + m[0].length]);
+
+  const refs: string[] = [];
+  const names = new Set<string>();
+  const masked = sql.replace(
+    variableRefRegExp,
+    (match: string, name1?: string, name2?: string, format2?: string, name3?: string, format3?: string, offset = 0) => {
+      const name = name1 || name2 || name3 || '';
+      const format = format2 || format3;
+      const inMacroCall = macroCalls.some(([start, end]) => offset >= start && offset < end);
+      if (!bindable.has(name) || format === 'raw' || inMacroCall) {
+        return match;
+      }
+      names.add(name);
+      refs.push(match);
+      return `\u0000${refs.length - 1}\u0000`;
+    }
+  );
+
+  const rawSql = interpolate(masked).replace(tokenRegExp, (_: string, i: string) => refs[Number(i)]);
+  const variables = Array.from(names, (name) => ({ name, values: variableValues(templateSrv, name, scopedVars) }));
+  return { rawSql, variables };
+}
+
+function variableValues(templateSrv: TemplateSrv, name: string, scopedVars: ScopedVars): string[] {
+  const value = templateSrv.replace(`\${${name}:json}`, scopedVars);
+  try {
+    const parsed = JSON.parse(value);
+    return (Array.isArray(parsed) ? parsed : [parsed]).map(String);
+  } catch {
+    // custom all values are not formatted
+    return [value];
+  }
+}
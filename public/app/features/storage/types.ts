@@ -40,6 +40,7 @@ export interface StorageConfig {
     root: string;
     requirePullRequest: boolean;
     accessToken: string;
+    branchUrl?: string;
   };
   sql?: {};
 }
@@ -48,6 +49,7 @@ export enum WorkflowID {
   Save = 'save',
   PR = 'pr',
   Push = 'push',
+  Branch = 'branch',
 }
 
 export interface WriteValueRequest {
@@ -33,7 +33,7 @@ const excludeCurrentKey = (dimensions: Dimensions, currentKey: string | undefine
 
 export const FilterItem: FunctionComponent<Props> = ({
   filter,
-  metricStat: { region, namespace, metricName, dimensions },
+  metricStat: { region, namespace, metricName, dimensions, accountId },
   datasource,
   dimensionKeys,
   disableExpressions,
@@ -51,7 +51,7 @@ export const FilterItem: FunctionComponent<Props> = ({
     }
 
     return datasource
-      .getDimensionValues(region, namespace, metricName, filter.key, dimensionsExcludingCurrentKey)
+      .getDimensionValues(region, namespace, metricName, filter.key, dimensionsExcludingCurrentKey, accountId)
       .then((result: Array<SelectableValue<string>>) => {
         if (result.length && !disableExpressions) {
           result.unshift(wildcardOption);
@@ -11,6 +11,7 @@ const ds = setupMockedDataSource({
   variables: [],
 });
 
+ds.datasource.getAccounts = jest.fn().mockResolvedValue([]);
 ds.datasource.getNamespaces = jest.fn().mockResolvedValue([]);
 ds.datasource.getMetrics = jest.fn().mockResolvedValue([]);
 ds.datasource.getDimensionKeys = jest.fn().mockResolvedValue([]);
@@ -103,6 +104,31 @@ describe('MetricStatEditor', () => {
     });
   });
 
+  describe('account field', () => {
+    it('should not be shown outside of a monitoring account', async () => {
+      await act(async () => {
+        render(<MetricStatEditor {...props} />);
+      });
+      expect(screen.queryByLabelText('Account')).not.toBeInTheDocument();
+    });
+
+    it('should set the source account of the metric', async () => {
+      const onChange = jest.fn();
+      props.datasource.getAccounts = jest.fn().mockResolvedValue([
+        { label: '123456789012 (monitoring account)', value: '123456789012', text: '123456789012' },
+      ]);
+
+      await act(async () => {
+        render(<MetricStatEditor {...props} onChange={onChange} />);
+      });
+      const accountSelect = screen.getByLabelText('Account');
+      await selectEvent.select(accountSelect, '123456789012 (monitoring account)', { container: document.body });
+
+      expect(onChange).toHaveBeenCalledWith({ ...props.metricStat, accountId: '123456789012' });
+      props.datasource.getAccounts = jest.fn().mockResolvedValue([]);
+    });
+  });
+
   describe('validating Query namespace / metricName', () => {
     const namespaces = [
       { value: 'n1', label: 'n1', text: 'n1' },
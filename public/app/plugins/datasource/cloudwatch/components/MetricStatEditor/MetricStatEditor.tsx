@@ -5,10 +5,13 @@ import { EditorField, EditorFieldGroup, EditorRow, EditorRows, EditorSwitch, Sel
 
 import { Dimensions } from '..';
 import { CloudWatchDatasource } from '../../datasource';
-import { useDimensionKeys, useMetrics, useNamespaces } from '../../hooks';
+import { useAccounts, useDimensionKeys, useMetrics, useNamespaces } from '../../hooks';
 import { MetricStat } from '../../types';
 import { appendTemplateVariables, toOption } from '../../utils/utils';
 
+// the account ID the backend reads as every source account
+const ALL_ACCOUNTS = 'all';
+
 export type Props = {
   refId: string;
   metricStat: MetricStat;
@@ -26,10 +29,11 @@ export function MetricStatEditor({
   onChange,
   onRunQuery,
 }: React.PropsWithChildren<Props>) {
-  const { region, namespace, metricName, dimensions } = metricStat;
+  const { region, namespace, metricName, dimensions, accountId } = metricStat;
+  const accounts = useAccounts(datasource, region);
   const namespaces = useNamespaces(datasource);
-  const metrics = useMetrics(datasource, region, namespace);
-  const dimensionKeys = useDimensionKeys(datasource, region, namespace, metricName, dimensions ?? {});
+  const metrics = useMetrics(datasource, region, namespace, accountId);
+  const dimensionKeys = useDimensionKeys(datasource, region, namespace, metricName, dimensions ?? {}, accountId);
 
   const onMetricStatChange = (metricStat: MetricStat) => {
     onChange(metricStat);
@@ -42,11 +46,11 @@ export function MetricStatEditor({
   };
 
   const validateMetricName = async (metricStat: MetricStat) => {
-    let { metricName, namespace, region } = metricStat;
+    let { metricName, namespace, region, accountId } = metricStat;
     if (!metricName) {
       return metricStat;
     }
-    await datasource.getMetrics(namespace, region).then((result: Array<SelectableValue<string>>) => {
+    await datasource.getMetrics(namespace, region, accountId).then((result: Array<SelectableValue<string>>) => {
       if (!result.find((metric) => metric.value === metricName)) {
         metricName = '';
       }
@@ -58,6 +62,24 @@ export function MetricStatEditor({
     <EditorRows>
       <EditorRow>
         <EditorFieldGroup>
+          {accounts.length > 0 && (
+            <EditorField
+              label="Account"
+              width={26}
+              tooltip="The source account of the metric with cross-account observability, All for every linked account."
+            >
+              <Select
+                aria-label="Account"
+                value={accountId ?? ALL_ACCOUNTS}
+                options={appendTemplateVariables(datasource, [{ label: 'All', value: ALL_ACCOUNTS }, ...accounts])}
+                onChange={({ value: accountId }) => {
+                  if (accountId) {
+                    onMetricStatChange({ ...metricStat, accountId });
+                  }
+                }}
+              />
+            </EditorField>
+          )}
           <EditorField label="Namespace" width={26}>
             <Select
               aria-label="Namespace"
@@ -711,11 +711,17 @@ export class CloudWatchDatasource
     ]);
   }
 
+  getAccounts(region: string): Promise<Array<{ label: string; value: string; text: string }>> {
+    return this.doMetricResourceRequest('accounts', {
+      region: this.templateSrv.replace(this.getActualRegion(region)),
+    });
+  }
+
   getNamespaces() {
     return this.doMetricResourceRequest('namespaces');
   }
 
-  async getMetrics(namespace: string | undefined, region?: string) {
+  async getMetrics(namespace: string | undefined, region?: string, accountId?: string) {
     if (!namespace) {
       return [];
     }
@@ -723,6 +729,7 @@ export class CloudWatchDatasource
     return this.doMetricResourceRequest('metrics', {
       region: this.templateSrv.replace(this.getActualRegion(region)),
       namespace: this.templateSrv.replace(namespace),
+      accountId: accountId && this.templateSrv.replace(accountId),
     });
   }
 
@@ -738,7 +745,8 @@ export class CloudWatchDatasource
     namespace: string | undefined,
     region: string,
     dimensionFilters: Dimensions = {},
-    metricName = ''
+    metricName = '',
+    accountId?: string
   ) {
     if (!namespace) {
       return [];
@@ -749,6 +757,7 @@ export class CloudWatchDatasource
       namespace: this.templateSrv.replace(namespace),
       dimensionFilters: JSON.stringify(this.convertDimensionFormat(dimensionFilters, {})),
       metricName,
+      accountId: accountId && this.templateSrv.replace(accountId),
     });
   }
 
@@ -757,7 +766,8 @@ export class CloudWatchDatasource
     namespace: string | undefined,
     metricName: string | undefined,
     dimensionKey: string,
-    filterDimensions: {}
+    filterDimensions: {},
+    accountId?: string
   ) {
     if (!namespace || !metricName) {
       return [];
@@ -769,6 +779,7 @@ export class CloudWatchDatasource
       metricName: this.templateSrv.replace(metricName.trim()),
       dimensionKey: this.templateSrv.replace(dimensionKey),
       dimensions: JSON.stringify(this.convertDimensionFormat(filterDimensions, {})),
+      accountId: accountId && this.templateSrv.replace(accountId),
     });
 
     return values;
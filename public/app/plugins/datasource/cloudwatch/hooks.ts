@@ -39,13 +39,32 @@ export const useNamespaces = (datasource: CloudWatchDatasource) => {
   return namespaces;
 };
 
-export const useMetrics = (datasource: CloudWatchDatasource, region: string, namespace: string | undefined) => {
+// useAccounts returns the source accounts of the region when it belongs to a cross-account observability
+// monitoring account, and an empty list otherwise.
+export const useAccounts = (datasource: CloudWatchDatasource, region: string) => {
+  const [accounts, setAccounts] = useState<Array<SelectableValue<string>>>([]);
+  useEffect(() => {
+    datasource
+      .getAccounts(region)
+      .then((result: Array<SelectableValue<string>>) => setAccounts(result))
+      .catch(() => setAccounts([]));
+  }, [datasource, region]);
+
+  return accounts;
+};
+
+export const useMetrics = (
+  datasource: CloudWatchDatasource,
+  region: string,
+  namespace: string | undefined,
+  accountId?: string
+) => {
   const [metrics, setMetrics] = useState<Array<SelectableValue<string>>>([]);
   useEffect(() => {
-    datasource.getMetrics(namespace, region).then((result: Array<SelectableValue<string>>) => {
+    datasource.getMetrics(namespace, region, accountId).then((result: Array<SelectableValue<string>>) => {
       setMetrics(appendTemplateVariables(datasource, result));
     });
-  }, [datasource, region, namespace]);
+  }, [datasource, region, namespace, accountId]);
 
   return metrics;
 };
@@ -55,18 +74,19 @@ export const useDimensionKeys = (
   region: string,
   namespace: string | undefined,
   metricName: string | undefined,
-  dimensionFilter?: Dimensions
+  dimensionFilter?: Dimensions,
+  accountId?: string
 ) => {
   const [dimensionKeys, setDimensionKeys] = useState<Array<SelectableValue<string>>>([]);
 
   // doing deep comparison to avoid making new api calls to list metrics unless dimension filter object props changes
   useDeepCompareEffect(() => {
     datasource
-      .getDimensionKeys(namespace, region, dimensionFilter, metricName)
+      .getDimensionKeys(namespace, region, dimensionFilter, metricName, accountId)
       .then((result: Array<SelectableValue<string>>) => {
         setDimensionKeys(appendTemplateVariables(datasource, result));
       });
-  }, [datasource, region, namespace, metricName, dimensionFilter]);
+  }, [datasource, region, namespace, metricName, dimensionFilter, accountId]);
 
   return dimensionKeys;
 };
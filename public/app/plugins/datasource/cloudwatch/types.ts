@@ -64,6 +64,10 @@ export interface MetricStat {
   dimensions?: Dimensions;
   matchExact?: boolean;
   period?: string;
+  /**
+   * The source account of the metric when using cross-account observability, `all` for every linked account
+   */
+  accountId?: string;
   statistic?: string;
   /**
    * @deprecated use statistic
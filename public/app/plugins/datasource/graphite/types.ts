@@ -15,6 +15,8 @@ export interface GraphiteQuery extends DataQuery {
   textEditor?: boolean;
   target?: string;
   tags?: string[];
+  /** Tag expressions the backend combines into a seriesByTag target, used when there is no target */
+  seriesByTag?: string[];
   fromAnnotations?: boolean;
 }
 
@@ -32,6 +32,11 @@ const versions = [
     value: InfluxVersion.Flux,
     description: 'Advanced data scripting and query language.  Supported in InfluxDB 2.x and 1.8+',
   },
+  {
+    label: 'SQL',
+    value: InfluxVersion.SQL,
+    description: 'Native SQL over Flight SQL.  Supported in InfluxDB 3.x',
+  },
 ] as Array<SelectableValue<InfluxVersion>>;
 
 export type Props = DataSourcePluginOptionsEditorProps<InfluxOptions>;
@@ -81,6 +86,12 @@ export class ConfigEditor extends PureComponent<Props, State> {
       delete copy.user;
       delete copy.database;
     }
+    if (selected.value === InfluxVersion.SQL) {
+      copy.access = 'proxy';
+
+      // SQL queries use a token and a database
+      delete copy.user;
+    }
 
     onOptionsChange(copy);
   };
@@ -159,6 +170,66 @@ export class ConfigEditor extends PureComponent<Props, State> {
     );
   }
 
+  renderInfluxSQL() {
+    const { options } = this.props;
+    const { secureJsonFields } = options;
+    const secureJsonData = (options.secureJsonData || {}) as InfluxSecureJsonData;
+    const { htmlPrefix } = this;
+
+    return (
+      <>
+        <div className="gf-form-inline">
+          <div className="gf-form">
+            <InlineFormLabel htmlFor={`${htmlPrefix}-sql-db`} className="width-10">
+              Database
+            </InlineFormLabel>
+            <div className="width-20">
+              <Input
+                id={`${htmlPrefix}-sql-db`}
+                className="width-20"
+                value={options.database || ''}
+                onChange={onUpdateDatasourceOption(this.props, 'database')}
+              />
+            </div>
+          </div>
+        </div>
+        <div className="gf-form-inline">
+          <div className="gf-form">
+            <SecretFormField
+              isConfigured={(secureJsonFields && secureJsonFields.token) as boolean}
+              value={secureJsonData.token || ''}
+              label="Token"
+              aria-label="Token"
+              labelWidth={10}
+              inputWidth={20}
+              onReset={this.onResetToken}
+              onChange={onUpdateDatasourceSecureJsonDataOption(this.props, 'token')}
+            />
+          </div>
+        </div>
+        <div className="gf-form-inline">
+          <div className="gf-form">
+            <InlineFormLabel
+              className="width-10"
+              tooltip="A lower limit for the auto group by time interval. Recommended to be set to write frequency,
+				for example 1m if your data is written every minute."
+            >
+              Min time interval
+            </InlineFormLabel>
+            <div className="width-10">
+              <Input
+                className="width-10"
+                placeholder="10s"
+                value={options.jsonData.timeInterval || ''}
+                onChange={onUpdateDatasourceJsonDataOption(this.props, 'timeInterval')}
+              />
+            </div>
+          </div>
+        </div>
+      </>
+    );
+  }
+
   renderInflux1x() {
     const { options } = this.props;
     const { secureJsonFields } = options;
@@ -280,7 +351,7 @@ export class ConfigEditor extends PureComponent<Props, State> {
               <Select
                 aria-label="Query language"
                 className="width-30"
-                value={options.jsonData.version === InfluxVersion.Flux ? versions[1] : versions[0]}
+                value={versions.find((v) => v.value === options.jsonData.version) ?? versions[0]}
                 options={versions}
                 defaultValue={versions[0]}
                 onChange={this.onVersionChanged}
@@ -318,7 +389,11 @@ export class ConfigEditor extends PureComponent<Props, State> {
           <div>
             <h3 className="page-heading">InfluxDB Details</h3>
           </div>
-          {options.jsonData.version === InfluxVersion.Flux ? this.renderInflux2x() : this.renderInflux1x()}
+          {options.jsonData.version === InfluxVersion.Flux && this.renderInflux2x()}
+          {options.jsonData.version === InfluxVersion.SQL && this.renderInfluxSQL()}
+          {options.jsonData.version !== InfluxVersion.Flux &&
+            options.jsonData.version !== InfluxVersion.SQL &&
+            this.renderInflux1x()}
           <div className="gf-form-inline">
             <InlineField
               labelWidth={20}
@@ -10,6 +10,7 @@ import { InfluxOptions, InfluxQuery } from '../types';
 import { FluxQueryEditor } from './FluxQueryEditor';
 import { QueryEditorModeSwitcher } from './QueryEditorModeSwitcher';
 import { RawInfluxQLEditor } from './RawInfluxQLEditor';
+import { SQLQueryEditor } from './SQLQueryEditor';
 import { Editor as VisualInfluxQLEditor } from './VisualInfluxQLEditor/Editor';
 
 type Props = QueryEditorProps<InfluxDatasource, InfluxQuery, InfluxOptions>;
@@ -23,6 +24,14 @@ export const QueryEditor = ({ query, onChange, onRunQuery, datasource, range, da
     );
   }
 
+  if (datasource.isSql) {
+    return (
+      <div className="gf-form-query-content">
+        <SQLQueryEditor query={query} onChange={onChange} onRunQuery={onRunQuery} />
+      </div>
+    );
+  }
+
   return (
     <div className={css({ display: 'flex' })}>
       <div className={css({ flexGrow: 1 })}>
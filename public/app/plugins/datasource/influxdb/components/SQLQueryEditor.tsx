@@ -0,0 +1,60 @@
+import React from 'react';
+
+import { TextArea, InlineFormLabel, Select, HorizontalGroup } from '@grafana/ui';
+
+import { InfluxQuery } from '../types';
+
+import { SQL_RESULT_FORMATS, DEFAULT_RESULT_FORMAT } from './constants';
+import { useShadowedState } from './useShadowedState';
+import { useUniqueId } from './useUniqueId';
+
+type Props = {
+  query: InfluxQuery;
+  onChange: (query: InfluxQuery) => void;
+  onRunQuery: () => void;
+};
+
+// "query" changes only happen on onblur, "resultFormat" changes are applied immediately
+export const SQLQueryEditor = ({ query, onChange, onRunQuery }: Props): JSX.Element => {
+  const [currentQuery, setCurrentQuery] = useShadowedState(query.query);
+  const selectElementId = useUniqueId();
+
+  const resultFormat = query.resultFormat ?? DEFAULT_RESULT_FORMAT;
+
+  const applyDelayedChangesAndRunQuery = () => {
+    onChange({
+      ...query,
+      query: currentQuery,
+      resultFormat,
+    });
+    onRunQuery();
+  };
+
+  return (
+    <div>
+      <TextArea
+        aria-label="query"
+        rows={3}
+        spellCheck={false}
+        placeholder="SELECT time, host, usage FROM cpu WHERE $__timeFilter(time) ORDER BY time"
+        onBlur={applyDelayedChangesAndRunQuery}
+        onChange={(e) => {
+          setCurrentQuery(e.currentTarget.value);
+        }}
+        value={currentQuery ?? ''}
+      />
+      <HorizontalGroup>
+        <InlineFormLabel htmlFor={selectElementId}>Format as</InlineFormLabel>
+        <Select
+          inputId={selectElementId}
+          onChange={(v) => {
+            onChange({ ...query, resultFormat: v.value });
+            onRunQuery();
+          }}
+          value={resultFormat}
+          options={SQL_RESULT_FORMATS}
+        />
+      </HorizontalGroup>
+    </div>
+  );
+};
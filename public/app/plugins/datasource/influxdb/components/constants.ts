@@ -8,4 +8,7 @@ export const RESULT_FORMATS: Array<SelectableValue<ResultFormat>> = [
   { label: 'Logs', value: 'logs' },
 ];
 
+// SQL results are either converted to time series or returned as they are
+export const SQL_RESULT_FORMATS: Array<SelectableValue<ResultFormat>> = RESULT_FORMATS.filter((f) => f.value !== 'logs');
+
 export const DEFAULT_RESULT_FORMAT: ResultFormat = 'time_series';
@@ -31,6 +31,7 @@ import config from 'app/core/config';
 import { getTemplateSrv, TemplateSrv } from 'app/features/templating/template_srv';
 
 import { FluxQueryEditor } from './components/FluxQueryEditor';
+import { SQLQueryEditor } from './components/SQLQueryEditor';
 import InfluxQueryModel from './influx_query_model';
 import InfluxSeries from './influx_series';
 import { buildRawQuery } from './queryUtils';
@@ -123,6 +124,7 @@ export default class InfluxDatasource extends DataSourceWithBackend<InfluxQuery,
   responseParser: any;
   httpMode: string;
   isFlux: boolean;
+  isSql: boolean;
 
   constructor(
     instanceSettings: DataSourceInstanceSettings<InfluxOptions>,
@@ -147,11 +149,14 @@ export default class InfluxDatasource extends DataSourceWithBackend<InfluxQuery,
     this.httpMode = settingsData.httpMode || 'GET';
     this.responseParser = new ResponseParser();
     this.isFlux = settingsData.version === InfluxVersion.Flux;
+    this.isSql = settingsData.version === InfluxVersion.SQL;
 
-    if (this.isFlux) {
-      // When flux, use an annotation processor rather than the `annotationQuery` lifecycle
+    if (this.isFlux || this.isSql) {
+      // When flux or sql, use an annotation processor rather than the `annotationQuery` lifecycle.
+      // The backend returns the rows as annotation events for annotation queries.
       this.annotations = {
-        QueryEditor: FluxQueryEditor,
+        QueryEditor: this.isFlux ? FluxQueryEditor : SQLQueryEditor,
+        prepareQuery: (anno) => (anno.target ? { ...anno.target, queryType: 'annotation' } : undefined),
       };
     }
   }
@@ -165,7 +170,7 @@ export default class InfluxDatasource extends DataSourceWithBackend<InfluxQuery,
       targets: request.targets.filter((t) => t.hide !== true),
     };
 
-    if (this.isFlux) {
+    if (this.isFlux || this.isSql) {
       return super.query(filteredRequest);
     }
 
@@ -214,7 +219,7 @@ export default class InfluxDatasource extends DataSourceWithBackend<InfluxQuery,
   }
 
   getQueryDisplayText(query: InfluxQuery) {
-    if (this.isFlux) {
+    if (this.isFlux || this.isSql) {
       return query.query;
     }
     return new InfluxQueryModel(query).render(false);
@@ -224,7 +229,7 @@ export default class InfluxDatasource extends DataSourceWithBackend<InfluxQuery,
    * Returns false if the query should be skipped
    */
   filterQuery(query: InfluxQuery): boolean {
-    if (this.isFlux) {
+    if (this.isFlux || this.isSql) {
       return !!query.query;
     }
     return true;
@@ -234,7 +239,7 @@ export default class InfluxDatasource extends DataSourceWithBackend<InfluxQuery,
     // We want to interpolate these variables on backend
     const { __interval, __interval_ms, ...rest } = scopedVars;
 
-    if (this.isFlux) {
+    if (this.isFlux || this.isSql) {
       return {
         ...query,
         query: this.templateSrv.replace(query.query ?? '', rest), // The raw query text
@@ -347,9 +352,9 @@ export default class InfluxDatasource extends DataSourceWithBackend<InfluxQuery,
   }
 
   async annotationQuery(options: AnnotationQueryRequest<any>): Promise<AnnotationEvent[]> {
-    if (this.isFlux) {
+    if (this.isFlux || this.isSql) {
       return Promise.reject({
-        message: 'Flux requires the standard annotation query',
+        message: 'Flux and SQL require the standard annotation query',
       });
     }
 
@@ -408,7 +413,7 @@ export default class InfluxDatasource extends DataSourceWithBackend<InfluxQuery,
   targetContainsTemplate(target: any) {
     // for flux-mode we just take target.query,
     // for influxql-mode we use InfluxQueryModel to create the text-representation
-    const queryText = this.isFlux ? target.query : buildRawQuery(target);
+    const queryText = this.isFlux || this.isSql ? target.query : buildRawQuery(target);
 
     return this.templateSrv.containsTemplate(queryText);
   }
@@ -419,7 +424,7 @@ export default class InfluxDatasource extends DataSourceWithBackend<InfluxQuery,
     }
 
     return queries.map((query) => {
-      if (this.isFlux) {
+      if (this.isFlux || this.isSql) {
         return {
           ...query,
           datasource: this.getRef(),
@@ -483,9 +488,10 @@ export default class InfluxDatasource extends DataSourceWithBackend<InfluxQuery,
   }
 
   async metricFindQuery(query: string, options?: any): Promise<MetricFindValue[]> {
-    if (this.isFlux || this.isMigrationToggleOnAndIsAccessProxy()) {
+    if (this.isFlux || this.isSql || this.isMigrationToggleOnAndIsAccessProxy()) {
       const target: InfluxQuery = {
         refId: 'metricFindQuery',
+        queryType: 'metricFind',
         query,
         rawQuery: true,
       };
@@ -3,6 +3,7 @@ import { DataQuery, DataSourceJsonData } from '@grafana/data';
 export enum InfluxVersion {
   InfluxQL = 'InfluxQL',
   Flux = 'Flux',
+  SQL = 'SQL',
 }
 
 export interface InfluxOptions extends DataSourceJsonData {
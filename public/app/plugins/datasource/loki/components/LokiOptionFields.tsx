@@ -26,6 +26,11 @@ export const queryTypeOptions: Array<SelectableValue<LokiQueryType>> = [
     label: 'Instant',
     description: 'Run query against a single point in time. For this query, the "To" time is used.',
   },
+  {
+    value: LokiQueryType.Volume,
+    label: 'Volume',
+    description: 'Count the log lines of the query per level, over a range of time.',
+  },
 ];
 
 if (config.featureToggles.lokiLive) {
@@ -8,6 +8,7 @@ import { LokiOptions } from '../types';
 
 import { DerivedFields } from './DerivedFields';
 import { MaxLinesField } from './MaxLinesField';
+import { QueryShardDurationField } from './QueryShardDurationField';
 
 export type Props = DataSourcePluginOptionsEditorProps<LokiOptions>;
 
@@ -25,6 +26,7 @@ const makeJsonUpdater =
 
 const setMaxLines = makeJsonUpdater('maxLines');
 const setDerivedFields = makeJsonUpdater('derivedFields');
+const setQueryShardDuration = makeJsonUpdater('queryShardDuration');
 
 export const ConfigEditor = (props: Props) => {
   const { options, onOptionsChange } = props;
@@ -54,6 +56,14 @@ export const ConfigEditor = (props: Props) => {
             />
           </div>
         </div>
+        <div className="gf-form-inline">
+          <div className="gf-form">
+            <QueryShardDurationField
+              value={options.jsonData.queryShardDuration || ''}
+              onChange={(value) => onOptionsChange(setQueryShardDuration(options, value))}
+            />
+          </div>
+        </div>
       </div>
 
       <DerivedFields
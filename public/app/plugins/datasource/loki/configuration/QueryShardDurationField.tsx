@@ -0,0 +1,37 @@
+import React from 'react';
+
+import { LegacyForms } from '@grafana/ui';
+const { FormField } = LegacyForms;
+
+type Props = {
+  value: string;
+  onChange: (value: string) => void;
+};
+
+export const QueryShardDurationField = (props: Props) => {
+  const { value, onChange } = props;
+  return (
+    <FormField
+      label="Query shard duration"
+      labelWidth={11}
+      inputWidth={20}
+      inputEl={
+        <input
+          type="text"
+          className="gf-form-input width-8 gf-form-input--has-help-icon"
+          value={value}
+          onChange={(event) => onChange(event.currentTarget.value)}
+          spellCheck={false}
+          placeholder="1d"
+        />
+      }
+      tooltip={
+        <>
+          Range queries run by the Grafana backend that cover more than this duration, for example 1d, are split into
+          shards of this duration. Up to four shards run at the same time and their results are merged. Leave empty to
+          send every query as a whole.
+        </>
+      }
+    />
+  );
+};
@@ -28,6 +28,7 @@ export enum LokiQueryType {
   Range = 'range',
   Instant = 'instant',
   Stream = 'stream',
+  Volume = 'volume',
 }
 
 export enum LokiQueryDirection {
@@ -56,6 +57,7 @@ export interface LokiOptions extends DataSourceJsonData {
   derivedFields?: DerivedFieldConfig[];
   alertmanager?: string;
   keepCookies?: string[];
+  queryShardDuration?: string;
 }
 
 export interface LokiStats {
@@ -44,6 +44,10 @@ export const ConfigurationEditor = (props: DataSourcePluginOptionsEditorProps<Ms
     updateDatasourcePluginJsonDataOption(props, 'tlsSkipVerify', event.currentTarget.checked);
   };
 
+  const onBindVariablesChanged = (event: SyntheticEvent<HTMLInputElement>) => {
+    updateDatasourcePluginJsonDataOption(props, 'bindVariables', event.currentTarget.checked);
+  };
+
   const onEncryptChanged = (value: SelectableValue) => {
     updateDatasourcePluginJsonDataOption(props, 'encrypt', value.value);
   };
@@ -240,6 +244,23 @@ export const ConfigurationEditor = (props: DataSourcePluginOptionsEditorProps<Ms
             onChange={onUpdateDatasourceJsonDataOption(props, 'timeInterval')}
           ></Input>
         </InlineField>
+        <InlineField
+          tooltip={
+            <span>
+              Send the values of dashboard variables to the database as bound query parameters instead of interpolating
+              them into the SQL. Variables used as identifiers or in <code>LIMIT</code> need the raw format, for example
+              <code>{'${table:raw}'}</code>.
+            </span>
+          }
+          htmlFor="bindVariables"
+          label="Bind variables"
+        >
+          <InlineSwitch
+            id="bindVariables"
+            onChange={onBindVariablesChanged}
+            value={jsonData.bindVariables || false}
+          ></InlineSwitch>
+        </InlineField>
       </FieldSet>
 
       <Alert title="User Permission" severity="info">
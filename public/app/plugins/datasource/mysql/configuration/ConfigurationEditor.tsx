@@ -163,6 +163,41 @@ export const ConfigurationEditor = (props: DataSourcePluginOptionsEditorProps<My
             onChange={onUpdateDatasourceJsonDataOption(props, 'timeInterval')}
           ></Input>
         </InlineField>
+        <InlineField
+          tooltip={
+            <span>
+              Allow several statements separated by semicolons in one query, each result set is returned as a frame.
+              Off by default, since it lets a query run statements after the one it was written for.
+            </span>
+          }
+          labelWidth={mediumWidth}
+          htmlFor="multiStatements"
+          label="Multiple statements"
+        >
+          <InlineSwitch
+            id="multiStatements"
+            onChange={onSwitchChanged('multiStatements')}
+            value={jsonData.multiStatements || false}
+          ></InlineSwitch>
+        </InlineField>
+        <InlineField
+          tooltip={
+            <span>
+              Send the values of dashboard variables to the database as bound query parameters instead of interpolating
+              them into the SQL. Variables used as identifiers or in <code>LIMIT</code> need the raw format, for example
+              <code>{'${table:raw}'}</code>.
+            </span>
+          }
+          labelWidth={mediumWidth}
+          htmlFor="bindVariables"
+          label="Bind variables"
+        >
+          <InlineSwitch
+            id="bindVariables"
+            onChange={onSwitchChanged('bindVariables')}
+            value={jsonData.bindVariables || false}
+          ></InlineSwitch>
+        </InlineField>
       </FieldSet>
 
       <Alert title="User Permission" severity="info">
@@ -8,6 +8,8 @@ export interface MysqlQueryForInterpolation {
   hide?: any;
 }
 
-export interface MySQLOptions extends SQLOptions {}
+export interface MySQLOptions extends SQLOptions {
+  multiStatements?: boolean;
+}
 
 export interface MySQLQuery extends SQLQuery {}
@@ -224,7 +224,7 @@ export default class OpenTsDatasource extends DataSourceApi<OpenTsdbQuery, OpenT
   }
 
   _performSuggestQuery(query: string, type: string): Observable<any> {
-    return this._get('/api/suggest', { type, q: query, max: this.lookupLimit }).pipe(
+    return this._getResource('suggest', { type, q: query, max: this.lookupLimit }).pipe(
       map((result: any) => {
         return result.data;
       })
@@ -236,28 +236,9 @@ export default class OpenTsDatasource extends DataSourceApi<OpenTsdbQuery, OpenT
       return of([]);
     }
 
-    const keysArray = keys.split(',').map((key: any) => {
-      return key.trim();
-    });
-    const key = keysArray[0];
-    let keysQuery = key + '=*';
-
-    if (keysArray.length > 1) {
-      keysQuery += ',' + keysArray.splice(1).join(',');
-    }
-
-    const m = metric + '{' + keysQuery + '}';
-
-    return this._get('/api/search/lookup', { m: m, limit: this.lookupLimit }).pipe(
+    return this._getResource('tag-values', { metric, keys }).pipe(
       map((result: any) => {
-        result = result.data.results;
-        const tagvs: any[] = [];
-        each(result, (r) => {
-          if (tagvs.indexOf(r.tags[key]) === -1) {
-            tagvs.push(r.tags[key]);
-          }
-        });
-        return tagvs;
+        return result.data;
       })
     );
   }
@@ -267,35 +248,20 @@ export default class OpenTsDatasource extends DataSourceApi<OpenTsdbQuery, OpenT
       return of([]);
     }
 
-    return this._get('/api/search/lookup', { m: metric, limit: 1000 }).pipe(
+    return this._getResource('tag-keys', { metric }).pipe(
       map((result: any) => {
-        result = result.data.results;
-        const tagks: any[] = [];
-        each(result, (r) => {
-          each(r.tags, (tagv, tagk) => {
-            if (tagks.indexOf(tagk) === -1) {
-              tagks.push(tagk);
-            }
-          });
-        });
-        return tagks;
+        return result.data;
       })
     );
   }
 
-  _get(
-    relativeUrl: string,
-    params?: { type?: string; q?: string; max?: number; m?: any; limit?: number }
-  ): Observable<FetchResponse> {
-    const options = {
+  // lookups go through the resources of the backend, which applies the lookup limit and credentials
+  _getResource(path: string, params?: { type?: string; q?: string; max?: number; metric?: string; keys?: string }) {
+    return getBackendSrv().fetch<any>({
       method: 'GET',
-      url: this.url + relativeUrl,
+      url: `/api/datasources/${this.id}/resources/${path}`,
       params: params,
-    };
-
-    this._addCredentialOptions(options);
-
-    return getBackendSrv().fetch(options);
+    });
   }
 
   _addCredentialOptions(options: any) {
@@ -377,7 +343,7 @@ export default class OpenTsDatasource extends DataSourceApi<OpenTsdbQuery, OpenT
     }
 
     this.aggregatorsPromise = lastValueFrom(
-      this._get('/api/aggregators').pipe(
+      this._getResource('aggregators').pipe(
         map((result: any) => {
           if (result.data && isArray(result.data)) {
             return result.data.sort();
@@ -395,10 +361,10 @@ export default class OpenTsDatasource extends DataSourceApi<OpenTsdbQuery, OpenT
     }
 
     this.filterTypesPromise = lastValueFrom(
-      this._get('/api/config/filters').pipe(
+      this._getResource('filter-types').pipe(
         map((result: any) => {
-          if (result.data) {
-            return Object.keys(result.data).sort();
+          if (result.data && isArray(result.data)) {
+            return result.data;
           }
           return [];
         })
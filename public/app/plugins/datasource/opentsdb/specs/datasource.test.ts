@@ -27,7 +27,7 @@ describe('opentsdb', () => {
     const fetchMock = jest.spyOn(backendSrv, 'fetch');
     fetchMock.mockImplementation(() => of(createFetchResponse(data)));
 
-    const instanceSettings = { url: '', jsonData: { tsdbVersion: 1 } };
+    const instanceSettings = { id: 1, url: '', jsonData: { tsdbVersion: 1 } };
     const replace = jest.fn((value) => value);
     const templateSrv: any = {
       replace,
@@ -45,7 +45,7 @@ describe('opentsdb', () => {
       const results = await ds.metricFindQuery('metrics(pew)');
 
       expect(fetchMock).toHaveBeenCalledTimes(1);
-      expect(fetchMock.mock.calls[0][0].url).toBe('/api/suggest');
+      expect(fetchMock.mock.calls[0][0].url).toBe('/api/datasources/1/resources/suggest');
       expect(fetchMock.mock.calls[0][0].params?.type).toBe('metrics');
       expect(fetchMock.mock.calls[0][0].params?.q).toBe('pew');
       expect(results).not.toBe(null);
@@ -57,8 +57,8 @@ describe('opentsdb', () => {
       const results = await ds.metricFindQuery('tag_names(cpu)');
 
       expect(fetchMock).toHaveBeenCalledTimes(1);
-      expect(fetchMock.mock.calls[0][0].url).toBe('/api/search/lookup');
-      expect(fetchMock.mock.calls[0][0].params?.m).toBe('cpu');
+      expect(fetchMock.mock.calls[0][0].url).toBe('/api/datasources/1/resources/tag-keys');
+      expect(fetchMock.mock.calls[0][0].params?.metric).toBe('cpu');
       expect(results).not.toBe(null);
     });
 
@@ -68,8 +68,9 @@ describe('opentsdb', () => {
       const results = await ds.metricFindQuery('tag_values(cpu, hostname)');
 
       expect(fetchMock).toHaveBeenCalledTimes(1);
-      expect(fetchMock.mock.calls[0][0].url).toBe('/api/search/lookup');
-      expect(fetchMock.mock.calls[0][0].params?.m).toBe('cpu{hostname=*}');
+      expect(fetchMock.mock.calls[0][0].url).toBe('/api/datasources/1/resources/tag-values');
+      expect(fetchMock.mock.calls[0][0].params?.metric).toBe('cpu');
+      expect(fetchMock.mock.calls[0][0].params?.keys).toBe('hostname');
       expect(results).not.toBe(null);
     });
 
@@ -79,8 +80,9 @@ describe('opentsdb', () => {
       const results = await ds.metricFindQuery('tag_values(cpu, hostname, env=$env)');
 
       expect(fetchMock).toHaveBeenCalledTimes(1);
-      expect(fetchMock.mock.calls[0][0].url).toBe('/api/search/lookup');
-      expect(fetchMock.mock.calls[0][0].params?.m).toBe('cpu{hostname=*,env=$env}');
+      expect(fetchMock.mock.calls[0][0].url).toBe('/api/datasources/1/resources/tag-values');
+      expect(fetchMock.mock.calls[0][0].params?.metric).toBe('cpu');
+      expect(fetchMock.mock.calls[0][0].params?.keys).toBe('hostname, env=$env');
       expect(results).not.toBe(null);
     });
 
@@ -90,8 +92,9 @@ describe('opentsdb', () => {
       const results = await ds.metricFindQuery('tag_values(cpu, hostname, env=$env, region=$region)');
 
       expect(fetchMock).toHaveBeenCalledTimes(1);
-      expect(fetchMock.mock.calls[0][0].url).toBe('/api/search/lookup');
-      expect(fetchMock.mock.calls[0][0].params?.m).toBe('cpu{hostname=*,env=$env,region=$region}');
+      expect(fetchMock.mock.calls[0][0].url).toBe('/api/datasources/1/resources/tag-values');
+      expect(fetchMock.mock.calls[0][0].params?.metric).toBe('cpu');
+      expect(fetchMock.mock.calls[0][0].params?.keys).toBe('hostname, env=$env, region=$region');
       expect(results).not.toBe(null);
     });
 
@@ -101,7 +104,7 @@ describe('opentsdb', () => {
       const results = await ds.metricFindQuery('suggest_tagk(foo)');
 
       expect(fetchMock).toHaveBeenCalledTimes(1);
-      expect(fetchMock.mock.calls[0][0].url).toBe('/api/suggest');
+      expect(fetchMock.mock.calls[0][0].url).toBe('/api/datasources/1/resources/suggest');
       expect(fetchMock.mock.calls[0][0].params?.type).toBe('tagk');
       expect(fetchMock.mock.calls[0][0].params?.q).toBe('foo');
       expect(results).not.toBe(null);
@@ -113,7 +116,7 @@ describe('opentsdb', () => {
       const results = await ds.metricFindQuery('suggest_tagv(bar)');
 
       expect(fetchMock).toHaveBeenCalledTimes(1);
-      expect(fetchMock.mock.calls[0][0].url).toBe('/api/suggest');
+      expect(fetchMock.mock.calls[0][0].url).toBe('/api/datasources/1/resources/suggest');
       expect(fetchMock.mock.calls[0][0].params?.type).toBe('tagv');
       expect(fetchMock.mock.calls[0][0].params?.q).toBe('bar');
       expect(results).not.toBe(null);
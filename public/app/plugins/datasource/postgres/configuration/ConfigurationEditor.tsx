@@ -66,6 +66,10 @@ export const PostgresConfigEditor = (props: DataSourcePluginOptionsEditorProps<P
     updateDatasourcePluginJsonDataOption(props, 'timescaledb', event.currentTarget.checked);
   };
 
+  const onBindVariablesChanged = (event: SyntheticEvent<HTMLInputElement>) => {
+    updateDatasourcePluginJsonDataOption(props, 'bindVariables', event.currentTarget.checked);
+  };
+
   const onDSOptionChanged = (property: keyof PostgresOptions) => {
     return (event: SyntheticEvent<HTMLInputElement>) => {
       onOptionsChange({ ...options, ...{ [property]: event.currentTarget.value } });
@@ -267,6 +271,24 @@ export const PostgresConfigEditor = (props: DataSourcePluginOptionsEditorProps<P
             onChange={onUpdateDatasourceJsonDataOption(props, 'timeInterval')}
           ></Input>
         </InlineField>
+        <InlineField
+          tooltip={
+            <span>
+              Send the values of dashboard variables to the database as bound query parameters instead of interpolating
+              them into the SQL. Variables used as identifiers or in <code>LIMIT</code> need the raw format, for example
+              <code>{'${table:raw}'}</code>.
+            </span>
+          }
+          labelWidth={labelWidthShort}
+          htmlFor="bindVariables"
+          label="Bind variables"
+        >
+          <InlineSwitch
+            id="bindVariables"
+            onChange={onBindVariablesChanged}
+            value={jsonData.bindVariables || false}
+          ></InlineSwitch>
+        </InlineField>
       </FieldSet>
 
       <Alert title="User Permission" severity="info">
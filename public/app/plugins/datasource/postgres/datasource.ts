@@ -5,6 +5,7 @@ import { map, catchError } from 'rxjs/operators';
 import { AnnotationEvent, DataSourceInstanceSettings, MetricFindValue, ScopedVars, TimeRange } from '@grafana/data';
 import { BackendDataSourceResponse, DataSourceWithBackend, FetchResponse, getBackendSrv } from '@grafana/runtime';
 import { toTestingStatus } from '@grafana/runtime/src/utils/queryResponse';
+import { bindTemplateVariables } from 'app/features/plugins/sql/utils/templateVariables';
 import { getTemplateSrv, TemplateSrv } from 'app/features/templating/template_srv';
 import PostgresQueryModel from 'app/plugins/datasource/postgres/postgres_query_model';
 
@@ -20,6 +21,7 @@ export class PostgresDatasource extends DataSourceWithBackend<PostgresQuery, Pos
   responseParser: ResponseParser;
   queryModel: PostgresQueryModel;
   interval: string;
+  bindVariables: boolean;
 
   constructor(
     instanceSettings: DataSourceInstanceSettings<PostgresOptions>,
@@ -33,6 +35,7 @@ export class PostgresDatasource extends DataSourceWithBackend<PostgresQuery, Pos
     this.queryModel = new PostgresQueryModel({});
     const settingsData = instanceSettings.jsonData || ({} as PostgresOptions);
     this.interval = settingsData.timeInterval || '1m';
+    this.bindVariables = settingsData.bindVariables ?? false;
   }
 
   interpolateVariable = (value: string | string[], variable: { multi: any; includeAll: any }) => {
@@ -79,11 +82,19 @@ export class PostgresDatasource extends DataSourceWithBackend<PostgresQuery, Pos
 
   applyTemplateVariables(target: PostgresQuery, scopedVars: ScopedVars): Record<string, any> {
     const queryModel = new PostgresQueryModel(target, this.templateSrv, scopedVars);
+    const { rawSql, variables } = bindTemplateVariables(
+      queryModel.render(),
+      this.templateSrv,
+      scopedVars,
+      (sql) => this.templateSrv.replace(sql, scopedVars, queryModel.interpolateQueryStr),
+      this.bindVariables
+    );
     return {
       refId: target.refId,
       datasource: this.getRef(),
-      rawSql: queryModel.render(this.interpolateVariable as any),
+      rawSql,
       format: target.format,
+      variables,
     };
   }
 
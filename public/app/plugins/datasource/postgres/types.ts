@@ -24,6 +24,7 @@ export interface PostgresOptions extends DataSourceJsonData, SQLConnectionLimits
   sslKeyFile: string;
   postgresVersion: number;
   timescaledb: boolean;
+  bindVariables?: boolean;
 }
 
 export interface SecureJsonData {
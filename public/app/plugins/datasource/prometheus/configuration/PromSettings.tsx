@@ -107,6 +107,52 @@ export const PromSettings = (props: Props) => {
             />
           </InlineField>
         </div>
+        <div className="gf-form">
+          <InlineField
+            labelWidth={28}
+            label="Incremental querying"
+            tooltip="Split range queries longer than a day into daily chunks and cache the chunks that can no longer change, so that dashboard refreshes only query the most recent data. Requires the prometheusStreamingJSONParser feature toggle."
+          >
+            <InlineSwitch
+              value={options.jsonData.incrementalQuerying ?? false}
+              onChange={onUpdateDatasourceJsonDataOptionChecked(props, 'incrementalQuerying')}
+            />
+          </InlineField>
+        </div>
+        {options.jsonData.incrementalQuerying && (
+          <div className="gf-form">
+            <InlineField
+              labelWidth={28}
+              label="Query overlap window"
+              tooltip="Samples more recent than this window can still change, for example because of late samples or recording rules, and are always queried. Defaults to 10m."
+            >
+              <Input
+                className="width-6"
+                value={options.jsonData.incrementalQueryOverlapWindow}
+                onChange={onChangeHandler('incrementalQueryOverlapWindow', options, onOptionsChange)}
+                spellCheck={false}
+                placeholder="10m"
+              />
+            </InlineField>
+          </div>
+        )}
+        {options.jsonData.incrementalQuerying && (
+          <div className="gf-form">
+            <InlineField
+              labelWidth={28}
+              label="Query cache size (MB)"
+              tooltip="Upper bound of the estimated memory used by the cached chunks of this data source. Chunks are queried without caching while the cache is full. Defaults to 64."
+            >
+              <Input
+                className="width-6"
+                value={options.jsonData.incrementalQueryCacheSizeMB}
+                onChange={onChangeHandler('incrementalQueryCacheSizeMB', options, onOptionsChange)}
+                spellCheck={false}
+                placeholder="64"
+              />
+            </InlineField>
+          </div>
+        )}
         <div className="gf-form-inline">
           <div className="gf-form max-width-30">
             <FormField
@@ -29,6 +29,9 @@ export interface PromOptions extends DataSourceJsonData {
   directUrl?: string;
   customQueryParameters?: string;
   disableMetricsLookup?: boolean;
+  incrementalQuerying?: boolean;
+  incrementalQueryOverlapWindow?: string;
+  incrementalQueryCacheSizeMB?: string;
   exemplarTraceIdDestinations?: ExemplarTraceIdDestination[];
 }
 
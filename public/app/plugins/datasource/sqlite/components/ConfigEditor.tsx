@@ -0,0 +1,81 @@
+import React, { SyntheticEvent } from 'react';
+
+import {
+  DataSourcePluginOptionsEditorProps,
+  onUpdateDatasourceJsonDataOption,
+  updateDatasourcePluginJsonDataOption,
+} from '@grafana/data';
+import { FieldSet, InlineField, InlineSwitch, Input } from '@grafana/ui';
+
+import { SQLiteOptions } from '../types';
+
+export const ConfigEditor = (props: DataSourcePluginOptionsEditorProps<SQLiteOptions>) => {
+  const { options, onOptionsChange } = props;
+  const jsonData = options.jsonData;
+
+  const onDatabaseChange = (event: SyntheticEvent<HTMLInputElement>) => {
+    onOptionsChange({ ...options, database: event.currentTarget.value });
+  };
+
+  const onBindVariablesChange = (event: SyntheticEvent<HTMLInputElement>) => {
+    updateDatasourcePluginJsonDataOption(props, 'bindVariables', event.currentTarget.checked);
+  };
+
+  const onNumberChange = (property: 'maxRows' | 'queryTimeout') => {
+    return (event: SyntheticEvent<HTMLInputElement>) => {
+      const value = parseInt(event.currentTarget.value, 10);
+      updateDatasourcePluginJsonDataOption(props, property, isNaN(value) ? undefined : value);
+    };
+  };
+
+  return (
+    <>
+      <FieldSet label="Database">
+        <InlineField
+          label="Path"
+          labelWidth={18}
+          tooltip="Path of the database file relative to one of the directories allowed for SQLite data sources, or storage://<path> for a file uploaded to Grafana storage."
+        >
+          <Input
+            width={40}
+            value={options.database || ''}
+            placeholder="metrics.db"
+            onChange={onDatabaseChange}
+          />
+        </InlineField>
+        <InlineField
+          label="Bind variables"
+          labelWidth={18}
+          tooltip="Send the values of dashboard variables as bound query parameters instead of interpolating them into the SQL. Variables used as identifiers or in LIMIT need the raw format, for example ${table:raw}."
+        >
+          <InlineSwitch value={jsonData.bindVariables || false} onChange={onBindVariablesChange} />
+        </InlineField>
+      </FieldSet>
+      <FieldSet label="Limits">
+        <InlineField label="Max rows" labelWidth={18} tooltip="Rows above this number are dropped. Empty means no limit.">
+          <Input type="number" width={20} value={jsonData.maxRows ?? ''} onChange={onNumberChange('maxRows')} />
+        </InlineField>
+        <InlineField
+          label="Query timeout"
+          labelWidth={18}
+          tooltip="Time in seconds after which a query is cancelled. Empty means no timeout."
+        >
+          <Input
+            type="number"
+            width={20}
+            value={jsonData.queryTimeout ?? ''}
+            onChange={onNumberChange('queryTimeout')}
+          />
+        </InlineField>
+        <InlineField label="Min time interval" labelWidth={18} tooltip="A lower limit for the $__interval variable.">
+          <Input
+            width={20}
+            value={jsonData.timeInterval || ''}
+            placeholder="1m"
+            onChange={onUpdateDatasourceJsonDataOption(props, 'timeInterval')}
+          />
+        </InlineField>
+      </FieldSet>
+    </>
+  );
+};
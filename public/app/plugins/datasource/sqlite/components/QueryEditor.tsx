@@ -0,0 +1,48 @@
+import React from 'react';
+
+import { QueryEditorProps, SelectableValue } from '@grafana/data';
+import { CodeEditor, InlineField, RadioButtonGroup } from '@grafana/ui';
+
+import { SQLiteDatasource } from '../datasource';
+import { SQLiteOptions, SQLiteQuery, SQLiteQueryFormat } from '../types';
+
+const formats: Array<SelectableValue<SQLiteQueryFormat>> = [
+  { label: 'Time series', value: SQLiteQueryFormat.TimeSeries },
+  { label: 'Table', value: SQLiteQueryFormat.Table },
+];
+
+type Props = QueryEditorProps<SQLiteDatasource, SQLiteQuery, SQLiteOptions>;
+
+export function QueryEditor({ query, onChange, onRunQuery }: Props) {
+  const onSqlChange = (rawSql: string) => {
+    if (rawSql !== query.rawSql) {
+      onChange({ ...query, rawSql });
+      onRunQuery();
+    }
+  };
+
+  const onFormatChange = (format: SQLiteQueryFormat) => {
+    onChange({ ...query, format });
+    onRunQuery();
+  };
+
+  return (
+    <>
+      <InlineField label="Format" labelWidth={12}>
+        <RadioButtonGroup
+          options={formats}
+          value={query.format ?? SQLiteQueryFormat.TimeSeries}
+          onChange={onFormatChange}
+        />
+      </InlineField>
+      <CodeEditor
+        language="sql"
+        height={200}
+        value={query.rawSql ?? ''}
+        onBlur={onSqlChange}
+        showMiniMap={false}
+        showLineNumbers={true}
+      />
+    </>
+  );
+}
@@ -0,0 +1,47 @@
+import { DataSourceInstanceSettings, ScopedVars } from '@grafana/data';
+import { DataSourceWithBackend, getTemplateSrv, TemplateSrv } from '@grafana/runtime';
+import { SQLVariable } from 'app/features/plugins/sql/types';
+import { bindTemplateVariables } from 'app/features/plugins/sql/utils/templateVariables';
+import { VariableWithMultiSupport } from 'app/features/variables/types';
+
+import { SQLiteOptions, SQLiteQuery, SQLiteQueryFormat } from './types';
+
+export class SQLiteDatasource extends DataSourceWithBackend<SQLiteQuery, SQLiteOptions> {
+  bindVariables: boolean;
+
+  constructor(
+    instanceSettings: DataSourceInstanceSettings<SQLiteOptions>,
+    private readonly templateSrv: TemplateSrv = getTemplateSrv()
+  ) {
+    super(instanceSettings);
+    this.bindVariables = instanceSettings.jsonData?.bindVariables ?? false;
+  }
+
+  filterQuery(query: SQLiteQuery): boolean {
+    return !query.hide && !!query.rawSql;
+  }
+
+  applyTemplateVariables(query: SQLiteQuery, scopedVars: ScopedVars): SQLiteQuery & { variables: SQLVariable[] } {
+    const { rawSql, variables } = bindTemplateVariables(
+      query.rawSql ?? '',
+      this.templateSrv,
+      scopedVars,
+      (sql) => this.templateSrv.replace(sql, scopedVars, this.interpolateVariable),
+      this.bindVariables
+    );
+    return {
+      ...query,
+      rawSql,
+      format: query.format ?? SQLiteQueryFormat.TimeSeries,
+      variables,
+    };
+  }
+
+  interpolateVariable = (value: string | string[], variable: VariableWithMultiSupport) => {
+    const quote = (v: string) => "'" + v.replace(/'/g, "''") + "'";
+    if (Array.isArray(value)) {
+      return value.map(quote).join(',');
+    }
+    return variable.multi || variable.includeAll ? quote(value) : value;
+  };
+}
@@ -0,0 +1,10 @@
+import { DataSourcePlugin } from '@grafana/data';
+
+import { ConfigEditor } from './components/ConfigEditor';
+import { QueryEditor } from './components/QueryEditor';
+import { SQLiteDatasource } from './datasource';
+import { SQLiteOptions, SQLiteQuery } from './types';
+
+export const plugin = new DataSourcePlugin<SQLiteDatasource, SQLiteQuery, SQLiteOptions>(SQLiteDatasource)
+  .setQueryEditor(QueryEditor)
+  .setConfigEditor(ConfigEditor);
@@ -0,0 +1,18 @@
+import { DataQuery, DataSourceJsonData } from '@grafana/data';
+
+export enum SQLiteQueryFormat {
+  Table = 'table',
+  TimeSeries = 'time_series',
+}
+
+export interface SQLiteQuery extends DataQuery {
+  rawSql?: string;
+  format?: SQLiteQueryFormat;
+}
+
+export interface SQLiteOptions extends DataSourceJsonData {
+  timeInterval?: string;
+  maxRows?: number;
+  queryTimeout?: number;
+  bindVariables?: boolean;
+}
@@ -76,6 +76,7 @@ class TempoQueryFieldComponent extends React.PureComponent<Props> {
       { value: 'traceId', label: 'TraceID' },
       { value: 'upload', label: 'JSON file' },
       { value: 'serviceMap', label: 'Service Graph' },
+      { value: 'traceqlMetrics', label: 'Metrics' },
     ];
 
     if (!datasource?.search?.hide) {
@@ -167,6 +168,26 @@ class TempoQueryFieldComponent extends React.PureComponent<Props> {
             </InlineField>
           </InlineFieldRow>
         )}
+        {query.queryType === 'traceqlMetrics' && (
+          <InlineFieldRow>
+            <InlineField label="TraceQL" labelWidth={14} grow>
+              <QueryField
+                query={query.query}
+                onChange={(val) => {
+                  onChange({
+                    ...query,
+                    query: val,
+                    queryType: 'traceqlMetrics',
+                  });
+                }}
+                onBlur={this.props.onBlur}
+                onRunQuery={this.props.onRunQuery}
+                placeholder={'Enter a TraceQL metrics query, e.g. {status=error} | rate() (run with Shift+Enter)'}
+                portalOrigin="tempo"
+              />
+            </InlineField>
+          </InlineFieldRow>
+        )}
         {query.queryType === 'serviceMap' && (
           <ServiceGraphSection graphDatasourceUid={graphDatasourceUid} query={query} onChange={onChange} />
         )}
@@ -53,7 +53,15 @@ import {
 } from './resultTransformer';
 
 // search = Loki search, nativeSearch = Tempo search for backwards compatibility
-export type TempoQueryType = 'search' | 'traceId' | 'serviceMap' | 'upload' | 'nativeSearch' | 'clear';
+// traceqlMetrics = TraceQL metrics query, run by the backend
+export type TempoQueryType =
+  | 'search'
+  | 'traceId'
+  | 'serviceMap'
+  | 'upload'
+  | 'nativeSearch'
+  | 'traceqlMetrics'
+  | 'clear';
 
 export interface TempoJsonData extends DataSourceJsonData {
   tracesToLogs?: TraceToLogsOptions;
@@ -254,6 +262,18 @@ export class TempoDatasource extends DataSourceWithBackend<TempoQuery, TempoJson
       }
     }
 
+    if (targets.traceqlMetrics?.length > 0) {
+      reportInteraction('grafana_traces_traceql_metrics_queried', {
+        datasourceType: 'tempo',
+        app: options.app ?? '',
+      });
+
+      const validTargets = targets.traceqlMetrics.filter((t) => t.query);
+      if (validTargets.length) {
+        subQueries.push(super.query({ ...options, targets: validTargets }));
+      }
+    }
+
     if (targets.traceId?.length > 0) {
       reportInteraction('grafana_traces_traceID_queried', {
         datasourceType: 'tempo',
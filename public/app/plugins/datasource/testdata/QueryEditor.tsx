@@ -14,12 +14,13 @@ import { GrafanaLiveEditor } from './components/GrafanaLiveEditor';
 import { NodeGraphEditor } from './components/NodeGraphEditor';
 import { PredictablePulseEditor } from './components/PredictablePulseEditor';
 import { RawFrameEditor } from './components/RawFrameEditor';
+import { RecordedEditor } from './components/RecordedEditor';
 import { SimulationQueryEditor } from './components/SimulationQueryEditor';
 import { USAQueryEditor, usaQueryModes } from './components/USAQueryEditor';
 import { defaultCSVWaveQuery, defaultPulseQuery, defaultQuery } from './constants';
 import { TestDataDataSource } from './datasource';
 import { defaultStreamQuery } from './runStreams';
-import { CSVWave, NodesQuery, TestDataQuery, USAQuery } from './types';
+import { CSVWave, NodesQuery, RecordingQuery, TestDataQuery, USAQuery } from './types';
 
 const showLabelsFor = ['random_walk', 'predictable_pulse'];
 const endpoints = [
@@ -159,6 +160,10 @@ export const QueryEditor = ({ query, datasource, onChange, onRunQuery }: Props)
     onUpdate({ ...query, usa });
   };
 
+  const onRecordingChange = (recording?: RecordingQuery) => {
+    onUpdate({ ...query, recording });
+  };
+
   const onCSVWaveChange = (csvWave?: CSVWave[]) => {
     onUpdate({ ...query, csvWave });
   };
@@ -271,6 +276,7 @@ export const QueryEditor = ({ query, datasource, onChange, onRunQuery }: Props)
       )}
 
       {scenarioId === 'usa' && <USAQueryEditor onChange={onUSAStatsChange} query={query.usa ?? {}} />}
+      {scenarioId === 'recorded' && <RecordedEditor onChange={onRecordingChange} query={query.recording ?? {}} />}
       {scenarioId === 'grafana_api' && (
         <InlineField labelWidth={14} label="Endpoint">
           <Select
@@ -0,0 +1,39 @@
+import React from 'react';
+
+import { InlineField, InlineFieldRow, InlineSwitch, Input } from '@grafana/ui';
+
+import { RecordingQuery } from '../types';
+
+export interface Props {
+  onChange: (value: RecordingQuery) => void;
+  query: RecordingQuery;
+}
+
+export function RecordedEditor({ query, onChange }: Props) {
+  return (
+    <InlineFieldRow>
+      <InlineField labelWidth={14} label="Recording" tooltip="Name of a recording made with /api/admin/testdata/recordings">
+        <Input
+          width={32}
+          value={query.name ?? ''}
+          placeholder="node-cpu"
+          onChange={(v) => onChange({ ...query, name: v.currentTarget.value })}
+        />
+      </InlineField>
+      <InlineField label="Query" tooltip="Recorded query to replay, defaults to the query with the same refId">
+        <Input
+          width={8}
+          value={query.refId ?? ''}
+          placeholder="A"
+          onChange={(v) => onChange({ ...query, refId: v.currentTarget.value })}
+        />
+      </InlineField>
+      <InlineField label="Shift to now" tooltip="Move the recorded times so the recording ends with the time range">
+        <InlineSwitch
+          value={!!query.timeShift}
+          onChange={(v) => onChange({ ...query, timeShift: v.currentTarget.checked })}
+        />
+      </InlineField>
+    </InlineFieldRow>
+  );
+}
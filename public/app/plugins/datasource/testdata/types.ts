@@ -25,6 +25,7 @@ export interface TestDataQuery extends DataQuery {
   csvContent?: string;
   rawFrameContent?: string;
   usa?: USAQuery;
+  recording?: RecordingQuery;
   errorType?: 'server_panic' | 'frontend_exception' | 'frontend_observable';
 }
 
@@ -67,6 +68,12 @@ export interface CSVWave {
   labels?: string;
 }
 
+export interface RecordingQuery {
+  name?: string;
+  refId?: string; // recorded query, defaults to the refId of the query
+  timeShift?: boolean;
+}
+
 export interface USAQuery {
   mode?: string;
   period?: string;